@@ -1,12 +1,15 @@
 package api
 
 import (
-	"crypto/tls"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"os/exec"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
@@ -33,6 +36,8 @@ func Init() {
 			TLSKey     string `yaml:"tls_key"`
 			UnixListen string `yaml:"unix_listen"`
 
+			ACME acmeConfig `yaml:"acme"`
+
 			AllowPaths []string `yaml:"allow_paths"`
 		} `yaml:"api"`
 	}
@@ -58,6 +63,12 @@ func Init() {
 	HandleFunc("api/exit", exitHandler)
 	HandleFunc("api/restart", restartHandler)
 	HandleFunc("api/log", logHandler)
+	HandleFunc("api/log/level", logLevelHandler)
+
+	// close endless responses (log streams), so server.Shutdown don't wait for them
+	app.OnShutdown(app.ShutdownConsumers, func(ctx context.Context) {
+		close(shutdown)
+	})
 
 	Handler = http.DefaultServeMux // 4th
 
@@ -73,6 +84,10 @@ func Init() {
 		Handler = middlewareLog(Handler) // 1st
 	}
 
+	if err := initTLS(cfg.Mod.TLSCert, cfg.Mod.TLSKey, &cfg.Mod.ACME); err != nil {
+		log.Error().Err(err).Caller().Send()
+	}
+
 	if cfg.Mod.Listen != "" {
 		_, port, _ := net.SplitHostPort(cfg.Mod.Listen)
 		Port, _ = strconv.Atoi(port)
@@ -85,8 +100,8 @@ func Init() {
 	}
 
 	// Initialize the HTTPS server
-	if cfg.Mod.TLSListen != "" && cfg.Mod.TLSCert != "" && cfg.Mod.TLSKey != "" {
-		go tlsListen("tcp", cfg.Mod.TLSListen, cfg.Mod.TLSCert, cfg.Mod.TLSKey)
+	if cfg.Mod.TLSListen != "" && tlsConfig != nil {
+		go tlsListen("tcp", cfg.Mod.TLSListen)
 	}
 }
 
@@ -99,30 +114,17 @@ func listen(network, address string) {
 
 	log.Info().Str("addr", address).Msg("[api] listen")
 
-	server := http.Server{
+	server := &http.Server{
 		Handler:           Handler,
 		ReadHeaderTimeout: 5 * time.Second, // Example: Set to 5 seconds
 	}
-	if err = server.Serve(ln); err != nil {
+	onShutdown(server)
+	if err = server.Serve(ln); err != nil && err != http.ErrServerClosed {
 		log.Fatal().Err(err).Msg("[api] serve")
 	}
 }
 
-func tlsListen(network, address, certFile, keyFile string) {
-	var cert tls.Certificate
-	var err error
-	if strings.IndexByte(certFile, '\n') < 0 && strings.IndexByte(keyFile, '\n') < 0 {
-		// check if file path
-		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
-	} else {
-		// if text file content
-		cert, err = tls.X509KeyPair([]byte(certFile), []byte(keyFile))
-	}
-	if err != nil {
-		log.Error().Err(err).Caller().Send()
-		return
-	}
-
+func tlsListen(network, address string) {
 	ln, err := net.Listen(network, address)
 	if err != nil {
 		log.Error().Err(err).Msg("[api] tls listen")
@@ -133,16 +135,29 @@ func tlsListen(network, address, certFile, keyFile string) {
 
 	server := &http.Server{
 		Handler:           Handler,
-		TLSConfig:         &tls.Config{Certificates: []tls.Certificate{cert}},
+		TLSConfig:         tlsConfig.Clone(), // server adds HTTP/2 to NextProtos
 		ReadHeaderTimeout: 5 * time.Second,
 	}
-	if err = server.ServeTLS(ln, "", ""); err != nil {
+	onShutdown(server)
+	if err = server.ServeTLS(ln, "", ""); err != nil && err != http.ErrServerClosed {
 		log.Fatal().Err(err).Msg("[api] tls serve")
 	}
 }
 
+// onShutdown - stop accepting new requests and wait for active requests.
+// HTTP streams finish when streams module closes their consumers in the same phase.
+func onShutdown(server *http.Server) {
+	app.OnShutdown(app.ShutdownConsumers, func(ctx context.Context) {
+		if err := server.Shutdown(ctx); err != nil {
+			log.Debug().Err(err).Msg("[api] shutdown")
+		}
+	})
+}
+
 var Port int
 
+var shutdown = make(chan struct{})
+
 const (
 	MimeJSON = "application/json"
 	MimeText = "text/plain"
@@ -273,17 +288,57 @@ func restartHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// check before shutdown, because after it the process has nothing to serve,
+	// Windows has no exec bit
+	if info, err := os.Stat(path); err != nil || runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		http.Error(w, "can't restart: "+path, http.StatusInternalServerError)
+		return
+	}
+
 	log.Debug().Msgf("[api] restart %s", path)
 
-	go syscall.Exec(path, os.Args, os.Environ())
+	go func() {
+		// stop consumers and producers, so exec/ffmpeg processes won't be orphaned
+		app.Shutdown()
+
+		if runtime.GOOS == "windows" {
+			// Windows doesn't support exec, so start new process and exit
+			cmd := exec.Command(path, os.Args[1:]...)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err = cmd.Start(); err == nil {
+				os.Exit(0)
+			}
+		} else {
+			err = syscall.Exec(path, os.Args, os.Environ())
+		}
+
+		// all modules are stopped, so exit and let the service manager restart us
+		log.Error().Err(err).Msg("[api] restart")
+		os.Exit(1)
+	}()
 }
 
 func logHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
-		// Send current state of the log file immediately
+		if r.Header.Get("Accept") == "text/event-stream" {
+			logStreamHandler(w, r)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/jsonlines")
-		_, _ = app.MemoryLog.WriteTo(w)
+
+		query := r.URL.Query()
+		if len(query) == 0 {
+			// Send current state of the log file immediately
+			_, _ = app.MemoryLog.WriteTo(w)
+			return
+		}
+
+		for _, line := range app.MemoryLog.Lines(app.ParseLogFilter(query)) {
+			_, _ = w.Write(line)
+		}
 	case "DELETE":
 		app.MemoryLog.Reset()
 		Response(w, "OK", "text/plain")
@@ -292,6 +347,109 @@ func logHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// logStreamHandler - Server-Sent Events with new log lines. Each event has line number as id,
+// so the client continues from the last received line after reconnect (Last-Event-ID).
+func logStreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := app.ParseLogFilter(query)
+
+	ch := make(chan app.LogLine, 100)
+	last, stop := app.MemoryLog.Follow(ch)
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// cursor - number of the last line sent to the client
+	cursor := last
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		cursor, _ = strconv.ParseUint(id, 10, 64)
+	} else if query.Has("history") {
+		cursor = 0
+	}
+
+	send := func(line app.LogLine) error {
+		cursor = line.Seq
+		if !filter.Match(line.Data) {
+			return nil
+		}
+		_, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", line.Seq, bytes.TrimSpace(line.Data))
+		return err
+	}
+
+	// buffered lines and lines dropped for slow client, until line with number upto
+	sendSince := func(upto uint64) error {
+		for _, line := range app.MemoryLog.Since(cursor) {
+			if line.Seq >= upto {
+				break
+			}
+			if err := send(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := sendSince(last + 1); err != nil {
+		return
+	}
+
+	flusher.Flush()
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line := <-ch:
+			if line.Seq <= cursor {
+				continue // already sent from the buffer
+			}
+			if line.Seq > cursor+1 {
+				if err := sendSince(line.Seq); err != nil {
+					return
+				}
+			}
+			if err := send(line); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// keepalive for proxies
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-shutdown:
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+func logLevelHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		ResponseJSON(w, app.GetLogLevels())
+	case "POST", "PUT":
+		query := r.URL.Query()
+		if err := app.SetLogLevel(query.Get("module"), query.Get("level")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ResponseJSON(w, app.GetLogLevels())
+	default:
+		http.Error(w, "Method not allowed", http.StatusBadRequest)
+	}
+}
+
 type Source struct {
 	ID       string `json:"id,omitempty"`
 	Name     string `json:"name,omitempty"`
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type acmeConfig struct {
+	Domains     []string `yaml:"domains"`
+	Email       string   `yaml:"email"`
+	Directory   string   `yaml:"directory"`    // default Let's Encrypt
+	DirectoryCA string   `yaml:"directory_ca"` // custom CA for directory (ex. local test server)
+	CacheDir    string   `yaml:"cache_dir"`    // account and certificates cache
+}
+
+var tlsConfig *tls.Config
+var tlsServerName string // default domain for ACME certificates
+
+// TLSConfig - HTTPS certificates for other servers (ex. RTSP over TLS) with their own
+// ALPN protocols list. Return nil if TLS isn't configured.
+func TLSConfig(nextProtos ...string) *tls.Config {
+	if tlsConfig == nil {
+		return nil
+	}
+	config := tlsConfig.Clone()
+	config.NextProtos = nextProtos
+	return config
+}
+
+// Certificate - current HTTPS certificate for other protocols (ex. WebRTC DTLS).
+// Return nil if TLS isn't configured.
+func Certificate() *tls.Certificate {
+	if tlsConfig == nil {
+		return nil
+	}
+
+	cert, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: tlsServerName})
+	if err != nil {
+		log.Warn().Err(err).Msg("[api] tls certificate")
+		return nil
+	}
+
+	if cert.Leaf == nil && len(cert.Certificate) > 0 {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil
+		}
+	}
+
+	return cert
+}
+
+// initTLS - init certificates from ACME (Let's Encrypt) or from static cert/key
+func initTLS(cert, key string, acmeCfg *acmeConfig) error {
+	if len(acmeCfg.Domains) > 0 {
+		manager, err := newACMEManager(acmeCfg)
+		if err != nil {
+			return err
+		}
+
+		// support TLS-ALPN-01 challenge
+		tlsConfig = manager.TLSConfig()
+		tlsServerName = acmeCfg.Domains[0]
+
+		// support HTTP-01 challenge on main API listener
+		Handler = manager.HTTPHandler(Handler)
+		return nil
+	}
+
+	if cert == "" || key == "" {
+		return nil
+	}
+
+	c := &staticCert{cert: cert, key: key}
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	tlsConfig = &tls.Config{GetCertificate: c.GetCertificate}
+	return nil
+}
+
+func newACMEManager(cfg *acmeConfig) (*autocert.Manager, error) {
+	if cfg.CacheDir == "" {
+		cfg.CacheDir = "acme"
+		// keep cache near config file
+		if app.ConfigPath != "" {
+			cfg.CacheDir = filepath.Join(filepath.Dir(app.ConfigPath), cfg.CacheDir)
+		}
+	}
+
+	client := &acme.Client{DirectoryURL: cfg.Directory, UserAgent: app.UserAgent}
+
+	if cfg.DirectoryCA != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(readPEM(cfg.DirectoryCA)) {
+			return nil, errors.New("acme: wrong directory_ca")
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		client.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	log.Info().Strs("domains", cfg.Domains).Str("cache", cfg.CacheDir).Msg("[api] acme")
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Client:     client,
+		Email:      cfg.Email,
+	}, nil
+}
+
+// staticCert - certificate from files or raw PEM content.
+// Files are checked for changes, so renewed certificate is used without restart.
+type staticCert struct {
+	cert, key string
+
+	tlsCert  *tls.Certificate
+	certTime time.Time
+	keyTime  time.Time
+	checked  time.Time
+	mu       sync.Mutex
+}
+
+func (c *staticCert) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now := time.Now(); now.Sub(c.checked) > time.Minute {
+		c.checked = now
+		if err := c.reload(); err != nil {
+			log.Warn().Err(err).Msg("[api] tls reload")
+		}
+	}
+
+	return c.tlsCert, nil
+}
+
+func (c *staticCert) load() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reload()
+}
+
+func (c *staticCert) reload() error {
+	if isPEM(c.cert) || isPEM(c.key) {
+		if c.tlsCert != nil {
+			return nil // content can't change
+		}
+		cert, err := tls.X509KeyPair([]byte(c.cert), []byte(c.key))
+		if err != nil {
+			return err
+		}
+		c.tlsCert = &cert
+		return nil
+	}
+
+	// cert and key can be renewed with separate writes
+	certInfo, err := os.Stat(c.cert)
+	if err != nil {
+		return err
+	}
+
+	keyInfo, err := os.Stat(c.key)
+	if err != nil {
+		return err
+	}
+
+	if c.tlsCert != nil && certInfo.ModTime().Equal(c.certTime) && keyInfo.ModTime().Equal(c.keyTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.cert, c.key)
+	if err != nil {
+		return err
+	}
+
+	if c.tlsCert != nil {
+		log.Info().Str("path", c.cert).Msg("[api] tls reload")
+	}
+
+	c.tlsCert = &cert
+	c.certTime = certInfo.ModTime()
+	c.keyTime = keyInfo.ModTime()
+	return nil
+}
+
+// isPEM - check if value is file content and not file path
+func isPEM(s string) bool {
+	return strings.IndexByte(s, '\n') >= 0
+}
+
+func readPEM(s string) []byte {
+	if isPEM(s) {
+		return []byte(s)
+	}
+	b, _ := os.ReadFile(s)
+	return b
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticCertReload(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+
+	writeCert := func(name string, modTime time.Time) {
+		certPEM, keyPEM := testCert(t, name, nil, nil)
+		require.Nil(t, os.WriteFile(certPath, certPEM, 0600))
+		require.Nil(t, os.WriteFile(keyPath, keyPEM, 0600))
+		require.Nil(t, os.Chtimes(certPath, modTime, modTime))
+	}
+
+	getName := func(c *staticCert) string {
+		cert, err := c.GetCertificate(nil)
+		require.Nil(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.Nil(t, err)
+		return leaf.Subject.CommonName
+	}
+
+	now := time.Now()
+	writeCert("first", now.Add(-time.Hour))
+
+	c := &staticCert{cert: certPath, key: keyPath}
+	require.Nil(t, c.load())
+	require.Equal(t, "first", getName(c))
+
+	// renewed certificate
+	writeCert("second", now)
+
+	// files are checked not often than once per minute
+	require.Equal(t, "first", getName(c))
+	c.checked = time.Time{}
+	require.Equal(t, "second", getName(c))
+
+	// raw PEM content instead of paths
+	certPEM, keyPEM := testCert(t, "inline", nil, nil)
+	c = &staticCert{cert: string(certPEM), key: string(keyPEM)}
+	require.Nil(t, c.load())
+	require.Equal(t, "inline", getName(c))
+
+	// other servers don't get HTTPS protocols
+	tlsConfig = &tls.Config{GetCertificate: c.GetCertificate, NextProtos: []string{"h2", "http/1.1"}}
+	t.Cleanup(func() { tlsConfig = nil })
+
+	require.Nil(t, TLSConfig().NextProtos)
+	require.Equal(t, []string{"h2", "http/1.1"}, tlsConfig.NextProtos)
+	require.Equal(t, "inline", Certificate().Leaf.Subject.CommonName)
+}
+
+func TestACME(t *testing.T) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.Nil(t, err)
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	// minimal ACME directory server, domain is already authorized
+	var server *httptest.Server
+	var leaf []byte
+
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+
+		switch r.URL.Path {
+		case "/directory":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"newNonce":   server.URL + "/nonce",
+				"newAccount": server.URL + "/account",
+				"newOrder":   server.URL + "/order",
+			})
+		case "/nonce":
+		case "/account":
+			w.Header().Set("Location", server.URL+"/account/1")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"status":"valid"}`))
+		case "/order":
+			w.Header().Set("Location", server.URL+"/order/1")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"status":"ready","finalize":"` + server.URL + `/finalize"}`))
+		case "/finalize":
+			var req struct {
+				CSR string `json:"csr"`
+			}
+			require.Nil(t, json.Unmarshal(jwsPayload(t, r), &req))
+			b, _ := base64.RawURLEncoding.DecodeString(req.CSR)
+			csr, err := x509.ParseCertificateRequest(b)
+			require.Nil(t, err)
+
+			certPEM, _ := testCert(t, csr.DNSNames[0], csr.PublicKey, &tlsSigner{caCert, caKey})
+			block, _ := pem.Decode(certPEM)
+			leaf = block.Bytes
+
+			_, _ = w.Write([]byte(`{"status":"valid","certificate":"` + server.URL + `/cert"}`))
+		case "/cert":
+			w.Header().Set("Content-Type", "application/pem-certificate-chain")
+			_ = pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: leaf})
+			_ = pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	directoryCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	manager, err := newACMEManager(&acmeConfig{
+		Domains:     []string{"camera.example.com"},
+		Directory:   server.URL + "/directory",
+		DirectoryCA: string(directoryCA),
+		CacheDir:    t.TempDir(),
+	})
+	require.Nil(t, err)
+
+	cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "camera.example.com"})
+	require.Nil(t, err)
+	require.Equal(t, leaf, cert.Certificate[0])
+
+	// domain not from config
+	_, err = manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.example.com"})
+	require.NotNil(t, err)
+}
+
+type tlsSigner struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// testCert - self-signed certificate or signed with parent
+func testCert(t *testing.T, name string, pub any, parent *tlsSigner) (certPEM, keyPEM []byte) {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	var signer *ecdsa.PrivateKey
+	parentCert := tmpl
+
+	if parent != nil {
+		signer, parentCert = parent.key, parent.cert
+	} else {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.Nil(t, err)
+		pub, signer = &key.PublicKey, key
+
+		b, err := x509.MarshalECPrivateKey(key)
+		require.Nil(t, err)
+		keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b})
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parentCert, pub, signer)
+	require.Nil(t, err)
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return
+}
+
+// jwsPayload - decode payload from ACME request without signature check
+func jwsPayload(t *testing.T, r *http.Request) []byte {
+	var jws struct {
+		Payload string `json:"payload"`
+	}
+	require.Nil(t, json.NewDecoder(r.Body).Decode(&jws))
+	b, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	require.Nil(t, err)
+	return b
+}
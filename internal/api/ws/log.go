@@ -0,0 +1,57 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sync"
+
+	"github.com/AlexxIT/go2rtc/internal/app"
+)
+
+// logHandler - stream new log lines with optional filter:
+// {"type":"log","value":{"level":"debug","module":"rtsp,webrtc","stream":"camera1"}}
+func logHandler(tr *Transport, msg *Message) error {
+	var v struct {
+		Level  string `json:"level"`
+		Module string `json:"module"`
+		Stream string `json:"stream"`
+	}
+	if msg.Value != nil {
+		if err := msg.Unmarshal(&v); err != nil {
+			return err
+		}
+	}
+
+	filter := app.ParseLogFilter(url.Values{
+		"level": {v.Level}, "module": {v.Module}, "stream": {v.Stream},
+	})
+
+	ch := make(chan app.LogLine, 100)
+	done := make(chan struct{})
+	stopLog := sync.OnceFunc(func() { close(done) })
+
+	// only one log subscription per connection
+	tr.WithContext(func(ctx map[any]any) {
+		if stopPrev, ok := ctx["log"].(func()); ok {
+			stopPrev()
+		}
+		ctx["log"] = stopLog
+	})
+
+	tr.OnClose(stopLog)
+
+	_, stop := app.MemoryLog.Follow(ch)
+	defer stop()
+
+	for {
+		select {
+		case line := <-ch:
+			if filter.Match(line.Data) {
+				tr.Write(&Message{Type: "log", Value: json.RawMessage(bytes.TrimSpace(line.Data))})
+			}
+		case <-done:
+			return nil
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -30,6 +31,10 @@ func Init() {
 	initWS(cfg.Mod.Origin)
 
 	api.HandleFunc("api/ws", apiWS)
+
+	HandleFunc("log", logHandler)
+
+	app.OnShutdown(app.ShutdownConsumers, closeAll)
 }
 
 var log zerolog.Logger
@@ -107,6 +112,16 @@ func apiWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	connsMu.Lock()
+	conns[ws] = struct{}{}
+	connsMu.Unlock()
+
+	defer func() {
+		connsMu.Lock()
+		delete(conns, ws)
+		connsMu.Unlock()
+	}()
+
 	tr := &Transport{Request: r}
 	tr.OnWrite(func(msg any) error {
 		_ = ws.SetWriteDeadline(time.Now().Add(time.Second * 5))
@@ -119,8 +134,17 @@ func apiWS(w http.ResponseWriter, r *http.Request) {
 	})
 
 	for {
+		msgType, data, err := ws.ReadMessage()
+		if err == nil && msgType == websocket.BinaryMessage {
+			tr.binary(data)
+			continue
+		}
+
 		msg := new(Message)
-		if err = ws.ReadJSON(msg); err != nil {
+		if err == nil {
+			err = json.Unmarshal(data, msg)
+		}
+		if err != nil {
 			if !websocket.IsCloseError(err, websocket.CloseNoStatusReceived) {
 				log.Trace().Err(err).Caller().Send()
 			}
@@ -132,7 +156,7 @@ func apiWS(w http.ResponseWriter, r *http.Request) {
 
 		if handler := wsHandlers[msg.Type]; handler != nil {
 			go func() {
-				if err = handler(tr, msg); err != nil {
+				if err := handler(tr, msg); err != nil {
 					errMsg := creds.SecretString(err.Error())
 					tr.Write(&Message{Type: "error", Value: msg.Type + ": " + errMsg})
 				}
@@ -145,6 +169,23 @@ func apiWS(w http.ResponseWriter, r *http.Request) {
 
 var wsUp *websocket.Upgrader
 
+var conns = map[*websocket.Conn]struct{}{}
+var connsMu sync.Mutex
+
+// closeAll - send close frame to all clients, so they can reconnect after restart
+func closeAll(ctx context.Context) {
+	connsMu.Lock()
+	defer connsMu.Unlock()
+
+	deadline, _ := ctx.Deadline()
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutdown")
+
+	for ws := range conns {
+		_ = ws.WriteControl(websocket.CloseMessage, msg, deadline)
+		_ = ws.Close()
+	}
+}
+
 type Transport struct {
 	Request *http.Request
 
@@ -156,6 +197,7 @@ type Transport struct {
 
 	onChange func()
 	onWrite  func(msg any) error
+	onBinary func(data []byte)
 	onClose  []func()
 }
 
@@ -189,6 +231,23 @@ func (t *Transport) OnChange(f func()) {
 	t.mx.Unlock()
 }
 
+// OnBinary - handler for binary messages from the client (ex. microphone frames)
+func (t *Transport) OnBinary(f func(data []byte)) {
+	t.mx.Lock()
+	t.onBinary = f
+	t.mx.Unlock()
+}
+
+func (t *Transport) binary(data []byte) {
+	t.mx.Lock()
+	f := t.onBinary
+	t.mx.Unlock()
+
+	if f != nil {
+		f(data)
+	}
+}
+
 func (t *Transport) OnClose(f func()) {
 	t.mx.Lock()
 	if t.closed {
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"runtime"
 	"runtime/debug"
+	"time"
 )
 
 var (
@@ -80,13 +81,18 @@ func Init() {
 
 	var cfg struct {
 		Mod struct {
-			Modules []string `yaml:"modules"`
+			Modules         []string      `yaml:"modules"`
+			ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
 		} `yaml:"app"`
 	}
 
 	LoadConfig(&cfg)
 
 	Modules = cfg.Mod.Modules
+
+	if cfg.Mod.ShutdownTimeout > 0 {
+		ShutdownTimeout = cfg.Mod.ShutdownTimeout
+	}
 }
 
 func readRevisionTime() (revision, vcsTime string) {
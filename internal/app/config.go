@@ -79,6 +79,7 @@ func initConfig(confs flagConfig) {
 			}
 
 			loadEnv(data)
+			loadSecrets(data)
 			data = creds.ReplaceVars(data)
 			configs = append(configs, data)
 		}
@@ -1,10 +1,16 @@
 package app
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"io"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/AlexxIT/go2rtc/pkg/creds"
 	"github.com/mattn/go-isatty"
@@ -16,15 +22,76 @@ var MemoryLog = newBuffer()
 func GetLogger(module string) zerolog.Logger {
 	Logger.Trace().Str("module", module).Msgf("[log] init")
 
+	lvl := Logger.GetLevel()
+
 	if s, ok := modules[module]; ok {
-		lvl, err := zerolog.ParseLevel(s)
-		if err == nil {
-			return Logger.Level(lvl)
+		var err error
+		if lvl, err = zerolog.ParseLevel(s); err != nil {
+			lvl = Logger.GetLevel()
+			Logger.Warn().Err(err).Caller().Send()
 		}
-		Logger.Warn().Err(err).Caller().Send()
 	}
 
-	return Logger
+	// real level check is inside moduleLevel, so it can be changed in runtime
+	return Logger.With().Str("module", module).Logger().
+		Level(zerolog.TraceLevel).Sample(getModuleLevel(module, lvl))
+}
+
+// moduleLevel - runtime log level for module, used as zerolog.Sampler,
+// so disabled events are dropped before they are created, like with usual level
+type moduleLevel struct {
+	level atomic.Int32
+}
+
+func (m *moduleLevel) Sample(lvl zerolog.Level) bool {
+	return int32(lvl) >= m.level.Load()
+}
+
+var levels = map[string]*moduleLevel{}
+var levelsMu sync.Mutex
+
+func getModuleLevel(module string, lvl zerolog.Level) *moduleLevel {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	m, ok := levels[module]
+	if !ok {
+		m = &moduleLevel{}
+		m.level.Store(int32(lvl))
+		levels[module] = m
+	}
+	return m
+}
+
+// SetLogLevel - change module log level in runtime without editing config
+func SetLogLevel(module, level string) error {
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	levelsMu.Lock()
+	m, ok := levels[module]
+	levelsMu.Unlock()
+
+	if !ok {
+		return errors.New("log: unknown module: " + module)
+	}
+
+	m.level.Store(int32(lvl))
+	return nil
+}
+
+// GetLogLevels - current log levels of all modules
+func GetLogLevels() map[string]string {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	info := make(map[string]string, len(levels))
+	for module, m := range levels {
+		info[module] = zerolog.Level(m.level.Load()).String()
+	}
+	return info
 }
 
 // initLogger support:
@@ -121,6 +188,15 @@ type circularBuffer struct {
 	chunks [][]byte
 	r, w   int
 	mu     sync.Mutex
+
+	seq       uint64 // number of the last written line
+	followers map[chan LogLine]struct{}
+}
+
+// LogLine - log line with sequence number, so readers can continue without gaps and duplicates
+type LogLine struct {
+	Seq  uint64
+	Data []byte
 }
 
 func newBuffer() *circularBuffer {
@@ -158,15 +234,84 @@ func (b *circularBuffer) Write(p []byte) (n int, err error) {
 	}
 
 	b.chunks[b.w] = append(b.chunks[b.w], p...)
+	b.seq++
+
+	if len(b.followers) > 0 {
+		line := LogLine{Seq: b.seq, Data: append([]byte(nil), p...)}
+		for ch := range b.followers {
+			select {
+			case ch <- line:
+			default: // drop line for slow follower
+			}
+		}
+	}
 	b.mu.Unlock()
 	return
 }
 
+// Follow - send new log lines to channel until stop is called. Return the number of
+// the last line already in the buffer. Lines are dropped if channel is full,
+// so slow reader can't block logging, and can be restored with Since.
+func (b *circularBuffer) Follow(ch chan LogLine) (last uint64, stop func()) {
+	b.mu.Lock()
+	if b.followers == nil {
+		b.followers = map[chan LogLine]struct{}{}
+	}
+	b.followers[ch] = struct{}{}
+	last = b.seq
+	b.mu.Unlock()
+
+	return last, func() {
+		b.mu.Lock()
+		delete(b.followers, ch)
+		b.mu.Unlock()
+	}
+}
+
+// Since - snapshot of buffered log lines with number greater than seq
+func (b *circularBuffer) Since(seq uint64) (lines []LogLine) {
+	buf := bytes.NewBuffer(nil)
+	last, _, _ := b.writeTo(buf)
+
+	data := bytes.SplitAfter(buf.Bytes(), []byte{'\n'})
+	if n := len(data); n > 0 && len(data[n-1]) == 0 {
+		data = data[:n-1]
+	}
+
+	// the last line in the buffer has number last
+	first := last - uint64(len(data)) + 1
+	for i, line := range data {
+		if n := first + uint64(i); n > seq {
+			lines = append(lines, LogLine{Seq: n, Data: line})
+		}
+	}
+	return
+}
+
+// Lines - snapshot of log lines matched to filter
+func (b *circularBuffer) Lines(filter *LogFilter) (lines [][]byte) {
+	buf := bytes.NewBuffer(nil)
+	_, _ = b.WriteTo(buf)
+
+	for _, line := range bytes.SplitAfter(buf.Bytes(), []byte{'\n'}) {
+		if len(line) > 0 && filter.Match(line) {
+			lines = append(lines, line)
+		}
+	}
+	return
+}
+
 func (b *circularBuffer) WriteTo(w io.Writer) (n int64, err error) {
+	_, n, err = b.writeTo(w)
+	return
+}
+
+func (b *circularBuffer) writeTo(w io.Writer) (seq uint64, n int64, err error) {
 	buf := make([]byte, 0, chunkCount*chunkSize)
 
 	// use temp buffer inside mutex because w.Write can take some time
 	b.mu.Lock()
+	seq = b.seq
 	for i := b.r; ; {
 		buf = append(buf, b.chunks[i]...)
 		if i == b.w {
@@ -179,7 +324,7 @@ func (b *circularBuffer) WriteTo(w io.Writer) (n int64, err error) {
 	b.mu.Unlock()
 
 	nn, err := w.Write(buf)
-	return int64(nn), err
+	return seq, int64(nn), err
 }
 
 func (b *circularBuffer) Reset() {
@@ -189,3 +334,57 @@ func (b *circularBuffer) Reset() {
 	b.w = 0
 	b.mu.Unlock()
 }
+
+// LogFilter - server-side filter for JSON log lines
+type LogFilter struct {
+	Level   zerolog.Level
+	Modules []string
+	Stream  string
+}
+
+// ParseLogFilter support query params:
+// - level:  minimal level (trace, debug, info, warn, error)
+// - module: comma separated list of modules (api, rtsp, webrtc...)
+// - stream: stream name, for lines with the same `stream` field
+func ParseLogFilter(query url.Values) *LogFilter {
+	filter := &LogFilter{Level: zerolog.TraceLevel}
+
+	if s := query.Get("level"); s != "" {
+		if lvl, err := zerolog.ParseLevel(s); err == nil {
+			filter.Level = lvl
+		}
+	}
+
+	if s := query.Get("module"); s != "" {
+		filter.Modules = strings.Split(s, ",")
+	}
+
+	filter.Stream = query.Get("stream")
+
+	return filter
+}
+
+func (f *LogFilter) Match(line []byte) bool {
+	if f == nil || f.Level <= zerolog.TraceLevel && f.Modules == nil && f.Stream == "" {
+		return true
+	}
+
+	var v struct {
+		Level  string `json:"level"`
+		Module string `json:"module"`
+		Stream string `json:"stream"`
+	}
+	if err := json.Unmarshal(line, &v); err != nil {
+		return false
+	}
+
+	if f.Stream != "" && v.Stream != f.Stream {
+		return false
+	}
+
+	if lvl, err := zerolog.ParseLevel(v.Level); err != nil || lvl < f.Level {
+		return false
+	}
+
+	return f.Modules == nil || slices.Contains(f.Modules, v.Module)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Shutdown phases. Phases run one by one, functions inside one phase run in parallel.
+const (
+	ShutdownListeners = iota // stop accepting new connections
+	ShutdownConsumers        // notify and close consumers (RTSP, WebSocket, HTTP streams)
+	ShutdownProducers        // stop producers (RTSP TEARDOWN, kill exec/ffmpeg)
+	shutdownPhases
+)
+
+var ShutdownTimeout = 5 * time.Second
+
+var shutdownFuncs [shutdownPhases][]func(ctx context.Context)
+var shutdownMu sync.Mutex
+
+// OnShutdown - register function for graceful shutdown. Function should return when ctx is done.
+func OnShutdown(phase int, f func(ctx context.Context)) {
+	shutdownMu.Lock()
+	shutdownFuncs[phase] = append(shutdownFuncs[phase], f)
+	shutdownMu.Unlock()
+}
+
+// Shutdown - stop all modules in order, wait no more than ShutdownTimeout for each phase.
+// Used on exit signal and before restart.
+func Shutdown() {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+
+	Logger.Info().Msg("[app] shutdown")
+
+	for phase, funcs := range shutdownFuncs {
+		if !shutdownPhase(funcs) {
+			Logger.Warn().Int("phase", phase).Msg("[app] shutdown timeout")
+		}
+	}
+
+	// all functions are done, so we don't need them anymore
+	shutdownFuncs = [shutdownPhases][]func(ctx context.Context){}
+}
+
+func shutdownPhase(funcs []func(ctx context.Context)) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, f := range funcs {
+		wg.Add(1)
+		go func() {
+			f(ctx)
+			wg.Done()
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
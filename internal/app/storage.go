@@ -1,7 +1,9 @@
 package app
 
 import (
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/AlexxIT/go2rtc/pkg/creds"
 	"github.com/AlexxIT/go2rtc/pkg/yaml"
@@ -29,6 +31,42 @@ func loadEnv(data []byte) {
 	storage.mu.Unlock()
 }
 
+func loadSecrets(data []byte) {
+	var cfg struct {
+		Secrets struct {
+			Dotenv []string `yaml:"dotenv"`
+			Dirs   []string `yaml:"dirs"`
+			HTTP   []struct {
+				URL     string            `yaml:"url"`
+				Headers map[string]string `yaml:"headers"`
+				TTL     time.Duration     `yaml:"ttl"`
+			} `yaml:"http"`
+		} `yaml:"secrets"`
+	}
+
+	// resolve ${VAULT_TOKEN} and other vars that can be used in secrets config
+	if err := yaml.Unmarshal(creds.ReplaceVars(data), &cfg); err != nil {
+		return
+	}
+
+	for _, path := range cfg.Secrets.Dotenv {
+		creds.AddProvider(creds.NewDotenv(path))
+	}
+
+	for _, dir := range cfg.Secrets.Dirs {
+		creds.AddProvider(creds.NewSecretsDir(dir))
+	}
+
+	for _, item := range cfg.Secrets.HTTP {
+		header := make(http.Header, len(item.Headers))
+		for k, v := range item.Headers {
+			header.Set(k, v)
+			creds.AddSecret(v)
+		}
+		creds.AddProvider(creds.NewHTTP(item.URL, header, item.TTL))
+	}
+}
+
 var storage *envStorage
 
 type envStorage struct {
@@ -2,6 +2,7 @@ package ffmpeg
 
 import (
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
@@ -51,6 +52,8 @@ func Init() {
 
 	streams.HandleFunc("ffmpeg", NewProducer)
 
+	streams.TranscodeFunc(transcodeSource)
+
 	api.HandleFunc("api/ffmpeg", apiFFmpeg)
 
 	device.Init(defaults["bin"])
@@ -151,6 +154,42 @@ var defaults = map[string]string{
 
 var log zerolog.Logger
 
+// transcodeSource - FFmpeg source for on-demand transcoding of the stream to the codec,
+// used when consumer codecs don't match any stream source
+func transcodeSource(name string, codec *core.Codec) string {
+	var query string
+
+	switch codec.Name {
+	case core.CodecH264:
+		query = "#video=h264"
+	case core.CodecH265:
+		query = "#video=h265"
+	case core.CodecJPEG:
+		query = "#video=mjpeg"
+	case core.CodecOpus:
+		query = "#audio=opus"
+	case core.CodecAAC:
+		query = "#audio=aac"
+	case core.CodecPCMA, core.CodecPCMU, core.CodecPCM, core.CodecPCML:
+		audio := strings.ToLower(codec.Name)
+		if codec.Name == core.CodecPCM {
+			audio = "pcm"
+		}
+		if rate := audio + "/" + strconv.Itoa(int(codec.ClockRate)); defaults[rate] != "" {
+			audio = rate
+		}
+		query = "#audio=" + audio
+	default:
+		return ""
+	}
+
+	if _, err := Version(); err != nil {
+		return ""
+	}
+
+	return "ffmpeg:" + name + query
+}
+
 // configTemplate - return template from config (defaults) if exist or return raw template
 func configTemplate(template string) string {
 	if s := defaults[template]; s != "" {
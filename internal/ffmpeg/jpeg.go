@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"os/exec"
+	"strings"
 
 	"github.com/AlexxIT/go2rtc/internal/ffmpeg/hardware"
 	"github.com/AlexxIT/go2rtc/pkg/core"
@@ -45,10 +46,14 @@ func parseQuery(query url.Values) *ffmpeg.Args {
 
 	var width = -1
 	var height = -1
-	var r, hw string
+	var r, hw, crop, aspect string
 
 	for k, v := range query {
 		switch k {
+		case "crop":
+			crop = v[0]
+		case "aspect":
+			aspect = v[0]
 		case "width", "w":
 			width = core.Atoi(v[0])
 		case "height", "h":
@@ -60,6 +65,16 @@ func parseQuery(query url.Values) *ffmpeg.Args {
 		}
 	}
 
+	if ss := strings.Split(crop, ":"); len(ss) == 4 {
+		args.AddFilter(fmt.Sprintf(
+			"crop=%d:%d:%d:%d", core.Atoi(ss[0]), core.Atoi(ss[1]), core.Atoi(ss[2]), core.Atoi(ss[3]),
+		)) // w:h:x:y
+	}
+
+	if sx, sy, ok := strings.Cut(aspect, ":"); ok {
+		args.AddFilter(fmt.Sprintf("scale=iw*%d/%d:ih", core.Atoi(sx), core.Atoi(sy)))
+	}
+
 	if width > 0 || height > 0 {
 		args.AddFilter(fmt.Sprintf("scale=%d:%d", width, height))
 	}
@@ -0,0 +1,338 @@
+package hls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/mp4"
+)
+
+// handlerDASH - new DASH session, redirects to the session manifest,
+// so manifest updates and segments use the same session
+func handlerDASH(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		return
+	}
+
+	query := r.URL.Query()
+
+	stream := streams.Get(query.Get("src"))
+	if stream == nil {
+		http.Error(w, api.StreamNotFound, http.StatusNotFound)
+		return
+	}
+
+	medias := mp4.ParseQuery(query)
+	if medias == nil {
+		medias = mp4.NewConsumer(nil).Medias // default medias
+	}
+
+	// separate consumer for each media kind, because DASH players support only
+	// CMAF tracks (one track in the AdaptationSet), not muxed segments
+	var group []*Session
+	var err error
+
+	for _, media := range medias {
+		cons := mp4.NewConsumer([]*core.Media{media})
+		cons.FormatName = "dash/fmp4"
+		cons.WithRequest(r)
+		if d := stream.Display(); d != nil {
+			cons.Rotate, cons.ScaleX, cons.ScaleY, cons.Crop = d.Rotate, d.ScaleX, d.ScaleY, d.Crop
+		}
+
+		if err = stream.AddConsumer(cons); err != nil {
+			log.Debug().Err(err).Msgf("[hls] skip dash media=%s", media)
+			continue
+		}
+
+		session := NewSessionLL(cons)
+		startSession(session, stream, cons)
+		group = append(group, session)
+	}
+
+	if group == nil {
+		log.Error().Err(err).Caller().Send()
+		http.Error(w, err.Error(), streams.StatusCode(err))
+		return
+	}
+
+	for _, session := range group {
+		session.group = group
+	}
+
+	location := "dash/manifest.mpd?id=" + group[0].id
+	if query.Has("ll") {
+		location += "&ll"
+	}
+
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+func handlerManifest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/dash+xml")
+
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		return
+	}
+
+	query := r.URL.Query()
+
+	sid := query.Get("id")
+	sessionsMu.RLock()
+	session := sessions[sid]
+	sessionsMu.RUnlock()
+	if session == nil || session.ll == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	session.touch(keepalive + session.ll.Timeout())
+
+	group := session.group
+	if group == nil {
+		group = []*Session{session}
+	}
+
+	var segmenters []*Segmenter
+	var codecs []string
+	for _, session := range group {
+		segmenters = append(segmenters, session.ll)
+		codecs = append(codecs, session.codecs())
+	}
+
+	data, err := Manifest(segmenters, codecs, query.Has("ll"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if _, err = w.Write(data); err != nil {
+		log.Error().Err(err).Caller().Send()
+		return
+	}
+
+	for _, session := range group {
+		session.sent()
+	}
+}
+
+func handlerSegmentDASH(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "video/iso.segment")
+
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		return
+	}
+
+	query := r.URL.Query()
+
+	sid := query.Get("id")
+	sessionsMu.RLock()
+	session := sessions[sid]
+	sessionsMu.RUnlock()
+	if session == nil || session.ll == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	session.touch(keepalive + session.ll.Timeout())
+
+	if err := session.ll.WriteSegment(w, core.Atoi(query.Get("n"))); err != nil {
+		log.Warn().Err(err).Msgf("[hls] can't get segment %s", r.URL.RawQuery)
+		http.NotFound(w, r)
+	}
+}
+
+// Manifest - DASH MPD with one AdaptationSet for each segmenter (track) and SegmentTimeline,
+// blocks until the first segment of each track is finished
+func Manifest(segmenters []*Segmenter, codecs []string, ll bool) ([]byte, error) {
+	var zero time.Time // availabilityStartTime, the latest start of the tracks
+	var target int
+	var depth float64
+	var ended bool
+
+	for i, s := range segmenters {
+		if !s.wait(func() bool { return s.hasPart(0, -1) }) {
+			s.mu.Lock()
+		}
+		if s.current == nil {
+			s.mu.Unlock()
+			return nil, errors.New("hls: no segments")
+		}
+
+		if s.zero.After(zero) {
+			zero = s.zero
+		}
+		target = max(target, s.target)
+		if i == 0 || s.stats.Duration < depth {
+			depth = s.stats.Duration
+		}
+		ended = ended || s.ended
+
+		s.mu.Unlock()
+	}
+
+	const timeFormat = "2006-01-02T15:04:05.000Z"
+
+	sb := &strings.Builder{}
+
+	now := time.Now().UTC()
+
+	fmt.Fprintf(sb, `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-live:2011,urn:mpeg:dash:profile:cmaf:2019" type="dynamic" availabilityStartTime="%s" publishTime="%s"`,
+		zero.UTC().Format(timeFormat), now.Format(timeFormat),
+	)
+
+	if !ended {
+		fmt.Fprintf(sb, ` minimumUpdatePeriod="PT%dS"`, target)
+	}
+
+	fmt.Fprintf(sb, ` minBufferTime="PT%dS" timeShiftBufferDepth="PT%.3fS" maxSegmentDuration="PT%dS">
+`, target, depth, target)
+
+	if ll {
+		s := segmenters[0]
+		fmt.Fprintf(sb, `  <ServiceDescription id="0">
+    <Latency target="%d"/>
+  </ServiceDescription>
+`, int(1000*(s.segment+s.part)))
+	}
+
+	sb.WriteString(`  <Period id="0" start="PT0S">
+`)
+
+	for i, s := range segmenters {
+		s.mu.Lock()
+		s.adaptationSet(sb, i, codecs[i], zero, ll)
+		s.mu.Unlock()
+	}
+
+	fmt.Fprintf(sb, `  </Period>
+  <UTCTiming schemeIdUri="urn:mpeg:dash:utc:direct:2014" value="%s"/>
+</MPD>
+`, now.Format(timeFormat))
+
+	return []byte(sb.String()), nil
+}
+
+// adaptationSet - one track with SegmentTimeline, media time is shifted with presentationTimeOffset,
+// because tracks start at different time (video from the keyframe)
+func (s *Segmenter) adaptationSet(sb *strings.Builder, id int, codecs string, zero time.Time, ll bool) {
+	mimeType := "audio/mp4"
+	if strings.Contains(codecs, "avc1.") || strings.Contains(codecs, "hvc1.") ||
+		strings.Contains(codecs, "vp09.") || strings.Contains(codecs, "av01.") {
+		mimeType = "video/mp4"
+	}
+
+	fmt.Fprintf(sb, `    <AdaptationSet id="%d" mimeType="%s" segmentAlignment="true" startWithSAP="1">
+      <Representation id="%d" bandwidth="192000" codecs="%s">
+`, id, mimeType, id, codecs)
+
+	first := s.current.msn
+	if len(s.segments) > 0 {
+		first = s.segments[0].msn
+	}
+
+	offset := zero.Sub(s.zero).Milliseconds()
+
+	fmt.Fprintf(sb, `        <SegmentTemplate timescale="1000" presentationTimeOffset="%d" startNumber="%d" initialization="init.mp4?id=%s" media="segment.m4s?id=%s&amp;n=$Number$"`,
+		offset, first, s.id, s.id,
+	)
+
+	// segment in progress with the duration of the previous one (camera GOP),
+	// it is available before it is finished and is sent with chunked transfer
+	current := ll && !s.ended
+	duration := s.segment
+	if n := len(s.segments); n > 0 {
+		duration = s.segments[n-1].duration
+	}
+
+	if current {
+		fmt.Fprintf(sb, ` availabilityTimeOffset="%.3f" availabilityTimeComplete="false"`, duration-s.part)
+	}
+
+	sb.WriteString(`>
+          <SegmentTimeline>
+`)
+
+	for _, seg := range s.segments {
+		// time and duration from the media time, so rounding errors don't accumulate
+		t := math.Round(1000 * seg.time)
+		d := math.Round(1000*(seg.time+seg.duration)) - t
+		fmt.Fprintf(sb, "            <S t=\"%d\" d=\"%d\"/>\n", int64(t), int64(d))
+	}
+
+	if current {
+		t := math.Round(1000 * s.current.time)
+		d := math.Round(1000*(s.current.time+duration)) - t
+		fmt.Fprintf(sb, "            <S t=\"%d\" d=\"%d\"/>\n", int64(t), int64(d))
+	}
+
+	sb.WriteString(`          </SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+`)
+}
+
+// WriteSegment - write segment parts as soon as they are finished (chunked transfer for LL-DASH)
+func (s *Segmenter) WriteSegment(w io.Writer, msn int) error {
+	if !s.wait(func() bool { return s.current != nil && msn <= s.current.msn }) {
+		return errors.New("hls: segment not ready")
+	}
+
+	seg := s.find(msn)
+	if seg == nil {
+		s.mu.Unlock()
+		return errors.New("hls: segment not found")
+	}
+
+	if seg != s.current {
+		s.mu.Unlock()
+
+		// finished segment from memory or from disk
+		if b := s.Segment(msn); b != nil {
+			_, err := w.Write(b)
+			return err
+		}
+		return errors.New("hls: segment not found")
+	}
+
+	s.mu.Unlock()
+
+	for i := 0; ; i++ {
+		if !s.wait(func() bool { return s.hasPart(msn, i) }) {
+			return nil // playlist ended or timeout
+		}
+
+		if i >= len(seg.parts) {
+			s.mu.Unlock()
+			return nil // segment finished
+		}
+
+		data := seg.parts[i].data
+		s.mu.Unlock()
+
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package hls
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/mp4"
+)
+
+// DVR - shared LL-HLS session for all viewers of the stream, with the rewind window
+type DVR struct {
+	*mp4.Consumer
+	session *Session
+}
+
+var dvrs = map[string]*DVR{}
+var dvrsMu sync.Mutex
+
+func (d *DVR) MarshalJSON() ([]byte, error) {
+	stats := d.session.ll.Stats()
+	v := struct {
+		*core.Connection
+		DVR *SegmenterStats `json:"dvr"`
+	}{
+		Connection: &d.Connection,
+		DVR:        &stats,
+	}
+	return json.Marshal(v)
+}
+
+// AddDVR - start recording the stream to the DVR window, in memory or in the path folder
+func AddDVR(name string, window time.Duration, path string) error {
+	stream := streams.Get(name)
+	if stream == nil {
+		return errors.New("hls: stream not found: " + name)
+	}
+
+	if path != "" {
+		// separate folder for each stream, clean segments from the previous run
+		path = filepath.Join(path, name)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return err
+		}
+		files, _ := filepath.Glob(filepath.Join(path, "*.m4s"))
+		for _, file := range files {
+			_ = os.Remove(file)
+		}
+	}
+
+	cons := mp4.NewConsumer([]*core.Media{
+		{
+			Kind:      core.KindVideo,
+			Direction: core.DirectionSendonly,
+			Codecs:    []*core.Codec{{Name: core.CodecH264}, {Name: core.CodecH265}},
+		},
+		{
+			Kind:      core.KindAudio,
+			Direction: core.DirectionSendonly,
+			Codecs:    []*core.Codec{{Name: core.CodecAAC}},
+		},
+	})
+	cons.FormatName = "hls/dvr"
+
+	d := &DVR{Consumer: cons}
+	if err := stream.AddConsumer(d); err != nil {
+		return err
+	}
+
+	d.session = NewSessionLL(cons)
+	d.session.ll.dvr = window.Seconds()
+	d.session.ll.path = path
+
+	sessionsMu.Lock()
+	sessions[d.session.id] = d.session
+	sessionsMu.Unlock()
+
+	dvrsMu.Lock()
+	dvrs[name] = d
+	dvrsMu.Unlock()
+
+	go d.session.Run()
+
+	return nil
+}
+
+// runDVR - start DVR with retries, because the stream may be not ready on start
+func runDVR(name string, window time.Duration, path string) {
+	// wait streams and preloads
+	delay := time.Second
+
+	for {
+		time.Sleep(delay)
+
+		err := AddDVR(name, window, path)
+		if err == nil {
+			return
+		}
+
+		delay = min(2*delay, time.Minute)
+		log.Warn().Err(err).Msgf("[hls] can't start dvr for %s, retry in %s", name, delay)
+	}
+}
+
+// GetDVR - session of the stream DVR, nil if DVR not enabled
+func GetDVR(name string) *Session {
+	dvrsMu.Lock()
+	defer dvrsMu.Unlock()
+	if d := dvrs[name]; d != nil {
+		return d.session
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package hls
 
 import (
+	"context"
 	"net/http"
 	"sync"
 	"time"
@@ -16,8 +17,25 @@ import (
 )
 
 func Init() {
+	var conf struct {
+		Mod Config `yaml:"hls"`
+	}
+
+	// default config
+	conf.Mod.Window = 6
+	conf.Mod.PartDuration = 500 * time.Millisecond
+	conf.Mod.SegmentDuration = 2 * time.Second
+
+	app.LoadConfig(&conf)
+
+	cfg = conf.Mod
+
 	log = app.GetLogger("hls")
 
+	for name, window := range cfg.DVR {
+		go runDVR(name, window, cfg.DVRPath)
+	}
+
 	api.HandleFunc("api/stream.m3u8", handlerStream)
 	api.HandleFunc("api/hls/playlist.m3u8", handlerPlaylist)
 
@@ -27,10 +45,58 @@ func Init() {
 	// HLS (fMP4)
 	api.HandleFunc("api/hls/init.mp4", handlerInit)
 	api.HandleFunc("api/hls/segment.m4s", handlerSegmentMP4)
+	api.HandleFunc("api/hls/part.m4s", handlerPart)
+
+	// DASH (fMP4) with same segments as LL-HLS
+	api.HandleFunc("api/stream.mpd", handlerDASH)
+	api.HandleFunc("api/dash/manifest.mpd", handlerManifest)
+	api.HandleFunc("api/dash/init.mp4", handlerInit)
+	api.HandleFunc("api/dash/segment.m4s", handlerSegmentDASH)
 
 	ws.HandleFunc("hls", handlerWSHLS)
+
+	// tell players that the stream has ended, before HTTP server stops
+	app.OnShutdown(app.ShutdownListeners, func(ctx context.Context) {
+		var waits []<-chan struct{}
+
+		sessionsMu.RLock()
+		for _, session := range sessions {
+			ch := session.End()
+			if session.alive != nil { // DVR sessions may have no players
+				waits = append(waits, ch)
+			}
+		}
+		sessionsMu.RUnlock()
+
+		// give players time to reload the playlist with ENDLIST, but no more than
+		// half of the shutdown timeout, so HTTP server has time to finish requests
+		timer := time.NewTimer(app.ShutdownTimeout / 2)
+		defer timer.Stop()
+
+		for _, ch := range waits {
+			select {
+			case <-ch:
+			case <-timer.C:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
 }
 
+// Config - LL-HLS options, segments are always aligned to keyframes
+type Config struct {
+	Window          int           `yaml:"window"`           // segments in the playlist
+	PartDuration    time.Duration `yaml:"part_duration"`    // partial segment target duration
+	SegmentDuration time.Duration `yaml:"segment_duration"` // min segment duration
+
+	DVR     map[string]time.Duration `yaml:"dvr"`      // stream name and rewind window
+	DVRPath string                   `yaml:"dvr_path"` // folder for DVR segments, memory if empty
+}
+
+var cfg Config
+
 var log zerolog.Logger
 
 const keepalive = 5 * time.Second
@@ -49,7 +115,25 @@ func handlerStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	src := r.URL.Query().Get("src")
+	query := r.URL.Query()
+	if len(query["src"]) > 1 || query.Has("variants") {
+		handlerMultivariant(w, r)
+		return
+	}
+
+	if query.Has("dvr") {
+		session := GetDVR(query.Get("src"))
+		if session == nil {
+			http.Error(w, "hls: DVR not enabled for the stream", http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write(session.Main()); err != nil {
+			log.Error().Err(err).Caller().Send()
+		}
+		return
+	}
+
+	src := query.Get("src")
 	stream := streams.Get(src)
 	if stream == nil {
 		http.Error(w, api.StreamNotFound, http.StatusNotFound)
@@ -58,12 +142,15 @@ func handlerStream(w http.ResponseWriter, r *http.Request) {
 
 	var cons core.Consumer
 
-	// use fMP4 with codecs filter and TS without
-	medias := mp4.ParseQuery(r.URL.Query())
-	if medias != nil {
+	// use fMP4 with codecs filter and TS without, LL-HLS always with fMP4
+	medias := mp4.ParseQuery(query)
+	if medias != nil || query.Has("ll") {
 		c := mp4.NewConsumer(medias)
 		c.FormatName = "hls/fmp4"
 		c.WithRequest(r)
+		if d := stream.Display(); d != nil {
+			c.Rotate, c.ScaleX, c.ScaleY, c.Crop = d.Rotate, d.ScaleX, d.ScaleY, d.Crop
+		}
 		cons = c
 	} else {
 		c := mpegts.NewConsumer()
@@ -74,10 +161,26 @@ func handlerStream(w http.ResponseWriter, r *http.Request) {
 
 	if err := stream.AddConsumer(cons); err != nil {
 		log.Error().Err(err).Caller().Send()
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
-	session := NewSession(cons)
+	var session *Session
+	if c, ok := cons.(*mp4.Consumer); ok && query.Has("ll") {
+		session = NewSessionLL(c)
+	} else {
+		session = NewSession(cons)
+	}
+
+	startSession(session, stream, cons)
+
+	if _, err := w.Write(session.Main()); err != nil {
+		log.Error().Err(err).Caller().Send()
+	}
+}
+
+// startSession - register session and remove consumer from the stream after keepalive timeout
+func startSession(session *Session, stream *streams.Stream, cons core.Consumer) {
 	session.alive = time.AfterFunc(keepalive, func() {
 		sessionsMu.Lock()
 		delete(sessions, session.id)
@@ -91,10 +194,6 @@ func handlerStream(w http.ResponseWriter, r *http.Request) {
 	sessionsMu.Unlock()
 
 	go session.Run()
-
-	if _, err := w.Write(session.Main()); err != nil {
-		log.Error().Err(err).Caller().Send()
-	}
 }
 
 func handlerPlaylist(w http.ResponseWriter, r *http.Request) {
@@ -106,7 +205,9 @@ func handlerPlaylist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sid := r.URL.Query().Get("id")
+	query := r.URL.Query()
+
+	sid := query.Get("id")
 	sessionsMu.RLock()
 	session := sessions[sid]
 	sessionsMu.RUnlock()
@@ -115,9 +216,26 @@ func handlerPlaylist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, err := w.Write(session.Playlist()); err != nil {
+	var data []byte
+
+	if session.ll != nil {
+		session.touch(keepalive + session.ll.Timeout())
+
+		var err error
+		if data, err = session.ll.Playlist(query); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		data = session.Playlist()
+	}
+
+	if _, err := w.Write(data); err != nil {
 		log.Error().Err(err).Caller().Send()
+		return
 	}
+
+	session.sent()
 }
 
 func handlerSegmentTS(w http.ResponseWriter, r *http.Request) {
@@ -138,7 +256,7 @@ func handlerSegmentTS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session.alive.Reset(keepalive)
+	session.touch(keepalive)
 
 	data := session.Segment()
 	if data == nil {
@@ -202,9 +320,16 @@ func handlerSegmentMP4(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session.alive.Reset(keepalive)
+	var data []byte
+
+	if session.ll != nil {
+		session.touch(keepalive + session.ll.Timeout())
+		data = session.ll.Segment(core.Atoi(query.Get("n")))
+	} else {
+		session.touch(keepalive)
+		data = session.Segment()
+	}
 
-	data := session.Segment()
 	if data == nil {
 		log.Warn().Msgf("[hls] can't get segment %s", r.URL.RawQuery)
 		http.NotFound(w, r)
@@ -215,3 +340,37 @@ func handlerSegmentMP4(w http.ResponseWriter, r *http.Request) {
 		log.Error().Err(err).Caller().Send()
 	}
 }
+
+func handlerPart(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "video/iso.segment")
+
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		return
+	}
+
+	query := r.URL.Query()
+
+	sid := query.Get("id")
+	sessionsMu.RLock()
+	session := sessions[sid]
+	sessionsMu.RUnlock()
+	if session == nil || session.ll == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	session.touch(keepalive + session.ll.Timeout())
+
+	data := session.ll.Part(core.Atoi(query.Get("n")), core.Atoi(query.Get("p")))
+	if data == nil {
+		log.Warn().Msgf("[hls] can't get part %s", r.URL.RawQuery)
+		http.NotFound(w, r)
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		log.Error().Err(err).Caller().Send()
+	}
+}
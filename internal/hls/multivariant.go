@@ -0,0 +1,240 @@
+package hls
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/mp4"
+)
+
+// variant - video or audio rendition from the stream, or from one producer of the stream
+type variant struct {
+	*mp4.Consumer
+	name    string
+	stream  *streams.Stream
+	source  string
+	session *Session
+	audio   *variant // audio rendition from the same source
+	kind    string
+	key     string // for the measured bitrate
+	started time.Time
+}
+
+// SelectSource - use only one producer of the stream
+func (v *variant) SelectSource(source string) bool {
+	return v.source == "" || v.source == source
+}
+
+// handlerMultivariant - multivariant playlist for several streams (src=main&src=sub)
+// or for all producers of one stream (src=camera1&variants), audio goes as a separate rendition
+func handlerMultivariant(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var variants []*variant
+
+	if names := query["src"]; len(names) > 1 {
+		for _, name := range names {
+			stream := streams.Get(name)
+			if stream == nil {
+				http.Error(w, api.StreamNotFound, http.StatusNotFound)
+				return
+			}
+			variants = append(variants, &variant{name: name, stream: stream})
+		}
+	} else {
+		name := query.Get("src")
+		stream := streams.Get(name)
+		if stream == nil {
+			http.Error(w, api.StreamNotFound, http.StatusNotFound)
+			return
+		}
+		for _, source := range stream.Sources() {
+			variants = append(variants, &variant{name: name, stream: stream, source: source})
+		}
+	}
+
+	// video and audio medias from the query, HLS supports only H264 and H265 video
+	var videos, audios []*core.Media
+	for _, media := range mp4.ParseQuery(query) {
+		if media.Kind == core.KindVideo {
+			videos = append(videos, media)
+		} else {
+			audios = append(audios, media)
+		}
+	}
+	if videos == nil {
+		videos = []*core.Media{
+			{
+				Kind:      core.KindVideo,
+				Direction: core.DirectionSendonly,
+				Codecs:    []*core.Codec{{Name: core.CodecH264}, {Name: core.CodecH265}},
+			},
+		}
+		if !query.Has("video") {
+			audios = []*core.Media{
+				{
+					Kind:      core.KindAudio,
+					Direction: core.DirectionSendonly,
+					Codecs:    []*core.Codec{{Name: core.CodecAAC}},
+				},
+			}
+		}
+	}
+
+	var active []*variant
+	for _, v := range variants {
+		if v.start(r, videos) {
+			active = append(active, v)
+		}
+	}
+
+	if len(active) == 0 {
+		http.Error(w, "hls: no video variants", http.StatusNotFound)
+		return
+	}
+
+	// audio from the same source for each variant, because audio from another source
+	// is not in sync with the video and can be different
+	if audios != nil {
+		for _, v := range active {
+			a := &variant{name: v.name, stream: v.stream, source: v.source}
+			if a.start(r, audios) {
+				v.audio = a
+			}
+		}
+	}
+
+	// player downloads only one video variant and its audio rendition
+	group := make([]*Session, 0, 2*len(active))
+	for _, v := range active {
+		group = append(group, v.session)
+		if v.audio != nil {
+			group = append(group, v.audio.session)
+		}
+	}
+	for _, session := range group {
+		session.group = group
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("#EXTM3U\n#EXT-X-VERSION:6\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+
+	for i, v := range active {
+		if v.audio != nil {
+			fmt.Fprintf(
+				sb, "#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"audio%d\",NAME=\"audio\",DEFAULT=YES,AUTOSELECT=YES,URI=\"hls/playlist.m3u8?id=%s\"\n",
+				i, v.audio.session.id,
+			)
+		}
+	}
+
+	for i, v := range active {
+		bandwidth := v.bandwidth()
+		codecs := v.mimeCodecs()
+		if v.audio != nil {
+			bandwidth += v.audio.bandwidth()
+			codecs += "," + v.audio.mimeCodecs()
+		}
+
+		fmt.Fprintf(sb, "#EXT-X-STREAM-INF:BANDWIDTH=%d", bandwidth)
+
+		if width, height := v.videoSize(); width > 0 {
+			fmt.Fprintf(sb, ",RESOLUTION=%dx%d", width, height)
+		}
+
+		sb.WriteString(`,CODECS="` + codecs + `"`)
+
+		if v.audio != nil {
+			fmt.Fprintf(sb, `,AUDIO="audio%d"`, i)
+		}
+
+		sb.WriteString("\nhls/playlist.m3u8?id=" + v.session.id + "\n")
+	}
+
+	if _, err := w.Write([]byte(sb.String())); err != nil {
+		log.Error().Err(err).Caller().Send()
+	}
+}
+
+// start - add consumer with medias to the stream and start HLS session
+func (v *variant) start(r *http.Request, medias []*core.Media) bool {
+	v.Consumer = mp4.NewConsumer(medias)
+	v.FormatName = "hls/fmp4"
+	v.WithRequest(r)
+	v.kind = medias[0].Kind
+	v.key = v.name + "#" + v.source + "#" + v.kind
+	v.started = time.Now()
+
+	if err := v.stream.AddConsumer(v); err != nil {
+		log.Debug().Err(err).Str("source", v.source).Msg("[hls] skip variant")
+		return false
+	}
+
+	if r.URL.Query().Has("ll") {
+		v.session = NewSessionLL(v.Consumer)
+	} else {
+		v.session = NewSession(v.Consumer)
+	}
+
+	startSession(v.session, v.stream, v)
+
+	return true
+}
+
+// bitrates - average bitrate of the previous sessions for each variant,
+// because the multivariant playlist can't wait for the data
+var bitrates = map[string]int{}
+var bitratesMu sync.Mutex
+
+// Stop - save measured bitrate of the consumer when the session is closed
+func (v *variant) Stop() error {
+	// short sessions may have only the first GOP
+	if elapsed := time.Since(v.started).Seconds(); elapsed >= 10 {
+		bitratesMu.Lock()
+		bitrates[v.key] = int(float64(8*v.Send) / elapsed)
+		bitratesMu.Unlock()
+	}
+	return v.Consumer.Stop()
+}
+
+// bandwidth - measured bitrate of the previous sessions, if any, or estimated from the video size (SPS),
+// default is the same as for the single playlist
+func (v *variant) bandwidth() int {
+	bitratesMu.Lock()
+	bitrate := bitrates[v.key]
+	bitratesMu.Unlock()
+	if bitrate > 0 {
+		return bitrate
+	}
+
+	width, height := v.videoSize()
+	if width == 0 {
+		if v.kind == core.KindAudio {
+			return 128000 // max for camera audio with overhead
+		}
+		return 192000
+	}
+	// about 4 Mbit/s for 1080p and 1.8 Mbit/s for 720p
+	return width * height * 2
+}
+
+// videoSize - video size from the codec parameter sets, zero if unknown
+func (v *variant) videoSize() (width, height int) {
+	for _, codec := range v.Codecs() {
+		if w, h := mp4.VideoSize(codec); w > 0 {
+			return int(w), int(h)
+		}
+	}
+	return 0, 0
+}
+
+func (v *variant) mimeCodecs() string {
+	codecs := mp4.MimeCodecs(v.Codecs())
+	return strings.Replace(codecs, mp4.MimeFlac, "fLaC", 1)
+}
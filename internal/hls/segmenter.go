@@ -0,0 +1,481 @@
+package hls
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/iso"
+)
+
+// Segmenter - LL-HLS playlist from fMP4 fragments: keyframe aligned segments with partial segments,
+// blocking playlist reload (_HLS_msn, _HLS_part) and sliding window
+type Segmenter struct {
+	id    string
+	track uint32 // main track for segments timing, video if exists
+	scale float64
+
+	window  int
+	part    float64 // part target duration in seconds
+	segment float64 // min segment duration in seconds
+
+	dvr  float64 // DVR window in seconds, instead of segments count
+	path string  // folder for DVR segments, memory if empty
+
+	stats SegmenterStats
+
+	buf      []byte // incomplete fragment
+	segments []*segment
+	current  *segment // segment in progress
+	parted   *part    // part in progress
+	target   int      // max segment duration, rounded up
+	ended    bool
+	zero     time.Time // wall time of the first segment start
+
+	notify chan struct{}
+	mu     sync.Mutex
+}
+
+type segment struct {
+	msn      int
+	start    time.Time
+	time     float64 // media time from the first segment in seconds
+	duration float64
+	parts    []*part
+	size     int
+	file     string // segment on disk
+}
+
+// SegmenterStats - DVR window and eviction accounting
+type SegmenterStats struct {
+	Storage      string  `json:"storage"`
+	Segments     int     `json:"segments"`
+	Duration     float64 `json:"duration"` // seconds
+	Bytes        int     `json:"bytes"`
+	Evicted      int     `json:"evicted_segments"`
+	EvictedBytes int     `json:"evicted_bytes"`
+}
+
+type part struct {
+	data        []byte
+	duration    float64
+	independent bool
+}
+
+func NewSegmenter(id string, codecs []*core.Codec, window int, partDuration, segmentDuration time.Duration) *Segmenter {
+	s := &Segmenter{
+		id:      id,
+		window:  window,
+		part:    partDuration.Seconds(),
+		segment: segmentDuration.Seconds(),
+		target:  int(math.Ceil(segmentDuration.Seconds())),
+		notify:  make(chan struct{}),
+	}
+
+	// track ID in the MP4 muxer is the codec index + 1
+	for i, codec := range codecs {
+		if s.track == 0 || codec.IsVideo() {
+			s.track = uint32(i + 1)
+			s.scale = float64(codec.ClockRate)
+		}
+		if codec.IsVideo() {
+			break
+		}
+	}
+
+	return s
+}
+
+// Write - fMP4 fragments (moof+mdat) from the MP4 consumer, can be called with several fragments
+func (s *Segmenter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = append(s.buf, p...)
+
+	for len(s.buf) >= 8 {
+		size := int(binary.BigEndian.Uint32(s.buf))
+		if size < 8 {
+			s.buf = nil
+			return 0, errors.New("hls: wrong atom size")
+		}
+
+		if string(s.buf[4:8]) != iso.Moof {
+			if len(s.buf) < size {
+				break
+			}
+			s.buf = s.buf[size:] // skip unknown atoms
+			continue
+		}
+
+		// moof with the following mdat
+		if len(s.buf) < size+8 {
+			break
+		}
+		total := size + int(binary.BigEndian.Uint32(s.buf[size:]))
+		if len(s.buf) < total {
+			break
+		}
+
+		s.writeFragment(s.buf[:size], s.buf[:total:total])
+		s.buf = s.buf[total:]
+	}
+
+	if len(s.buf) == 0 {
+		s.buf = nil // release memory
+	}
+
+	return len(p), nil
+}
+
+func (s *Segmenter) writeFragment(moof, fragment []byte) {
+	atoms, err := iso.DecodeAtoms(moof)
+	if err != nil {
+		return
+	}
+
+	var tfhd *iso.AtomTfhd
+	for _, atom := range atoms {
+		if atom, ok := atom.(*iso.AtomTfhd); ok {
+			tfhd = atom
+		}
+	}
+	if tfhd == nil {
+		return
+	}
+
+	main := tfhd.TrackID == s.track
+	independent := main && tfhd.SampleFlags == iso.SampleVideoIFrame // same flags for audio
+	duration := float64(tfhd.SampleDuration) / s.scale
+
+	if independent && (s.current == nil || s.current.duration+s.parted.duration >= s.segment) {
+		s.closeSegment()
+	}
+
+	if s.current == nil {
+		return // wait first keyframe
+	}
+
+	if main && len(s.parted.data) > 0 && s.parted.duration+duration > s.part {
+		s.closePart()
+		s.parted.independent = independent
+	}
+
+	s.parted.data = append(s.parted.data, fragment...)
+
+	if main {
+		s.parted.duration += duration
+	}
+}
+
+func (s *Segmenter) closePart() {
+	s.current.parts = append(s.current.parts, s.parted)
+	s.current.duration += s.parted.duration
+	s.parted = &part{}
+	s.broadcast()
+}
+
+func (s *Segmenter) closeSegment() {
+	msn := 0
+	var mediaTime float64
+
+	if s.current != nil {
+		if len(s.parted.data) > 0 {
+			s.closePart()
+		}
+
+		s.addSegment(s.current)
+
+		if d := int(math.Ceil(s.current.duration)); d > s.target {
+			s.target = d
+		}
+
+		msn = s.current.msn + 1
+		mediaTime = s.current.time + s.current.duration
+	}
+
+	s.current = &segment{msn: msn, start: time.Now(), time: mediaTime}
+	if msn == 0 {
+		s.zero = s.current.start
+	}
+	s.parted = &part{independent: true}
+	s.broadcast()
+}
+
+func (s *Segmenter) addSegment(seg *segment) {
+	for _, p := range seg.parts {
+		seg.size += len(p.data)
+	}
+
+	if s.path != "" {
+		file := filepath.Join(s.path, s.id+"_"+strconv.Itoa(seg.msn)+".m4s")
+		if err := os.WriteFile(file, seg.bytes(), 0644); err == nil {
+			seg.file = file
+		} else {
+			log.Warn().Err(err).Caller().Send()
+		}
+	}
+
+	s.segments = append(s.segments, seg)
+	s.stats.Segments++
+	s.stats.Duration += seg.duration
+	s.stats.Bytes += seg.size
+
+	// evict old segments by DVR duration or by count
+	for len(s.segments) > 1 {
+		if s.dvr > 0 {
+			if s.stats.Duration <= s.dvr {
+				break
+			}
+		} else if len(s.segments) <= s.window {
+			break
+		}
+
+		old := s.segments[0]
+		s.segments = s.segments[1:]
+
+		if old.file != "" {
+			_ = os.Remove(old.file)
+		}
+
+		s.stats.Segments--
+		s.stats.Duration -= old.duration
+		s.stats.Bytes -= old.size
+		s.stats.Evicted++
+		s.stats.EvictedBytes += old.size
+	}
+
+	// parts are needed only for last segments, others are on disk
+	if n := len(s.segments) - 3; n >= 0 && s.segments[n].file != "" {
+		s.segments[n].parts = nil
+	}
+}
+
+func (s *segment) bytes() (b []byte) {
+	for _, p := range s.parts {
+		b = append(b, p.data...)
+	}
+	return
+}
+
+// Stats - segments in the window and evicted segments
+func (s *Segmenter) Stats() SegmenterStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	if s.path != "" {
+		stats.Storage = "disk"
+	} else {
+		stats.Storage = "memory"
+	}
+	return stats
+}
+
+func (s *Segmenter) broadcast() {
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// End - finish playlist and release all waiting requests
+func (s *Segmenter) End() {
+	s.mu.Lock()
+	s.ended = true
+	s.broadcast()
+	s.mu.Unlock()
+}
+
+// Timeout - max time for blocking requests
+func (s *Segmenter) Timeout() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return 3 * time.Duration(s.target) * time.Second
+}
+
+// wait - until check returns true, the playlist ended or timeout
+func (s *Segmenter) wait(check func() bool) bool {
+	timer := time.NewTimer(s.Timeout())
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		if check() {
+			return true // locked
+		}
+		if s.ended {
+			s.mu.Unlock()
+			return false
+		}
+		notify := s.notify
+		s.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// hasPart - segment msn is finished (part < 0) or has part
+func (s *Segmenter) hasPart(msn, part int) bool {
+	if s.current == nil {
+		return false
+	}
+	if msn < s.current.msn {
+		return true
+	}
+	return msn == s.current.msn && part >= 0 && part < len(s.current.parts)
+}
+
+func (s *Segmenter) find(msn int) *segment {
+	if s.current != nil && s.current.msn == msn {
+		return s.current
+	}
+	for _, seg := range s.segments {
+		if seg.msn == msn {
+			return seg
+		}
+	}
+	return nil
+}
+
+// Playlist - media playlist, blocks with _HLS_msn and _HLS_part params until the playlist contains them
+func (s *Segmenter) Playlist(query url.Values) ([]byte, error) {
+	msn, part := -1, -1
+	if v := query.Get("_HLS_msn"); v != "" {
+		msn = core.Atoi(v)
+		if v = query.Get("_HLS_part"); v != "" {
+			part = core.Atoi(v)
+		}
+
+		s.mu.Lock()
+		next := 0
+		if s.current != nil {
+			next = s.current.msn
+		}
+		s.mu.Unlock()
+
+		// https://datatracker.ietf.org/doc/html/draft-pantos-hls-rfc8216bis#section-6.2.5.2
+		if msn > next+2 {
+			return nil, errors.New("hls: _HLS_msn is too far in the future")
+		}
+	} else {
+		// first request, wait first segment for player buffer
+		msn = 0
+	}
+
+	if !s.wait(func() bool { return s.hasPart(msn, part) }) {
+		s.mu.Lock()
+	}
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		return nil, errors.New("hls: no segments")
+	}
+
+	return []byte(s.playlist()), nil
+}
+
+func (s *Segmenter) playlist() string {
+	sb := &strings.Builder{}
+
+	first := s.current.msn
+	if len(s.segments) > 0 {
+		first = s.segments[0].msn
+	}
+
+	fmt.Fprintf(sb, `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-TARGETDURATION:%d
+#EXT-X-SERVER-CONTROL:CAN-BLOCK-RELOAD=YES,PART-HOLD-BACK=%.3f
+#EXT-X-PART-INF:PART-TARGET=%.3f
+#EXT-X-MEDIA-SEQUENCE:%d
+#EXT-X-MAP:URI="init.mp4?id=%s"
+`, s.target, 3*s.part, s.part, first, s.id)
+
+	if s.dvr > 0 {
+		// start near the live edge, the player can seek back to the DVR window start
+		fmt.Fprintf(sb, "#EXT-X-START:TIME-OFFSET=-%d\n", 3*s.target)
+	}
+
+	// parts only for last segments, recommended for last 3 target durations
+	withParts := len(s.segments) - 2
+
+	for i, seg := range s.segments {
+		s.writeSegment(sb, seg, i >= withParts)
+		fmt.Fprintf(sb, "#EXTINF:%.5f,\nsegment.m4s?id=%s&n=%d\n", seg.duration, s.id, seg.msn)
+	}
+
+	if s.ended {
+		sb.WriteString("#EXT-X-ENDLIST\n")
+		return sb.String()
+	}
+
+	s.writeSegment(sb, s.current, true)
+	fmt.Fprintf(
+		sb, "#EXT-X-PRELOAD-HINT:TYPE=PART,URI=\"part.m4s?id=%s&n=%d&p=%d\"\n",
+		s.id, s.current.msn, len(s.current.parts),
+	)
+
+	return sb.String()
+}
+
+func (s *Segmenter) writeSegment(sb *strings.Builder, seg *segment, withParts bool) {
+	sb.WriteString("#EXT-X-PROGRAM-DATE-TIME:" + seg.start.UTC().Format("2006-01-02T15:04:05.000Z") + "\n")
+
+	if !withParts {
+		return
+	}
+
+	for i, p := range seg.parts {
+		fmt.Fprintf(sb, "#EXT-X-PART:DURATION=%.5f,URI=\"part.m4s?id=%s&n=%d&p=%d\"", p.duration, s.id, seg.msn, i)
+		if p.independent {
+			sb.WriteString(",INDEPENDENT=YES")
+		}
+		sb.WriteByte('\n')
+	}
+}
+
+// Segment - full segment, blocks until the segment is finished
+func (s *Segmenter) Segment(msn int) []byte {
+	if !s.wait(func() bool { return s.hasPart(msn, -1) }) {
+		return nil
+	}
+
+	seg := s.find(msn)
+	if seg == nil {
+		s.mu.Unlock()
+		return nil
+	}
+
+	if file := seg.file; file != "" {
+		s.mu.Unlock()
+		b, _ := os.ReadFile(file)
+		return b
+	}
+
+	defer s.mu.Unlock()
+	return seg.bytes()
+}
+
+// Part - partial segment, blocks until the part is finished (for preload hint)
+func (s *Segmenter) Part(msn, part int) []byte {
+	if !s.wait(func() bool { return s.hasPart(msn, part) }) {
+		return nil
+	}
+	defer s.mu.Unlock()
+
+	if seg := s.find(msn); seg != nil && part >= 0 && part < len(seg.parts) {
+		return seg.parts[part].data
+	}
+	return nil
+}
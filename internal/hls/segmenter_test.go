@@ -0,0 +1,138 @@
+package hls
+
+import (
+	"bytes"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/iso"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmenter(t *testing.T) {
+	codecs := []*core.Codec{
+		{Name: core.CodecAAC, ClockRate: 16000},
+		{Name: core.CodecH264, ClockRate: 90000},
+	}
+	s := NewSegmenter("test", codecs, 6, 250*time.Millisecond, 900*time.Millisecond)
+	require.Equal(t, uint32(2), s.track)
+
+	// several fragments in one write, like the first buffer flush
+	_, _ = s.Write(append(testFragment(2, 0), testFragment(2, 1)...))
+	for i := 2; i <= 60; i++ {
+		_, _ = s.Write(testFragment(2, i))
+	}
+
+	require.Len(t, s.segments, 2)
+	require.Len(t, s.segments[0].parts, 5) // 7+7+7+7+2 frames
+	require.True(t, s.segments[0].parts[0].independent)
+	require.False(t, s.segments[0].parts[1].independent)
+	require.Equal(t, 2, s.current.msn)
+
+	b, err := s.Playlist(url.Values{"_HLS_msn": {"1"}})
+	require.Nil(t, err)
+	playlist := string(b)
+	require.Contains(t, playlist, "#EXT-X-MEDIA-SEQUENCE:0\n")
+	require.Contains(t, playlist, "#EXT-X-PART-INF:PART-TARGET=0.250\n")
+	require.Contains(t, playlist, "#EXTINF:1.00000,\nsegment.m4s?id=test&n=0\n")
+	require.Contains(t, playlist, `#EXT-X-PART:DURATION=0.23333,URI="part.m4s?id=test&n=1&p=0",INDEPENDENT=YES`)
+	require.Contains(t, playlist, `#EXT-X-PRELOAD-HINT:TYPE=PART,URI="part.m4s?id=test&n=2&p=0"`)
+
+	_, err = s.Playlist(url.Values{"_HLS_msn": {"5"}})
+	require.NotNil(t, err)
+
+	require.Len(t, s.Part(1, 4), 2*len(testFragment(2, 0)))
+	require.Len(t, s.Segment(0), 30*len(testFragment(2, 0)))
+
+	s.End()
+	require.Nil(t, s.Part(2, 5))
+}
+
+func TestSegmenterDVR(t *testing.T) {
+	codecs := []*core.Codec{{Name: core.CodecH264, ClockRate: 90000}}
+	s := NewSegmenter("test", codecs, 6, 500*time.Millisecond, 900*time.Millisecond)
+	s.dvr = 2.5
+	s.path = t.TempDir()
+
+	for i := 0; i <= 150; i++ {
+		_, _ = s.Write(testFragment(1, i))
+	}
+
+	// 5 segments by 1 second, 2 in the window
+	stats := s.Stats()
+	require.Equal(t, "disk", stats.Storage)
+	require.Equal(t, 2, stats.Segments)
+	require.Equal(t, 3, stats.Evicted)
+	require.Equal(t, 2*stats.EvictedBytes/3, stats.Bytes)
+
+	b, err := s.Playlist(url.Values{"_HLS_msn": {"4"}})
+	require.Nil(t, err)
+	require.Contains(t, string(b), "#EXT-X-MEDIA-SEQUENCE:3\n#EXT-X-MAP:URI=\"init.mp4?id=test\"\n#EXT-X-START:TIME-OFFSET=-3\n")
+
+	require.Nil(t, s.Segment(2))
+	require.Len(t, s.Segment(3), stats.Bytes/2)
+}
+
+func TestSegmenterDASH(t *testing.T) {
+	codecs := []*core.Codec{{Name: core.CodecH264, ClockRate: 90000}}
+	s := NewSegmenter("test", codecs, 6, 250*time.Millisecond, 900*time.Millisecond)
+
+	for i := 0; i <= 70; i++ {
+		_, _ = s.Write(testFragment(1, i))
+	}
+
+	b, err := Manifest([]*Segmenter{s}, []string{"avc1.640029"}, false)
+	require.Nil(t, err)
+	mpd := string(b)
+	require.Contains(t, mpd, `mimeType="video/mp4"`)
+	require.Contains(t, mpd, `presentationTimeOffset="0" startNumber="0" initialization="init.mp4?id=test" media="segment.m4s?id=test&amp;n=$Number$">`)
+	require.Contains(t, mpd, `<S t="0" d="1000"/>`)
+	require.Contains(t, mpd, `<S t="1000" d="1000"/>`)
+	require.NotContains(t, mpd, `<S t="2000"`)
+	require.Contains(t, mpd, `<UTCTiming schemeIdUri="urn:mpeg:dash:utc:direct:2014"`)
+
+	// segment in progress with the measured GOP duration, not the config one
+	b, err = Manifest([]*Segmenter{s}, []string{"avc1.640029"}, true)
+	require.Nil(t, err)
+	mpd = string(b)
+	require.Contains(t, mpd, `availabilityTimeOffset="0.750" availabilityTimeComplete="false"`)
+	require.Contains(t, mpd, `<S t="2000" d="1000"/>`)
+
+	// audio track started before the video keyframe
+	audio := NewSegmenter("audio", []*core.Codec{{Name: core.CodecAAC, ClockRate: 16000}}, 6, 250*time.Millisecond, 900*time.Millisecond)
+	for i := 0; i <= 70; i++ {
+		_, _ = audio.Write(testFragment(1, i))
+	}
+	audio.zero = s.zero.Add(-500 * time.Millisecond)
+
+	b, err = Manifest([]*Segmenter{s, audio}, []string{"avc1.640029", "mp4a.40.2"}, false)
+	require.Nil(t, err)
+	mpd = string(b)
+	require.Contains(t, mpd, `<AdaptationSet id="1" mimeType="audio/mp4"`)
+	require.Contains(t, mpd, `presentationTimeOffset="500" startNumber="0" initialization="init.mp4?id=audio"`)
+
+	// finished segment
+	buf := &bytes.Buffer{}
+	require.Nil(t, s.WriteSegment(buf, 1))
+	require.Equal(t, 30*len(testFragment(1, 0)), buf.Len())
+
+	// segment in progress, parts are written until the end
+	buf.Reset()
+	go s.End()
+	require.Nil(t, s.WriteSegment(buf, 2))
+	require.Equal(t, 7*len(testFragment(1, 0)), buf.Len())
+}
+
+// testFragment - one byte H264 frame with 30 fps and keyframe every second
+func testFragment(track uint32, i int) []byte {
+	flags := uint32(iso.SampleVideoNonIFrame)
+	if i%30 == 0 {
+		flags = iso.SampleVideoIFrame
+	}
+	mv := iso.NewMovie(64)
+	mv.WriteMovieFragment(uint32(i), track, 3000, 1, flags, uint64(i*3000), 0)
+	mv.WriteData([]byte{byte(i)})
+	return mv.Bytes()
+}
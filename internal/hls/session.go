@@ -19,7 +19,12 @@ type Session struct {
 	buffer   []byte
 	seq      int
 	alive    *time.Timer
+	group    []*Session // sessions of one multivariant playlist, kept alive together
+	ended    bool
+	endSent  chan struct{} // closed after the playlist with ENDLIST was sent
 	mu       sync.Mutex
+
+	ll *Segmenter // LL-HLS mode for fMP4
 }
 
 func NewSession(cons core.Consumer) *Session {
@@ -53,10 +58,23 @@ segment.ts?id=` + s.id + `&n=%d`
 	return s
 }
 
+// NewSessionLL - LL-HLS session with partial segments for fMP4 consumer
+func NewSessionLL(cons *mp4.Consumer) *Session {
+	s := &Session{
+		id:   core.RandString(8, 62),
+		cons: cons,
+	}
+	s.ll = NewSegmenter(s.id, cons.Codecs(), cfg.Window, cfg.PartDuration, cfg.SegmentDuration)
+	return s
+}
+
 func (s *Session) Write(p []byte) (n int, err error) {
 	s.mu.Lock()
 	if s.init == nil {
 		s.init = p
+	} else if s.ll != nil {
+		s.mu.Unlock()
+		return s.ll.Write(p)
 	} else {
 		s.buffer = append(s.buffer, p...)
 	}
@@ -69,21 +87,79 @@ func (s *Session) Run() {
 }
 
 func (s *Session) Main() []byte {
-	type withCodecs interface {
-		Codecs() []*core.Codec
-	}
-
-	codecs := mp4.MimeCodecs(s.cons.(withCodecs).Codecs())
-	codecs = strings.Replace(codecs, mp4.MimeFlac, "fLaC", 1)
-
 	// bandwidth important for Safari, codecs useful for smooth playback
 	return []byte(`#EXTM3U
-#EXT-X-STREAM-INF:BANDWIDTH=192000,CODECS="` + codecs + `"
+#EXT-X-STREAM-INF:BANDWIDTH=192000,CODECS="` + s.codecs() + `"
 hls/playlist.m3u8?id=` + s.id)
 }
 
+// codecs - RFC 6381 codecs of the consumer tracks
+func (s *Session) codecs() string {
+	var codecs string
+
+	switch cons := s.cons.(type) {
+	case *mp4.Consumer:
+		codecs = cons.MimeCodecs() // VP9 and AV1 config from the keyframe
+	case interface{ Codecs() []*core.Codec }:
+		codecs = mp4.MimeCodecs(cons.Codecs())
+	}
+
+	return strings.Replace(codecs, mp4.MimeFlac, "fLaC", 1)
+}
+
 func (s *Session) Playlist() []byte {
-	return []byte(fmt.Sprintf(s.template, s.seq, s.seq, s.seq+1))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlist := fmt.Sprintf(s.template, s.seq, s.seq, s.seq+1)
+	if s.ended {
+		playlist += "\n#EXT-X-ENDLIST"
+	}
+	return []byte(playlist)
+}
+
+// touch - postpone the session timeout, DVR sessions have no timeout.
+// Player downloads only one variant, so all sessions of the group are postponed.
+func (s *Session) touch(d time.Duration) {
+	if s.alive != nil {
+		s.alive.Reset(d)
+	}
+	for _, session := range s.group {
+		if session != s && session.alive != nil {
+			session.alive.Reset(d)
+		}
+	}
+}
+
+// End - mark playlist as finished (ex. on server shutdown), returns channel
+// that is closed when the player gets the final playlist
+func (s *Session) End() <-chan struct{} {
+	s.mu.Lock()
+	if !s.ended {
+		s.ended = true
+		s.endSent = make(chan struct{})
+	}
+	ch := s.endSent
+	s.mu.Unlock()
+
+	if s.ll != nil {
+		s.ll.End()
+	}
+
+	return ch
+}
+
+// sent - called after the playlist was written to the player
+func (s *Session) sent() {
+	s.mu.Lock()
+	if s.ended {
+		select {
+		case <-s.endSent:
+		default:
+			close(s.endSent)
+		}
+	}
+	s.mu.Unlock()
 }
 
 func (s *Session) Init() (init []byte) {
@@ -94,7 +170,7 @@ func (s *Session) Init() (init []byte) {
 
 		s.mu.Lock()
 		// return init only when have some buffer
-		if len(s.buffer) > 0 {
+		if len(s.buffer) > 0 || (s.ll != nil && s.init != nil) {
 			init = s.init
 		}
 		s.mu.Unlock()
@@ -22,6 +22,10 @@ func handlerWSHLS(tr *ws.Transport, msg *ws.Message) error {
 	cons.FormatName = "hls/fmp4"
 	cons.WithRequest(tr.Request)
 
+	if d := stream.Display(); d != nil {
+		cons.Rotate, cons.ScaleX, cons.ScaleY, cons.Crop = d.Rotate, d.ScaleX, d.ScaleY, d.Crop
+	}
+
 	log.Trace().Msgf("[hls] new ws consumer codecs=%s", codecs)
 
 	if err := stream.AddConsumer(cons); err != nil {
@@ -37,8 +37,10 @@ func handleHTTP(rawURL string) (core.Producer, error) {
 		return nil, err
 	}
 
+	var query url.Values
+
 	if rawQuery != "" {
-		query := streams.ParseQuery(rawQuery)
+		query = streams.ParseQuery(rawQuery)
 
 		for _, header := range query["header"] {
 			key, value, _ := strings.Cut(header, ":")
@@ -46,7 +48,7 @@ func handleHTTP(rawURL string) (core.Producer, error) {
 		}
 	}
 
-	prod, err := do(req)
+	prod, err := do(req, query)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +62,7 @@ func handleHTTP(rawURL string) (core.Producer, error) {
 	return prod, nil
 }
 
-func do(req *http.Request) (core.Producer, error) {
+func do(req *http.Request, query url.Values) (core.Producer, error) {
 	res, err := tcp.Do(req)
 	if err != nil {
 		return nil, err
@@ -83,7 +85,7 @@ func do(req *http.Request) (core.Producer, error) {
 
 	switch {
 	case ct == "application/vnd.apple.mpegurl" || ext == "m3u8":
-		return hls.OpenURL(req.URL, res.Body)
+		return hls.OpenURL(req, res.Body, query.Get("variant"))
 	case ct == "image/jpeg":
 		return image.Open(res)
 	case ct == "multipart/x-mixed-replace":
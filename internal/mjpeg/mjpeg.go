@@ -1,9 +1,13 @@
 package mjpeg
 
 import (
+	"bytes"
 	"errors"
+	"image"
+	"image/jpeg"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +20,7 @@ import (
 	"github.com/AlexxIT/go2rtc/internal/streams"
 	"github.com/AlexxIT/go2rtc/pkg/ascii"
 	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264/decoder"
 	"github.com/AlexxIT/go2rtc/pkg/magic"
 	"github.com/AlexxIT/go2rtc/pkg/mjpeg"
 	"github.com/AlexxIT/go2rtc/pkg/mpjpeg"
@@ -44,6 +49,8 @@ func handlerKeyframe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	stream.Display().SetQuery(query)
+
 	var b []byte
 
 	if s := query.Get("cache"); s != "" {
@@ -80,6 +87,7 @@ func handlerKeyframe(w http.ResponseWriter, r *http.Request) {
 
 	if err := stream.AddConsumer(cons); err != nil {
 		log.Error().Err(err).Caller().Send()
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
@@ -93,18 +101,75 @@ func handlerKeyframe(w http.ResponseWriter, r *http.Request) {
 	case core.CodecH264, core.CodecH265:
 		ts := time.Now()
 		var err error
-		if b, err = ffmpeg.JPEGWithQuery(b, query); err != nil {
+		if b, err = keyframeToJPEG(b, cons.CodecName(), query); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		log.Debug().Msgf("[mjpeg] transcoding time=%s", time.Since(ts))
 	case core.CodecJPEG:
 		b = mjpeg.FixJPEG(b)
+		if query.Has("rotate") || query.Has("aspect") || query.Has("crop") {
+			var err error
+			if b, err = transformJPEG(b, query); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
 	}
 
 	writeJPEGResponse(w, b)
 }
 
+// keyframeToJPEG - decode H264 Baseline keyframe in-process and fall back to FFmpeg
+// for H265, Main/High profiles (CABAC), interlace and hardware transcoding
+func keyframeToJPEG(b []byte, codecName string, query url.Values) ([]byte, error) {
+	if codecName == core.CodecH264 && query.Get("hardware") == "" && query.Get("hw") == "" {
+		img, err := decoder.Decode(b)
+		if err == nil {
+			return encodeImage(img, query)
+		}
+		log.Debug().Err(err).Msg("[mjpeg] fallback to ffmpeg")
+	}
+
+	return ffmpeg.JPEGWithQuery(b, query)
+}
+
+// transformJPEG - apply display transforms to JPEG in-process for 4:2:0 images and with FFmpeg for others
+func transformJPEG(b []byte, query url.Values) ([]byte, error) {
+	if img, err := jpeg.Decode(bytes.NewReader(b)); err == nil {
+		if img, ok := img.(*image.YCbCr); ok && img.SubsampleRatio == image.YCbCrSubsampleRatio420 {
+			return encodeImage(img, query)
+		}
+	}
+
+	return ffmpeg.JPEGWithQuery(b, query)
+}
+
+// encodeImage - crop, pixel aspect, scale and rotate, same order as FFmpeg filters
+func encodeImage(img *image.YCbCr, query url.Values) ([]byte, error) {
+	if d := streams.ParseDisplay(0, query.Get("aspect"), query.Get("crop")); d != nil {
+		if !d.Crop.Empty() {
+			img = mjpeg.Crop(img, d.Crop)
+		}
+		if d.ScaleX > 0 && d.ScaleY > 0 {
+			img = mjpeg.Resize(img, img.Rect.Dx()*d.ScaleX/d.ScaleY, img.Rect.Dy())
+		}
+	}
+
+	width, height := -1, -1
+	if s := query.Get("width"); s != "" {
+		width = core.Atoi(s)
+	} else if s = query.Get("w"); s != "" {
+		width = core.Atoi(s)
+	}
+	if s := query.Get("height"); s != "" {
+		height = core.Atoi(s)
+	} else if s = query.Get("h"); s != "" {
+		height = core.Atoi(s)
+	}
+	return mjpeg.EncodeImage(img, width, height, core.Atoi(query.Get("rotate")))
+}
+
 var cache map[string]cacheEntry
 var cacheMu sync.Mutex
 
@@ -148,6 +213,7 @@ func outputMjpeg(w http.ResponseWriter, r *http.Request) {
 
 	if err := stream.AddConsumer(cons); err != nil {
 		log.Error().Err(err).Msg("[api.mjpeg] add consumer")
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
@@ -228,6 +294,7 @@ func apiStreamY4M(w http.ResponseWriter, r *http.Request) {
 
 	if err := stream.AddConsumer(cons); err != nil {
 		log.Error().Err(err).Caller().Send()
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
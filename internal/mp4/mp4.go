@@ -20,6 +20,7 @@ func Init() {
 	log = app.GetLogger("mp4")
 
 	ws.HandleFunc("mse", handlerWSMSE)
+	ws.HandleFunc("mse/buffer", handlerWSMSEBuffer)
 	ws.HandleFunc("mp4", handlerWSMP4)
 
 	api.HandleFunc("api/frame.mp4", handlerKeyframe)
@@ -51,7 +52,7 @@ func handlerKeyframe(w http.ResponseWriter, r *http.Request) {
 
 	if err := stream.AddConsumer(cons); err != nil {
 		log.Error().Err(err).Caller().Send()
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
@@ -105,10 +106,14 @@ func handlerMP4(w http.ResponseWriter, r *http.Request) {
 
 	if err := stream.AddConsumer(cons); err != nil {
 		log.Error().Err(err).Caller().Send()
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
+	if d := stream.Display(); d != nil {
+		cons.Rotate, cons.ScaleX, cons.ScaleY, cons.Crop = d.Rotate, d.ScaleX, d.ScaleY, d.Crop
+	}
+
 	if rotate := query.Get("rotate"); rotate != "" {
 		cons.Rotate = core.Atoi(rotate)
 	}
@@ -121,7 +126,7 @@ func handlerMP4(w http.ResponseWriter, r *http.Request) {
 	}
 
 	header := w.Header()
-	header.Set("Content-Type", mp4.ContentType(cons.Codecs()))
+	header.Set("Content-Type", cons.ContentType())
 
 	if filename := query.Get("filename"); filename != "" {
 		header.Set("Content-Disposition", `attachment; filename="`+filename+`"`)
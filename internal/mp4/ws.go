@@ -2,6 +2,10 @@ package mp4
 
 import (
 	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/api/ws"
@@ -26,22 +30,197 @@ func handlerWSMSE(tr *ws.Transport, msg *ws.Message) error {
 	cons.FormatName = "mse/fmp4"
 	cons.WithRequest(tr.Request)
 
+	if d := stream.Display(); d != nil {
+		cons.Rotate, cons.ScaleX, cons.ScaleY, cons.Crop = d.Rotate, d.ScaleX, d.ScaleY, d.Crop
+	}
+
 	if err := stream.AddConsumer(cons); err != nil {
 		log.Debug().Err(err).Msg("[mp4] add consumer")
 		return err
 	}
 
-	tr.Write(&ws.Message{Type: "mse", Value: mp4.ContentType(cons.Codecs())})
+	wr := &mseWriter{tr: tr, wr: tr.Writer()}
+	go wr.start(cons)
 
-	go cons.WriteTo(tr.Writer())
+	query := tr.Request.URL.Query()
+	latency, _ := strconv.ParseFloat(query.Get("latency"), 64)
+
+	a := &mseAdaptive{
+		tr:       tr,
+		stream:   stream,
+		cons:     cons,
+		wr:       wr,
+		latency:  latency,
+		fallback: query.Get("fallback") == "audio",
+	}
+
+	tr.WithContext(func(ctx map[any]any) {
+		ctx["mse"] = a
+	})
 
 	tr.OnClose(func() {
-		stream.RemoveConsumer(cons)
+		a.mu.Lock()
+		stream.RemoveConsumer(a.cons)
+		a.mu.Unlock()
+	})
+
+	return nil
+}
+
+// handlerWSMSEBuffer - client feedback with the playback time and the buffer level in seconds
+func handlerWSMSEBuffer(tr *ws.Transport, msg *ws.Message) error {
+	var v struct {
+		Time   float64 `json:"time"`
+		Buffer float64 `json:"buffer"`
+	}
+	if err := msg.Unmarshal(&v); err != nil {
+		return err
+	}
+
+	var a *mseAdaptive
+	tr.WithContext(func(ctx map[any]any) {
+		a, _ = ctx["mse"].(*mseAdaptive)
 	})
 
+	if a != nil {
+		a.update(v.Time, v.Buffer)
+	}
+
 	return nil
 }
 
+const (
+	mseSkipInterval   = 3 * time.Second // minimum time between skips, so client can catch up
+	mseFallbackSkips  = 3               // skips in the window for audio-only fallback
+	mseFallbackWindow = 30 * time.Second
+)
+
+// mseAdaptive - drop frames to the next keyframe when the client is behind the latency target,
+// and switch to audio-only after several drops (if enabled)
+type mseAdaptive struct {
+	tr     *ws.Transport
+	stream *streams.Stream
+	cons   *mp4.Consumer
+	wr     *mseWriter
+
+	latency  float64 // target latency in seconds, zero - disabled
+	fallback bool
+
+	skips []time.Time
+	mu    sync.Mutex
+}
+
+func (a *mseAdaptive) update(clientTime, clientBuffer float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.latency <= 0 {
+		return
+	}
+
+	// written media time minus playback time: network queue and client buffer
+	latency := a.cons.Time() - clientTime
+	if latency <= a.latency {
+		return
+	}
+
+	now := time.Now()
+	if n := len(a.skips); n > 0 && now.Sub(a.skips[n-1]) < mseSkipInterval {
+		return
+	}
+
+	// keep only skips from the fallback window
+	for len(a.skips) > 0 && now.Sub(a.skips[0]) > mseFallbackWindow {
+		a.skips = a.skips[1:]
+	}
+	a.skips = append(a.skips, now)
+
+	if a.fallback && len(a.skips) >= mseFallbackSkips {
+		log.Debug().Msgf("[mp4] MSE audio-only fallback latency=%.1fs", latency)
+		a.fallback = false
+		if a.audioOnly() {
+			return
+		}
+	}
+
+	log.Trace().Msgf("[mp4] MSE skip to keyframe latency=%.1fs buffer=%.1fs", latency, clientBuffer)
+	a.cons.Skip()
+}
+
+// audioOnly - replace the consumer with audio-only consumer and send new codecs to the client
+func (a *mseAdaptive) audioOnly() bool {
+	var medias []*core.Media
+	for _, media := range a.cons.Medias {
+		if media.Kind == core.KindAudio {
+			medias = append(medias, media)
+		}
+	}
+	if medias == nil {
+		return false
+	}
+
+	cons := mp4.NewConsumer(medias)
+	cons.FormatName = "mse/fmp4"
+	cons.WithRequest(a.tr.Request)
+
+	if err := a.stream.AddConsumer(cons); err != nil {
+		log.Debug().Err(err).Msg("[mp4] MSE audio-only fallback")
+		return false
+	}
+
+	a.stream.RemoveConsumer(a.cons)
+	// old consumer may still flush video, so no writes after the new codecs message
+	a.wr.close()
+
+	a.cons = cons
+	a.wr = &mseWriter{tr: a.tr, wr: a.tr.Writer()}
+
+	go a.wr.start(cons)
+
+	return true
+}
+
+// mseWriter - consumer output to the WS transport, that can be closed before the consumer
+// is replaced, so the client never gets data of the old consumer after the new init
+type mseWriter struct {
+	tr     *ws.Transport
+	wr     io.Writer
+	closed bool
+	mu     sync.Mutex
+}
+
+// start - send codecs message and consumer data. Codecs of VP9 and AV1 are known only
+// after the first keyframe, so the message is sent under the same lock as data.
+func (w *mseWriter) start(cons *mp4.Consumer) {
+	msg := &ws.Message{Type: "mse", Value: cons.ContentType()}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.tr.Write(msg)
+	w.mu.Unlock()
+
+	_, _ = cons.WriteTo(w)
+}
+
+func (w *mseWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe // also stops consumer WriteTo
+	}
+	return w.wr.Write(p)
+}
+
+// close - waits for the current write to finish
+func (w *mseWriter) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+}
+
 func handlerWSMP4(tr *ws.Transport, msg *ws.Message) error {
 	stream, _ := streams.GetOrPatch(tr.Request.URL.Query())
 	if stream == nil {
@@ -6,6 +6,7 @@ import (
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/streams"
 	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/mpegts"
 )
 
@@ -30,11 +31,12 @@ func outputMpegTS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cons := mpegts.NewConsumer()
+	// optional codecs filter, ex. video&audio=aac,opus
+	cons := mpegts.NewConsumer(core.ParseQuery(r.URL.Query())...)
 	cons.WithRequest(r)
 
 	if err := stream.AddConsumer(cons); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
@@ -80,7 +82,7 @@ func apiStreamAAC(w http.ResponseWriter, r *http.Request) {
 	cons.WithRequest(r)
 
 	if err := stream.AddConsumer(cons); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
@@ -0,0 +1,68 @@
+package rtmp
+
+import (
+	"crypto/subtle"
+	"net/url"
+	"strings"
+
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/rtmp"
+)
+
+var paths map[string]string       // app/streamKey => stream name
+var publishKeys map[string]string // stream name => publish key
+var playTokens map[string]string  // stream name => play token
+
+// resolve - stream name and credential params for the RTMP connection,
+// query can be in the app (rtmp://host/live?key=xxx) or in the stream key (key?token=xxx)
+func resolve(conn *rtmp.Conn) (name, key string, query url.Values) {
+	app, rawQuery1, _ := strings.Cut(conn.App, "?")
+	key, rawQuery2, _ := strings.Cut(conn.Stream, "?")
+
+	query, _ = url.ParseQuery(rawQuery1)
+	if query2, _ := url.ParseQuery(rawQuery2); query2 != nil {
+		for k, v := range query2 {
+			query[k] = append(query[k], v...)
+		}
+	}
+
+	return streamName(app, key), key, query
+}
+
+// streamName - stream name from config paths, or app/streamKey, or stream key (OBS layout),
+// or app (rtmp://host/camera1 links)
+func streamName(app, key string) string {
+	path := app
+	if key != "" {
+		path += "/" + key
+	}
+
+	if name, ok := paths[path]; ok {
+		return name
+	}
+
+	for _, name := range []string{path, key, app} {
+		if name != "" && streams.Get(name) != nil {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// authorized - check secret for the stream if it set in config, secret can be
+// the query param or the stream key itself (rtmp://host/camera1 with stream key secret)
+func authorized(secrets map[string]string, name, param, key string, query url.Values) bool {
+	secret, ok := secrets[name]
+	if !ok {
+		return true
+	}
+
+	for _, s := range append([]string{key}, query[param]...) {
+		if subtle.ConstantTimeCompare([]byte(s), []byte(secret)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
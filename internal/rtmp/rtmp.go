@@ -1,6 +1,8 @@
 package rtmp
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"net"
@@ -18,12 +20,20 @@ import (
 func Init() {
 	var conf struct {
 		Mod struct {
-			Listen string `yaml:"listen" json:"listen"`
+			Listen      string            `yaml:"listen" json:"listen"`
+			TLSListen   string            `yaml:"tls_listen" json:"tls_listen,omitempty"`
+			Paths       map[string]string `yaml:"paths" json:"paths"`
+			PublishKeys map[string]string `yaml:"publish_keys" json:"-"`
+			PlayTokens  map[string]string `yaml:"play_tokens" json:"-"`
 		} `yaml:"rtmp"`
 	}
 
 	app.LoadConfig(&conf)
 
+	paths = conf.Mod.Paths
+	publishKeys = conf.Mod.PublishKeys
+	playTokens = conf.Mod.PlayTokens
+
 	log = app.GetLogger("rtmp")
 
 	streams.HandleFunc("rtmp", streamsHandle)
@@ -36,18 +46,39 @@ func Init() {
 	streams.HandleConsumerFunc("rtmps", streamsConsumerHandle)
 	streams.HandleConsumerFunc("rtmpx", streamsConsumerHandle)
 
-	address := conf.Mod.Listen
-	if address == "" {
-		return
+	if address := conf.Mod.Listen; address != "" {
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Error().Err(err).Caller().Send()
+		} else {
+			log.Info().Str("addr", address).Msg("[rtmp] listen")
+			serve(ln)
+		}
 	}
 
-	ln, err := net.Listen("tcp", address)
-	if err != nil {
-		log.Error().Err(err).Caller().Send()
-		return
+	// RTMP over TLS (rtmps) with same certificates as HTTPS API
+	if address := conf.Mod.TLSListen; address != "" {
+		config := api.TLSConfig()
+		if config == nil {
+			log.Error().Msg("[rtmp] tls_listen requires api tls_cert/tls_key or acme")
+			return
+		}
+
+		ln, err := tls.Listen("tcp", address, config)
+		if err != nil {
+			log.Error().Err(err).Caller().Send()
+			return
+		}
+
+		log.Info().Str("addr", address).Msg("[rtmp] tls listen")
+		serve(ln)
 	}
+}
 
-	log.Info().Str("addr", address).Msg("[rtmp] listen")
+func serve(ln net.Listener) {
+	app.OnShutdown(app.ShutdownListeners, func(ctx context.Context) {
+		_ = ln.Close()
+	})
 
 	go func() {
 		for {
@@ -75,14 +106,25 @@ func tcpHandle(netConn net.Conn) error {
 		return err
 	}
 
+	name, key, query := resolve(rtmpConn)
+
 	switch rtmpConn.Intent {
 	case rtmp.CommandPlay:
-		stream := streams.Get(rtmpConn.App)
-		if stream == nil {
-			return errors.New("stream not found: " + rtmpConn.App)
+		stream := streams.Get(name)
+		if stream == nil || !authorized(playTokens, name, "token", key, query) {
+			_ = rtmpConn.WriteError(rtmp.StatusPlayNotFound, "stream not found")
+			return errors.New("rtmp: play rejected: " + rtmpConn.App + "/" + rtmpConn.Stream)
 		}
 
-		cons := flv.NewConsumer()
+		// enhanced codecs only for clients that support them
+		var cons *flv.Consumer
+		if rtmpConn.FourCcList != nil || query.Has("enhanced") {
+			cons = flv.NewConsumer(flv.EnhancedMedias(rtmpConn.FourCcList...)...)
+		} else {
+			cons = flv.NewConsumer()
+		}
+		cons.Protocol = "rtmp"
+		cons.SetRemoteAddr(netConn.RemoteAddr().String())
 		if err = stream.AddConsumer(cons); err != nil {
 			return err
 		}
@@ -98,9 +140,10 @@ func tcpHandle(netConn net.Conn) error {
 		return nil
 
 	case rtmp.CommandPublish:
-		stream := streams.Get(rtmpConn.App)
-		if stream == nil {
-			return errors.New("stream not found: " + rtmpConn.App)
+		stream := streams.Get(name)
+		if stream == nil || !authorized(publishKeys, name, "key", key, query) {
+			_ = rtmpConn.WriteError(rtmp.StatusPublishBadName, "bad stream name or key")
+			return errors.New("rtmp: publish rejected: " + rtmpConn.App + "/" + rtmpConn.Stream)
 		}
 
 		if err = rtmpConn.WriteStart(); err != nil {
@@ -130,14 +173,15 @@ func streamsHandle(url string) (core.Producer, error) {
 	return rtmp.DialPlay(url)
 }
 
-func streamsConsumerHandle(url string) (core.Consumer, func(), error) {
+func streamsConsumerHandle(url string) (core.Consumer, func() error, error) {
 	cons := flv.NewConsumer()
-	run := func() {
+	run := func() error {
 		wr, err := rtmp.DialPublish(url, cons)
 		if err != nil {
-			return
+			return err
 		}
 		_, err = cons.WriteTo(wr)
+		return err
 	}
 
 	return cons, run, nil
@@ -159,11 +203,17 @@ func outputFLV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cons := flv.NewConsumer()
+	var cons *flv.Consumer
+	if r.URL.Query().Has("enhanced") {
+		cons = flv.NewConsumer(flv.EnhancedMedias()...)
+	} else {
+		cons = flv.NewConsumer()
+	}
 	cons.WithRequest(r)
 
 	if err := stream.AddConsumer(cons); err != nil {
 		log.Error().Err(err).Caller().Send()
+		http.Error(w, err.Error(), streams.StatusCode(err))
 		return
 	}
 
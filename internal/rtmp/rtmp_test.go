@@ -0,0 +1,26 @@
+package rtmp
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/AlexxIT/go2rtc/pkg/rtmp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	paths = map[string]string{"live/obs_key": "camera1"}
+	publishKeys = map[string]string{"camera1": "obs_key"}
+
+	name, key, query := resolve(&rtmp.Conn{App: "live", Stream: "obs_key"})
+	require.Equal(t, "camera1", name)
+	require.True(t, authorized(publishKeys, name, "key", key, query))
+
+	name, key, query = resolve(&rtmp.Conn{App: "live?key=wrong", Stream: "obs_key?token=123"})
+	require.Equal(t, "camera1", name)
+	require.Equal(t, url.Values{"key": {"wrong"}, "token": {"123"}}, query)
+
+	require.True(t, authorized(publishKeys, "camera1", "key", "", url.Values{"key": {"obs_key"}}))
+	require.False(t, authorized(publishKeys, "camera1", "key", "camera1", nil))
+	require.True(t, authorized(publishKeys, "camera2", "key", "", nil))
+}
@@ -1,12 +1,15 @@
 package rtsp
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"net"
 	"net/url"
 	"strings"
 
+	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/app"
 	"github.com/AlexxIT/go2rtc/internal/streams"
 	"github.com/AlexxIT/go2rtc/pkg/core"
@@ -19,6 +22,7 @@ func Init() {
 	var conf struct {
 		Mod struct {
 			Listen       string `yaml:"listen" json:"listen"`
+			TLSListen    string `yaml:"tls_listen" json:"tls_listen,omitempty"`
 			Username     string `yaml:"username" json:"-"`
 			Password     string `yaml:"password" json:"-"`
 			DefaultQuery string `yaml:"default_query" json:"default_query"`
@@ -41,41 +45,61 @@ func Init() {
 	streams.HandleFunc("rtspx", rtspHandler)
 
 	// RTSP server support
-	address := conf.Mod.Listen
-	if address == "" {
-		return
+	if query, err := url.ParseQuery(conf.Mod.DefaultQuery); err == nil {
+		defaultMedias = ParseQuery(query)
 	}
 
-	ln, err := net.Listen("tcp", address)
-	if err != nil {
-		log.Error().Err(err).Msg("[rtsp] listen")
-		return
-	}
+	serve := func(ln net.Listener) {
+		app.OnShutdown(app.ShutdownListeners, func(ctx context.Context) {
+			_ = ln.Close()
+		})
 
-	_, Port, _ = net.SplitHostPort(address)
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
 
-	log.Info().Str("addr", address).Msg("[rtsp] listen")
+				c := rtsp.NewServer(conn)
+				c.PacketSize = conf.Mod.PacketSize
+				// skip check auth for localhost
+				if conf.Mod.Username != "" && !conn.RemoteAddr().(*net.TCPAddr).IP.IsLoopback() {
+					c.Auth(conf.Mod.Username, conf.Mod.Password)
+				}
+				go tcpHandler(c)
+			}
+		}()
+	}
 
-	if query, err := url.ParseQuery(conf.Mod.DefaultQuery); err == nil {
-		defaultMedias = ParseQuery(query)
+	if address := conf.Mod.Listen; address != "" {
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Error().Err(err).Msg("[rtsp] listen")
+		} else {
+			_, Port, _ = net.SplitHostPort(address)
+			log.Info().Str("addr", address).Msg("[rtsp] listen")
+			serve(ln)
+		}
 	}
 
-	go func() {
-		for {
-			conn, err := ln.Accept()
-			if err != nil {
-				return
-			}
+	// RTSP over TLS (rtsps) with same certificates as HTTPS API
+	if address := conf.Mod.TLSListen; address != "" {
+		config := api.TLSConfig()
+		if config == nil {
+			log.Error().Msg("[rtsp] tls_listen requires api tls_cert/tls_key or acme")
+			return
+		}
 
-			c := rtsp.NewServer(conn)
-			c.PacketSize = conf.Mod.PacketSize
-			// skip check auth for localhost
-			if conf.Mod.Username != "" && !conn.RemoteAddr().(*net.TCPAddr).IP.IsLoopback() {
-				c.Auth(conf.Mod.Username, conf.Mod.Password)
-			}
-			go tcpHandler(c)
+		ln, err := tls.Listen("tcp", address, config)
+		if err != nil {
+			log.Error().Err(err).Msg("[rtsp] tls listen")
+			return
 		}
-	}()
+
+		log.Info().Str("addr", address).Msg("[rtsp] tls listen")
+		serve(ln)
+	}
 }
 
 type Handler func(conn *rtsp.Conn) bool
@@ -220,6 +244,9 @@ func tcpHandler(conn *rtsp.Conn) {
 
 			if err := stream.AddConsumer(conn); err != nil {
 				log.WithLevel(level).Err(err).Str("stream", name).Msg("[rtsp]")
+				if errors.Is(err, streams.ErrLimit) {
+					conn.RejectStatus = "453 Not Enough Bandwidth"
+				}
 				return
 			}
 
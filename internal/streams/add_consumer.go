@@ -2,16 +2,41 @@ package streams
 
 import (
 	"errors"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/pcm"
 )
 
+// match passes for each consumer media
+const (
+	matchDirect = iota
+	matchPCM    // in-process audio transcoding
+	matchFFmpeg // on-demand transcoding producer
+)
+
+// Selector - consumer that accepts only some producers of the stream
+type Selector interface {
+	SelectSource(source string) bool
+}
+
 func (s *Stream) AddConsumer(cons core.Consumer) (err error) {
+	if err = s.acquire(cons); err != nil {
+		return err
+	}
+
 	// support for multiple simultaneous pending from different consumers
 	consN := s.pending.Add(1) - 1
 
-	var prodErrors = make([]error, len(s.producers))
+	s.mu.Lock()
+	producers := s.producers
+	s.mu.Unlock()
+
+	prodCount := len(producers)
+
+	var prodErrors = make([]error, prodCount)
 	var prodMedias []*core.Media
 	var prodStarts []*Producer
 
@@ -20,74 +45,123 @@ func (s *Stream) AddConsumer(cons core.Consumer) (err error) {
 	for _, consMedia := range consMedias {
 		log.Trace().Msgf("[streams] check cons=%d media=%s", consN, consMedia)
 
-	producers:
-		for prodN, prod := range s.producers {
-			// check for loop request, ex. `camera1: ffmpeg:camera1`
-			if info, ok := cons.(core.Info); ok && prod.url == info.GetSource() {
-				log.Trace().Msgf("[streams] skip cons=%d prod=%d", consN, prodN)
-				continue
-			}
+		var matched bool
 
-			if prodErrors[prodN] != nil {
-				log.Trace().Msgf("[streams] skip cons=%d prod=%d", consN, prodN)
-				continue
+		for pass := matchDirect; pass <= matchFFmpeg && !matched; pass++ {
+			switch pass {
+			case matchPCM:
+				if consMedia.Kind != core.KindAudio {
+					continue
+				}
+			case matchFFmpeg:
+				prod := s.transcodeProducer(cons, consMedia, prodMedias)
+				if prod == nil {
+					continue
+				}
+				if !slices.Contains(producers, prod) {
+					producers = append(producers, prod)
+					prodErrors = append(prodErrors, nil)
+				}
 			}
 
-			if err = prod.Dial(); err != nil {
-				log.Trace().Err(err).Msgf("[streams] dial cons=%d prod=%d", consN, prodN)
-				prodErrors[prodN] = err
-				continue
-			}
+		producers:
+			for prodN, prod := range producers {
+				if pass == matchFFmpeg && prod.transcode == nil {
+					continue
+				}
 
-			// Step 2. Get producer medias (not tracks yet)
-			for _, prodMedia := range prod.GetMedias() {
-				log.Trace().Msgf("[streams] check cons=%d prod=%d media=%s", consN, prodN, prodMedia)
-				prodMedias = append(prodMedias, prodMedia)
+				// check for loop request, ex. `camera1: ffmpeg:camera1`
+				if info, ok := cons.(core.Info); ok && prod.url == info.GetSource() {
+					log.Trace().Msgf("[streams] skip cons=%d prod=%d", consN, prodN)
+					continue
+				}
 
-				// Step 3. Match consumer/producer codecs list
-				prodCodec, consCodec := prodMedia.MatchMedia(consMedia)
-				if prodCodec == nil {
+				// consumer can select producers, ex. HLS variants from main and sub streams
+				if sel, ok := cons.(Selector); ok && !sel.SelectSource(prod.url) {
 					continue
 				}
 
-				var track *core.Receiver
+				if prodErrors[prodN] != nil {
+					log.Trace().Msgf("[streams] skip cons=%d prod=%d", consN, prodN)
+					continue
+				}
 
-				switch prodMedia.Direction {
-				case core.DirectionRecvonly:
-					log.Trace().Msgf("[streams] match cons=%d <= prod=%d", consN, prodN)
+				if err = prod.Dial(); err != nil {
+					log.Trace().Err(err).Msgf("[streams] dial cons=%d prod=%d", consN, prodN)
+					prodErrors[prodN] = err
+					continue
+				}
 
-					// Step 4. Get recvonly track from producer
-					if track, err = prod.GetTrack(prodMedia, prodCodec); err != nil {
-						log.Info().Err(err).Msg("[streams] can't get track")
-						prodErrors[prodN] = err
-						continue
+				// Step 2. Get producer medias (not tracks yet)
+				for _, prodMedia := range prod.GetMedias() {
+					log.Trace().Msgf("[streams] check cons=%d prod=%d media=%s", consN, prodN, prodMedia)
+
+					// Step 3. Match consumer/producer codecs list
+					var prodCodec, consCodec *core.Codec
+					if pass == matchPCM {
+						prodCodec, consCodec = pcm.MatchTranscode(prodMedia, consMedia)
+					} else {
+						if pass == matchDirect {
+							prodMedias = append(prodMedias, prodMedia)
+						}
+						prodCodec, consCodec = prodMedia.MatchMedia(consMedia)
 					}
-					// Step 5. Add track to consumer
-					if err = cons.AddTrack(consMedia, consCodec, track); err != nil {
-						log.Info().Err(err).Msg("[streams] can't add track")
+					if prodCodec == nil {
 						continue
 					}
 
-				case core.DirectionSendonly:
-					log.Trace().Msgf("[streams] match cons=%d => prod=%d", consN, prodN)
-
-					// Step 4. Get recvonly track from consumer (backchannel)
-					if track, err = cons.(core.Producer).GetTrack(consMedia, consCodec); err != nil {
-						log.Info().Err(err).Msg("[streams] can't get track")
-						continue
-					}
-					// Step 5. Add track to producer
-					if err = prod.AddTrack(prodMedia, prodCodec, track); err != nil {
-						log.Info().Err(err).Msg("[streams] can't add track")
-						prodErrors[prodN] = err
-						continue
+					var track *core.Receiver
+
+					switch prodMedia.Direction {
+					case core.DirectionRecvonly:
+						log.Trace().Msgf("[streams] match cons=%d <= prod=%d pass=%d", consN, prodN, pass)
+
+						// Step 4. Get recvonly track from producer
+						if track, err = prod.GetTrack(prodMedia, prodCodec); err != nil {
+							log.Info().Err(err).Msg("[streams] can't get track")
+							prodErrors[prodN] = err
+							continue
+						}
+						if pass == matchPCM {
+							if track, err = pcm.TranscodeReceiver(consMedia, consCodec, track, dropLogger(track.Codec)); err != nil {
+								log.Info().Err(err).Msg("[streams] can't transcode track")
+								continue
+							}
+						}
+						// Step 5. Add track to consumer
+						if err = cons.AddTrack(consMedia, consCodec, track); err != nil {
+							log.Info().Err(err).Msg("[streams] can't add track")
+							continue
+						}
+
+					case core.DirectionSendonly:
+						log.Trace().Msgf("[streams] match cons=%d => prod=%d pass=%d", consN, prodN, pass)
+
+						// Step 4. Get recvonly track from consumer (backchannel)
+						if track, err = cons.(core.Producer).GetTrack(consMedia, consCodec); err != nil {
+							log.Info().Err(err).Msg("[streams] can't get track")
+							continue
+						}
+						if pass == matchPCM {
+							if track, err = pcm.TranscodeReceiver(prodMedia, prodCodec, track, dropLogger(track.Codec)); err != nil {
+								log.Info().Err(err).Msg("[streams] can't transcode track")
+								continue
+							}
+						}
+						// Step 5. Add track to producer
+						if err = prod.AddTrack(prodMedia, prodCodec, track); err != nil {
+							log.Info().Err(err).Msg("[streams] can't add track")
+							prodErrors[prodN] = err
+							continue
+						}
 					}
-				}
 
-				prodStarts = append(prodStarts, prod)
+					prodStarts = append(prodStarts, prod)
+					matched = true
 
-				if !consMedia.MatchAll() {
-					break producers
+					if !consMedia.MatchAll() {
+						break producers
+					}
 				}
 			}
 		}
@@ -99,7 +173,9 @@ func (s *Stream) AddConsumer(cons core.Consumer) (err error) {
 	}
 
 	if len(prodStarts) == 0 {
-		return formatError(consMedias, prodMedias, prodErrors)
+		s.release(cons)
+		// skip errors from transcoding producers
+		return formatError(consMedias, prodMedias, prodErrors[:prodCount])
 	}
 
 	s.mu.Lock()
@@ -164,3 +240,13 @@ func appendString(s, elem string) string {
 	}
 	return s + ", " + elem
 }
+
+// dropLogger - warn once per transcoded track about packets that can't be decoded
+func dropLogger(codec *core.Codec) func(error) {
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			log.Warn().Err(err).Msgf("[streams] transcode drops packets codec=%s", codec)
+		})
+	}
+}
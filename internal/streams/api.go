@@ -2,6 +2,7 @@ package streams
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/app"
@@ -31,11 +32,16 @@ func apiStreams(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if query.Has("analyze") {
+			apiAnalyze(w, r, stream)
+			return
+		}
+
 		cons := probe.Create("probe", query)
 		if len(cons.Medias) != 0 {
 			cons.WithRequest(r)
 			if err := stream.AddConsumer(cons); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				http.Error(w, err.Error(), StatusCode(err))
 				return
 			}
 
@@ -87,8 +93,12 @@ func apiStreams(w http.ResponseWriter, r *http.Request) {
 			} else if stream = Get(src); stream != nil {
 				if err := Validate(dst); err != nil {
 					http.Error(w, err.Error(), http.StatusBadRequest)
+				} else if err = validatePublish(dst); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
 				} else if err = stream.Publish(dst); err != nil {
 					http.Error(w, err.Error(), http.StatusInternalServerError)
+				} else {
+					api.ResponseJSON(w, stream)
 				}
 			} else {
 				http.Error(w, "", http.StatusNotFound)
@@ -98,7 +108,17 @@ func apiStreams(w http.ResponseWriter, r *http.Request) {
 		}
 
 	case "DELETE":
-		delete(streams, src)
+		// with dst - stop publish stream to dst
+		if dst := query.Get("dst"); dst != "" {
+			if stream := Get(src); stream == nil {
+				http.Error(w, "", http.StatusNotFound)
+			} else if err := stream.StopPublish(dst); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+			}
+			return
+		}
+
+		Delete(src)
 
 		if err := app.PatchConfig([]string{"streams", src}, nil); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -106,6 +126,32 @@ func apiStreams(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// apiAnalyze - connect passive analyzer to the stream and return tracks health after duration seconds
+func apiAnalyze(w http.ResponseWriter, r *http.Request, stream *Stream) {
+	query := r.URL.Query()
+
+	duration := 5 * time.Second
+	if i := core.Atoi(query.Get("duration")); i > 0 {
+		duration = time.Duration(i) * time.Second
+	}
+
+	cons := probe.NewAnalyzer(query)
+	cons.WithRequest(r)
+	if err := stream.AddConsumer(cons); err != nil {
+		http.Error(w, err.Error(), StatusCode(err))
+		return
+	}
+
+	select {
+	case <-time.After(duration):
+	case <-r.Context().Done():
+	}
+
+	stream.RemoveConsumer(cons)
+
+	api.ResponsePrettyJSON(w, cons)
+}
+
 func apiStreamsDOT(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
@@ -0,0 +1,86 @@
+package streams
+
+import (
+	"image"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+)
+
+// Display - stream display transforms without transcoding:
+// MP4 matrix, H264/H265 SEI display orientation and snapshots processing
+type Display struct {
+	Rotate int             // clockwise degrees: 90, 180, 270
+	ScaleX int             // pixel aspect ratio, ex. 4:3 for anamorphic 1440x1080
+	ScaleY int             //
+	Crop   image.Rectangle // in source pixels
+}
+
+// ParseDisplay - rotate in degrees, aspect in format "4:3", crop in format "w:h:x:y" (like FFmpeg crop filter)
+func ParseDisplay(rotate int, aspect, crop string) *Display {
+	d := &Display{}
+
+	switch rotate {
+	case 90, 180, 270:
+		d.Rotate = rotate
+	}
+
+	if sx, sy, ok := strings.Cut(aspect, ":"); ok {
+		d.ScaleX = core.Atoi(sx)
+		d.ScaleY = core.Atoi(sy)
+	}
+
+	if ss := strings.Split(crop, ":"); len(ss) == 4 {
+		w, h := core.Atoi(ss[0]), core.Atoi(ss[1])
+		x, y := core.Atoi(ss[2]), core.Atoi(ss[3])
+		d.Crop = image.Rect(x, y, x+w, y+h)
+	}
+
+	if d.Rotate == 0 && (d.ScaleX == 0 || d.ScaleY == 0) && d.Crop.Empty() {
+		return nil
+	}
+
+	return d
+}
+
+// Filter - SEI display orientation for video codecs, nil if not supported
+func (d *Display) Filter(codec *core.Codec) core.Filter {
+	if d == nil || d.Rotate == 0 {
+		return nil
+	}
+
+	switch codec.Name {
+	case core.CodecH264:
+		sei := h264.DisplayOrientationSEI(d.Rotate)
+		return func(handler core.HandlerFunc) core.HandlerFunc {
+			return h264.InsertSEI(codec, sei, handler)
+		}
+	case core.CodecH265:
+		sei := h265.DisplayOrientationSEI(d.Rotate)
+		return func(handler core.HandlerFunc) core.HandlerFunc {
+			return h265.InsertSEI(codec, sei, handler)
+		}
+	}
+
+	return nil
+}
+
+// SetQuery - add display transforms to the snapshot query if the query has no own values
+func (d *Display) SetQuery(query url.Values) {
+	if d == nil {
+		return
+	}
+	if d.Rotate != 0 && !query.Has("rotate") {
+		query.Set("rotate", strconv.Itoa(d.Rotate))
+	}
+	if d.ScaleX != 0 && d.ScaleY != 0 && !query.Has("aspect") {
+		query.Set("aspect", strconv.Itoa(d.ScaleX)+":"+strconv.Itoa(d.ScaleY))
+	}
+	if r := d.Crop; !r.Empty() && !query.Has("crop") {
+		query.Set("crop", strconv.Itoa(r.Dx())+":"+strconv.Itoa(r.Dy())+":"+strconv.Itoa(r.Min.X)+":"+strconv.Itoa(r.Min.Y))
+	}
+}
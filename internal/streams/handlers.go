@@ -92,7 +92,8 @@ func Location(url string) (string, error) {
 
 // TODO: rework
 
-type ConsumerHandler func(url string) (core.Consumer, func(), error)
+// ConsumerHandler - returns consumer and blocking run function, that returns on publish end
+type ConsumerHandler func(url string) (core.Consumer, func() error, error)
 
 var consumerHandlers = map[string]ConsumerHandler{}
 
@@ -100,7 +101,15 @@ func HandleConsumerFunc(scheme string, handler ConsumerHandler) {
 	consumerHandlers[scheme] = handler
 }
 
-func GetConsumer(url string) (core.Consumer, func(), error) {
+func HasConsumer(url string) bool {
+	if i := strings.IndexByte(url, ':'); i > 0 {
+		_, ok := consumerHandlers[url[:i]]
+		return ok
+	}
+	return false
+}
+
+func GetConsumer(url string) (core.Consumer, func() error, error) {
 	if i := strings.IndexByte(url, ':'); i > 0 {
 		scheme := url[:i]
 
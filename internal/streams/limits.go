@@ -0,0 +1,176 @@
+package streams
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+var ErrLimit = errors.New("streams: consumers limit reached")
+
+// StatusCode - HTTP status for AddConsumer error
+func StatusCode(err error) int {
+	if errors.Is(err, ErrLimit) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+type limitsConfig struct {
+	Stream int `yaml:"stream"` // max consumers per stream
+	IP     int `yaml:"ip"`     // max consumers per remote IP (for all streams)
+	User   int `yaml:"user"`   // max consumers per authorized user (for all streams)
+
+	TrustedProxies []string `yaml:"trusted_proxies"` // IP or CIDR of reverse proxies
+}
+
+var limits limitsConfig
+var trustedProxies []*net.IPNet
+
+var limitsIP = map[string]int{}
+var limitsUser = map[string]int{}
+var limitsMu sync.Mutex
+
+type client interface {
+	GetRemoteAddr() string
+	GetUser() string
+}
+
+func setLimits(cfg limitsConfig) {
+	limits = cfg
+	trustedProxies = nil
+
+	for _, s := range cfg.TrustedProxies {
+		if !strings.Contains(s, "/") {
+			if strings.Contains(s, ":") {
+				s += "/128"
+			} else {
+				s += "/32"
+			}
+		}
+		if _, ipnet, err := net.ParseCIDR(s); err == nil {
+			trustedProxies = append(trustedProxies, ipnet)
+		} else {
+			log.Warn().Err(err).Caller().Send()
+		}
+	}
+}
+
+func isTrustedProxy(ip net.IP) bool {
+	for _, ipnet := range trustedProxies {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientInfo - return remote IP and user of consumer. Internal consumers
+// (without remote address) and loopback IP are not limited, because loopback
+// is used by internal transcoding (ffmpeg, exec).
+func clientInfo(cons core.Consumer) (ip, user string, ok bool) {
+	c, ok := cons.(client)
+	if !ok || c.GetRemoteAddr() == "" {
+		return "", "", false
+	}
+
+	// use real connection address, because `forwarded` header can be fake,
+	// forwarded address is used only from trusted reverse proxy
+	addr, forwarded, _ := strings.Cut(c.GetRemoteAddr(), " forwarded ")
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	if forwarded != "" && isTrustedProxy(net.ParseIP(addr)) {
+		// X-Forwarded-For: client, proxy1, proxy2 - the last address is added by our proxy
+		if i := strings.LastIndexByte(forwarded, ','); i >= 0 {
+			forwarded = forwarded[i+1:]
+		}
+		addr = strings.TrimSpace(forwarded)
+	}
+
+	if ip := net.ParseIP(addr); ip != nil && ip.IsLoopback() {
+		return "", "", false
+	}
+
+	return addr, c.GetUser(), true
+}
+
+func (s *Stream) acquire(cons core.Consumer) error {
+	ip, user, ok := clientInfo(cons)
+	if !ok {
+		return nil
+	}
+
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+
+	maxStream := limits.Stream
+	if s.maxConsumers != 0 {
+		maxStream = s.maxConsumers
+	}
+
+	switch {
+	case maxStream > 0 && s.limited >= maxStream:
+	case limits.IP > 0 && ip != "" && limitsIP[ip] >= limits.IP:
+	case limits.User > 0 && user != "" && limitsUser[user] >= limits.User:
+	default:
+		s.limited++
+		if ip != "" {
+			limitsIP[ip]++
+		}
+		if user != "" {
+			limitsUser[user]++
+		}
+		return nil
+	}
+
+	s.rejected++
+	return ErrLimit
+}
+
+func (s *Stream) release(cons core.Consumer) {
+	ip, user, ok := clientInfo(cons)
+	if !ok {
+		return
+	}
+
+	limitsMu.Lock()
+	s.limited--
+	if ip != "" {
+		if limitsIP[ip]--; limitsIP[ip] <= 0 {
+			delete(limitsIP, ip)
+		}
+	}
+	if user != "" {
+		if limitsUser[user]--; limitsUser[user] <= 0 {
+			delete(limitsUser, user)
+		}
+	}
+	limitsMu.Unlock()
+}
+
+type limitsInfo struct {
+	MaxConsumers int `json:"max_consumers,omitempty"`
+	Consumers    int `json:"consumers"`
+	Rejected     int `json:"rejected"`
+}
+
+func (s *Stream) limitsInfo() *limitsInfo {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+
+	if limits.Stream == 0 && limits.IP == 0 && limits.User == 0 && s.maxConsumers == 0 {
+		return nil
+	}
+
+	info := &limitsInfo{MaxConsumers: limits.Stream, Consumers: s.limited, Rejected: s.rejected}
+	if s.maxConsumers != 0 {
+		info.MaxConsumers = s.maxConsumers
+	}
+	return info
+}
@@ -3,6 +3,7 @@ package streams
 import (
 	"encoding/json"
 	"errors"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -24,8 +25,10 @@ const (
 type Producer struct {
 	core.Listener
 
-	url      string
-	template string
+	url       string
+	template  string
+	transcode *core.Codec // target codec for on-demand transcoding producer
+	stream    *Stream     // parent stream, display transforms are taken on each new track
 
 	conn      core.Producer
 	receivers []*core.Receiver
@@ -34,6 +37,12 @@ type Producer struct {
 	state    state
 	mu       sync.Mutex
 	workerID int
+
+	// video stall watchdog state (#stall=10)
+	stall     time.Duration
+	stalled   bool
+	stalls    int
+	lastStall time.Time
 }
 
 const SourceTemplate = "{input}"
@@ -101,6 +110,8 @@ func (p *Producer) GetTrack(media *core.Media, codec *core.Codec) (*core.Receive
 		return nil, err
 	}
 
+	p.applyFilters(track)
+
 	p.receivers = append(p.receivers, track)
 
 	if p.state == stateMedias {
@@ -141,6 +152,48 @@ func (p *Producer) MarshalJSON() ([]byte, error) {
 
 // internals
 
+// applyFilters - timestamps filters from the source URL fragment for unstable sources:
+// #jitter=32 - reorder RTP packets with buffer of N packets,
+// #timestamps=rebase - fix timestamp jumps, #timestamps=wallclock - timestamps from arrival time,
+// and SEI display orientation from the stream config
+func (p *Producer) applyFilters(track *core.Receiver) {
+	var filters []core.Filter
+
+	if query := p.fragment(); query != nil {
+		if n := core.Atoi(query.Get("jitter")); n > 0 && track.Codec.IsRTP() {
+			filters = append(filters, core.JitterBuffer(n))
+		}
+
+		if clockRate := track.Codec.ClockRate; clockRate != 0 {
+			switch query.Get("timestamps") {
+			case "rebase":
+				filters = append(filters, core.TimestampRebase(clockRate))
+			case "wallclock":
+				filters = append(filters, core.TimestampWallclock(clockRate))
+			}
+		}
+	}
+
+	if p.stream != nil {
+		if filter := p.stream.display.Filter(track.Codec); filter != nil {
+			filters = append(filters, filter)
+		}
+	}
+
+	// first filter gets packets first
+	for i := len(filters) - 1; i >= 0; i-- {
+		track.Input = filters[i](track.Input)
+	}
+}
+
+// fragment - options from the source URL fragment, nil if there are no options
+func (p *Producer) fragment() url.Values {
+	if _, rawQuery, ok := strings.Cut(p.url, "#"); ok {
+		return ParseQuery(rawQuery)
+	}
+	return nil
+}
+
 func (p *Producer) start() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -155,6 +208,12 @@ func (p *Producer) start() {
 	p.workerID++
 
 	go p.worker(p.conn, p.workerID)
+
+	// #stall=10 - restart the source if there is no video for 10 seconds
+	if i := core.Atoi(p.fragment().Get("stall")); i > 0 {
+		p.stall = time.Duration(i) * time.Second
+		go p.watchdog(p.workerID, p.stall)
+	}
 }
 
 func (p *Producer) worker(conn core.Producer, workerID int) {
@@ -217,6 +276,8 @@ func (p *Producer) reconnect(workerID, retry int) {
 					continue
 				}
 
+				p.applyFilters(track)
+
 				receiver.Replace(track)
 				p.receivers[i] = track
 				break
@@ -242,6 +303,91 @@ func (p *Producer) reconnect(workerID, retry int) {
 	go p.worker(conn, workerID)
 }
 
+// watchdog - mark the source unhealthy when video packets stop coming, even if the connection
+// is still alive, and stop the connection, so the worker reconnects it
+func (p *Producer) watchdog(workerID int, timeout time.Duration) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var packets int64
+	lastTime := time.Now()
+
+	for now := range ticker.C {
+		p.mu.Lock()
+		if p.workerID != workerID {
+			p.mu.Unlock()
+			return
+		}
+
+		var n int64
+		var video bool
+		for _, receiver := range p.receivers {
+			if receiver.Codec.IsVideo() {
+				n += receiver.Packets.Load()
+				video = true
+			}
+		}
+
+		if !video || n != packets {
+			packets = n
+			lastTime = now
+			p.stalled = false
+		} else if now.Sub(lastTime) > timeout {
+			log.Warn().Msgf("[streams] video stall for %s url=%s", timeout, p.url)
+			lastTime = now
+			p.stalled = true
+			p.stalls++
+			p.lastStall = now
+			_ = p.conn.Stop()
+		}
+		p.mu.Unlock()
+	}
+}
+
+type healthInfo struct {
+	URL       string     `json:"url"`
+	Stalled   bool       `json:"stalled"`
+	Stalls    int        `json:"stalls,omitempty"`
+	LastStall *time.Time `json:"last_stall,omitempty"`
+}
+
+// healthInfo - watchdog state for producers with #stall option
+func (s *Stream) healthInfo() (info []healthInfo) {
+	for _, prod := range s.producers {
+		prod.mu.Lock()
+		if prod.stall != 0 {
+			i := healthInfo{URL: prod.url, Stalled: prod.stalled, Stalls: prod.stalls}
+			if prod.stalls != 0 {
+				lastStall := prod.lastStall
+				i.LastStall = &lastStall
+			}
+			info = append(info, i)
+		}
+		prod.mu.Unlock()
+	}
+	return
+}
+
+// shutdown - stop producer in any state, including external (RTSP ANNOUNCE, WebRTC WHIP...)
+func (p *Producer) shutdown() {
+	p.mu.Lock()
+	if p.state == stateExternal {
+		p.mu.Unlock()
+		log.Debug().Msgf("[streams] stop external producer")
+		_ = p.conn.Stop()
+		return
+	}
+	p.mu.Unlock()
+
+	p.stop()
+}
+
+func (p *Producer) isNone() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state == stateNone
+}
+
 func (p *Producer) stop() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -1,29 +1,117 @@
 package streams
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+// Restream - publish the stream to the destination with reconnects,
+// destination can have options for shared transcoding: rtmp://...#video=h264#audio=aac
+type Restream struct {
+	stream *Stream
+	url    string
+
+	cons    core.Consumer
+	state   string
+	err     error
+	retries int       // failed attempts in a row
+	since   time.Time // last state change
+	send    int       // bytes from previous connections
+
+	stop chan struct{}
+	mu   sync.Mutex
+}
+
+const (
+	restreamConnecting = "connecting"
+	restreamLive       = "live"
+	restreamFailed     = "failed"
+)
+
+const (
+	backoffMin   = time.Second
+	backoffMax   = 5 * time.Minute
+	backoffReset = time.Minute // connection longer than this resets retries
+)
+
+var restreams []*Restream
+var restreamsMu sync.Mutex
 
 func (s *Stream) Publish(url string) error {
-	cons, run, err := GetConsumer(url)
-	if err != nil {
+	if err := validatePublish(url); err != nil {
 		return err
 	}
 
-	if err = s.AddConsumer(cons); err != nil {
-		return err
+	restreamsMu.Lock()
+	defer restreamsMu.Unlock()
+
+	for _, r := range restreams {
+		if r.stream == s && r.url == url {
+			return errors.New("streams: already publishing: " + url)
+		}
 	}
 
-	go func() {
-		run()
-		s.RemoveConsumer(cons)
+	r := &Restream{stream: s, url: url, stop: make(chan struct{})}
+	restreams = append(restreams, r)
 
-		// TODO: more smart retry
-		time.Sleep(5 * time.Second)
-		_ = s.Publish(url)
-	}()
+	go r.run()
 
 	return nil
 }
 
+func (s *Stream) StopPublish(url string) error {
+	restreamsMu.Lock()
+	defer restreamsMu.Unlock()
+
+	for i, r := range restreams {
+		if r.stream == s && r.url == url {
+			restreams = append(restreams[:i], restreams[i+1:]...)
+			r.close()
+			return nil
+		}
+	}
+
+	return errors.New("streams: publish not found: " + url)
+}
+
+// validatePublish - check the scheme and the codecs options of the destination,
+// consumer handlers connect only on run, so it is safe to create the consumer here
+func validatePublish(url string) error {
+	if !HasConsumer(url) {
+		return errors.New("streams: unsupported scheme: " + url)
+	}
+
+	dst, rawQuery, _ := strings.Cut(url, "#")
+	if rawQuery == "" {
+		return nil
+	}
+
+	cons, _, err := GetConsumer(dst)
+	if err != nil {
+		return err
+	}
+
+	return forceCodecs(cons.GetMedias(), ParseQuery(rawQuery))
+}
+
+func (s *Stream) restreams() (items []*Restream) {
+	restreamsMu.Lock()
+	for _, r := range restreams {
+		if r.stream == s {
+			items = append(items, r)
+		}
+	}
+	restreamsMu.Unlock()
+	return
+}
+
 func Publish(stream *Stream, destination any) {
 	switch v := destination.(type) {
 	case string:
@@ -36,3 +124,172 @@ func Publish(stream *Stream, destination any) {
 		}
 	}
 }
+
+func (r *Restream) run() {
+	for {
+		start := time.Now()
+
+		err := r.publish()
+
+		if shutdown.Load() || r.closed() {
+			return
+		}
+
+		r.mu.Lock()
+		if time.Since(start) > backoffReset {
+			r.retries = 0
+		}
+		delay := backoff(r.retries)
+		r.retries++
+		r.setState(restreamFailed, err)
+		r.mu.Unlock()
+
+		log.Warn().Err(err).Msgf("[streams] publish retry in %s url=%s", delay.Round(time.Second), r.url)
+
+		select {
+		case <-r.stop:
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (r *Restream) publish() error {
+	dst, rawQuery, _ := strings.Cut(r.url, "#")
+
+	cons, run, err := GetConsumer(dst)
+	if err != nil {
+		return err
+	}
+
+	if rawQuery != "" {
+		if err = forceCodecs(cons.GetMedias(), ParseQuery(rawQuery)); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	if r.closed() {
+		r.mu.Unlock()
+		return nil
+	}
+	r.cons = cons
+	r.setState(restreamConnecting, nil)
+	r.mu.Unlock()
+
+	if err = r.stream.AddConsumer(cons); err == nil {
+		err = run()
+		r.stream.RemoveConsumer(cons)
+	}
+
+	r.mu.Lock()
+	r.send += getSend(cons)
+	r.cons = nil
+	r.mu.Unlock()
+
+	if err == nil {
+		err = errors.New("streams: publish closed")
+	}
+
+	return err
+}
+
+func (r *Restream) close() {
+	r.mu.Lock()
+	close(r.stop)
+	cons := r.cons
+	r.mu.Unlock()
+
+	if cons != nil {
+		r.stream.RemoveConsumer(cons)
+	}
+}
+
+func (r *Restream) closed() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *Restream) setState(state string, err error) {
+	r.state = state
+	r.err = err
+	r.since = time.Now()
+}
+
+func (r *Restream) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info := struct {
+		URL     string    `json:"url"`
+		State   string    `json:"state"`
+		Since   time.Time `json:"since"`
+		Error   string    `json:"error,omitempty"`
+		Retries int       `json:"retries,omitempty"`
+		Send    int       `json:"bytes_send,omitempty"`
+	}{
+		URL:     r.url,
+		State:   r.state,
+		Since:   r.since,
+		Retries: r.retries,
+		Send:    r.send,
+	}
+
+	if r.err != nil {
+		info.Error = r.err.Error()
+	}
+
+	if r.cons != nil {
+		// consumer is live after the first sent bytes
+		if n := getSend(r.cons); n > 0 {
+			info.State = restreamLive
+			info.Send += n
+		}
+	}
+
+	return json.Marshal(info)
+}
+
+// backoff - exponential delay with jitter (from 50% to 100% of the delay)
+func backoff(retries int) time.Duration {
+	delay := backoffMax
+	if retries < 16 {
+		delay = min(backoffMin<<retries, backoffMax)
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// forceCodecs - leave only selected codecs in consumer medias, so several destinations
+// with the same options share one transcoding producer (if source codecs don't match),
+// return error if the consumer doesn't support selected codec
+func forceCodecs(medias []*core.Media, query url.Values) error {
+	for _, media := range medias {
+		name := query.Get(media.Kind)
+		if name == "" || media.Direction != core.DirectionSendonly {
+			continue
+		}
+
+		var codecs []*core.Codec
+		for _, codec := range media.Codecs {
+			if strings.EqualFold(codec.Name, name) {
+				codecs = append(codecs, codec)
+			}
+		}
+		if codecs == nil {
+			return errors.New("streams: unsupported " + media.Kind + " codec: " + name)
+		}
+		media.Codecs = codecs
+	}
+	return nil
+}
+
+func getSend(cons core.Consumer) int {
+	if c, ok := cons.(interface{ GetSend() int }); ok {
+		return c.GetSend()
+	}
+	return 0
+}
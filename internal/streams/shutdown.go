@@ -0,0 +1,54 @@
+package streams
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+var shutdown atomic.Bool
+
+func initShutdown() {
+	app.OnShutdown(app.ShutdownConsumers, func(ctx context.Context) {
+		shutdown.Store(true)
+
+		for _, stream := range allStreams() {
+			stream.mu.Lock()
+			consumers := append([]core.Consumer(nil), stream.consumers...)
+			stream.mu.Unlock()
+
+			for _, cons := range consumers {
+				stream.RemoveConsumer(cons)
+			}
+		}
+	})
+
+	app.OnShutdown(app.ShutdownProducers, func(ctx context.Context) {
+		for _, stream := range allStreams() {
+			stream.mu.Lock()
+			producers := append([]*Producer(nil), stream.producers...)
+			stream.mu.Unlock()
+
+			for _, prod := range producers {
+				prod.shutdown()
+			}
+		}
+	})
+}
+
+// allStreams - unique streams, because one stream can have multiple names (aliases)
+func allStreams() []*Stream {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+
+	var unique []*Stream
+	for _, stream := range streams {
+		if !slices.Contains(unique, stream) {
+			unique = append(unique, stream)
+		}
+	}
+	return unique
+}
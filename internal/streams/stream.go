@@ -13,22 +13,31 @@ type Stream struct {
 	consumers []core.Consumer
 	mu        sync.Mutex
 	pending   atomic.Int32
+
+	maxConsumers int // override for limits.stream, protected by limitsMu
+	limited      int // consumers count for limits
+	rejected     int // consumers rejected by limits
+
+	display *Display // display transforms from the stream config
+
+	transcode bool // allow on-demand FFmpeg transcoding for consumers
 }
 
 func NewStream(source any) *Stream {
+	var s *Stream
+
 	switch source := source.(type) {
 	case string:
-		return &Stream{
+		s = &Stream{
 			producers: []*Producer{NewProducer(source)},
 		}
 	case []string:
-		s := new(Stream)
+		s = new(Stream)
 		for _, str := range source {
 			s.producers = append(s.producers, NewProducer(str))
 		}
-		return s
 	case []any:
-		s := new(Stream)
+		s = new(Stream)
 		for _, src := range source {
 			str, ok := src.(string)
 			if !ok {
@@ -37,14 +46,27 @@ func NewStream(source any) *Stream {
 			}
 			s.producers = append(s.producers, NewProducer(str))
 		}
-		return s
 	case map[string]any:
-		return NewStream(source["url"])
+		s = NewStream(source["url"])
+		if n, ok := source["max_consumers"].(int); ok {
+			s.maxConsumers = n
+		}
+		s.transcode, _ = source["transcode"].(bool)
+		rotate, _ := source["rotate"].(int)
+		aspect, _ := source["aspect"].(string)
+		crop, _ := source["crop"].(string)
+		s.display = ParseDisplay(rotate, aspect, crop)
 	case nil:
-		return new(Stream)
+		s = new(Stream)
 	default:
 		panic(core.Caller())
 	}
+
+	for _, prod := range s.producers {
+		prod.stream = s
+	}
+
+	return s
 }
 
 func (s *Stream) Sources() []string {
@@ -55,6 +77,11 @@ func (s *Stream) Sources() []string {
 	return sources
 }
 
+// Display - display transforms from the stream config, nil if not set
+func (s *Stream) Display() *Display {
+	return s.display
+}
+
 func (s *Stream) SetSource(source string) {
 	for _, prod := range s.producers {
 		prod.SetSource(source)
@@ -64,20 +91,27 @@ func (s *Stream) SetSource(source string) {
 func (s *Stream) RemoveConsumer(cons core.Consumer) {
 	_ = cons.Stop()
 
+	var found bool
+
 	s.mu.Lock()
 	for i, consumer := range s.consumers {
 		if consumer == cons {
 			s.consumers = append(s.consumers[:i], s.consumers[i+1:]...)
+			found = true
 			break
 		}
 	}
 	s.mu.Unlock()
 
+	if found {
+		s.release(cons)
+	}
+
 	s.stopProducers()
 }
 
 func (s *Stream) AddProducer(prod core.Producer) {
-	producer := &Producer{conn: prod, state: stateExternal, url: "external"}
+	producer := &Producer{conn: prod, state: stateExternal, url: "external", stream: s}
 	s.mu.Lock()
 	s.producers = append(s.producers, producer)
 	s.mu.Unlock()
@@ -115,16 +149,34 @@ producers:
 		}
 		producer.stop()
 	}
+
+	// remove unused transcoding producers (copy, because AddConsumer may use the old slice)
+	var producers []*Producer
+	for _, producer := range s.producers {
+		if producer.transcode != nil && producer.isNone() {
+			continue
+		}
+		producers = append(producers, producer)
+	}
+	s.producers = producers
 	s.mu.Unlock()
 }
 
 func (s *Stream) MarshalJSON() ([]byte, error) {
 	var info = struct {
-		Producers []*Producer     `json:"producers"`
-		Consumers []core.Consumer `json:"consumers"`
+		Producers   []*Producer     `json:"producers"`
+		Consumers   []core.Consumer `json:"consumers"`
+		Transcoding []transcodeInfo `json:"transcoding,omitempty"`
+		Limits      *limitsInfo     `json:"limits,omitempty"`
+		Publish     []*Restream     `json:"publish,omitempty"`
+		Health      []healthInfo    `json:"health,omitempty"`
 	}{
-		Producers: s.producers,
-		Consumers: s.consumers,
+		Producers:   s.producers,
+		Consumers:   s.consumers,
+		Transcoding: s.transcodeInfo(),
+		Limits:      s.limitsInfo(),
+		Publish:     s.restreams(),
+		Health:      s.healthInfo(),
 	}
 	return json.Marshal(info)
 }
@@ -3,8 +3,12 @@ package streams
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/mjpeg"
+	"github.com/AlexxIT/go2rtc/pkg/probe"
+	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,3 +44,146 @@ func TestTempate(t *testing.T) {
 	require.Equal(t, stream1, stream2)
 	require.Equal(t, "ffmpeg:rtsp://example.com#video=copy", stream1.producers[0].url)
 }
+
+func TestLimits(t *testing.T) {
+	setLimits(limitsConfig{Stream: 3, IP: 2, TrustedProxies: []string{"127.0.0.1"}})
+	t.Cleanup(func() {
+		setLimits(limitsConfig{})
+		limitsIP = map[string]int{}
+		limitsUser = map[string]int{}
+	})
+
+	stream := NewStream(nil)
+
+	newConsumer := func(addr string) core.Consumer {
+		cons := mjpeg.NewConsumer()
+		cons.RemoteAddr = addr
+		return cons
+	}
+
+	cons1 := newConsumer("192.168.1.10:50001")
+	require.NoError(t, stream.acquire(cons1))
+	require.NoError(t, stream.acquire(newConsumer("192.168.1.10:50002 forwarded 10.0.0.1")))
+	require.ErrorIs(t, stream.acquire(newConsumer("192.168.1.10:50003")), ErrLimit)
+
+	// loopback and internal consumers are not limited and don't take stream slots
+	require.NoError(t, stream.acquire(newConsumer("127.0.0.1:50004")))
+	require.NoError(t, stream.acquire(newConsumer("")))
+	require.NoError(t, stream.acquire(newConsumer("192.168.1.11:50005")))
+	require.ErrorIs(t, stream.acquire(newConsumer("192.168.1.12:50006")), ErrLimit)
+
+	stream.release(cons1)
+	require.NoError(t, stream.acquire(newConsumer("192.168.1.11:50007")))
+
+	// forwarded address is used only from trusted reverse proxy
+	ip, _, ok := clientInfo(newConsumer("127.0.0.1:50008 forwarded 10.0.0.2, 192.168.1.13"))
+	require.True(t, ok)
+	require.Equal(t, "192.168.1.13", ip)
+	ip, _, _ = clientInfo(newConsumer("192.168.1.10:50009 forwarded 192.168.1.13"))
+	require.Equal(t, "192.168.1.10", ip)
+
+	info := stream.limitsInfo()
+	require.Equal(t, &limitsInfo{MaxConsumers: 3, Consumers: 3, Rejected: 2}, info)
+}
+
+func TestTranscodeProducer(t *testing.T) {
+	TranscodeFunc(func(name string, codec *core.Codec) string {
+		return "ffmpeg:" + name + "#video=mjpeg"
+	})
+	defer TranscodeFunc(nil)
+
+	stream := NewStream(map[string]any{"url": nil})
+	streams["camera1"] = stream
+	defer delete(streams, "camera1")
+
+	prodMedias := []*core.Media{{
+		Kind: core.KindVideo, Direction: core.DirectionRecvonly,
+		Codecs: []*core.Codec{{Name: core.CodecH265}},
+	}}
+
+	// disabled by default
+	cons := mjpeg.NewConsumer()
+	require.Nil(t, stream.transcodeProducer(cons, cons.GetMedias()[0], prodMedias))
+
+	stream = NewStream(map[string]any{"url": nil, "transcode": true})
+	streams["camera1"] = stream
+
+	// same producer for all consumers with the same codec
+	cons1, cons2 := mjpeg.NewConsumer(), mjpeg.NewConsumer()
+	prod1 := stream.transcodeProducer(cons1, cons1.GetMedias()[0], prodMedias)
+	prod2 := stream.transcodeProducer(cons2, cons2.GetMedias()[0], prodMedias)
+	require.NotNil(t, prod1)
+	require.Equal(t, prod1, prod2)
+	require.Equal(t, "ffmpeg:camera1#video=mjpeg", prod1.url)
+	require.Equal(t, stream, prod1.stream) // display transforms for the new producer
+	require.Len(t, stream.transcodeInfo(), 1)
+
+	// unused producer is removed
+	stream.stopProducers()
+	require.Len(t, stream.producers, 0)
+}
+
+func TestRestream(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		delay := backoff(i)
+		require.GreaterOrEqual(t, delay, min(backoffMin<<i, backoffMax)/2)
+		require.LessOrEqual(t, delay, backoffMax)
+	}
+
+	medias := []*core.Media{
+		{Kind: core.KindVideo, Direction: core.DirectionSendonly, Codecs: []*core.Codec{
+			{Name: core.CodecH264}, {Name: core.CodecH265},
+		}},
+		{Kind: core.KindAudio, Direction: core.DirectionSendonly, Codecs: []*core.Codec{
+			{Name: core.CodecAAC}, {Name: core.CodecOpus},
+		}},
+	}
+	require.Nil(t, forceCodecs(medias, ParseQuery("video=h264")))
+	require.Len(t, medias[0].Codecs, 1)
+	require.Equal(t, core.CodecH264, medias[0].Codecs[0].Name)
+	require.Len(t, medias[1].Codecs, 2)
+
+	// unsupported codec shouldn't leave the media without codecs
+	require.NotNil(t, forceCodecs(medias, ParseQuery("audio=pcma")))
+	require.Len(t, medias[1].Codecs, 2)
+
+	// deleted stream stops publishing
+	stream := &Stream{}
+	streams["test"] = stream
+	restreams = append(restreams, &Restream{stream: stream, url: "rtmp://localhost/test", stop: make(chan struct{})})
+	Delete("test")
+	require.Nil(t, Get("test"))
+	require.Empty(t, stream.restreams())
+}
+
+func TestWatchdog(t *testing.T) {
+	video := core.NewReceiver(nil, &core.Codec{Name: core.CodecH264})
+	prod := &Producer{
+		url:       "rtsp://localhost/stream1#stall=1",
+		conn:      probe.NewAnalyzer(nil),
+		receivers: []*core.Receiver{video},
+		workerID:  1,
+		stall:     time.Second,
+	}
+	stream := &Stream{producers: []*Producer{prod}}
+
+	go prod.watchdog(1, time.Second)
+	defer func() {
+		prod.mu.Lock()
+		prod.workerID++
+		prod.mu.Unlock()
+	}()
+
+	require.Eventually(t, func() bool {
+		info := stream.healthInfo()
+		return info[0].Stalled && info[0].Stalls == 1
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// video packets are back
+	video.Input(&rtp.Packet{})
+
+	require.Eventually(t, func() bool {
+		info := stream.healthInfo()
+		return !info[0].Stalled && info[0].LastStall != nil
+	}, 3*time.Second, 100*time.Millisecond)
+}
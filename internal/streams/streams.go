@@ -16,12 +16,15 @@ func Init() {
 		Streams map[string]any    `yaml:"streams"`
 		Publish map[string]any    `yaml:"publish"`
 		Preload map[string]string `yaml:"preload"`
+		Limits  limitsConfig      `yaml:"limits"`
 	}
 
 	app.LoadConfig(&cfg)
 
 	log = app.GetLogger("streams")
 
+	setLimits(cfg.Limits)
+
 	for name, item := range cfg.Streams {
 		streams[name] = NewStream(item)
 	}
@@ -31,6 +34,8 @@ func Init() {
 	api.HandleFunc("api/preload", apiPreload)
 	api.HandleFunc("api/schemes", apiSchemes)
 
+	initShutdown()
+
 	if cfg.Publish == nil && cfg.Preload == nil {
 		return
 	}
@@ -151,8 +156,18 @@ func Get(name string) *Stream {
 
 func Delete(name string) {
 	streamsMu.Lock()
-	defer streamsMu.Unlock()
+	stream := streams[name]
 	delete(streams, name)
+	streamsMu.Unlock()
+
+	if stream == nil {
+		return
+	}
+
+	// deleted stream shouldn't keep publishing to destinations
+	for _, r := range stream.restreams() {
+		_ = stream.StopPublish(r.url)
+	}
 }
 
 func GetAllNames() []string {
@@ -0,0 +1,94 @@
+package streams
+
+import (
+	"slices"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+// Transcoder - returns source of the on-demand producer, that transcodes the stream
+// with this name to the codec, or empty string if the codec isn't supported
+type Transcoder func(name string, codec *core.Codec) string
+
+var transcoder Transcoder
+
+// TranscodeFunc - set transcoding fallback for consumers, which codecs don't match
+// any stream source (used by FFmpeg module)
+func TranscodeFunc(fn Transcoder) {
+	transcoder = fn
+}
+
+// transcodeProducer - get or add shared transcoding producer for the consumer media,
+// only for streams with `transcode: true` in the config
+func (s *Stream) transcodeProducer(cons core.Consumer, consMedia *core.Media, prodMedias []*core.Media) *Producer {
+	if transcoder == nil || !s.transcode || consMedia.Direction != core.DirectionSendonly || consMedia.MatchAll() {
+		return nil
+	}
+
+	// skip internal consumers from other sources (ex. FFmpeg), to prevent transcoding loops
+	if info, ok := cons.(core.Info); ok && info.GetSource() != "" {
+		return nil
+	}
+
+	// the stream should have source media with the same kind
+	if !slices.ContainsFunc(prodMedias, func(media *core.Media) bool {
+		return media.Kind == consMedia.Kind && media.Direction == core.DirectionRecvonly
+	}) {
+		return nil
+	}
+
+	name := s.name()
+	if name == "" {
+		return nil
+	}
+
+	for _, codec := range consMedia.Codecs {
+		source := transcoder(name, codec)
+		if source == "" {
+			continue
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, prod := range s.producers {
+			if prod.url == source {
+				return prod
+			}
+		}
+
+		log.Debug().Msgf("[streams] add transcoding producer url=%s", source)
+
+		prod := &Producer{url: source, transcode: codec, stream: s}
+		s.producers = append(s.producers, prod)
+		return prod
+	}
+
+	return nil
+}
+
+// name - any name of the stream (streams can have aliases)
+func (s *Stream) name() string {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	for name, stream := range streams {
+		if stream == s {
+			return name
+		}
+	}
+	return ""
+}
+
+type transcodeInfo struct {
+	URL   string      `json:"url"`
+	Codec *core.Codec `json:"codec"`
+}
+
+func (s *Stream) transcodeInfo() (info []transcodeInfo) {
+	for _, prod := range s.producers {
+		if prod.transcode != nil {
+			info = append(info, transcodeInfo{URL: prod.url, Codec: prod.transcode})
+		}
+	}
+	return
+}
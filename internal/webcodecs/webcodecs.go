@@ -0,0 +1,66 @@
+package webcodecs
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/api/ws"
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/webcodecs"
+	"github.com/rs/zerolog"
+)
+
+func Init() {
+	ws.HandleFunc("webcodecs", handlerWS)
+
+	log = app.GetLogger("webcodecs")
+}
+
+var log zerolog.Logger
+
+func handlerWS(tr *ws.Transport, msg *ws.Message) error {
+	stream, _ := streams.GetOrPatch(tr.Request.URL.Query())
+	if stream == nil {
+		return errors.New(api.StreamNotFound)
+	}
+
+	// optional comma separated list: video,audio,microphone
+	video, audio, microphone := true, true, false
+	if s := msg.String(); s != "" {
+		video = strings.Contains(s, "video")
+		audio = strings.Contains(s, "audio")
+		microphone = strings.Contains(s, "microphone")
+	}
+
+	cons := webcodecs.NewConsumer(video, audio, microphone)
+	cons.WithRequest(tr.Request)
+
+	if err := stream.AddConsumer(cons); err != nil {
+		log.Debug().Err(err).Msg("[webcodecs] add consumer")
+		return err
+	}
+
+	tracks, mic := cons.Tracks()
+	tr.Write(&ws.Message{Type: "webcodecs", Value: map[string]any{
+		"tracks":     tracks,
+		"microphone": mic,
+	}})
+
+	if mic != nil {
+		tr.OnBinary(func(data []byte) {
+			if err := cons.WriteBackchannel(data); err != nil {
+				log.Trace().Err(err).Send()
+			}
+		})
+	}
+
+	go cons.WriteTo(tr.Writer())
+
+	tr.OnClose(func() {
+		stream.RemoveConsumer(cons)
+	})
+
+	return nil
+}
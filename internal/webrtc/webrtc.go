@@ -1,9 +1,11 @@
 package webrtc
 
 import (
+	"context"
 	"errors"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/api/ws"
@@ -61,6 +63,9 @@ func Init() {
 		switch ln := ln.(type) {
 		case *net.TCPListener:
 			log.Info().Stringer("addr", ln.Addr()).Msg("[webrtc] listen tcp")
+			app.OnShutdown(app.ShutdownListeners, func(ctx context.Context) {
+				_ = ln.Close()
+			})
 		case *net.UDPConn:
 			log.Info().Stringer("addr", ln.LocalAddr()).Msg("[webrtc] listen udp")
 		}
@@ -92,7 +97,7 @@ func Init() {
 		if active {
 			return clientAPI.NewPeerConnection(pionConf)
 		} else {
-			return serverAPI.NewPeerConnection(pionConf)
+			return serverAPI.NewPeerConnection(serverConf(pionConf))
 		}
 	}
 
@@ -112,6 +117,16 @@ var serverAPI, clientAPI *pion.API
 
 var log zerolog.Logger
 
+// serverConf - use HTTPS certificate for DTLS, if it is configured, so the server has
+// a stable and trusted fingerprint instead of new random certificate for each connection
+func serverConf(conf pion.Configuration) pion.Configuration {
+	// pion rejects expired certificates
+	if cert := api.Certificate(); cert != nil && time.Now().Before(cert.Leaf.NotAfter) {
+		conf.Certificates = []pion.Certificate{pion.CertificateFromX509(cert.PrivateKey, cert.Leaf)}
+	}
+	return conf
+}
+
 var PeerConnection func(active bool) (*pion.PeerConnection, error)
 
 func asyncHandler(tr *ws.Transport, msg *ws.Message) (err error) {
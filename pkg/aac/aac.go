@@ -77,6 +77,43 @@ func DecodeConfig(b []byte) (objType, sampleFreqIdx, channels byte, sampleRate u
 	return
 }
 
+// FrameLength - samples per access unit from the codec config:
+// 1024 or 960 for AAC-LC, 512 or 480 for AAC-LD and AAC-ELD
+func FrameLength(codec *core.Codec) uint32 {
+	conf, err := hex.DecodeString(core.Between(codec.FmtpLine, "config=", ";"))
+	if err != nil || len(conf) < 2 {
+		return AUTime
+	}
+
+	rd := bits.NewReader(conf)
+
+	objType := rd.ReadBits8(5)
+	if objType == TypeESCAPE {
+		objType = 32 + rd.ReadBits8(6)
+	}
+
+	if rd.ReadBits8(4) == 0x0F {
+		_ = rd.ReadBits(24) // sampleRate
+	}
+	_ = rd.ReadBits8(4) // channels
+
+	shortFrame := rd.ReadBit() == 1
+
+	switch objType {
+	case TypeAACLD, TypeAACELD:
+		if shortFrame {
+			return 480
+		}
+		return 512
+	case TypeAACLC:
+		if shortFrame {
+			return 960
+		}
+	}
+
+	return AUTime
+}
+
 func EncodeConfig(objType byte, sampleRate uint32, channels byte, shortFrame bool) []byte {
 	wr := bits.NewWriter(nil)
 
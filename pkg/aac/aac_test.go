@@ -50,3 +50,16 @@ func TestEncodeConfig(t *testing.T) {
 	conf = EncodeConfig(TypeAACLC, 8000, 1, false)
 	require.Equal(t, "1588", hex.EncodeToString(conf))
 }
+
+func TestFrameLength(t *testing.T) {
+	codec := &core.Codec{FmtpLine: FMTP + "1408"}
+	require.Equal(t, uint32(1024), FrameLength(codec))
+
+	conf := EncodeConfig(TypeAACELD, 24000, 1, true)
+	codec.FmtpLine = FMTP + hex.EncodeToString(conf)
+	require.Equal(t, uint32(480), FrameLength(codec))
+
+	conf = EncodeConfig(TypeAACELD, 48000, 2, false)
+	codec.FmtpLine = FMTP + hex.EncodeToString(conf)
+	require.Equal(t, uint32(512), FrameLength(codec))
+}
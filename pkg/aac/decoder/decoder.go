@@ -0,0 +1,294 @@
+package decoder
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	elemSCE = 0 // single channel element
+	elemCPE = 1 // channel pair element
+	elemCCE = 2 // coupling channel element
+	elemLFE = 3 // LFE channel element
+	elemDSE = 4 // data stream element
+	elemPCE = 5 // program config element
+	elemFIL = 6 // fill element
+	elemEND = 7
+)
+
+const frameLength = 1024
+
+var (
+	errWrongVLC   = errors.New("aac: wrong VLC code")
+	errWrongFrame = errors.New("aac: wrong frame")
+)
+
+// Decoder - AAC-LC and AAC-ELD decoder, SBR and PS extensions are ignored,
+// so HE-AAC is decoded with core sample rate
+type Decoder struct {
+	SampleRate uint32
+	Channels   byte
+
+	rateIdx  int
+	channels []channel
+	random   uint32
+
+	long, short *imdct
+
+	eld      bool
+	frameLen int // 1024 for AAC-LC, 512 or 480 for AAC-ELD
+}
+
+type channel struct {
+	ics       ics
+	overlap   [frameLength]float32
+	prevShape byte
+
+	saved []float32 // AAC-ELD overlap for 3 previous frames
+}
+
+// New - create decoder from AudioSpecificConfig
+func New(conf []byte) (*Decoder, error) {
+	r := &reader{buf: conf}
+
+	objType := readObjectType(r)
+	rateIdx := r.bits(4)
+	if rateIdx == 15 {
+		return nil, errors.New("aac: unsupported sample rate")
+	}
+	channels := byte(r.bits(4))
+
+	if objType == 5 || objType == 29 {
+		// explicit SBR/PS signaling, skip extension sample rate
+		if r.bits(4) == 15 {
+			r.skip(24)
+		}
+		objType = readObjectType(r)
+	}
+
+	if int(rateIdx) >= len(sampleRates) || channels == 0 || channels > 7 {
+		return nil, errors.New("aac: wrong config")
+	}
+
+	d := &Decoder{
+		SampleRate: sampleRates[rateIdx],
+		Channels:   channels,
+		rateIdx:    int(rateIdx),
+		random:     0x1f2e3d4c,
+		frameLen:   frameLength,
+	}
+
+	switch objType {
+	case 2:
+		if r.flag() {
+			return nil, errors.New("aac: unsupported frame length 960")
+		}
+		d.long = newIMDCT(2 * frameLength)
+		d.short = newIMDCT(2 * frameLength / 8)
+	case 39:
+		if err := d.readELDConfig(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("aac: unsupported object type")
+	}
+
+	if r.overrun() {
+		return nil, errors.New("aac: wrong config")
+	}
+
+	if channels == 7 {
+		d.Channels = 8 // 7.1
+	}
+	d.channels = make([]channel, d.Channels)
+
+	if d.eld {
+		for i := range d.channels {
+			d.channels[i].saved = make([]float32, 3*d.frameLen)
+		}
+	}
+
+	return d, nil
+}
+
+func readObjectType(r *reader) uint32 {
+	if objType := r.bits(5); objType != 31 {
+		return objType
+	}
+	return 32 + r.bits(6)
+}
+
+// Decode - decode raw AAC frame (without ADTS header) to interleaved 16-bit PCM
+func (d *Decoder) Decode(frame []byte) ([]int16, error) {
+	samples, err := d.DecodeFloat(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	pcm := make([]int16, len(samples))
+	for i, f := range samples {
+		pcm[i] = clip16(f)
+	}
+	return pcm, nil
+}
+
+// DecodeFloat - decode raw AAC frame to interleaved samples in 16-bit range
+func (d *Decoder) DecodeFloat(frame []byte) ([]float32, error) {
+	r := &reader{buf: frame}
+
+	if d.eld {
+		return d.decodeELD(r)
+	}
+
+	var ch int
+
+	for {
+		switch elemID := r.bits(3); elemID {
+		case elemSCE, elemLFE:
+			r.skip(4) // element_instance_tag
+			if ch >= len(d.channels) {
+				return nil, errWrongFrame
+			}
+			c := &d.channels[ch]
+			if err := d.readICS(r, &c.ics, false); err != nil {
+				return nil, err
+			}
+			d.dequant(&c.ics)
+			c.ics.applyTNS()
+			ch++
+
+		case elemCPE:
+			r.skip(4)
+			if ch+1 >= len(d.channels) {
+				return nil, errWrongFrame
+			}
+			if err := d.readCPE(r, &d.channels[ch].ics, &d.channels[ch+1].ics); err != nil {
+				return nil, err
+			}
+			ch += 2
+
+		case elemCCE:
+			return nil, errors.New("aac: unsupported coupling channel")
+
+		case elemDSE:
+			r.skip(4)
+			align := r.flag()
+			count := int(r.bits(8))
+			if count == 255 {
+				count += int(r.bits(8))
+			}
+			if align {
+				r.align()
+			}
+			r.skip(count * 8)
+
+		case elemPCE:
+			skipPCE(r)
+
+		case elemFIL:
+			count := int(r.bits(4))
+			if count == 15 {
+				count += int(r.bits(8)) - 1
+			}
+			r.skip(count * 8) // SBR and other extensions
+
+		case elemEND:
+			if r.overrun() || ch != len(d.channels) {
+				return nil, errWrongFrame
+			}
+
+			out := make([]float32, frameLength*len(d.channels))
+			for i := range d.channels {
+				d.filterbank(&d.channels[i], out[i:], len(d.channels))
+			}
+			return out, nil
+		}
+
+		if r.overrun() {
+			return nil, errWrongFrame
+		}
+	}
+}
+
+func (d *Decoder) readCPE(r *reader, left, right *ics) error {
+	commonWindow := d.eld || r.flag() // AAC-ELD always uses common window
+	if commonWindow {
+		if err := d.readICSInfo(r, left); err != nil {
+			return err
+		}
+		right.info = left.info
+
+		switch r.bits(2) {
+		case 0:
+			left.msUsed = [8][64]bool{}
+		case 1:
+			for g := 0; g < left.numGroups; g++ {
+				for sfb := 0; sfb < left.maxSfb; sfb++ {
+					left.msUsed[g][sfb] = r.flag()
+				}
+			}
+		case 2:
+			for g := 0; g < left.numGroups; g++ {
+				for sfb := 0; sfb < left.maxSfb; sfb++ {
+					left.msUsed[g][sfb] = true
+				}
+			}
+		default:
+			return errWrongFrame
+		}
+	}
+
+	if err := d.readICS(r, left, commonWindow); err != nil {
+		return err
+	}
+	if err := d.readICS(r, right, commonWindow); err != nil {
+		return err
+	}
+
+	d.dequant(left)
+	d.dequant(right)
+
+	if commonWindow {
+		stereo(left, right)
+	}
+
+	left.applyTNS()
+	right.applyTNS()
+
+	return nil
+}
+
+// skipPCE - program_config_element
+func skipPCE(r *reader) {
+	r.skip(4 + 2 + 4) // element_instance_tag, object_type, sampling_frequency_index
+
+	front := int(r.bits(4))
+	side := int(r.bits(4))
+	back := int(r.bits(4))
+	lfe := int(r.bits(2))
+	assoc := int(r.bits(3))
+	cc := int(r.bits(4))
+
+	for i := 0; i < 3; i++ {
+		// mono_mixdown, stereo_mixdown, matrix_mixdown
+		if r.flag() {
+			r.skip(4 - i/2)
+		}
+	}
+
+	r.skip((front+side+back)*5 + lfe*4 + assoc*4 + cc*5)
+	r.align()
+	r.skip(int(r.bits(8)) * 8) // comment_field_data
+}
+
+func clip16(f float32) int16 {
+	switch {
+	case f >= math.MaxInt16:
+		return math.MaxInt16
+	case f <= math.MinInt16:
+		return math.MinInt16
+	case f < 0:
+		return int16(f - 0.5)
+	}
+	return int16(f + 0.5)
+}
@@ -0,0 +1,72 @@
+package decoder
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	// AAC-LC, 16000 Hz, stereo, one frame
+	conf := []byte{0x14, 0x10}
+	frame, err := hex.DecodeString(
+		"211ad4458aa309a1c0a8761a230502b7c74b2b5499252a010555e32e460128303c8ace4fd3260d654a424f7e7c65eddc" +
+			"96735fc6f1ac0edf94fdefa0e0bd6370da1c07b9c0e77a9d6e86b196a1ac7439dcafadcffcf6d89f60ac67f8884868e9" +
+			"31383ad3e40cf5495470d1f606ef6f7624d285b951ebfa0e42641ab98f1371182b237d14f1bd16ad714fa2f1c6a7d23e" +
+			"bde7a0e34a2eca156a608a4caec49d9dca4b6fe2a09e9cdbf762c5b4148a3914abb7959c991228b0837b5988334b9fc1" +
+			"8b8fac689b5ca1e4661573bbb8b253a86cae7ec14ace49969a9a76fd571ab6e650764cb59114d61dcedf07ac61b39e4a" +
+			"c66adebfd0d0ab45d518dd3c161049823f150864d977cf0855172ac8482e4b25fe911325d19617558c5405af74aff549" +
+			"2e4599bee53f2dbdf0503730af37078550f84c956b7ee89aae83c154fa2fa6e6792c5ddd5cd5cf6bb96bf055fee7f93b" +
+			"ed59ffb039daee5ea7e5593cb194e9091e417c67d8f73026a6a6ae056e808f7c65c03d1b9197d3709ceb63bc7b979f7b" +
+			"a71df5e7c6395d99d6ea229000a6bc16fb4346d6b27d32f5d8d1200736d9366d59c0c9547210813b602473da9c46f901" +
+			"5bbb37594c1dd90cd6a36e96bd5d6a1445ab93c9e65505ec2c722bb4cc27a10600139a48c83594dde145253c386f6627" +
+			"d8c6e5102fe3828a590c709bc87f55b37e97d1ae72b017b09c6bb2c13299817bb45cc67318e10b6822075b97c6a03ec1" +
+			"c0",
+	)
+	require.Nil(t, err)
+
+	d, err := New(conf)
+	require.Nil(t, err)
+	require.Equal(t, uint32(16000), d.SampleRate)
+	require.Equal(t, byte(2), d.Channels)
+
+	pcm, err := d.Decode(frame)
+	require.Nil(t, err)
+	require.Len(t, pcm, 2048)
+
+	// reference values from browser WebCodecs decoder
+	for i, v := range map[int]int16{100: -6, 500: -26, 1000: -9, 1500: 77, 2000: 100} {
+		require.InDelta(t, v, pcm[i], 1)
+	}
+}
+
+func TestDecodeELD(t *testing.T) {
+	// AAC-ELD, 24000 Hz, mono, 480 frame length, same as HomeKit
+	conf := []byte{0xF8, 0xEC, 0x30, 0x00}
+
+	d, err := New(conf)
+	require.Nil(t, err)
+	require.Equal(t, uint32(24000), d.SampleRate)
+	require.Equal(t, byte(1), d.Channels)
+
+	var pcm []int16
+	for _, s := range []string{
+		"652882e150d040341708050261c775be99699664544d3fe1fbb7e7fd9fc917e33ff3ff1770",
+		"8b3a03410088542019090483213098502a1d66b8bcd76e752d528b85d2e734fb737e3bffcedb47f43e21fbfb9fca749faff3f100",
+	} {
+		frame, err := hex.DecodeString(s)
+		require.Nil(t, err)
+
+		samples, err := d.Decode(frame)
+		require.Nil(t, err)
+		require.Len(t, samples, 480)
+
+		pcm = append(pcm, samples...)
+	}
+
+	// reference values from browser WebCodecs decoder
+	for i, v := range map[int]int16{500: 3, 600: -63, 700: 20, 800: -40, 900: -13} {
+		require.InDelta(t, v, pcm[i], 1)
+	}
+}
@@ -0,0 +1,149 @@
+package decoder
+
+import "errors"
+
+// readELDConfig - ELDSpecificConfig, without LD-SBR and error resilience tools
+func (d *Decoder) readELDConfig(r *reader) error {
+	d.eld = true
+	d.frameLen = 512
+	if r.flag() {
+		d.frameLen = 480
+	}
+
+	if r.bits(3) != 0 {
+		return errors.New("aac: unsupported error resilience")
+	}
+	if r.flag() {
+		return errors.New("aac: unsupported LD-SBR")
+	}
+
+	// skip extensions until ELDEXT_TERM
+	for r.bits(4) != 0 && !r.overrun() {
+		size := r.bits(4)
+		if size == 15 {
+			size += r.bits(8)
+			if size == 15+255 {
+				size += r.bits(16)
+			}
+		}
+		r.skip(int(size) * 8)
+	}
+
+	if d.Channels > 2 {
+		return errors.New("aac: unsupported channels")
+	}
+
+	if d.swbELD() == nil {
+		return errors.New("aac: unsupported sample rate")
+	}
+
+	d.long = newIMDCT(2 * d.frameLen)
+
+	return nil
+}
+
+func (d *Decoder) swbELD() []uint16 {
+	if d.frameLen == 480 {
+		return swbELD480[d.rateIdx]
+	}
+	return swbELD512[d.rateIdx]
+}
+
+// readELDInfo - AAC-ELD has only long windows, so ics_info is just max_sfb
+func (d *Decoder) readELDInfo(s *ics, maxSfb int) error {
+	s.windowSequence = onlyLongSequence
+	s.windowShape = 0
+	s.maxSfb = maxSfb
+
+	s.numWindows = 1
+	s.numGroups = 1
+	s.groupLen = [8]int{1}
+	s.swb = d.swbELD()
+
+	if d.frameLen == 480 {
+		s.tnsMaxBands = tnsMaxBands480[d.rateIdx]
+	} else {
+		s.tnsMaxBands = tnsMaxBands512[d.rateIdx]
+	}
+
+	if s.maxSfb >= len(s.swb) {
+		return errWrongFrame
+	}
+
+	return nil
+}
+
+// decodeELD - er_raw_data_block for mono and stereo channel configurations
+func (d *Decoder) decodeELD(r *reader) ([]float32, error) {
+	if len(d.channels) == 1 {
+		s := &d.channels[0].ics
+		if err := d.readICS(r, s, false); err != nil {
+			return nil, err
+		}
+		d.dequant(s)
+		s.applyTNS()
+	} else if err := d.readCPE(r, &d.channels[0].ics, &d.channels[1].ics); err != nil {
+		return nil, err
+	}
+
+	if r.overrun() {
+		return nil, errWrongFrame
+	}
+
+	out := make([]float32, d.frameLen*len(d.channels))
+	for i := range d.channels {
+		d.filterbankELD(&d.channels[i], out[i:], len(d.channels))
+	}
+	return out, nil
+}
+
+// filterbankELD - low delay IMDCT, windowing and overlap-add with 3 previous frames,
+// same as FFmpeg, the IMDCT is mapped to the conventional one
+func (d *Decoder) filterbankELD(c *channel, out []float32, step int) {
+	n := d.frameLen
+	n2, n4 := n/2, n/4
+
+	window := eldWindow512[:]
+	if n == 480 {
+		window = eldWindow480[:]
+	}
+
+	in := make([]float32, n)
+	copy(in, c.ics.coef[:n])
+	for i := 0; i < n2; i += 2 {
+		in[i], in[n-1-i] = -in[n-1-i], in[i]
+		in[i+1], in[n-2-i] = in[n-2-i], -in[i+1]
+	}
+
+	tmp := make([]float32, 2*n)
+	d.long.transform(tmp, in)
+
+	// middle half of the IMDCT output
+	buf := tmp[n2 : n2+n]
+	for i := 0; i < n; i += 2 {
+		buf[i] = -buf[i]
+	}
+
+	saved := c.saved
+
+	for i := n4; i < n2; i++ {
+		out[(i-n4)*step] = buf[n2-1-i]*window[i-n4] +
+			saved[i+n2]*window[i+n-n4] -
+			saved[n+n2-1-i]*window[i+2*n-n4] -
+			saved[2*n+n2+i]*window[i+3*n-n4]
+	}
+	for i := 0; i < n2; i++ {
+		out[(n4+i)*step] = buf[i]*window[i+n2-n4] -
+			saved[n-1-i]*window[i+n2+n-n4] -
+			saved[n+i]*window[i+n2+2*n-n4] +
+			saved[2*n+n-1-i]*window[i+n2+3*n-n4]
+	}
+	for i := 0; i < n4; i++ {
+		out[(n2+n4+i)*step] = buf[i+n2]*window[i+n-n4] -
+			saved[n2-1-i]*window[i+2*n-n4] -
+			saved[n+n2+i]*window[i+3*n-n4]
+	}
+
+	copy(saved[n:], saved[:2*n])
+	copy(saved, buf)
+}
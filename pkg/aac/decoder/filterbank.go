@@ -0,0 +1,223 @@
+package decoder
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// rising half of sine and KBD windows for long (1024) and short (128) blocks
+var (
+	sineLong, kbdLong   [1024]float32
+	sineShort, kbdShort [128]float32
+)
+
+func init() {
+	sineWindow(sineLong[:])
+	sineWindow(sineShort[:])
+	kbdWindow(kbdLong[:], 4)
+	kbdWindow(kbdShort[:], 6)
+}
+
+func sineWindow(w []float32) {
+	n := float64(2 * len(w))
+	for i := range w {
+		w[i] = float32(math.Sin(math.Pi / n * (float64(i) + 0.5)))
+	}
+}
+
+// kbdWindow - Kaiser-Bessel derived window (4.6.11.3.2)
+func kbdWindow(w []float32, alpha float64) {
+	n := len(w)
+	k := alpha * math.Pi / float64(n)
+
+	sums := make([]float64, n)
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += besselI0(k * math.Sqrt(float64(i*(n-i))) * 2)
+		sums[i] = sum
+	}
+	sum += 1 // last term of Bessel sum, I0(0)
+
+	for i := range w {
+		w[i] = float32(math.Sqrt(sums[i] / sum))
+	}
+}
+
+func besselI0(x float64) float64 {
+	// I0(x) = sum((x^2/4)^k / (k!)^2)
+	v, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= x * x / 4 / float64(k*k)
+		v += term
+	}
+	return v
+}
+
+func windows(shape byte) ([]float32, []float32) {
+	if shape == 1 {
+		return kbdLong[:], kbdShort[:]
+	}
+	return sineLong[:], sineShort[:]
+}
+
+// filterbank - IMDCT, windowing and overlap-add (4.6.11),
+// samples are written to out with step for interleaving channels
+func (d *Decoder) filterbank(c *channel, out []float32, step int) {
+	s := &c.ics
+
+	prevLong, prevShort := windows(c.prevShape)
+	long, short := windows(s.windowShape)
+
+	var buf [2 * frameLength]float32
+
+	if s.windowSequence == eightShortSequence {
+		var tmp [256]float32
+		for w := 0; w < 8; w++ {
+			d.short.transform(tmp[:], s.coef[w*128:w*128+128])
+
+			rising := short
+			if w == 0 {
+				rising = prevShort
+			}
+
+			dst := buf[448+w*128:]
+			for i := 0; i < 128; i++ {
+				dst[i] += tmp[i] * rising[i]
+				dst[128+i] += tmp[128+i] * short[127-i]
+			}
+		}
+	} else {
+		d.long.transform(buf[:], s.coef[:])
+
+		switch s.windowSequence {
+		case onlyLongSequence, longStartSequence:
+			for i := 0; i < 1024; i++ {
+				buf[i] *= prevLong[i]
+			}
+		case longStopSequence:
+			for i := 0; i < 448; i++ {
+				buf[i] = 0
+			}
+			for i := 0; i < 128; i++ {
+				buf[448+i] *= prevShort[i]
+			}
+		}
+
+		switch s.windowSequence {
+		case onlyLongSequence, longStopSequence:
+			for i := 0; i < 1024; i++ {
+				buf[1024+i] *= long[1023-i]
+			}
+		case longStartSequence:
+			for i := 0; i < 128; i++ {
+				buf[1472+i] *= short[127-i]
+			}
+			for i := 1600; i < 2048; i++ {
+				buf[i] = 0
+			}
+		}
+	}
+
+	for i := 0; i < frameLength; i++ {
+		out[i*step] = c.overlap[i] + buf[i]
+	}
+	copy(c.overlap[:], buf[frameLength:])
+
+	c.prevShape = s.windowShape
+}
+
+// imdct - inverse MDCT with N/2 inputs and N outputs, uses N-point complex FFT:
+// x[n] = 2/N * sum(X[k] * cos(2pi/N * (n + n0) * (k + 1/2)))
+type imdct struct {
+	pre, post []complex128
+	fft       *fft
+	buf       []complex128
+}
+
+func newIMDCT(n int) *imdct {
+	m := &imdct{
+		pre:  make([]complex128, n/2),
+		post: make([]complex128, n),
+		fft:  newFFT(n),
+		buf:  make([]complex128, n),
+	}
+
+	n0 := float64(n/2+1) / 2
+	for k := range m.pre {
+		m.pre[k] = cmplx.Exp(complex(0, 2*math.Pi*float64(k)*n0/float64(n))) * complex(2/float64(n), 0)
+	}
+	for i := range m.post {
+		m.post[i] = cmplx.Exp(complex(0, math.Pi*(float64(i)+n0)/float64(n)))
+	}
+
+	return m
+}
+
+func (m *imdct) transform(dst, src []float32) {
+	for k, x := range src {
+		m.buf[k] = complex(float64(x), 0) * m.pre[k]
+	}
+	clear(m.buf[len(src):])
+
+	m.fft.inverse(m.buf)
+
+	for i := range dst {
+		dst[i] = float32(real(m.buf[i] * m.post[i]))
+	}
+}
+
+// fft - mixed radix complex FFT for sizes with factors 2, 3 and 5
+type fft struct {
+	n       int
+	factors []int
+	twiddle []complex128
+	tmp     []complex128
+}
+
+func newFFT(n int) *fft {
+	f := &fft{n: n, twiddle: make([]complex128, n), tmp: make([]complex128, n)}
+	for i := range f.twiddle {
+		f.twiddle[i] = cmplx.Exp(complex(0, 2*math.Pi*float64(i)/float64(n)))
+	}
+	for _, p := range []int{2, 3, 5} {
+		for n%p == 0 {
+			f.factors = append(f.factors, p)
+			n /= p
+		}
+	}
+	return f
+}
+
+// inverse - unnormalized inverse transform: sum(z[k] * exp(2pi*i*k*n/N))
+func (f *fft) inverse(z []complex128) {
+	copy(f.tmp, z)
+	f.transform(z, f.tmp, 1, f.factors)
+}
+
+// transform - recursive decimation in time, src is read with stride
+func (f *fft) transform(dst, src []complex128, stride int, factors []int) {
+	if len(factors) == 0 {
+		dst[0] = src[0]
+		return
+	}
+
+	p := factors[0]
+	m := len(dst) / p
+	for q := 0; q < p; q++ {
+		f.transform(dst[q*m:(q+1)*m], src[q*stride:], stride*p, factors[1:])
+	}
+
+	var tmp [5]complex128
+	for k := 0; k < m; k++ {
+		for q := 0; q < p; q++ {
+			tmp[q] = dst[q*m+k] * f.twiddle[q*k*stride%f.n]
+		}
+		for s := 0; s < p; s++ {
+			sum := tmp[0]
+			for q := 1; q < p; q++ {
+				sum += tmp[q] * f.twiddle[q*s*m*stride%f.n]
+			}
+			dst[s*m+k] = sum
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package decoder
+
+import "errors"
+
+const (
+	onlyLongSequence   = 0
+	longStartSequence  = 1
+	eightShortSequence = 2
+	longStopSequence   = 3
+)
+
+const (
+	zeroHCB       = 0
+	escHCB        = 11
+	noiseHCB      = 13
+	intensityHCB2 = 14
+	intensityHCB  = 15
+)
+
+// info - ics_info, can be shared by channel pair with common window
+type info struct {
+	windowSequence byte
+	windowShape    byte
+	maxSfb         int
+
+	numWindows int
+	numGroups  int
+	groupLen   [8]int
+	swb        []uint16
+
+	tnsMaxBands int
+}
+
+// ics - individual_channel_stream
+type ics struct {
+	info
+
+	bandType [8][64]byte
+	sf       [8][64]int  // scalefactor, noise energy or intensity position
+	msUsed   [8][64]bool // only for left channel of pair
+
+	pulse []pulse
+	tns   [8][]tnsFilter
+
+	quant [frameLength]int32
+	coef  [frameLength]float32
+}
+
+type pulse struct {
+	pos int
+	amp int32
+}
+
+type tnsFilter struct {
+	length    int
+	direction bool
+	lpc       []float32
+}
+
+func (d *Decoder) readICSInfo(r *reader, s *ics) error {
+	if d.eld {
+		return d.readELDInfo(s, int(r.bits(6)))
+	}
+
+	if r.flag() {
+		return errWrongFrame // ics_reserved_bit
+	}
+
+	s.windowSequence = byte(r.bits(2))
+	s.windowShape = byte(r.bits(1))
+
+	if s.windowSequence == eightShortSequence {
+		s.maxSfb = int(r.bits(4))
+		grouping := r.bits(7)
+
+		s.numWindows = 8
+		s.numGroups = 1
+		s.groupLen = [8]int{1}
+		for i := 6; i >= 0; i-- {
+			if grouping>>i&1 == 1 {
+				s.groupLen[s.numGroups-1]++
+			} else {
+				s.groupLen[s.numGroups] = 1
+				s.numGroups++
+			}
+		}
+		s.swb = swbShort[d.rateIdx]
+		s.tnsMaxBands = tnsMaxBandsShort[d.rateIdx]
+	} else {
+		s.maxSfb = int(r.bits(6))
+		if r.flag() {
+			return errors.New("aac: unsupported prediction")
+		}
+
+		s.numWindows = 1
+		s.numGroups = 1
+		s.groupLen = [8]int{1}
+		s.swb = swbLong[d.rateIdx]
+		s.tnsMaxBands = tnsMaxBandsLong[d.rateIdx]
+	}
+
+	if s.maxSfb >= len(s.swb) {
+		return errWrongFrame
+	}
+
+	return nil
+}
+
+func (d *Decoder) readICS(r *reader, s *ics, commonWindow bool) error {
+	globalGain := int(r.bits(8))
+
+	if !commonWindow {
+		if err := d.readICSInfo(r, s); err != nil {
+			return err
+		}
+	}
+
+	if err := s.readSections(r); err != nil {
+		return err
+	}
+	if err := s.readScalefactors(r, globalGain); err != nil {
+		return err
+	}
+
+	s.pulse = s.pulse[:0]
+	if !d.eld && r.flag() {
+		if s.windowSequence == eightShortSequence {
+			return errWrongFrame
+		}
+
+		n := int(r.bits(2)) + 1
+		startSfb := int(r.bits(6))
+		if startSfb >= len(s.swb)-1 {
+			return errWrongFrame
+		}
+
+		pos := int(s.swb[startSfb])
+		for i := 0; i < n; i++ {
+			pos += int(r.bits(5))
+			if pos >= frameLength {
+				return errWrongFrame
+			}
+			s.pulse = append(s.pulse, pulse{pos: pos, amp: int32(r.bits(4))})
+		}
+	}
+
+	for w := range s.tns {
+		s.tns[w] = s.tns[w][:0]
+	}
+	if r.flag() {
+		if err := s.readTNS(r); err != nil {
+			return err
+		}
+	}
+
+	if !d.eld && r.flag() {
+		return errors.New("aac: unsupported gain control")
+	}
+
+	return s.readSpectrum(r)
+}
+
+// readSections - section_data
+func (s *ics) readSections(r *reader) error {
+	n, esc := 5, uint32(31)
+	if s.windowSequence == eightShortSequence {
+		n, esc = 3, 7
+	}
+
+	for g := 0; g < s.numGroups; g++ {
+		for sfb := 0; sfb < s.maxSfb; {
+			cb := byte(r.bits(4))
+			if cb == 12 || r.overrun() {
+				return errWrongFrame
+			}
+
+			end := sfb
+			for {
+				incr := r.bits(n)
+				end += int(incr)
+				if incr != esc {
+					break
+				}
+				if r.overrun() {
+					return errWrongFrame
+				}
+			}
+
+			if end > s.maxSfb {
+				return errWrongFrame
+			}
+
+			for ; sfb < end; sfb++ {
+				s.bandType[g][sfb] = cb
+			}
+		}
+	}
+
+	return nil
+}
+
+// readScalefactors - scale_factor_data
+func (s *ics) readScalefactors(r *reader, globalGain int) error {
+	sf := globalGain
+	noise := globalGain - 90
+	position := 0
+	noiseFirst := true
+
+	for g := 0; g < s.numGroups; g++ {
+		for sfb := 0; sfb < s.maxSfb; sfb++ {
+			switch s.bandType[g][sfb] {
+			case zeroHCB:
+				s.sf[g][sfb] = 0
+
+			case intensityHCB, intensityHCB2:
+				delta, err := scalefactorVLC.read(r)
+				if err != nil {
+					return err
+				}
+				position += delta - 60
+				s.sf[g][sfb] = position
+
+			case noiseHCB:
+				if noiseFirst {
+					noise += int(r.bits(9)) - 256
+					noiseFirst = false
+				} else {
+					delta, err := scalefactorVLC.read(r)
+					if err != nil {
+						return err
+					}
+					noise += delta - 60
+				}
+				s.sf[g][sfb] = noise
+
+			default:
+				delta, err := scalefactorVLC.read(r)
+				if err != nil {
+					return err
+				}
+				sf += delta - 60
+				if sf < 0 || sf > 255 {
+					return errWrongFrame
+				}
+				s.sf[g][sfb] = sf
+			}
+		}
+	}
+
+	return nil
+}
+
+// readTNS - tns_data, filters are converted to LPC coefficients
+func (s *ics) readTNS(r *reader) error {
+	nBits, lengthBits, orderBits, maxOrder := 2, 6, 5, 12
+	if s.windowSequence == eightShortSequence {
+		nBits, lengthBits, orderBits, maxOrder = 1, 4, 3, 7
+	}
+
+	for w := 0; w < s.numWindows; w++ {
+		nFilt := int(r.bits(nBits))
+		if nFilt == 0 {
+			continue
+		}
+
+		coefRes := int(r.bits(1)) + 3
+
+		for i := 0; i < nFilt; i++ {
+			filter := tnsFilter{length: int(r.bits(lengthBits))}
+
+			order := int(r.bits(orderBits))
+			if order > maxOrder {
+				return errWrongFrame
+			}
+
+			if order > 0 {
+				filter.direction = r.flag()
+				coefBits := coefRes - int(r.bits(1))
+
+				parcor := make([]float64, order)
+				for j := range parcor {
+					parcor[j] = tnsCoef(r.bits(coefBits), coefBits, coefRes)
+				}
+				filter.lpc = parcorToLPC(parcor)
+			}
+
+			s.tns[w] = append(s.tns[w], filter)
+		}
+	}
+
+	return nil
+}
+
+// readSpectrum - spectral_data, values are placed to quant[window*128+offset]
+func (s *ics) readSpectrum(r *reader) error {
+	s.quant = [frameLength]int32{}
+
+	w0 := 0
+	for g := 0; g < s.numGroups; g++ {
+		for sfb := 0; sfb < s.maxSfb; sfb++ {
+			cb := s.bandType[g][sfb]
+			if cb == zeroHCB || cb >= noiseHCB {
+				continue
+			}
+
+			for w := w0; w < w0+s.groupLen[g]; w++ {
+				start := w*128 + int(s.swb[sfb])
+				end := w*128 + int(s.swb[sfb+1])
+				for i := start; i < end; {
+					n, err := readCodeword(r, cb, s.quant[i:end])
+					if err != nil {
+						return err
+					}
+					i += n
+				}
+			}
+		}
+		w0 += s.groupLen[g]
+	}
+
+	for _, p := range s.pulse {
+		if s.quant[p.pos] > 0 {
+			s.quant[p.pos] += p.amp
+		} else {
+			s.quant[p.pos] -= p.amp
+		}
+	}
+
+	if r.overrun() {
+		return errWrongFrame
+	}
+
+	return nil
+}
+
+// readCodeword - read spectral codeword with signs and escapes for codebook cb (1..11),
+// returns number of values (2 or 4)
+func readCodeword(r *reader, cb byte, dst []int32) (int, error) {
+	idx, err := spectrumVLC[cb-1].read(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var v [4]int32
+	var n int
+
+	switch cb {
+	case 1, 2:
+		v = [4]int32{int32(idx/27) - 1, int32(idx/9%3) - 1, int32(idx/3%3) - 1, int32(idx%3) - 1}
+		n = 4
+	case 3, 4:
+		v = [4]int32{int32(idx / 27), int32(idx / 9 % 3), int32(idx / 3 % 3), int32(idx % 3)}
+		n = 4
+	case 5, 6:
+		v = [4]int32{int32(idx/9) - 4, int32(idx%9) - 4}
+		n = 2
+	case 7, 8:
+		v = [4]int32{int32(idx / 8), int32(idx % 8)}
+		n = 2
+	case 9, 10:
+		v = [4]int32{int32(idx / 13), int32(idx % 13)}
+		n = 2
+	case escHCB:
+		v = [4]int32{int32(idx / 17), int32(idx % 17)}
+		n = 2
+	}
+
+	if len(dst) < n {
+		return 0, errWrongFrame
+	}
+
+	// unsigned codebooks
+	if cb == 3 || cb == 4 || cb >= 7 {
+		for i := 0; i < n; i++ {
+			if v[i] != 0 && r.flag() {
+				v[i] = -v[i]
+			}
+		}
+	}
+
+	if cb == escHCB {
+		for i := 0; i < n; i++ {
+			if v[i] != 16 && v[i] != -16 {
+				continue
+			}
+
+			var bits int
+			for r.flag() {
+				if bits++; bits > 8 {
+					return 0, errWrongFrame
+				}
+			}
+
+			esc := int32(1)<<(bits+4) + int32(r.bits(bits+4))
+			if v[i] < 0 {
+				v[i] = -esc
+			} else {
+				v[i] = esc
+			}
+		}
+	}
+
+	copy(dst, v[:n])
+	return n, nil
+}
@@ -0,0 +1,39 @@
+package decoder
+
+// reader - MSB first bit reader with overrun detection
+type reader struct {
+	buf []byte
+	pos int // current bit position
+}
+
+func (r *reader) bit() uint32 {
+	i, n := r.pos>>3, 7-r.pos&7
+	r.pos++
+	if i >= len(r.buf) {
+		return 0
+	}
+	return uint32(r.buf[i]>>n) & 1
+}
+
+func (r *reader) bits(n int) (v uint32) {
+	for ; n > 0; n-- {
+		v = v<<1 | r.bit()
+	}
+	return
+}
+
+func (r *reader) flag() bool {
+	return r.bit() == 1
+}
+
+func (r *reader) align() {
+	r.pos = (r.pos + 7) &^ 7
+}
+
+func (r *reader) skip(n int) {
+	r.pos += n
+}
+
+func (r *reader) overrun() bool {
+	return r.pos > len(r.buf)*8
+}
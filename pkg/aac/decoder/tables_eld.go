@@ -0,0 +1,512 @@
+package decoder
+
+// scalefactor bands offsets for AAC-ELD frames with 512 and 480 samples
+var (
+	swb512_48 = []uint16{
+		0, 4, 8, 12, 16, 20, 24, 28, 32, 36, 40, 44, 48, 52, 56, 60, 68, 76, 84, 92, 100, 112, 124, 136,
+		148, 164, 184, 208, 236, 268, 300, 332, 364, 396, 428, 460, 512,
+	}
+	swb512_32 = []uint16{
+		0, 4, 8, 12, 16, 20, 24, 28, 32, 36, 40, 44, 48, 52, 56, 64, 72, 80, 88, 96, 108, 120, 132, 144,
+		160, 176, 192, 212, 236, 260, 288, 320, 352, 384, 416, 448, 480, 512,
+	}
+	swb512_24 = []uint16{
+		0, 4, 8, 12, 16, 20, 24, 28, 32, 36, 40, 44, 52, 60, 68, 80, 92, 104, 120, 140, 164, 192, 224, 256,
+		288, 320, 352, 384, 416, 448, 480, 512,
+	}
+	swb480_48 = []uint16{
+		0, 4, 8, 12, 16, 20, 24, 28, 32, 36, 40, 44, 48, 52, 56, 64, 72, 80, 88, 96, 108, 120, 132, 144,
+		156, 172, 188, 212, 240, 272, 304, 336, 368, 400, 432, 480,
+	}
+	swb480_32 = []uint16{
+		0, 4, 8, 12, 16, 20, 24, 28, 32, 36, 40, 44, 48, 52, 56, 60, 64, 72, 80, 88, 96, 104, 112, 124,
+		136, 148, 164, 180, 200, 224, 256, 288, 320, 352, 384, 416, 448, 480,
+	}
+	swb480_24 = []uint16{
+		0, 4, 8, 12, 16, 20, 24, 28, 32, 36, 40, 44, 52, 60, 68, 80, 92, 104, 120, 140, 164, 192, 224, 256,
+		288, 320, 352, 384, 416, 448, 480,
+	}
+)
+
+// AAC-ELD low delay synthesis windows, window length is 4 frames,
+// the last quarter of the last frame is always zero and not stored
+var (
+	eldWindow512 = [1920]float32{
+		0.00338834, 0.00567745, 0.00847677, 0.01172641, 0.01532555, 0.01917664, 0.02318809, 0.02729259,
+		0.03144503, 0.03560261, 0.03972499, 0.04379783, 0.04783094, 0.05183357, 0.05581342, 0.05977723,
+		0.06373173, 0.06768364, 0.07163937, 0.07559976, 0.07956096, 0.08352024, 0.08747623, 0.09143035,
+		0.09538618, 0.09934771, 0.10331917, 0.10730456, 0.11130697, 0.11532867, 0.11937133, 0.12343922,
+		0.12753911, 0.13167705, 0.13585812, 0.1400853, 0.14435986, 0.1486829, 0.15305531, 0.15747593,
+		0.16194193, 0.1664507, 0.17099991, 0.17558633, 0.180206, 0.18485548, 0.1895319, 0.19423322,
+		0.198958, 0.20370512, 0.20847374, 0.21326312, 0.21807244, 0.22290082, 0.22774743, 0.2326121,
+		0.23749542, 0.24239767, 0.2473189, 0.25225887, 0.2572172, 0.2621933, 0.2671865, 0.2721963,
+		0.27722263, 0.28226513, 0.28732336, 0.29239628, 0.29748246, 0.30258054, 0.30768913, 0.3128051,
+		0.31792384, 0.3230417, 0.3281558, 0.33326396, 0.3383647, 0.3434566, 0.34853867, 0.3536119,
+		0.35867864, 0.3637407, 0.368799, 0.37385347, 0.37890348, 0.38394836, 0.3889873, 0.39401913,
+		0.39904237, 0.40405574, 0.4090582, 0.4140482, 0.419024, 0.42398423, 0.42892805, 0.4338544,
+		0.4387621, 0.44365013, 0.44851786, 0.4533663, 0.4581976, 0.46301302, 0.4678131, 0.4725972,
+		0.47736436, 0.48211366, 0.4868445, 0.49155593, 0.49624678, 0.50091636, 0.5055644, 0.5101913,
+		0.5147977, 0.5193839, 0.52395, 0.52849585, 0.5330215, 0.5375268, 0.5420116, 0.54647577,
+		0.5509192, 0.55534184, 0.55974376, 0.5641251, 0.56848615, 0.5728271, 0.5771483, 0.5814503,
+		0.5849249, 0.5891851, 0.59342325, 0.5976394, 0.60183346, 0.6060056, 0.6101558, 0.6142841,
+		0.61839056, 0.62247515, 0.626538, 0.6305791, 0.63459873, 0.63859695, 0.642574, 0.64653003,
+		0.65046495, 0.6543789, 0.6582718, 0.6621438, 0.665995, 0.6698254, 0.673635, 0.67742395,
+		0.6811922, 0.68493974, 0.6886665, 0.69237256, 0.6960578, 0.69972205, 0.7033654, 0.70698756,
+		0.71058863, 0.71416837, 0.71772677, 0.7212636, 0.7247789, 0.72827244, 0.73174417, 0.7351939,
+		0.7386214, 0.74202645, 0.7454087, 0.74876815, 0.7521046, 0.7554178, 0.7587078, 0.7619744,
+		0.76521707, 0.7684357, 0.77162987, 0.7747994, 0.777944, 0.7810636, 0.7841579, 0.7872267,
+		0.7902698, 0.7932869, 0.7962779, 0.79924244, 0.8021803, 0.80509114, 0.8079747, 0.81083083,
+		0.81365913, 0.8164595, 0.8192316, 0.8219753, 0.82469034, 0.8273767, 0.8300342, 0.83266264,
+		0.8352619, 0.83783174, 0.84037215, 0.842883, 0.84536403, 0.84781516, 0.8502363, 0.8526274,
+		0.85498834, 0.85731924, 0.8596199, 0.8618905, 0.86413103, 0.8663414, 0.86852175, 0.8706721,
+		0.8727927, 0.87488383, 0.8769456, 0.87897825, 0.88098204, 0.8829573, 0.8849042, 0.8868233,
+		0.8887152, 0.8905805, 0.8924198, 0.8942339, 0.8960234, 0.89778894, 0.89953125, 0.90125144,
+		0.9029509, 0.904631, 0.9062934, 0.90793943, 0.9095707, 0.91118854, 0.91279465, 0.91439074,
+		0.91597897, 0.91756153, 0.9191405, 0.9207169, 0.9222907, 0.9238618, 0.92542994, 0.92698944,
+		0.9285296, 0.9300393, 0.9315073, 0.9329174, 0.9342486, 0.93547976, 0.93658984, 0.93756586,
+		0.9389407, 0.9392278, 0.93955475, 0.9399129, 0.94029105, 0.94067794, 0.94106257, 0.9414408,
+		0.9418155, 0.94218963, 0.9425663, 0.9429466, 0.94333, 0.94371563, 0.9441028, 0.9444912,
+		0.9448811, 0.9452725, 0.94566566, 0.9460607, 0.94645774, 0.9468567, 0.9472576, 0.94766057,
+		0.94806546, 0.9484723, 0.94888115, 0.9492919, 0.9497047, 0.9501196, 0.9505367, 0.95095605,
+		0.9513775, 0.95180106, 0.9522266, 0.9526541, 0.9530838, 0.9535157, 0.9539499, 0.95438653,
+		0.9548254, 0.9552664, 0.9557096, 0.9561549, 0.95660233, 0.9570521, 0.95750433, 0.95795894,
+		0.9584158, 0.95887494, 0.95933616, 0.95979947, 0.960265, 0.96073276, 0.96120286, 0.9616753,
+		0.96214986, 0.9626266, 0.9631052, 0.96358585, 0.96406853, 0.9645533, 0.96504027, 0.9655294,
+		0.9660205, 0.9665136, 0.9670085, 0.9675052, 0.96800375, 0.96850425, 0.9690067, 0.9695111,
+		0.9700174, 0.9705253, 0.9710349, 0.971546, 0.97205865, 0.97257304, 0.97308916, 0.97360694,
+		0.97412634, 0.9746471, 0.97516924, 0.97569263, 0.9762173, 0.9767435, 0.97727114, 0.97780013,
+		0.9783305, 0.97886205, 0.9793946, 0.97992826, 0.9804629, 0.98099875, 0.9815358, 0.982074,
+		0.9826134, 0.98315364, 0.98369473, 0.98423666, 0.9847794, 0.98532313, 0.9858678, 0.9864135,
+		0.98696005, 0.98750734, 0.9880553, 0.9886039, 0.9891532, 0.9897033, 0.9902542, 0.99080604,
+		0.9913586, 0.9919117, 0.99246544, 0.99301964, 0.99357444, 0.9941299, 0.9946862, 0.9952432,
+		0.9958009, 0.9963593, 0.99691814, 0.9974775, 0.9980372, 0.99859726, 0.99915755, 0.99971795,
+		1.0002822, 1.0008432, 1.0014048, 1.0019666, 1.0025289, 1.0030913, 1.003654, 1.0042168,
+		1.0047796, 1.0053422, 1.0059048, 1.0064671, 1.0070294, 1.0075918, 1.0081543, 1.0087168,
+		1.0092793, 1.0098417, 1.0104039, 1.0109657, 1.0115274, 1.0120891, 1.0126507, 1.0132122,
+		1.0137737, 1.0143348, 1.0148956, 1.0154558, 1.0160158, 1.0165756, 1.017135, 1.0176942,
+		1.0182532, 1.0188116, 1.0193692, 1.0199264, 1.0204829, 1.0210389, 1.0215944, 1.0221494,
+		1.0227039, 1.0232575, 1.0238103, 1.0243621, 1.024913, 1.025463, 1.0260124, 1.0265609,
+		1.0271086, 1.0276551, 1.0282004, 1.0287445, 1.0292873, 1.0298291, 1.0303698, 1.0309094,
+		1.0314476, 1.0319846, 1.03252, 1.0330539, 1.0335861, 1.0341171, 1.0346466, 1.0351747,
+		1.0357013, 1.0362262, 1.0367494, 1.0372707, 1.0377903, 1.0383081, 1.0388244, 1.0393392,
+		1.039852, 1.0403631, 1.0408722, 1.0413792, 1.0418843, 1.0423875, 1.0428889, 1.0433885,
+		1.0438861, 1.0443817, 1.0448751, 1.0453664, 1.0458556, 1.046343, 1.0468284, 1.0473119,
+		1.0477935, 1.0482731, 1.0487504, 1.0492257, 1.049699, 1.0501702, 1.0506397, 1.0511074,
+		1.0515733, 1.0520372, 1.052499, 1.0529588, 1.0534167, 1.0538728, 1.054327, 1.0547795,
+		1.0552301, 1.0556791, 1.0561261, 1.0565712, 1.0570146, 1.0574561, 1.057896, 1.0583342,
+		1.0587711, 1.0592067, 1.0596412, 1.0600744, 1.0605054, 1.0609334, 1.0613575, 1.0617791,
+		1.0622016, 1.0626286, 1.0630631, 1.0635005, 1.0639284, 1.0643339, 1.0647044, 1.06503,
+		1.0648108, 1.0646976, 1.06445, 1.06408, 1.0636138, 1.0630772, 1.0624945, 1.0618837,
+		1.0612562, 1.0606229, 1.0599942, 1.0593714, 1.0587473, 1.0581149, 1.0574672, 1.0568,
+		1.0561107, 1.0553972, 1.0546573, 1.0538933, 1.0531108, 1.0523158, 1.0515137, 1.050708,
+		1.0499004, 1.049092, 1.0482843, 1.0474764, 1.0466659, 1.04585, 1.0450263, 1.04419,
+		1.043335, 1.0424545, 1.0415424, 1.0405946, 1.0396085, 1.0385821, 1.0375133, 1.0364019,
+		1.0352497, 1.0340587, 1.0328305, 1.0315682, 1.0302757, 1.0289575, 1.0276172, 1.026258,
+		1.0248822, 1.0234919, 1.0220889, 1.0206745, 1.0192486, 1.0178112, 1.0163623, 1.0149004,
+		1.0134232, 1.0119277, 1.0104117, 1.0088729, 1.0073092, 1.0057188, 1.0041, 1.0024503,
+		1.0007674, 0.9990484, 0.997291, 0.9954938, 0.99365664, 0.99177945, 0.9898623, 0.9879102,
+		0.98593295, 0.98394036, 0.98194224, 0.9799453, 0.97795326, 0.97596955, 0.9739975, 0.97203326,
+		0.97006625, 0.96808547, 0.9660802, 0.96404415, 0.9619756, 0.9598728, 0.9577342, 0.9555602,
+		0.9533529, 0.9511146, 0.94884765, 0.9465566, 0.94424856, 0.94193053, 0.9396095, 0.93729156,
+		0.9349816, 0.93268454, 0.930405, 0.9281377, 0.92586756, 0.9235791, 0.9212573, 0.91889644,
+		0.9165, 0.9140719, 0.9116162, 0.90913975, 0.90665203, 0.9041627, 0.9016811, 0.8992093,
+		0.89674187, 0.8942731, 0.8917974, 0.8893115, 0.8868142, 0.88430446, 0.8817814, 0.8792453,
+		0.87669754, 0.87413967, 0.8715732, 0.8689996, 0.8664204, 0.863837, 0.86125106, 0.8586639,
+		0.8560424, 0.85344386, 0.8508309, 0.8482055, 0.84556943, 0.8429246, 0.8402728, 0.83761585,
+		0.83495563, 0.8322939, 0.8296324, 0.82697135, 0.82430935, 0.82164496, 0.8189767, 0.81630015,
+		0.8136082, 0.81089354, 0.8081492, 0.8053774, 0.8025892, 0.7997961, 0.7970095, 0.79423815,
+		0.7914878, 0.7887643, 0.7860729, 0.7834059, 0.7807429, 0.77806276, 0.77534515, 0.7725819,
+		0.76977736, 0.76693654, 0.76406443, 0.76116854, 0.75825894, 0.7553458, 0.75243926, 0.74954635,
+		0.7466714, 0.7438184, 0.7409915, 0.7381915, 0.7354164, 0.7326641, 0.72993195, 0.72720915,
+		0.72447664, 0.7217149, 0.71890515, 0.7160393, 0.7131206, 0.7101525, 0.707139, 0.70409083,
+		0.70102566, 0.6979614, 0.69491553, 0.6918977, 0.6889093, 0.6859514, 0.683025, 0.6801285,
+		0.677258, 0.6744094, 0.6715784, 0.66876084, 0.66595197, 0.6631472, 0.6603419, 0.65753025,
+		0.6547052, 0.6518598, 0.6489871, 0.64608216, 0.6431422, 0.6401646, 0.6371468, 0.63409036,
+		0.6310008, 0.627884, 0.6247458, 0.6215947, 0.61844224, 0.61529976, 0.6121787, 0.6090881,
+		0.6060351, 0.60302657, 0.60006917, 0.5971659, 0.5943158, 0.59151787, 0.5887707, 0.58606493,
+		0.5833835, 0.5807089, 0.57802355, 0.5753086, 0.57254404, 0.5697096, 0.56678575, 0.5637686,
+		0.5606695, 0.55750066, 0.5542745, 0.551013, 0.5477473, 0.54450905, 0.5413294, 0.53822744,
+		0.5352107, 0.5322861, 0.5294598, 0.52672, 0.52403706, 0.5213807, 0.51872087, 0.5160357,
+		0.5133117, 0.5105356, 0.50769466, 0.5047893, 0.5018331, 0.49884, 0.49582407, 0.49279904,
+		0.4898575, 0.4867964, 0.4837943, 0.48085362, 0.47796577, 0.4751215, 0.4723115, 0.46952403,
+		0.46674487, 0.46395978, 0.46115497, 0.45832607, 0.4554783, 0.45261726, 0.44974867, 0.4468801,
+		0.44402125, 0.44118178, 0.43837094, 0.43558773, 0.43282083, 0.43005848, 0.42728913, 0.4245057,
+		0.42170566, 0.41888657, 0.41604632, 0.41318896, 0.41032472, 0.40746406, 0.40461725, 0.40178943,
+		0.39898065, 0.39619073, 0.3934194, 0.3906652, 0.38792536, 0.38519713, 0.38247773, 0.37976477,
+		0.3770562, 0.37435007, 0.37164438, 0.36893868, 0.36623397, 0.36353123, 0.36083153, 0.35813534,
+		0.3554426, 0.35275337, 0.35006756, 0.3473853, 0.34470698, 0.34203297, 0.3393636, 0.33669922,
+		0.33404028, 0.3313871, 0.32874012, 0.32609943, 0.32346493, 0.32083645, 0.31821388, 0.31559703,
+		0.31298572, 0.31037986, 0.3077794, 0.30518445, 0.30259526, 0.30001202, 0.297435, 0.29486427,
+		0.2922999, 0.28974178, 0.28718996, 0.2846445, 0.28210562, 0.27957347, 0.2770482, 0.27452993,
+		0.27201855, 0.269514, 0.26701623, 0.26452532, 0.26204157, 0.25956526, 0.25709662, 0.25463584,
+		0.25218293, 0.24973798, 0.247301, 0.24487206, 0.24245133, 0.24003893, 0.237635, 0.2352396,
+		0.23285262, 0.23047401, 0.2281037, 0.2257417, 0.22338818, 0.22104329, 0.21870719, 0.21637987,
+		0.21406117, 0.21175095, 0.20944904, 0.20715535, 0.20486987, 0.20259261, 0.20032357, 0.19806258,
+		0.19580944, 0.19356385, 0.19132556, 0.18909442, 0.1868704, 0.1846535, 0.18244372, 0.18024164,
+		0.17804842, 0.1758652, 0.17369322, 0.1715336, 0.16938755, 0.16725622, 0.16514081, 0.16304247,
+		0.16098975, 0.15896562, 0.15696026, 0.15497258, 0.15300152, 0.1510459, 0.14910465, 0.14717665,
+		0.14526081, 0.143356, 0.1414611, 0.1395757, 0.13769993, 0.135834, 0.13397805, 0.13213229,
+		0.13029683, 0.12847178, 0.12665729, 0.12485353, 0.12306074, 0.12127916, 0.119509, 0.11775043,
+		0.11600347, 0.1142682, 0.11254464, 0.11083292, 0.10913318, 0.10744559, 0.10577028, 0.10410733,
+		0.10245672, 0.10081842, 0.0991924, 0.09757872, 0.0959775, 0.09438884, 0.09281288, 0.09124964,
+		0.08969907, 0.08816111, 0.0866357, 0.08512288, 0.08362274, 0.0821354, 0.08066096, 0.07919944,
+		0.07775076, 0.07631484, 0.07489161, 0.07348108, 0.07208335, 0.07069851, 0.06932666, 0.06796781,
+		0.06662187, 0.06528874, 0.06396833, 0.06266065, 0.06136578, 0.0600838, 0.0588148, 0.05755876,
+		0.05631557, 0.05508511, 0.05386728, 0.05266206, 0.05146951, 0.05028971, 0.04912272, 0.04796855,
+		0.04682709, 0.04569825, 0.04458194, 0.04347817, 0.04238704, 0.04130868, 0.04024318, 0.03919056,
+		0.03815071, 0.03712352, 0.0361089, 0.03510679, 0.0341172, 0.03314013, 0.0321756, 0.03122343,
+		0.03028332, 0.02935494, 0.02843799, 0.0275323, 0.02663788, 0.02575472, 0.02488283, 0.02402232,
+		0.02317341, 0.02233631, 0.02151124, 0.02069866, 0.01989922, 0.01911359, 0.01834241, 0.01758563,
+		0.01684248, 0.01611219, 0.01539397, 0.01468726, 0.01399167, 0.01330687, 0.0126325, 0.01196871,
+		0.01131609, 0.01067527, 0.01004684, 0.00943077, 0.00882641, 0.00823307, 0.00765011, 0.00707735,
+		0.00651513, 0.00596377, 0.00542364, 0.00489514, 0.00437884, 0.0038753, 0.00338509, 0.00290795,
+		0.00244282, 0.0019886, 0.00154417, 0.00110825, 0.00067934, 0.00025589, -0.00016357, -0.00057897,
+		-0.00098865, -0.00139089, -0.00178397, -0.00216547, -0.0025323, -0.00288133, -0.00320955, -0.00351626,
+		-0.00380315, -0.00407198, -0.00432457, -0.00456373, -0.00479326, -0.00501699, -0.00523871, -0.00546066,
+		-0.0056836, -0.00590821, -0.00613508, -0.00636311, -0.00658944, -0.00681117, -0.0070254, -0.00722982,
+		-0.00742268, -0.00760226, -0.00776687, -0.0079158, -0.00804933, -0.00816774, -0.00827139, -0.00836122,
+		-0.00843882, -0.00850583, -0.00856383, -0.0086143, -0.00865853, -0.00869781, -0.00873344, -0.00876633,
+		-0.00879707, -0.00882622, -0.00885433, -0.00888132, -0.00890652, -0.00892925, -0.00894881, -0.00896446,
+		-0.00897541, -0.00898088, -0.0089801, -0.00897234, -0.00895696, -0.0089333, -0.00890076, -0.00885914,
+		-0.00880875, -0.00874987, -0.00868282, -0.00860825, -0.00852716, -0.00844055, -0.00834941, -0.00825485,
+		-0.00815807, -0.00806025, -0.00796253, -0.00786519, -0.00776767, -0.00766937, -0.00756971, -0.0074679,
+		-0.00736305, -0.00725422, -0.00714055, -0.00702161, -0.00689746, -0.00676816, -0.00663381, -0.00649489,
+		-0.0063523, -0.00620694, -0.00605969, -0.00591116, -0.00576167, -0.00561155, -0.0054611, -0.00531037,
+		-0.00515917, -0.00500732, -0.00485462, -0.00470075, -0.0045453, -0.00438786, -0.00422805, -0.00406594,
+		-0.00390204, -0.00373686, -0.00357091, -0.00340448, -0.0032377, -0.00307066, -0.00290344, -0.0027361,
+		-0.00256867, -0.00240117, -0.00223365, -0.00206614, -0.00189866, -0.00173123, -0.0015639, -0.00139674,
+		-0.00122989, -0.00106351, -0.00089772, -0.00073267, -0.00056849, -0.0004053, -0.00024324, -8.241e-05,
+		8.214e-05, 0.00024102, 0.00039922, 0.0005566, 0.00071299, 0.00086826, 0.00102224, 0.0011748,
+		0.00132579, 0.00147507, 0.00162252, 0.00176804, 0.00191161, 0.00205319, 0.00219277, 0.00233029,
+		0.00246567, 0.00259886, 0.00272975, 0.00285832, 0.00298453, 0.00310839, 0.0032299, 0.00334886,
+		0.00346494, 0.00357778, 0.00368706, 0.00379273, 0.00389501, 0.00399411, 0.0040902, 0.0041835,
+		0.00427419, 0.00436249, 0.00444858, 0.0045325, 0.00461411, 0.00469328, 0.00476988, 0.00484356,
+		0.00491375, 0.00497987, 0.00504139, 0.00509806, 0.0051499, 0.00519693, 0.0052392, 0.005277,
+		0.00531083, 0.00534122, 0.00536864, 0.00539357, 0.00541649, 0.00543785, 0.00545809, 0.00547713,
+		0.00549441, 0.00550936, 0.00552146, 0.00553017, 0.00553494, 0.00553524, 0.00553058, 0.00552065,
+		0.00550536, 0.00548459, 0.00545828, 0.00542662, 0.00539007, 0.0053491, 0.00530415, 0.00525568,
+		0.00520417, 0.00515009, 0.00509387, 0.00503595, 0.00497674, 0.00491665, 0.00485605, 0.00479503,
+		0.00473336, 0.00467082, 0.00460721, 0.00454216, 0.00447517, 0.00440575, 0.00433344, 0.00425768,
+		0.00417786, 0.00409336, 0.00400363, 0.00390837, 0.00380759, 0.0037013, 0.00358952, 0.00347268,
+		0.00335157, 0.00322699, 0.00309975, 0.00297088, 0.00284164, 0.00271328, 0.002587, 0.00246328,
+		0.00234195, 0.00222281, 0.00210562, 0.00198958, 0.00187331, 0.00175546, 0.00163474, 0.0015102,
+		0.0013813, 0.0012475, 0.00110831, 0.00096411, 0.00081611, 0.00066554, 0.00051363, 0.00036134,
+		0.0002094, 5.853e-05, -9.058e-05, -0.00023783, -0.00038368, -0.00052861, -0.0006731, -0.00081757,
+		-0.00096237, -0.00110786, -0.00125442, -0.0014021, -0.00155065, -0.00169984, -0.0018494, -0.0019991,
+		-0.00214872, -0.00229798, -0.00244664, -0.00259462, -0.00274205, -0.00288912, -0.00303596, -0.00318259,
+		-0.0033289, -0.0034748, -0.00362024, -0.00376519, -0.00390962, -0.00405345, -0.00419658, -0.00433902,
+		-0.00448085, -0.00462219, -0.00476309, -0.00490357, -0.00504361, -0.00518321, -0.00532243, -0.00546132,
+		-0.00559988, -0.00573811, -0.00587602, -0.00601363, -0.00615094, -0.00628795, -0.00642466, -0.00656111,
+		-0.00669737, -0.00683352, -0.00696963, -0.00710578, -0.00724208, -0.00737862, -0.00751554, -0.00765295,
+		-0.00779098, -0.00792976, -0.00806941, -0.00821006, -0.00835183, -0.00849485, -0.00863926, -0.00878522,
+		-0.00893293, -0.0090826, -0.00923444, -0.00938864, -0.00954537, -0.00970482, -0.00986715, -0.01003173,
+		-0.01019711, -0.01036164, -0.01052357, -0.01068184, -0.01083622, -0.01098652, -0.01113252, -0.01127409,
+		-0.01141114, -0.01154358, -0.01167135, -0.01179439, -0.01191268, -0.01202619, -0.01213493, -0.01223891,
+		-0.01233817, -0.01243275, -0.01252272, -0.01260815, -0.01268915, -0.01276583, -0.01283832, -0.01290685,
+		-0.01297171, -0.0130332, -0.01309168, -0.01314722, -0.01319969, -0.01324889, -0.01329466, -0.01333693,
+		-0.01337577, -0.01341125, -0.01344345, -0.01347243, -0.01349823, -0.01352089, -0.01354045, -0.013557,
+		-0.01357068, -0.01358164, -0.01359003, -0.01359587, -0.01359901, -0.01359931, -0.01359661, -0.01359087,
+		-0.01358219, -0.01357065, -0.01355637, -0.01353935, -0.01351949, -0.0134967, -0.01347088, -0.01344214,
+		-0.01341078, -0.01337715, -0.01334158, -0.01330442, -0.01326601, -0.01322671, -0.01318689, -0.01314692,
+		-0.01310123, -0.0130647, -0.01302556, -0.01298381, -0.01293948, -0.01289255, -0.01284305, -0.01279095,
+		-0.01273625, -0.01267893, -0.01261897, -0.01255632, -0.01249096, -0.01242283, -0.0123519, -0.01227827,
+		-0.01220213, -0.01212366, -0.01204304, -0.01196032, -0.01187543, -0.01178829, -0.01169884, -0.01160718,
+		-0.01151352, -0.01141809, -0.01132111, -0.01122272, -0.01112304, -0.01102217, -0.01092022, -0.0108173,
+		-0.01071355, -0.01060912, -0.01050411, -0.01039854, -0.01029227, -0.01018521, -0.01007727, -0.00996859,
+		-0.00985959, -0.00975063, -0.00964208, -0.0095342, -0.00942723, -0.00932135, -0.00921677, -0.00911364,
+		-0.00901208, -0.0089122, -0.00881412, -0.00871792, -0.00862369, -0.00853153, -0.00844149, -0.0083536,
+		-0.00826785, -0.00818422, -0.00810267, -0.00802312, -0.00794547, -0.00786959, -0.00779533, -0.00772165,
+		-0.00764673, -0.00756886, -0.00748649, -0.00739905, -0.00730681, -0.00721006, -0.0071091, -0.00700419,
+		-0.00689559, -0.00678354, -0.00666829, -0.00655007, -0.00642916, -0.00630579, -0.00618022, -0.00605267,
+		-0.00592333, -0.0057924, -0.00566006, -0.00552651, -0.00539194, -0.00525653, -0.00512047, -0.0049839,
+		-0.00484693, -0.00470969, -0.00457228, -0.00443482, -0.00429746, -0.00416034, -0.00402359, -0.00388738,
+		-0.00375185, -0.00361718, -0.0034835, -0.003351, -0.00321991, -0.00309043, -0.00296276, -0.00283698,
+		-0.00271307, -0.00259098, -0.00247066, -0.0023521, -0.00223531, -0.0021203, -0.00200709, -0.00189576,
+		-0.00178647, -0.00167936, -0.00157457, -0.00147216, -0.00137205, -0.00127418, -0.00117849, -0.00108498,
+		-0.00099375, -0.00090486, -0.0008184, -0.00073444, -0.00065309, -0.00057445, -0.0004986, -0.00042551,
+		-0.00035503, -0.000287, -0.00022125, -0.00015761, -9.588e-05, -3.583e-05, 2.272e-05, 7.975e-05,
+		0.00013501, 0.00018828, 0.00023933, 0.00028784, 0.00033342, 0.00037572, 0.00041438, 0.00044939,
+		0.00048103, 0.00050958, 0.00053533, 0.00055869, 0.00058015, 0.00060022, 0.00061935, 0.00063781,
+		0.00065568, 0.00067303, 0.00068991, 0.00070619, 0.00072155, 0.00073567, 0.00074826, 0.00075912,
+		0.00076811, 0.00077509, 0.00077997, 0.00078275, 0.00078351, 0.00078237, 0.00077943, 0.00077484,
+		0.00076884, 0.0007616, 0.00075335, 0.00074423, 0.00073442, 0.00072404, 0.00071323, 0.00070209,
+		0.00069068, 0.00067906, 0.00066728, 0.00065534, 0.00064321, 0.00063086, 0.00061824, 0.00060534,
+		0.00059211, 0.00057855, 0.00056462, 0.00055033, 0.00053566, 0.00052063, 0.00050522, 0.00048949,
+		0.00047349, 0.00045728, 0.00044092, 0.00042447, 0.00040803, 0.00039166, 0.00037544, 0.00035943,
+		0.00034371, 0.00032833, 0.00031333, 0.00029874, 0.00028452, 0.00027067, 0.00025715, 0.00024395,
+		0.00023104, 0.00021842, 0.00020606, 0.00019398, 0.00018218, 0.00017069, 0.00015953, 0.00014871,
+		0.00013827, 0.00012823, 0.00011861, 0.00010942, 0.00010067, 9.236e-05, 8.448e-05, 7.703e-05,
+		6.999e-05, 6.337e-05, 5.714e-05, 5.129e-05, 4.583e-05, 4.072e-05, 3.597e-05, 3.157e-05,
+		2.752e-05, 2.38e-05, 2.042e-05, 1.736e-05, 1.461e-05, 1.215e-05, 9.98e-06, 8.07e-06,
+		6.41e-06, 4.99e-06, 3.78e-06, 2.78e-06, 1.96e-06, 1.32e-06, 8.2e-07, 4.6e-07,
+		2e-07, 5e-08, -3e-08, -6e-08, -4e-08, -1e-08, 1e-08, 1e-08,
+		1e-08, 1e-08, -1e-08, -4e-08, -5e-08, -3e-08, 5e-08, 2e-07,
+		4.3e-07, 7.7e-07, 1.23e-06, 1.83e-06, 2.57e-06, 3.48e-06, 4.55e-06, 5.81e-06,
+		7.27e-06, 8.93e-06, 1.08e-05, 1.29e-05, 1.522e-05, 1.778e-05, 2.057e-05, 2.362e-05,
+		2.691e-05, 3.044e-05, 3.422e-05, 3.824e-05, 4.25e-05, 4.701e-05, 5.176e-05, 5.676e-05,
+		6.2e-05, 6.749e-05, 7.322e-05, 7.92e-05, 8.541e-05, 9.186e-05, 9.854e-05, 0.00010543,
+		0.00011251, 0.00011975, 0.00012714, 0.00013465, 0.00014227, 0.00014997, 0.00015775, 0.00016558,
+		0.00017348, 0.00018144, 0.00018947, 0.00019756, 0.00020573, 0.00021399, 0.00022233, 0.00023076,
+		0.00023924, 0.00024773, 0.00025621, 0.00026462, 0.00027293, 0.00028108, 0.00028904, 0.00029675,
+		0.00030419, 0.00031132, 0.0003181, 0.00032453, 0.00033061, 0.00033632, 0.00034169, 0.00034672,
+		0.00035142, 0.0003558, 0.00035988, 0.00036369, 0.00036723, 0.00037053, 0.00037361, 0.00037647,
+		0.00037909, 0.00038145, 0.00038352, 0.00038527, 0.00038663, 0.00038757, 0.00038801, 0.0003879,
+		0.00038717, 0.00038572, 0.0003835, 0.00038044, 0.00037651, 0.0003717, 0.00036597, 0.00035936,
+		0.00035191, 0.0003437, 0.0003348, 0.00032531, 0.00031537, 0.00030512, 0.0002947, 0.00028417,
+		0.00027354, 0.00026279, 0.00025191, 0.00024081, 0.00022933, 0.00021731, 0.00020458, 0.00019101,
+		0.00017654, 0.00016106, 0.00014452, 0.00012694, 0.00010848, 8.929e-05, 6.953e-05, 4.935e-05,
+		2.884e-05, 8.13e-06, -1.268e-05, -3.357e-05, -5.457e-05, -7.574e-05, -9.714e-05, -0.00011882,
+		-0.00014082, -0.00016318, -0.00018595, -0.00020912, -0.00023265, -0.0002565, -0.0002806, -0.00030492,
+		-0.00032941, -0.000354, -0.00037865, -0.00040333, -0.00042804, -0.00045279, -0.00047759, -0.00050243,
+		-0.00052728, -0.00055209, -0.00057685, -0.00060153, -0.00062611, -0.00065056, -0.00067485, -0.00069895,
+		-0.00072287, -0.0007466, -0.00077013, -0.00079345, -0.00081653, -0.00083936, -0.00086192, -0.00088421,
+		-0.00090619, -0.00092786, -0.00094919, -0.00097017, -0.00099077, -0.00101098, -0.00103077, -0.00105012,
+		-0.00106904, -0.0010875, -0.00110549, -0.00112301, -0.00114005, -0.0011566, -0.00117265, -0.00118821,
+		-0.00120325, -0.00121779, -0.0012318, -0.00124528, -0.00125822, -0.00127061, -0.00128243, -0.00129368,
+		-0.00130435, -0.00131445, -0.00132395, -0.00133285, -0.00134113, -0.00134878, -0.00135577, -0.00136215,
+		-0.00136797, -0.00137333, -0.00137834, -0.00138305, -0.00138748, -0.00139163, -0.00139551, -0.00139913,
+		-0.00140249, -0.00140559, -0.00140844, -0.00141102, -0.00141334, -0.00141538, -0.00141714, -0.00141861,
+		-0.00141978, -0.00142064, -0.00142117, -0.00142138, -0.00142125, -0.00142077, -0.00141992, -0.0014187,
+		-0.0014171, -0.0014151, -0.00141268, -0.00140986, -0.00140663, -0.00140301, -0.001399, -0.0013946,
+		-0.00138981, -0.00138464, -0.00137908, -0.00137313, -0.0013668, -0.0013601, -0.00135301, -0.00134555,
+		-0.00133772, -0.00132952, -0.00132095, -0.00131201, -0.00130272, -0.00129307, -0.00128309, -0.00127277,
+		-0.00126211, -0.00125113, -0.00123981, -0.00122817, -0.00121622, -0.00120397, -0.00119141, -0.00117859,
+		-0.00116552, -0.00115223, -0.00113877, -0.00112517, -0.00111144, -0.00109764, -0.00108377, -0.00106989,
+	}
+	eldWindow480 = [1800]float32{
+		0.00101191, 0.00440397, 0.00718669, 0.0107213, 0.01459757, 0.01875954, 0.02308987, 0.02751541,
+		0.0319813, 0.03643738, 0.0408529, 0.04522835, 0.0495762, 0.05390454, 0.05821503, 0.06251214,
+		0.06680463, 0.07109582, 0.07538014, 0.07965207, 0.08390857, 0.08815177, 0.09238785, 0.09662163,
+		0.1008586, 0.10510892, 0.1093811, 0.11367819, 0.11800355, 0.1223641, 0.12676834, 0.13122384,
+		0.13573477, 0.14030106, 0.1449234, 0.14960314, 0.15433829, 0.15912396, 0.16395663, 0.1688331,
+		0.17374837, 0.1786968, 0.18367393, 0.18867661, 0.19370368, 0.19875413, 0.20382641, 0.20892055,
+		0.21403775, 0.2191776, 0.224339, 0.2295225, 0.23472992, 0.23996189, 0.24521859, 0.2504993,
+		0.2558031, 0.2611294, 0.26647747, 0.27184704, 0.27723786, 0.28264967, 0.28808087, 0.29352832,
+		0.2989898, 0.3044638, 0.30994293, 0.31541663, 0.32087943, 0.3263277, 0.3317629, 0.3371864,
+		0.3425961, 0.34799346, 0.35338858, 0.35878843, 0.36419505, 0.3696063, 0.37501568, 0.38042068,
+		0.3858207, 0.39121276, 0.39659312, 0.40195993, 0.40731156, 0.41264382, 0.41795278, 0.4232367,
+		0.4284948, 0.43372753, 0.43893453, 0.44411397, 0.44927117, 0.4544188, 0.4595619, 0.46470168,
+		0.46983016, 0.47493637, 0.48001826, 0.4850748, 0.4901024, 0.49509782, 0.50005984, 0.5049904,
+		0.5098979, 0.5147871, 0.519658, 0.5245097, 0.52933955, 0.53414667, 0.53893113, 0.54369175,
+		0.5484273, 0.55313754, 0.5578226, 0.56248254, 0.56711763, 0.57172817, 0.5763147, 0.5808776,
+		0.58719975, 0.59173065, 0.59623647, 0.6007172, 0.60517293, 0.6096037, 0.61400956, 0.61839056,
+		0.6227467, 0.62707806, 0.63138473, 0.635667, 0.639925, 0.64415896, 0.64836895, 0.652555,
+		0.6567171, 0.6608555, 0.66497004, 0.66906095, 0.67312825, 0.677172, 0.6811922, 0.68518883,
+		0.6891619, 0.6931113, 0.697037, 0.7009388, 0.7048168, 0.70867074, 0.71250045, 0.716306,
+		0.72008705, 0.7238436, 0.7275755, 0.73128253, 0.7349646, 0.7386214, 0.74225265, 0.745858,
+		0.7494373, 0.75299037, 0.7565171, 0.7600173, 0.7634906, 0.7669367, 0.77035517, 0.77374566,
+		0.7771079, 0.7804417, 0.7837468, 0.7870229, 0.7902698, 0.79348713, 0.7966747, 0.79983217,
+		0.80295914, 0.80605537, 0.8091205, 0.8121542, 0.81515616, 0.81812614, 0.8210639, 0.8239691,
+		0.8268418, 0.8296815, 0.8324883, 0.8352619, 0.838002, 0.8407087, 0.8433816, 0.8460206,
+		0.84862554, 0.85119635, 0.85373294, 0.8562352, 0.85870326, 0.86113703, 0.8635365, 0.8659017,
+		0.8682327, 0.87052965, 0.8727927, 0.8750222, 0.8772183, 0.8793813, 0.88151157, 0.8836094,
+		0.8856752, 0.88770956, 0.8897133, 0.89168715, 0.893632, 0.8955486, 0.8974377, 0.8993003,
+		0.9011374, 0.9029509, 0.9047424, 0.9065138, 0.90826684, 0.91000336, 0.91172516, 0.91343415,
+		0.91513276, 0.91682357, 0.91850924, 0.9201917, 0.9218713, 0.9235478, 0.92522115, 0.92688596,
+		0.9285296, 0.9301386, 0.931699, 0.9331911, 0.93458503, 0.93587625, 0.93694276, 0.9382556,
+		0.9388222, 0.9391078, 0.93944186, 0.939815, 0.94021434, 0.94062626, 0.9410371, 0.9414408,
+		0.9418404, 0.94223964, 0.94264203, 0.9430486, 0.9434583, 0.9438703, 0.9442839, 0.9446989,
+		0.94511575, 0.9455344, 0.9459552, 0.9463782, 0.94680333, 0.9472308, 0.94766057, 0.9480925,
+		0.94852674, 0.94896317, 0.9494018, 0.94984275, 0.9502862, 0.9507321, 0.9511806, 0.95163137,
+		0.9520845, 0.9525399, 0.9529977, 0.953458, 0.9539209, 0.95438653, 0.9548547, 0.95532537,
+		0.95579845, 0.956274, 0.956752, 0.9572327, 0.95771617, 0.9582023, 0.958691, 0.9591822,
+		0.9596757, 0.9601717, 0.96067023, 0.96117145, 0.9616753, 0.96218157, 0.96269023, 0.96320117,
+		0.96371436, 0.9642299, 0.96474785, 0.96526825, 0.96579105, 0.96631616, 0.96684337, 0.9673726,
+		0.9679039, 0.9684374, 0.96897316, 0.9695111, 0.97005117, 0.97059315, 0.971137, 0.97168255,
+		0.97222996, 0.9727793, 0.97333056, 0.97388375, 0.9744386, 0.9749951, 0.9755529, 0.9761123,
+		0.97667325, 0.9772359, 0.97780013, 0.9783659, 0.978933, 0.97950125, 0.9800707, 0.98064137,
+		0.9812134, 0.98178685, 0.98236156, 0.98293746, 0.9835143, 0.98409206, 0.98467076, 0.98525053,
+		0.98583144, 0.9864135, 0.9869965, 0.98758036, 0.98816496, 0.9887503, 0.9893365, 0.98992354,
+		0.99051166, 0.9911006, 0.9916904, 0.9922808, 0.99287176, 0.9934634, 0.9940558, 0.99464905,
+		0.9952432, 0.9958381, 0.99643373, 0.99702996, 0.9976267, 0.99822384, 0.9988213, 0.99941903,
+		1.0005813, 1.00118, 1.0017793, 1.002379, 1.0029789, 1.003579, 1.0041792, 1.0047796,
+		1.0053797, 1.0059798, 1.0065796, 1.0071794, 1.0077792, 1.0083792, 1.0089793, 1.0095793,
+		1.010179, 1.0107784, 1.0113777, 1.0119768, 1.0125759, 1.0131748, 1.0137737, 1.0143722,
+		1.0149703, 1.0155679, 1.0161651, 1.016762, 1.0173588, 1.0179552, 1.018551, 1.0191463,
+		1.0197407, 1.0203346, 1.0209277, 1.0215204, 1.0221125, 1.0227039, 1.0232944, 1.0238838,
+		1.0244722, 1.0250597, 1.0256462, 1.0262319, 1.0268166, 1.0274001, 1.0279825, 1.0285633,
+		1.0291427, 1.0297209, 1.0302978, 1.0308734, 1.0314476, 1.0320203, 1.0325912, 1.0331604,
+		1.0337279, 1.0342938, 1.034858, 1.0354207, 1.0359814, 1.0365403, 1.0370971, 1.0376519,
+		1.0382047, 1.0387557, 1.0393049, 1.039852, 1.0403972, 1.0409399, 1.0414804, 1.0420187,
+		1.0425549, 1.0430889, 1.043621, 1.0441507, 1.044678, 1.045203, 1.0457255, 1.0462457,
+		1.0467638, 1.0472797, 1.0477935, 1.0483049, 1.0488139, 1.0493205, 1.0498247, 1.050327,
+		1.050827, 1.0513251, 1.051821, 1.0523145, 1.0528058, 1.0532949, 1.0537817, 1.0542666,
+		1.0547494, 1.0552301, 1.0557089, 1.0561855, 1.05666, 1.0571325, 1.057603, 1.0580715,
+		1.0585383, 1.0590036, 1.0594676, 1.0599302, 1.0603907, 1.0608481, 1.0613011, 1.061751,
+		1.0622016, 1.0626574, 1.0631214, 1.0635873, 1.0640392, 1.0644618, 1.0648404, 1.0651644,
+		1.0652786, 1.0649807, 1.0647019, 1.0642574, 1.063721, 1.0631146, 1.0624663, 1.0617927,
+		1.0611081, 1.0604246, 1.059745, 1.059062, 1.058367, 1.0576525, 1.0569147, 1.0561517,
+		1.0553607, 1.0545415, 1.0537003, 1.0528444, 1.051981, 1.0511143, 1.0502464, 1.0493786,
+		1.0485125, 1.0476462, 1.0467758, 1.0458986, 1.0450104, 1.044105, 1.0431741, 1.0422101,
+		1.0412065, 1.0401602, 1.0390685, 1.0379289, 1.0367409, 1.0355065, 1.034228, 1.0329077,
+		1.0315495, 1.0301583, 1.0287393, 1.0272971, 1.0258347, 1.0243546, 1.0228596, 1.0213511,
+		1.0198298, 1.0182952, 1.0167475, 1.0151854, 1.0136056, 1.0120051, 1.0103807, 1.0087299,
+		1.0070504, 1.00534, 1.0035962, 1.0018162, 0.9999967, 0.9981348, 0.9962279, 0.9942757,
+		0.99227816, 0.99023503, 0.98815125, 0.98603857, 0.98390895, 0.98177415, 0.9796415, 0.9775153,
+		0.9754, 0.97329754, 0.97119933, 0.9690918, 0.9669615, 0.9647982, 0.9625984, 0.9603603,
+		0.9580818, 0.9557629, 0.9534062, 0.95101434, 0.9485903, 0.9461401, 0.9436723, 0.94119555,
+		0.93871796, 0.9362463, 0.93378633, 0.9313446, 0.92892075, 0.9264997, 0.92406255, 0.9215904,
+		0.9190741, 0.91651714, 0.9139243, 0.91130054, 0.9086547, 0.9059984, 0.9033435, 0.9006993,
+		0.8980644, 0.89543134, 0.89279336, 0.89014494, 0.887484, 0.88480943, 0.88211995, 0.8794156,
+		0.87669796, 0.8739689, 0.8712303, 0.86848396, 0.86573166, 0.86297524, 0.8602165, 0.8574572,
+		0.8547434, 0.8519366, 0.84911454, 0.8462797, 0.8434342, 0.84058046, 0.8377206, 0.8348568,
+		0.8319913, 0.82912624, 0.8262614, 0.8233953, 0.8205262, 0.81765145, 0.8147643, 0.8118559,
+		0.808917, 0.8059445, 0.80294883, 0.7999443, 0.79694486, 0.79396164, 0.7910022, 0.7880735,
+		0.7851812, 0.78231424, 0.7794471, 0.77655405, 0.7736137, 0.7706228, 0.7675881, 0.76451504,
+		0.7614114, 0.7582886, 0.7551589, 0.7520348, 0.7489256, 0.7458368, 0.7427734, 0.7397401,
+		0.73673755, 0.7337631, 0.73081446, 0.72788614, 0.7249607, 0.72201425, 0.7190228, 0.7159699,
+		0.7128554, 0.70968425, 0.70646065, 0.70319587, 0.69991076, 0.69662714, 0.69336593, 0.69013745,
+		0.686943, 0.6837842, 0.68066144, 0.6775716, 0.6745095, 0.6714703, 0.6684488, 0.6654395,
+		0.6624368, 0.65943503, 0.65642756, 0.6534059, 0.6503616, 0.6472863, 0.6441744, 0.6410227,
+		0.6378277, 0.6345876, 0.6313063, 0.6279911, 0.6246488, 0.6212882, 0.617922, 0.61456436,
+		0.6112291, 0.60792804, 0.6046697, 0.60146254, 0.5983146, 0.59522873, 0.59220374, 0.5892386,
+		0.58632934, 0.5834606, 0.58061075, 0.5777587, 0.57488245, 0.5719579, 0.5689608, 0.56586635,
+		0.56266594, 0.5593719, 0.555999, 0.552563, 0.5490918, 0.5456238, 0.5421974, 0.53884727,
+		0.53559047, 0.5324345, 0.52938896, 0.5264505, 0.52358955, 0.52076864, 0.5179508, 0.51510763,
+		0.5122218, 0.50927734, 0.50625944, 0.5031707, 0.50002766, 0.49685022, 0.49364117, 0.4904869,
+		0.48726127, 0.4840489, 0.48090875, 0.47783482, 0.47481564, 0.47184023, 0.46889392, 0.46595836,
+		0.46301612, 0.46005088, 0.45705923, 0.45404822, 0.45102447, 0.44799542, 0.44497138, 0.44196397,
+		0.43898547, 0.43604106, 0.43312058, 0.43020943, 0.42729336, 0.42436272, 0.42141387, 0.418444,
+		0.4154508, 0.41244015, 0.40942463, 0.40641716, 0.40342873, 0.40046293, 0.39751923, 0.3945976,
+		0.39169693, 0.38881436, 0.38594642, 0.3830898, 0.38024145, 0.37739897, 0.37455985, 0.37172186,
+		0.36888462, 0.36604938, 0.36321735, 0.36038968, 0.35756668, 0.3547483, 0.35193455, 0.34912542,
+		0.34632128, 0.34352258, 0.34072974, 0.33794323, 0.33516353, 0.33239114, 0.32962647, 0.32686967,
+		0.32412043, 0.32137918, 0.31864044, 0.31588373, 0.3130991, 0.3102863, 0.30745527, 0.3046268,
+		0.30180657, 0.29899424, 0.29619083, 0.29339716, 0.29061332, 0.28783935, 0.28507563, 0.28232265,
+		0.27958068, 0.27684984, 0.27413017, 0.27142158, 0.26872396, 0.26603737, 0.2633621, 0.26069856,
+		0.258047, 0.2554083, 0.2527833, 0.2501721, 0.24757451, 0.24498713, 0.2424074, 0.2398355,
+		0.237272, 0.23471867, 0.23217624, 0.22964458, 0.22712345, 0.22461258, 0.22211201, 0.21962197,
+		0.2171429, 0.21467522, 0.21221878, 0.20977323, 0.20733693, 0.2049086, 0.20248823, 0.20007615,
+		0.19767357, 0.19528091, 0.19289781, 0.19052348, 0.1881566, 0.18579693, 0.18344441, 0.1811001,
+		0.17876595, 0.17644344, 0.174134, 0.17183904, 0.16956003, 0.16729836, 0.16505547, 0.16283278,
+		0.1599078, 0.1577602, 0.15563326, 0.15352558, 0.15143584, 0.1493627, 0.1473048, 0.14526081,
+		0.14322937, 0.14120919, 0.13919976, 0.13720138, 0.13521422, 0.13323852, 0.13127445, 0.12932216,
+		0.12738182, 0.12545358, 0.12353773, 0.12163457, 0.11974436, 0.1178673, 0.11600347, 0.11415293,
+		0.11231573, 0.11049201, 0.10868196, 0.10688578, 0.10510362, 0.10333551, 0.10158143, 0.09984133,
+		0.09811524, 0.09640327, 0.09470556, 0.09302228, 0.09135347, 0.08969907, 0.08805903, 0.08643326,
+		0.08482183, 0.08322486, 0.08164249, 0.08007481, 0.07852179, 0.07698335, 0.07545938, 0.07394984,
+		0.07245482, 0.07097444, 0.06950883, 0.068058, 0.06662187, 0.06520031, 0.06379324, 0.06240065,
+		0.06102266, 0.05965936, 0.05831084, 0.05697701, 0.05565775, 0.0543529, 0.05306239, 0.05178628,
+		0.05052464, 0.04927758, 0.0480451, 0.04682709, 0.04562344, 0.04443405, 0.04325893, 0.04209822,
+		0.04095208, 0.03982059, 0.03870371, 0.03760131, 0.03651325, 0.03543944, 0.03437987, 0.03333454,
+		0.03230348, 0.03128653, 0.03028332, 0.02929346, 0.02831658, 0.02735252, 0.02640127, 0.02546283,
+		0.02453725, 0.02362471, 0.02272547, 0.0218398, 0.0209681, 0.02011108, 0.01926957, 0.01844439,
+		0.01763565, 0.01684248, 0.01606394, 0.01529909, 0.01454726, 0.01380802, 0.01308092, 0.01236569,
+		0.01166273, 0.01097281, 0.01029671, 0.00963479, 0.00898646, 0.00835089, 0.00772725, 0.00711521,
+		0.00651513, 0.00592741, 0.00535249, 0.00479089, 0.00424328, 0.00371041, 0.00319271, 0.00268947,
+		0.00219928, 0.00172084, 0.00125271, 0.00079311, 0.00034023, -0.00010786, -0.00055144, -0.00098865,
+		-0.00141741, -0.00183557, -0.0022401, -0.00262725, -0.00299314, -0.00333475, -0.0036525, -0.00394867,
+		-0.00422533, -0.00448528, -0.00473278, -0.00497252, -0.00520916, -0.00544584, -0.0056836, -0.00592326,
+		-0.00616547, -0.00640861, -0.00664914, -0.00688354, -0.00710845, -0.00732136, -0.00752022, -0.00770289,
+		-0.00786789, -0.00801521, -0.00814526, -0.00825839, -0.00835563, -0.00843882, -0.00850996, -0.00857097,
+		-0.0086236, -0.00866943, -0.00871004, -0.00874688, -0.00878091, -0.00881277, -0.0088432, -0.00887248,
+		-0.00890002, -0.00892494, -0.00894641, -0.00896355, -0.00897541, -0.00898104, -0.00897948, -0.0089699,
+		-0.00895149, -0.00892346, -0.00888519, -0.0088367, -0.00877839, -0.00871058, -0.00863388, -0.00854936,
+		-0.00845826, -0.00836179, -0.00826124, -0.00815807, -0.00805372, -0.00794953, -0.00784572, -0.00774156,
+		-0.00763634, -0.00752929, -0.00741941, -0.00730556, -0.00718664, -0.00706184, -0.00693107, -0.00679443,
+		-0.006652, -0.00650428, -0.0063523, -0.00619718, -0.00603995, -0.00588133, -0.00572169, -0.00556143,
+		-0.00540085, -0.00523988, -0.00507828, -0.00491582, -0.0047522, -0.00458693, -0.00441953, -0.0042495,
+		-0.00407681, -0.00390204, -0.00372581, -0.00354874, -0.00337115, -0.00319318, -0.00301494, -0.00283652,
+		-0.00265797, -0.00247934, -0.00230066, -0.00212197, -0.00194331, -0.00176471, -0.0015862, -0.00140787,
+		-0.00122989, -0.00105244, -0.00087567, -0.00069976, -0.00052487, -0.00035115, -0.00017875, -7.82e-06,
+		7.79e-06, 0.00017701, 0.00034552, 0.00051313, 0.00067966, 0.00084492, 0.00100873, 0.00117093,
+		0.00133133, 0.00148978, 0.00164611, 0.00180023, 0.00195211, 0.00210172, 0.00224898, 0.00239383,
+		0.00253618, 0.00267593, 0.00281306, 0.00294756, 0.00307942, 0.00320864, 0.00333502, 0.00345816,
+		0.00357762, 0.00369297, 0.00380414, 0.0039114, 0.00401499, 0.00411524, 0.00421242, 0.00430678,
+		0.00439859, 0.00448799, 0.00457487, 0.00465908, 0.00474045, 0.00481857, 0.00489277, 0.00496235,
+		0.00502666, 0.00508546, 0.00513877, 0.00518662, 0.00522904, 0.00526648, 0.00529956, 0.00532895,
+		0.00535532, 0.00537929, 0.00540141, 0.00542228, 0.00544196, 0.00545981, 0.00547515, 0.00548726,
+		0.00549542, 0.00549899, 0.00549732, 0.00548986, 0.00547633, 0.00545664, 0.00543067, 0.00539849,
+		0.00536061, 0.00531757, 0.00526993, 0.00521822, 0.005163, 0.00510485, 0.00504432, 0.00498194,
+		0.00491822, 0.00485364, 0.00478862, 0.00472309, 0.00465675, 0.00458939, 0.00452067, 0.00445003,
+		0.00437688, 0.00430063, 0.00422062, 0.00413609, 0.00404632, 0.0039506, 0.00384863, 0.00374044,
+		0.003626, 0.0035054, 0.00337934, 0.00324885, 0.00311486, 0.00297849, 0.00284122, 0.00270458,
+		0.00257013, 0.00243867, 0.00231005, 0.00218399, 0.00206023, 0.00193766, 0.0018146, 0.00168938,
+		0.0015605, 0.00142701, 0.0012883, 0.00114365, 0.00099297, 0.00083752, 0.00067884, 0.00051845,
+		0.0003576, 0.0001972, 3.813e-05, -0.00011885, -0.00027375, -0.00042718, -0.00057975, -0.00073204,
+		-0.00088453, -0.00103767, -0.00119192, -0.00134747, -0.00150411, -0.00166151, -0.00181932, -0.00197723,
+		-0.00213493, -0.0022921, -0.00244849, -0.00260415, -0.00275928, -0.0029141, -0.00306879, -0.00322332,
+		-0.00337759, -0.00353145, -0.0036847, -0.00383722, -0.00398892, -0.00413972, -0.00428967, -0.00443889,
+		-0.00458749, -0.00473571, -0.00488366, -0.00503137, -0.00517887, -0.0053261, -0.00547302, -0.00561965,
+		-0.00576598, -0.00591199, -0.00605766, -0.006203, -0.00634801, -0.00649273, -0.00663727, -0.0067817,
+		-0.00692617, -0.00707084, -0.00721583, -0.00736129, -0.00750735, -0.00765415, -0.00780184, -0.00795059,
+		-0.00810058, -0.00825195, -0.00840487, -0.0085595, -0.00871607, -0.0088748, -0.00903596, -0.00919978,
+		-0.0093665, -0.00953635, -0.00970931, -0.00988421, -0.01005916, -0.01023208, -0.0104013, -0.01056627,
+		-0.01072678, -0.01088259, -0.01103348, -0.01117933, -0.01132004, -0.01145552, -0.01158573, -0.01171065,
+		-0.01183025, -0.01194454, -0.01205352, -0.01215722, -0.01225572, -0.01234911, -0.01243749, -0.01252102,
+		-0.01259985, -0.01267419, -0.01274437, -0.01281078, -0.01287379, -0.0129335, -0.01298972, -0.01304224,
+		-0.01309086, -0.01313556, -0.01317644, -0.01321357, -0.01324707, -0.01327697, -0.01330334, -0.01332622,
+		-0.0133457, -0.01336194, -0.0133751, -0.01338538, -0.01339276, -0.01339708, -0.01339816, -0.01339584,
+		-0.01339014, -0.01338116, -0.01336903, -0.01335382, -0.01333545, -0.01331381, -0.01328876, -0.01326033,
+		-0.0132288, -0.01319457, -0.01315806, -0.01311968, -0.01307987, -0.01303906, -0.01299769, -0.01295623,
+		-0.01308207, -0.01304153, -0.01299802, -0.01295155, -0.01290215, -0.0128498, -0.0127945, -0.01273625,
+		-0.01267501, -0.01261077, -0.01254347, -0.01247306, -0.0123995, -0.01232277, -0.01224304, -0.01216055,
+		-0.01207554, -0.01198813, -0.01189829, -0.0118059, -0.0117109, -0.01161335, -0.01151352, -0.01141167,
+		-0.01130807, -0.01120289, -0.01109626, -0.0109883, -0.01087916, -0.01076898, -0.01065793, -0.01054618,
+		-0.0104338, -0.01032068, -0.0102067, -0.01009171, -0.00997585, -0.00985959, -0.00974338, -0.00962765,
+		-0.00951273, -0.00939888, -0.00928634, -0.00917534, -0.00906604, -0.0089586, -0.00885313, -0.00874977,
+		-0.00864862, -0.00854979, -0.00845337, -0.00835939, -0.00826785, -0.00817872, -0.00809195, -0.00800745,
+		-0.00792506, -0.00784469, -0.00776588, -0.00768695, -0.00760568, -0.00752004, -0.00742875, -0.00733186,
+		-0.00722976, -0.00712279, -0.0070113, -0.00689559, -0.00677595, -0.00665269, -0.0065261, -0.00639649,
+		-0.00626417, -0.00612943, -0.00599252, -0.00585368, -0.00571315, -0.00557115, -0.00542792, -0.00528367,
+		-0.00513864, -0.00499301, -0.00484693, -0.00470054, -0.00455395, -0.00440733, -0.00426086, -0.00411471,
+		-0.00396904, -0.00382404, -0.00367991, -0.00353684, -0.00339502, -0.00325472, -0.00311618, -0.00297967,
+		-0.00284531, -0.00271307, -0.0025829, -0.00245475, -0.0023286, -0.00220447, -0.00208236, -0.00196233,
+		-0.0018445, -0.00172906, -0.0016162, -0.00150603, -0.00139852, -0.00129358, -0.00119112, -0.00109115,
+		-0.00099375, -0.00089902, -0.00080705, -0.00071796, -0.00063185, -0.00054886, -0.00046904, -0.00039231,
+		-0.00031845, -0.00024728, -0.0001786, -0.00011216, -4.771e-05, 1.5e-05, 7.6e-05, 0.00013501,
+		0.00019176, 0.00024595, 0.0002972, 0.00034504, 0.00038902, 0.00042881, 0.00046456, 0.00049662,
+		0.00052534, 0.00055114, 0.00057459, 0.00059629, 0.00061684, 0.0006366, 0.00065568, 0.00067417,
+		0.00069213, 0.00070935, 0.00072545, 0.00074005, 0.00075283, 0.00076356, 0.00077209, 0.00077828,
+		0.00078205, 0.0007835, 0.00078275, 0.00077992, 0.0007752, 0.00076884, 0.00076108, 0.00075218,
+		0.00074232, 0.0007317, 0.00072048, 0.00070881, 0.0006968, 0.0006845, 0.00067201, 0.00065934,
+		0.00064647, 0.00063335, 0.00061994, 0.00060621, 0.00059211, 0.00057763, 0.00056274, 0.00054743,
+		0.00053169, 0.00051553, 0.00049897, 0.00048206, 0.00046487, 0.00044748, 0.00042996, 0.00041241,
+		0.00039492, 0.00037759, 0.00036049, 0.00034371, 0.00032732, 0.00031137, 0.00029587, 0.00028079,
+		0.00026612, 0.00025183, 0.00023789, 0.00022428, 0.00021097, 0.00019797, 0.0001853, 0.00017297,
+		0.000161, 0.00014942, 0.00013827, 0.00012757, 0.00011736, 0.00010764, 9.841e-05, 8.969e-05,
+		8.145e-05, 7.369e-05, 6.641e-05, 5.958e-05, 5.32e-05, 4.725e-05, 4.171e-05, 3.659e-05,
+		3.186e-05, 2.752e-05, 2.357e-05, 1.999e-05, 1.679e-05, 1.392e-05, 1.14e-05, 9.18e-06,
+		7.26e-06, 5.62e-06, 4.24e-06, 3.09e-06, 2.17e-06, 1.43e-06, 8.8e-07, 4.8e-07,
+		2e-07, 4e-08, -4e-08, -6e-08, -4e-08, -0, 2e-08, 0,
+		0, 2e-08, -0, -4e-08, -5e-08, -4e-08, 4e-08, 1.9e-07,
+		4.5e-07, 8.3e-07, 1.34e-06, 2.01e-06, 2.85e-06, 3.87e-06, 5.1e-06, 6.54e-06,
+		8.21e-06, 1.011e-05, 1.227e-05, 1.468e-05, 1.735e-05, 2.03e-05, 2.352e-05, 2.702e-05,
+		3.08e-05, 3.486e-05, 3.918e-05, 4.379e-05, 4.866e-05, 5.382e-05, 5.924e-05, 6.495e-05,
+		7.093e-05, 7.719e-05, 8.373e-05, 9.053e-05, 9.758e-05, 0.00010488, 0.0001124, 0.0001201,
+		0.00012796, 0.00013596, 0.00014406, 0.00015226, 0.00016053, 0.00016886, 0.00017725, 0.00018571,
+		0.00019424, 0.00020286, 0.00021156, 0.00022037, 0.00022928, 0.00023825, 0.00024724, 0.00025621,
+		0.00026509, 0.00027385, 0.00028241, 0.00029072, 0.00029874, 0.00030643, 0.00031374, 0.00032065,
+		0.00032715, 0.00033325, 0.00033895, 0.00034425, 0.00034917, 0.00035374, 0.00035796, 0.00036187,
+		0.00036549, 0.00036883, 0.00037194, 0.00037479, 0.00037736, 0.00037963, 0.00038154, 0.00038306,
+		0.00038411, 0.00038462, 0.00038453, 0.00038373, 0.00038213, 0.00037965, 0.00037621, 0.00037179,
+		0.00036636, 0.00035989, 0.00035244, 0.00034407, 0.00033488, 0.00032497, 0.00031449, 0.00030361,
+		0.00029252, 0.00028133, 0.00027003, 0.00025862, 0.00024706, 0.00023524, 0.00022297, 0.00021004,
+		0.00019626, 0.0001815, 0.00016566, 0.00014864, 0.00013041, 0.00011112, 9.096e-05, 7.014e-05,
+		4.884e-05, 2.718e-05, 5.3e-06, -1.667e-05, -3.871e-05, -6.09e-05, -8.331e-05, -0.000106,
+		-0.00012902, -0.00015244, -0.00017631, -0.00020065, -0.00022541, -0.00025052, -0.00027594, -0.00030159,
+		-0.0003274, -0.00035332, -0.00037928, -0.00040527, -0.00043131, -0.00045741, -0.00048357, -0.00050978,
+		-0.00053599, -0.00056217, -0.00058827, -0.00061423, -0.00064002, -0.00066562, -0.000691, -0.00071616,
+		-0.0007411, -0.00076584, -0.00079036, -0.00081465, -0.00083869, -0.00086245, -0.0008859, -0.00090901,
+		-0.00093176, -0.00095413, -0.00097608, -0.00099758, -0.00101862, -0.00103918, -0.00105924, -0.00107879,
+		-0.00109783, -0.00111635, -0.00113434, -0.00115181, -0.00116873, -0.0011851, -0.00120091, -0.00121615,
+		-0.00123082, -0.0012449, -0.00125838, -0.00127125, -0.0012835, -0.00129511, -0.0013061, -0.00131643,
+		-0.0013261, -0.00133509, -0.00134334, -0.00135069, -0.00135711, -0.00136272, -0.00136768, -0.00137225,
+		-0.00137649, -0.00138042, -0.00138404, -0.00138737, -0.00139041, -0.00139317, -0.00139565, -0.00139785,
+		-0.00139976, -0.00140137, -0.00140267, -0.00140366, -0.00140432, -0.00140464, -0.00140461, -0.00140423,
+		-0.00140347, -0.00140235, -0.00140084, -0.00139894, -0.00139664, -0.00139388, -0.00139065, -0.00138694,
+		-0.00138278, -0.00137818, -0.00137317, -0.00136772, -0.00136185, -0.00135556, -0.00134884, -0.0013417,
+		-0.00133415, -0.00132619, -0.00131784, -0.00130908, -0.00129991, -0.00129031, -0.00128031, -0.0012699,
+		-0.00125912, -0.00124797, -0.00123645, -0.00122458, -0.00121233, -0.00119972, -0.00118676, -0.00117347,
+		-0.00115988, -0.00114605, -0.001132, -0.00111778, -0.00110343, -0.00108898, -0.00107448, -0.00105995,
+	}
+)
+
+var swbELD512 = [13][]uint16{nil, nil, nil, swb512_48, swb512_48, swb512_32, swb512_24, swb512_24}
+var swbELD480 = [13][]uint16{nil, nil, nil, swb480_48, swb480_48, swb480_32, swb480_24, swb480_24}
+
+// TNS_MAX_BANDS for AAC-ELD
+var (
+	tnsMaxBands512 = [13]int{0, 0, 0, 31, 32, 37, 31, 31}
+	tnsMaxBands480 = [13]int{0, 0, 0, 31, 32, 37, 30, 30}
+)
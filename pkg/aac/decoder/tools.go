@@ -0,0 +1,175 @@
+package decoder
+
+import "math"
+
+// pow43 - |x|^(4/3) for quantized values
+var pow43 [8192]float32
+
+// pow2sf - 2^(0.25*(sf-100)) for scalefactors
+var pow2sf [256]float32
+
+func init() {
+	for i := range pow43 {
+		pow43[i] = float32(math.Pow(float64(i), 4.0/3.0))
+	}
+	for i := range pow2sf {
+		pow2sf[i] = float32(math.Pow(2, 0.25*float64(i-100)))
+	}
+}
+
+// dequant - inverse quantization, scaling and perceptual noise substitution
+func (d *Decoder) dequant(s *ics) {
+	s.coef = [frameLength]float32{}
+
+	w0 := 0
+	for g := 0; g < s.numGroups; g++ {
+		for sfb := 0; sfb < s.maxSfb; sfb++ {
+			cb := s.bandType[g][sfb]
+			if cb == zeroHCB || cb == intensityHCB || cb == intensityHCB2 {
+				continue
+			}
+
+			for w := w0; w < w0+s.groupLen[g]; w++ {
+				coef := s.coef[w*128+int(s.swb[sfb]) : w*128+int(s.swb[sfb+1])]
+
+				if cb == noiseHCB {
+					var energy float32
+					for i := range coef {
+						d.random = d.random*1664525 + 1013904223
+						coef[i] = float32(int32(d.random))
+						energy += coef[i] * coef[i]
+					}
+
+					scale := float32(math.Pow(2, 0.25*float64(s.sf[g][sfb]))) / float32(math.Sqrt(float64(energy)))
+					for i := range coef {
+						coef[i] *= scale
+					}
+					continue
+				}
+
+				scale := pow2sf[s.sf[g][sfb]]
+				quant := s.quant[w*128+int(s.swb[sfb]):]
+				for i := range coef {
+					switch q := quant[i]; {
+					case q > 0:
+						coef[i] = pow43[q] * scale
+					case q < 0:
+						coef[i] = -pow43[-q] * scale
+					}
+				}
+			}
+		}
+		w0 += s.groupLen[g]
+	}
+}
+
+// stereo - M/S and intensity stereo for channel pair with common window
+func stereo(left, right *ics) {
+	w0 := 0
+	for g := 0; g < left.numGroups; g++ {
+		for sfb := 0; sfb < left.maxSfb; sfb++ {
+			cb := right.bandType[g][sfb]
+
+			for w := w0; w < w0+left.groupLen[g]; w++ {
+				l := left.coef[w*128+int(left.swb[sfb]) : w*128+int(left.swb[sfb+1])]
+				r := right.coef[w*128+int(left.swb[sfb]):]
+
+				switch {
+				case cb == intensityHCB || cb == intensityHCB2:
+					scale := float32(math.Pow(0.5, 0.25*float64(right.sf[g][sfb])))
+					if cb == intensityHCB2 {
+						scale = -scale
+					}
+					if left.msUsed[g][sfb] {
+						scale = -scale
+					}
+					for i := range l {
+						r[i] = l[i] * scale
+					}
+
+				case left.msUsed[g][sfb] && cb < noiseHCB && left.bandType[g][sfb] < noiseHCB:
+					for i := range l {
+						l[i], r[i] = l[i]+r[i], l[i]-r[i]
+					}
+				}
+			}
+		}
+		w0 += left.groupLen[g]
+	}
+}
+
+// tnsCoef - inverse quantization of TNS coefficient (4.6.9.3)
+func tnsCoef(v uint32, bits, res int) float64 {
+	i := int(v)
+	if i >= 1<<(bits-1) {
+		i -= 1 << bits // sign extension
+	}
+
+	if i >= 0 {
+		return math.Sin(float64(i) / ((float64(int(1)<<(res-1)) - 0.5) / (math.Pi / 2)))
+	}
+	return math.Sin(float64(i) / ((float64(int(1)<<(res-1)) + 0.5) / (math.Pi / 2)))
+}
+
+// parcorToLPC - conversion of reflection coefficients to LPC, without a[0]
+func parcorToLPC(parcor []float64) []float32 {
+	a := make([]float64, len(parcor)+1)
+	b := make([]float64, len(parcor)+1)
+	a[0] = 1
+
+	for m := 1; m <= len(parcor); m++ {
+		for i := 1; i < m; i++ {
+			b[i] = a[i] + parcor[m-1]*a[m-i]
+		}
+		for i := 1; i < m; i++ {
+			a[i] = b[i]
+		}
+		a[m] = parcor[m-1]
+	}
+
+	lpc := make([]float32, len(parcor))
+	for i := range lpc {
+		lpc[i] = float32(a[i+1])
+	}
+	return lpc
+}
+
+// applyTNS - temporal noise shaping all-pole filter (4.6.9.3)
+func (s *ics) applyTNS() {
+	numSwb := len(s.swb) - 1
+	limit := min(s.tnsMaxBands, s.maxSfb)
+
+	for w := 0; w < s.numWindows; w++ {
+		coef := s.coef[w*128:]
+
+		bottom := numSwb
+		for _, f := range s.tns[w] {
+			top := bottom
+			bottom = max(top-f.length, 0)
+
+			order := len(f.lpc)
+			if order == 0 {
+				continue
+			}
+
+			start := int(s.swb[min(bottom, limit)])
+			end := int(s.swb[min(top, limit)])
+			size := end - start
+			if size <= 0 {
+				continue
+			}
+
+			inc := 1
+			if f.direction {
+				inc = -1
+				start = end - 1
+			}
+
+			for m := 0; m < size; m, start = m+1, start+inc {
+				for i := 1; i <= min(m, order); i++ {
+					coef[start] -= coef[start-i*inc] * f.lpc[i-1]
+				}
+			}
+		}
+	}
+}
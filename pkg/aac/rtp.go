@@ -10,6 +10,11 @@ import (
 const RTPPacketVersionAAC = 0
 
 func RTPDepay(handler core.HandlerFunc) core.HandlerFunc {
+	return RTPDepayFrames(AUTime, handler)
+}
+
+// RTPDepayFrames - RTPDepay for codecs with custom access unit duration (AAC-ELD)
+func RTPDepayFrames(frameLength uint32, handler core.HandlerFunc) core.HandlerFunc {
 	var timestamp uint32
 
 	return func(packet *rtp.Packet) {
@@ -48,7 +53,7 @@ func RTPDepay(handler core.HandlerFunc) core.HandlerFunc {
 			headers = headers[2:]
 			units = units[unitSize:]
 
-			timestamp += AUTime
+			timestamp += frameLength
 
 			clone := *packet
 			clone.Version = RTPPacketVersionAAC
@@ -64,6 +69,11 @@ func RTPDepay(handler core.HandlerFunc) core.HandlerFunc {
 }
 
 func RTPPay(handler core.HandlerFunc) core.HandlerFunc {
+	return RTPPayFrames(AUTime, handler)
+}
+
+// RTPPayFrames - RTPPay for codecs with custom access unit duration (AAC-ELD)
+func RTPPayFrames(frameLength uint32, handler core.HandlerFunc) core.HandlerFunc {
 	var seq uint16
 	var ts uint32
 
@@ -93,7 +103,7 @@ func RTPPay(handler core.HandlerFunc) core.HandlerFunc {
 		handler(&clone)
 
 		seq++
-		ts += AUTime
+		ts += frameLength
 	}
 }
 
@@ -0,0 +1,68 @@
+package av1
+
+import "github.com/pion/rtp/codecs/av1/obu"
+
+// OBU types from AV1 specification, section 6.2.2
+const (
+	OBUTypeSequenceHeader    = 1
+	OBUTypeTemporalDelimiter = 2
+)
+
+// OBUs - split low overhead bitstream (OBUs with size fields) to separate OBUs
+func OBUs(b []byte) (obus [][]byte) {
+	for len(b) > 0 {
+		header, err := obu.ParseOBUHeader(b)
+		if err != nil || !header.HasSizeField {
+			return
+		}
+
+		i := header.Size()
+		size, n, err := obu.ReadLeb128(b[i:])
+		if err != nil {
+			return
+		}
+
+		i += int(n) + int(size)
+		if i > len(b) {
+			return
+		}
+
+		obus = append(obus, b[:i])
+		b = b[i:]
+	}
+	return
+}
+
+func OBUType(b []byte) byte {
+	return (b[0] >> 3) & 0x0F
+}
+
+// IsKeyframe - WebRTC and most encoders send the sequence header with each keyframe
+func IsKeyframe(b []byte) bool {
+	for _, unit := range OBUs(b) {
+		if OBUType(unit) == OBUTypeSequenceHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// TrimTemporalDelimiter - remove TD OBU, it is not allowed in MP4 samples and RTP payloads
+func TrimTemporalDelimiter(b []byte) []byte {
+	// TD always has zero size: 0x12 0x00
+	if len(b) >= 2 && OBUType(b) == OBUTypeTemporalDelimiter && b[1] == 0 {
+		return b[2:]
+	}
+	return b
+}
+
+// EncodeConfig - AV1 Codec Configuration Box (av1C) for main profile, 8 bit, 4:2:0
+// https://aomediacodec.github.io/av1-isobmff/#av1codecconfigurationbox-syntax
+func EncodeConfig(level byte) []byte {
+	return []byte{
+		0x81,         // marker=1, version=1
+		level & 0x1F, // seq_profile=0 (3 bit), seq_level_idx_0 (5 bit)
+		0b0000_1100,  // tier, high_bitdepth, twelve_bit, monochrome, chroma_subsampling_x=1, chroma_subsampling_y=1, chroma_sample_position (2 bit)
+		0,            // initial_presentation_delay_present=0
+	}
+}
@@ -0,0 +1,86 @@
+package av1
+
+import (
+	"testing"
+
+	"github.com/AlexxIT/go2rtc/pkg/bits"
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs/av1/obu"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTP(t *testing.T) {
+	frame := make([]byte, 3000)
+	for i := range frame {
+		frame[i] = byte(i)
+	}
+
+	// sequence header OBU + frame OBU with size fields
+	src := []byte{0x0A, 0x03, 0x00, 0x00, 0x00}
+	src = append(src, 0x32)
+	src = append(src, obu.WriteToLeb128(uint(len(frame)))...)
+	src = append(src, frame...)
+
+	require.Len(t, OBUs(src), 2)
+	require.True(t, IsKeyframe(src))
+	require.False(t, IsKeyframe(src[5:]))
+
+	var dst []byte
+	var packets int
+
+	depay := RTPDepay(func(packet *rtp.Packet) {
+		dst = append(dst, packet.Payload...)
+	})
+	handler := RTPPay(1200, func(packet *rtp.Packet) {
+		packets++
+		depay(packet)
+	})
+
+	handler(&rtp.Packet{Header: rtp.Header{Version: RTPPacketVersionAV1}, Payload: src})
+
+	require.Greater(t, packets, 1)
+	require.Equal(t, src, dst)
+}
+
+func TestTrimTemporalDelimiter(t *testing.T) {
+	b := TrimTemporalDelimiter([]byte{0x12, 0x00, 0x0A, 0x00})
+	require.Equal(t, []byte{0x0A, 0x00}, b)
+}
+
+func TestSequenceHeader(t *testing.T) {
+	w := bits.NewWriter(nil)
+	w.WriteBits8(0, 3)       // seq_profile
+	w.WriteBits8(0, 2)       // still_picture, reduced_still_picture_header
+	w.WriteBits8(0, 2)       // timing_info_present_flag, initial_display_delay_present_flag
+	w.WriteBits8(0, 5)       // operating_points_cnt_minus_1
+	w.WriteBits16(0, 12)     // operating_point_idc
+	w.WriteBits8(8, 5)       // seq_level_idx
+	w.WriteBit(0)            // seq_tier
+	w.WriteBits8(10, 4)      // frame_width_bits_minus_1
+	w.WriteBits8(10, 4)      // frame_height_bits_minus_1
+	w.WriteBits16(1279, 11)  // max_frame_width_minus_1
+	w.WriteBits16(719, 11)   // max_frame_height_minus_1
+	w.WriteBits8(0, 1+3+4)   // frame_id_numbers_present_flag, superblock, filters, compound tools
+	w.WriteBits8(0b100, 3)   // enable_order_hint, enable_jnt_comp, enable_ref_frame_mvs
+	w.WriteBits8(0b11, 2)    // seq_choose_screen_content_tools, seq_choose_integer_mv
+	w.WriteBits8(6, 3)       // order_hint_bits_minus_1
+	w.WriteBits8(0b011, 3)   // enable_superres, enable_cdef, enable_restoration
+	w.WriteBits8(0, 3)       // high_bitdepth, mono_chrome, color_description_present_flag
+	w.WriteBits8(0, 1+2+1+1) // color_range, chroma_sample_position, separate_uv_delta_q, film_grain
+
+	payload := w.Bytes()
+	unit := append([]byte{0x0A}, obu.WriteToLeb128(uint(len(payload)))...)
+	unit = append(unit, payload...)
+
+	// temporal delimiter + sequence header
+	seq := FindSequenceHeader(append([]byte{0x12, 0x00}, unit...))
+	require.NotNil(t, seq)
+	require.Equal(t, uint16(1280), seq.Width)
+	require.Equal(t, uint16(720), seq.Height)
+	require.Equal(t, byte(8), seq.Level)
+	require.True(t, seq.SubsamplingX && seq.SubsamplingY)
+
+	config := seq.EncodeConfig()
+	require.Equal(t, []byte{0x81, 0x08, 0x0C, 0x00}, config[:4])
+	require.Equal(t, unit, config[4:])
+}
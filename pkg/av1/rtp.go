@@ -0,0 +1,74 @@
+package av1
+
+import (
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+)
+
+// RTPPacketVersionAV1 - mark packets with full temporal units inside
+const RTPPacketVersionAV1 = 0
+
+func RTPDepay(handler core.HandlerFunc) core.HandlerFunc {
+	depack := &codecs.AV1Depacketizer{}
+
+	buf := make([]byte, 0, 512*1024) // 512K
+
+	return func(packet *rtp.Packet) {
+		// depacketizer returns OBUs with size fields (low overhead bitstream format)
+		payload, err := depack.Unmarshal(packet.Payload)
+		if err != nil {
+			return
+		}
+
+		// start of new coded video sequence or memory overflow protection
+		if depack.N || len(buf) > 5*1024*1024 {
+			buf = buf[:0]
+		}
+
+		buf = append(buf, payload...)
+
+		if !packet.Marker || len(buf) == 0 {
+			return
+		}
+
+		clone := *packet
+		clone.Version = RTPPacketVersionAV1
+		clone.Payload = buf
+		handler(&clone)
+
+		buf = buf[:0]
+	}
+}
+
+func RTPPay(mtu uint16, handler core.HandlerFunc) core.HandlerFunc {
+	if mtu == 0 {
+		mtu = 1472
+	}
+
+	payloader := &codecs.AV1Payloader{}
+	sequencer := rtp.NewRandomSequencer()
+	mtu -= 12 // rtp.Header size
+
+	return func(packet *rtp.Packet) {
+		if packet.Version != RTPPacketVersionAV1 {
+			handler(packet)
+			return
+		}
+
+		payloads := payloader.Payload(mtu, packet.Payload)
+		last := len(payloads) - 1
+		for i, payload := range payloads {
+			clone := rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         i == last,
+					SequenceNumber: sequencer.NextSequenceNumber(),
+					Timestamp:      packet.Timestamp,
+				},
+				Payload: payload,
+			}
+			handler(&clone)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package av1
+
+import (
+	"github.com/AlexxIT/go2rtc/pkg/bits"
+	"github.com/pion/rtp/codecs/av1/obu"
+)
+
+// SequenceHeader - fields from sequence header OBU, needed for av1C box and video size
+// https://aomediacodec.github.io/av1-spec/#sequence-header-obu-syntax
+type SequenceHeader struct {
+	Profile byte
+	Level   byte
+	Tier    byte
+
+	HighBitdepth         bool
+	TwelveBit            bool
+	Monochrome           bool
+	SubsamplingX         bool
+	SubsamplingY         bool
+	ChromaSamplePosition byte
+
+	Width  uint16
+	Height uint16
+
+	OBU []byte // raw OBU with size field, for configOBUs
+}
+
+// FindSequenceHeader - find and decode sequence header OBU in the frame (temporal unit)
+func FindSequenceHeader(b []byte) *SequenceHeader {
+	for _, unit := range OBUs(b) {
+		if OBUType(unit) == OBUTypeSequenceHeader {
+			return DecodeSequenceHeader(unit)
+		}
+	}
+	return nil
+}
+
+// DecodeSequenceHeader - decode OBU with size field, return nil on error
+func DecodeSequenceHeader(b []byte) *SequenceHeader {
+	header, err := obu.ParseOBUHeader(b)
+	if err != nil || !header.HasSizeField {
+		return nil
+	}
+
+	i := header.Size()
+	size, n, err := obu.ReadLeb128(b[i:])
+	if err != nil || i+int(n)+int(size) > len(b) {
+		return nil
+	}
+
+	i += int(n)
+
+	h := &SequenceHeader{OBU: b[:i+int(size)]}
+
+	r := bits.NewReader(b[i : i+int(size)])
+
+	h.Profile = r.ReadBits8(3)
+	_ = r.ReadBit() // still_picture
+	reduced := r.ReadBit() == 1
+
+	var decoderModel, delayLength byte
+
+	if reduced {
+		h.Level = r.ReadBits8(5)
+	} else {
+		if r.ReadBit() == 1 { // timing_info_present_flag
+			_ = r.ReadBits(32) // num_units_in_display_tick
+			_ = r.ReadBits(32) // time_scale
+			if r.ReadBit() == 1 {
+				readUVLC(r) // num_ticks_per_picture_minus_1
+			}
+
+			decoderModel = r.ReadBit()
+			if decoderModel == 1 {
+				delayLength = r.ReadBits8(5) + 1 // buffer_delay_length_minus_1
+				_ = r.ReadBits(32)               // num_units_in_decoding_tick
+				_ = r.ReadBits8(5)               // buffer_removal_time_length_minus_1
+				_ = r.ReadBits8(5)               // frame_presentation_time_length_minus_1
+			}
+		}
+
+		initialDisplayDelay := r.ReadBit()
+
+		count := r.ReadBits8(5) + 1 // operating_points_cnt_minus_1
+		for op := byte(0); op < count; op++ {
+			_ = r.ReadBits16(12) // operating_point_idc
+			level := r.ReadBits8(5)
+			var tier byte
+			if level > 7 {
+				tier = r.ReadBit()
+			}
+			if op == 0 {
+				h.Level, h.Tier = level, tier
+			}
+			if decoderModel == 1 && r.ReadBit() == 1 {
+				_ = r.ReadBits(delayLength) // decoder_buffer_delay
+				_ = r.ReadBits(delayLength) // encoder_buffer_delay
+				_ = r.ReadBit()             // low_delay_mode_flag
+			}
+			if initialDisplayDelay == 1 && r.ReadBit() == 1 {
+				_ = r.ReadBits8(4) // initial_display_delay_minus_1
+			}
+		}
+	}
+
+	widthBits := r.ReadBits8(4) + 1
+	heightBits := r.ReadBits8(4) + 1
+	h.Width = uint16(r.ReadBits(widthBits) + 1)
+	h.Height = uint16(r.ReadBits(heightBits) + 1)
+
+	if !reduced && r.ReadBit() == 1 { // frame_id_numbers_present_flag
+		_ = r.ReadBits8(4) // delta_frame_id_length_minus_2
+		_ = r.ReadBits8(3) // additional_frame_id_length_minus_1
+	}
+
+	_ = r.ReadBits8(3) // use_128x128_superblock, enable_filter_intra, enable_intra_edge_filter
+
+	if !reduced {
+		_ = r.ReadBits8(4) // enable_interintra_compound, masked_compound, warped_motion, dual_filter
+
+		orderHint := r.ReadBit()
+		if orderHint == 1 {
+			_ = r.ReadBits8(2) // enable_jnt_comp, enable_ref_frame_mvs
+		}
+
+		forceScreenContentTools := byte(2) // SELECT_SCREEN_CONTENT_TOOLS
+		if r.ReadBit() == 0 {              // seq_choose_screen_content_tools
+			forceScreenContentTools = r.ReadBit()
+		}
+		if forceScreenContentTools > 0 && r.ReadBit() == 0 { // seq_choose_integer_mv
+			_ = r.ReadBit() // seq_force_integer_mv
+		}
+
+		if orderHint == 1 {
+			_ = r.ReadBits8(3) // order_hint_bits_minus_1
+		}
+	}
+
+	_ = r.ReadBits8(3) // enable_superres, enable_cdef, enable_restoration
+
+	// color_config
+	h.HighBitdepth = r.ReadBit() == 1
+	if h.Profile == 2 && h.HighBitdepth {
+		h.TwelveBit = r.ReadBit() == 1
+	}
+	if h.Profile != 1 {
+		h.Monochrome = r.ReadBit() == 1
+	}
+
+	primaries, transfer, matrix := byte(2), byte(2), byte(2) // unspecified
+	if r.ReadBit() == 1 {                                    // color_description_present_flag
+		primaries, transfer, matrix = r.ReadByte(), r.ReadByte(), r.ReadByte()
+	}
+
+	switch {
+	case h.Monochrome:
+		h.SubsamplingX, h.SubsamplingY = true, true
+	case primaries == 1 && transfer == 13 && matrix == 0: // sRGB, 4:4:4
+	default:
+		_ = r.ReadBit() // color_range
+		switch h.Profile {
+		case 0:
+			h.SubsamplingX, h.SubsamplingY = true, true
+		case 1:
+		default:
+			if h.TwelveBit {
+				if h.SubsamplingX = r.ReadBit() == 1; h.SubsamplingX {
+					h.SubsamplingY = r.ReadBit() == 1
+				}
+			} else {
+				h.SubsamplingX = true
+			}
+		}
+		if h.SubsamplingX && h.SubsamplingY {
+			h.ChromaSamplePosition = r.ReadBits8(2)
+		}
+	}
+
+	if r.EOF {
+		return nil
+	}
+
+	return h
+}
+
+// EncodeConfig - AV1 Codec Configuration Box (av1C) with the sequence header in configOBUs
+func (h *SequenceHeader) EncodeConfig() []byte {
+	b := byte(h.Tier << 7)
+	if h.HighBitdepth {
+		b |= 0b0100_0000
+	}
+	if h.TwelveBit {
+		b |= 0b0010_0000
+	}
+	if h.Monochrome {
+		b |= 0b0001_0000
+	}
+	if h.SubsamplingX {
+		b |= 0b0000_1000
+	}
+	if h.SubsamplingY {
+		b |= 0b0000_0100
+	}
+	b |= h.ChromaSamplePosition & 0b11
+
+	return append([]byte{0x81, h.Profile<<5 | h.Level&0x1F, b, 0}, h.OBU...)
+}
+
+func readUVLC(r *bits.Reader) uint32 {
+	var leadingZeros byte
+	for r.ReadBit() == 0 && !r.EOF {
+		leadingZeros++
+	}
+	if leadingZeros >= 32 {
+		return 1<<32 - 1
+	}
+	return r.ReadBits(leadingZeros) + (1 << leadingZeros) - 1
+}
@@ -75,6 +75,10 @@ func FFmpegCodecName(name string) string {
 		return "flac"
 	case CodecMP3:
 		return "mp3"
+	case CodecAC3:
+		return "ac3"
+	case CodecEAC3:
+		return "eac3"
 	}
 	return name
 }
@@ -265,6 +269,10 @@ func ParseCodecString(s string) *Codec {
 		codec.Name = CodecPCMU
 	case "aac", "mpeg4-generic":
 		codec.Name = CodecAAC
+	case "eld", "aac/eld":
+		codec.Name = CodecELD
+	case "g722":
+		codec.Name = CodecG722
 	case "opus":
 		codec.Name = CodecOpus
 	case "flac":
@@ -42,6 +42,7 @@ type Connection struct {
 	URL        string `json:"url,omitempty"`
 	SDP        string `json:"sdp,omitempty"`
 	UserAgent  string `json:"user_agent,omitempty"`
+	User       string `json:"user,omitempty"` // authorized username
 
 	Medias    []*Media    `json:"medias,omitempty"`
 	Receivers []*Receiver `json:"receivers,omitempty"`
@@ -122,12 +123,28 @@ func (c *Connection) WithRequest(r *http.Request) {
 	}
 
 	c.UserAgent = r.UserAgent()
+
+	if user, _, ok := r.BasicAuth(); ok {
+		c.User = user
+	}
 }
 
 func (c *Connection) GetSource() string {
 	return c.Source
 }
 
+func (c *Connection) GetRemoteAddr() string {
+	return c.RemoteAddr
+}
+
+func (c *Connection) GetUser() string {
+	return c.User
+}
+
+func (c *Connection) GetSend() int {
+	return c.Send
+}
+
 // Create like os.Create, init Consumer with existing Transport
 func Create(w io.Writer) (*Connection, error) {
 	return &Connection{Transport: w}, nil
@@ -34,6 +34,8 @@ const (
 
 	CodecELD  = "ELD" // AAC-ELD
 	CodecFLAC = "FLAC"
+	CodecAC3  = "AC3"  // AC-3 (Dolby Digital)
+	CodecEAC3 = "EAC3" // E-AC-3 (Dolby Digital Plus)
 
 	CodecAll = "ALL"
 	CodecAny = "ANY"
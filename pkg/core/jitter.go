@@ -0,0 +1,155 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// jitterMaxHold - max time packets wait for the missing one, so the buffer adds
+// no more latency than this on a lost packet and low packet rate (audio)
+const jitterMaxHold = 200 * time.Millisecond
+
+// JitterBuffer - reorder RTP packets by sequence number with buffer of size packets.
+// Late packets (older than the last sent) and duplicates are dropped.
+// Gap is skipped when the buffer is full or after jitterMaxHold.
+// Only for RTP packets, because raw packets from demuxers may have no sequence numbers.
+func JitterBuffer(size int) Filter {
+	return func(handler HandlerFunc) HandlerFunc {
+		var mu sync.Mutex
+		var buf []*Packet // sorted by sequence number
+		var next uint16   // next expected sequence number
+		var started bool
+		var timer *time.Timer
+
+		// send packets in order, skip the gap if the buffer is full or on timeout
+		var flush func(skip bool)
+		flush = func(skip bool) {
+			for len(buf) > 0 && (!started || buf[0].SequenceNumber == next || len(buf) > size || skip) {
+				packet := buf[0]
+				buf = buf[1:]
+
+				next = packet.SequenceNumber + 1
+				started = true
+				skip = false
+
+				handler(packet)
+			}
+
+			// some packets are waiting for the missing one
+			if len(buf) > 0 {
+				if timer == nil {
+					var t *time.Timer
+					t = time.AfterFunc(jitterMaxHold, func() {
+						mu.Lock()
+						if timer == t { // timer wasn't stopped while waiting for the lock
+							timer = nil
+							flush(true)
+						}
+						mu.Unlock()
+					})
+					timer = t
+				}
+			} else if timer != nil {
+				timer.Stop()
+				timer = nil
+			}
+		}
+
+		return func(packet *Packet) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if started && int16(packet.SequenceNumber-next) < 0 {
+				return // late packet
+			}
+
+			// insert sorted, usually to the end of the buffer
+			i := len(buf)
+			for ; i > 0; i-- {
+				diff := int16(packet.SequenceNumber - buf[i-1].SequenceNumber)
+				if diff == 0 {
+					return // duplicate
+				}
+				if diff > 0 {
+					break
+				}
+			}
+			buf = append(buf, nil)
+			copy(buf[i+1:], buf[i:])
+			buf[i] = packet
+
+			flush(false)
+		}
+	}
+}
+
+// TimestampRebase - detect timestamp jumps (more than 1 second back or 3 seconds forward)
+// and shift all following timestamps, so output timestamps stay continuous
+func TimestampRebase(clockRate uint32) Filter {
+	maxBack := -int32(clockRate)
+	maxForward := 3 * int32(clockRate)
+
+	return func(handler HandlerFunc) HandlerFunc {
+		var mu sync.Mutex
+		var last, offset, step uint32
+		var started bool
+
+		return func(packet *Packet) {
+			mu.Lock()
+			if started {
+				if delta := int32(packet.Timestamp - last); delta < maxBack || delta > maxForward {
+					// continue from the last timestamp with the last frame duration
+					offset += last - packet.Timestamp + step
+				} else if delta > 0 {
+					step = uint32(delta)
+				}
+			} else {
+				started = true
+			}
+			last = packet.Timestamp
+			ts := packet.Timestamp + offset
+			mu.Unlock()
+
+			if ts != packet.Timestamp {
+				clone := *packet
+				clone.Timestamp = ts
+				packet = &clone
+			}
+
+			handler(packet)
+		}
+	}
+}
+
+// TimestampWallclock - replace timestamps with the arrival time in the clockRate units.
+// Packets with the same source timestamp (one video frame) get the same timestamp.
+// Absolute time is used, so tracks of the same source stay in sync.
+func TimestampWallclock(clockRate uint32) Filter {
+	return func(handler HandlerFunc) HandlerFunc {
+		var mu sync.Mutex
+		var lastIn, lastOut uint32
+		var started bool
+
+		return func(packet *Packet) {
+			mu.Lock()
+			if !started || packet.Timestamp != lastIn {
+				ts := wallclock(time.Now(), clockRate)
+				if started && int32(ts-lastOut) <= 0 {
+					ts = lastOut + 1 // keep timestamps monotonic
+				}
+				lastIn, lastOut = packet.Timestamp, ts
+				started = true
+			}
+			clone := *packet
+			clone.Timestamp = lastOut
+			mu.Unlock()
+
+			handler(&clone)
+		}
+	}
+}
+
+func wallclock(t time.Time, clockRate uint32) uint32 {
+	sec, nsec := uint64(t.Unix()), uint64(t.Nanosecond())
+	return uint32(sec*uint64(clockRate) + nsec*uint64(clockRate)/1e9)
+}
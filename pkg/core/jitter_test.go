@@ -0,0 +1,63 @@
+package core
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJitterBuffer(t *testing.T) {
+	var seqs []uint16
+	input := JitterBuffer(3)(func(packet *Packet) {
+		seqs = append(seqs, packet.SequenceNumber)
+	})
+
+	// reorder, duplicate, late packet and lost packet (65535+3=2)
+	for _, seq := range []uint16{65534, 0, 65535, 0, 1, 65533, 3, 4, 5, 6} {
+		input(&Packet{Header: rtp.Header{SequenceNumber: seq}})
+	}
+
+	require.Equal(t, []uint16{65534, 65535, 0, 1, 3, 4, 5, 6}, seqs)
+}
+
+func TestJitterBufferTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var seqs []uint16
+	input := JitterBuffer(32)(func(packet *Packet) {
+		mu.Lock()
+		seqs = append(seqs, packet.SequenceNumber)
+		mu.Unlock()
+	})
+
+	// packet 2 is lost, buffer is not full
+	for _, seq := range []uint16{0, 1, 3, 4} {
+		input(&Packet{Header: rtp.Header{SequenceNumber: seq}})
+	}
+
+	mu.Lock()
+	require.Equal(t, []uint16{0, 1}, seqs)
+	mu.Unlock()
+
+	time.Sleep(jitterMaxHold + 50*time.Millisecond)
+
+	mu.Lock()
+	require.Equal(t, []uint16{0, 1, 3, 4}, seqs)
+	mu.Unlock()
+}
+
+func TestTimestampRebase(t *testing.T) {
+	var ts []uint32
+	input := TimestampRebase(90000)(func(packet *Packet) {
+		ts = append(ts, packet.Timestamp)
+	})
+
+	// wrap is OK, jump back and jump forward are rebased
+	for _, v := range []uint32{4294964296, 4294964296, 0, 3000, 4200000000, 4200003000, 900000000, 900003000} {
+		input(&Packet{Header: rtp.Header{Timestamp: v}})
+	}
+
+	require.Equal(t, []uint32{4294964296, 4294964296, 0, 3000, 6000, 9000, 12000, 15000}, ts)
+}
@@ -0,0 +1,47 @@
+package core
+
+// Timestamp model for streams with B-frames:
+// - Packet.Timestamp - always DTS (decode time), grows monotonically in decode order
+// - CTS (composition time offset) - PTS minus DTS, zero for streams without B-frames,
+//   can be negative for files with shifted DTS (ex. MP4 trun version 1)
+// - PTS (presentation time) - DTS plus CTS
+//
+// Exception: RTP packets from the network always have PTS in Timestamp and no CTS.
+// CTS is stored in the SSRC field, which is not used by demuxers, and is marked by the private
+// ExtensionProfile without Extension flag. So it never conflicts with real RTP packets from
+// the network (they have ExtensionProfile only with extensions) and doesn't need allocation.
+
+// ExtensionProfileCTS - private profile for packets with CTS
+const ExtensionProfileCTS = 0x4354 // "CT"
+
+// SetCTS - set composition time offset (in codec clock rate) for the packet.
+// Packets with real RTP extensions can't have CTS.
+func SetCTS(packet *Packet, cts int32) {
+	if packet.Extension {
+		return
+	}
+
+	if cts == 0 {
+		if packet.ExtensionProfile == ExtensionProfileCTS {
+			packet.ExtensionProfile = 0
+			packet.SSRC = 0
+		}
+		return
+	}
+
+	packet.ExtensionProfile = ExtensionProfileCTS
+	packet.SSRC = uint32(cts)
+}
+
+// GetCTS - get composition time offset (in codec clock rate) for the packet
+func GetCTS(packet *Packet) int32 {
+	if packet.Extension || packet.ExtensionProfile != ExtensionProfileCTS {
+		return 0
+	}
+	return int32(packet.SSRC)
+}
+
+// GetPTS - get presentation time for the packet
+func GetPTS(packet *Packet) uint32 {
+	return packet.Timestamp + uint32(GetCTS(packet))
+}
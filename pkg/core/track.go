@@ -3,6 +3,7 @@ package core
 import (
 	"encoding/json"
 	"errors"
+	"sync/atomic"
 
 	"github.com/pion/rtp"
 )
@@ -17,8 +18,9 @@ type Receiver struct {
 	// Deprecated: should be removed
 	ID byte `json:"-"` // Channel for RTSP, PayloadType for MPEG-TS
 
-	Bytes   int `json:"bytes,omitempty"`
-	Packets int `json:"packets,omitempty"`
+	// atomic, because they are read outside the source goroutine (API, watchdog)
+	Bytes   atomic.Int64
+	Packets atomic.Int64
 }
 
 func NewReceiver(media *Media, codec *Codec) *Receiver {
@@ -27,8 +29,8 @@ func NewReceiver(media *Media, codec *Codec) *Receiver {
 		Media: media,
 	}
 	r.Input = func(packet *Packet) {
-		r.Bytes += len(packet.Payload)
-		r.Packets++
+		r.Bytes.Add(int64(len(packet.Payload)))
+		r.Packets.Add(1)
 		for _, child := range r.childs {
 			child.Input(packet)
 		}
@@ -186,8 +188,8 @@ func (r *Receiver) MarshalJSON() ([]byte, error) {
 	}{
 		ID:      r.Node.id,
 		Codec:   r.Node.Codec,
-		Bytes:   r.Bytes,
-		Packets: r.Packets,
+		Bytes:   int(r.Bytes.Load()),
+		Packets: int(r.Packets.Load()),
 	}
 	for _, child := range r.childs {
 		v.Childs = append(v.Childs, child.id)
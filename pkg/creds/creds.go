@@ -9,8 +9,8 @@ import (
 )
 
 type Storage interface {
+	Provider
 	SetValue(name, value string) error
-	GetValue(name string) (string, bool)
 }
 
 var storage Storage
@@ -43,6 +43,10 @@ func getValue(name string) (string, bool) {
 		}
 	}
 
+	if value, ok := getProviderValue(name); ok {
+		return value, true
+	}
+
 	if dir, ok := os.LookupEnv("CREDENTIALS_DIRECTORY"); ok {
 		if value, _ := os.ReadFile(filepath.Join(dir, name)); value != nil {
 			return strings.TrimSpace(string(value)), true
@@ -0,0 +1,79 @@
+package creds
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+	"time"
+)
+
+// NewDotenv - provider for file with `NAME=value` lines
+func NewDotenv(path string) Provider {
+	return &dotenvFile{path: path}
+}
+
+// ParseDotenv - support comments, `export` prefix and quoted values
+func ParseDotenv(data []byte) map[string]string {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+
+		if n := len(value); n >= 2 && (value[0] == '"' || value[0] == '\'') {
+			if i := strings.IndexByte(value[1:], value[0]); i >= 0 {
+				quote := value[0]
+				value = value[1 : i+1]
+				if quote == '"' {
+					value = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value)
+				}
+			}
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+
+		values[name] = value
+	}
+
+	return values
+}
+
+type dotenvFile struct {
+	path string
+
+	cache
+}
+
+func (f *dotenvFile) GetValue(name string) (string, bool) {
+	return f.get(name, f.modTime, f.load)
+}
+
+func (f *dotenvFile) modTime() (time.Time, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+func (f *dotenvFile) load() (map[string]string, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDotenv(data), nil
+}
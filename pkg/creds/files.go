@@ -0,0 +1,112 @@
+package creds
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewSecretsDir - provider for Docker (/run/secrets) and Kubernetes secret mounts,
+// where each file name is a secret name and file content is a value
+func NewSecretsDir(dir string) Provider {
+	return &secretsDir{dir: dir}
+}
+
+type secretsDir struct {
+	dir string
+
+	cache
+}
+
+func (d *secretsDir) GetValue(name string) (string, bool) {
+	return d.get(name, d.modTime, d.load)
+}
+
+// modTime - Kubernetes updates secrets by replacing `..data` symlink, so the folder
+// itself changes, Docker and manual edits change files inside the folder
+func (d *secretsDir) modTime() (time.Time, error) {
+	info, err := os.Stat(d.dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	mtime := info.ModTime()
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, entry := range entries {
+		// os.Stat follows symlinks to real files
+		if info, err = os.Stat(filepath.Join(d.dir, entry.Name())); err == nil {
+			if t := info.ModTime(); t.After(mtime) {
+				mtime = t
+			}
+		}
+	}
+
+	return mtime, nil
+}
+
+func (d *secretsDir) load() (map[string]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		name := entry.Name()
+		// Kubernetes keep real files in `..data` folder and symlinks to them
+		if name[0] == '.' {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(d.dir, name))
+		if err != nil {
+			continue // skip folders and broken symlinks
+		}
+
+		values[name] = strings.TrimSpace(string(data))
+	}
+
+	return values, nil
+}
+
+// cacheCheckInterval - how often file based providers check modification time
+const cacheCheckInterval = time.Second
+
+// cache - values for file based providers. Files are polled by modification time
+// no more than once per cacheCheckInterval and are re-read after change.
+type cache struct {
+	values  map[string]string
+	mtime   time.Time
+	checked time.Time
+	mu      sync.Mutex
+}
+
+func (c *cache) get(
+	name string, modTime func() (time.Time, error), load func() (map[string]string, error),
+) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now := time.Now(); now.Sub(c.checked) >= cacheCheckInterval {
+		c.checked = now
+
+		// keep old values if file is not available (ex. in the middle of update)
+		if mtime, err := modTime(); err == nil && (c.values == nil || !mtime.Equal(c.mtime)) {
+			if values, err := load(); err == nil {
+				c.values = values
+				c.mtime = mtime
+			}
+		}
+	}
+
+	value, ok := c.values[name]
+	return value, ok
+}
@@ -0,0 +1,103 @@
+package creds
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// NewHTTP - provider for HTTP secret store with JSON response. Support Vault KV v1/v2
+// (`{"data":{"data":{"name":"value"}}}`) and plain JSON objects (`{"name":"value"}`).
+// All values from response are cached for ttl (forever if ttl is zero).
+func NewHTTP(url string, header http.Header, ttl time.Duration) Provider {
+	return &httpProvider{
+		url:    url,
+		header: header,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type httpProvider struct {
+	url    string
+	header http.Header
+	ttl    time.Duration
+	client *http.Client
+
+	values map[string]string
+	next   time.Time // time of next request
+	loaded bool
+	mu     sync.Mutex
+}
+
+func (p *httpProvider) GetValue(name string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if now := time.Now(); (!p.loaded || p.ttl > 0) && !now.Before(p.next) {
+		if values, err := p.fetch(); err == nil {
+			p.values = values
+			p.loaded = true
+			p.next = now.Add(p.ttl)
+		} else {
+			// don't spam secret store on errors and keep old values until it recovers
+			p.next = now.Add(30 * time.Second)
+		}
+	}
+
+	value, ok := p.values[name]
+	return value, ok
+}
+
+func (p *httpProvider) fetch() (map[string]string, error) {
+	req, err := http.NewRequest("GET", p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range p.header {
+		req.Header[k] = v
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.New("credentials: " + p.url + ": " + res.Status)
+	}
+
+	var v map[string]any
+	if err = json.NewDecoder(res.Body).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return parseHTTPValues(v), nil
+}
+
+func parseHTTPValues(v map[string]any) map[string]string {
+	// Vault KV v2: data.data, Vault KV v1: data
+	for i := 0; i < 2; i++ {
+		data, ok := v["data"].(map[string]any)
+		if !ok {
+			break
+		}
+		v = data
+	}
+
+	values := make(map[string]string, len(v))
+	for name, value := range v {
+		switch value := value.(type) {
+		case string:
+			values[name] = value
+		case float64, bool:
+			b, _ := json.Marshal(value)
+			values[name] = string(b)
+		}
+	}
+	return values
+}
@@ -0,0 +1,33 @@
+package creds
+
+import (
+	"sync"
+)
+
+// Provider - read-only source of secrets (dotenv file, secrets dir, HTTP secret store).
+// Storage is a writable Provider and is always checked before external providers.
+type Provider interface {
+	GetValue(name string) (string, bool)
+}
+
+var providers []Provider
+var providersMu sync.RWMutex
+
+func AddProvider(p Provider) {
+	providersMu.Lock()
+	providers = append(providers, p)
+	providersMu.Unlock()
+}
+
+func getProviderValue(name string) (string, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	for _, p := range providers {
+		if value, ok := p.GetValue(name); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
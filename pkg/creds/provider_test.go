@@ -0,0 +1,96 @@
+package creds
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	values := ParseDotenv([]byte(`# comment
+CAMERA_USER=admin
+export CAMERA_PASS="pa ss#word"
+RTSP_PASS='single' 
+EMPTY=
+VALUE=123 # comment
+`))
+	require.Equal(t, map[string]string{
+		"CAMERA_USER": "admin",
+		"CAMERA_PASS": "pa ss#word",
+		"RTSP_PASS":   "single",
+		"EMPTY":       "",
+		"VALUE":       "123",
+	}, values)
+}
+
+func TestSecretsDir(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "camera_pass"), []byte("secret\n"), 0600))
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "..data"), 0700))
+
+	p := NewSecretsDir(dir)
+
+	value, ok := p.GetValue("camera_pass")
+	require.True(t, ok)
+	require.Equal(t, "secret", value)
+
+	_, ok = p.GetValue("..data")
+	require.False(t, ok)
+
+	// secret updated
+	mtime := time.Now().Add(time.Minute)
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "camera_pass"), []byte("secret2"), 0600))
+	require.Nil(t, os.Chtimes(filepath.Join(dir, "camera_pass"), mtime, mtime))
+
+	p.(*secretsDir).checked = time.Time{}
+
+	value, _ = p.GetValue("camera_pass")
+	require.Equal(t, "secret2", value)
+}
+
+func TestHTTP(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"CAMERA_PASS":"vault","PORT":554},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+
+	header := http.Header{"X-Vault-Token": {"token"}}
+	p := NewHTTP(server.URL, header, 0)
+
+	value, ok := p.GetValue("CAMERA_PASS")
+	require.True(t, ok)
+	require.Equal(t, "vault", value)
+
+	value, ok = p.GetValue("PORT")
+	require.True(t, ok)
+	require.Equal(t, "554", value)
+
+	_, ok = p.GetValue("metadata")
+	require.False(t, ok)
+
+	// cached
+	require.Equal(t, 1, requests)
+
+	// only values resolved with creds.GetValue are masked
+	require.Equal(t, "port 554", SecretString("port 554"))
+
+	p = NewHTTP(server.URL, header, time.Nanosecond)
+	_, _ = p.GetValue("CAMERA_PASS")
+	time.Sleep(time.Millisecond)
+	_, _ = p.GetValue("CAMERA_PASS")
+
+	// refreshed after ttl
+	require.Equal(t, 3, requests)
+}
@@ -11,9 +11,10 @@ const (
 	TypeBoolean
 	TypeString
 	TypeObject
-	TypeNull      = 5
-	TypeEcmaArray = 8
-	TypeObjectEnd = 9
+	TypeNull        = 5
+	TypeEcmaArray   = 8
+	TypeObjectEnd   = 9
+	TypeStrictArray = 10
 )
 
 // AMF spec: http://download.macromedia.com/pub/labs/amf/amf0_spec_121207.pdf
@@ -63,6 +64,9 @@ func (a *AMF) ReadItem() (any, error) {
 	case TypeEcmaArray:
 		return a.ReadEcmaArray()
 
+	case TypeStrictArray:
+		return a.ReadStrictArray()
+
 	case TypeNull:
 		return nil, nil
 
@@ -144,6 +148,27 @@ func (a *AMF) ReadEcmaArray() (map[string]any, error) {
 	return a.ReadObject()
 }
 
+// ReadStrictArray - ex. fourCcList from Enhanced RTMP connect command
+func (a *AMF) ReadStrictArray() ([]any, error) {
+	if a.pos+4 > len(a.buf) {
+		return nil, ErrRead
+	}
+
+	n := int(binary.BigEndian.Uint32(a.buf[a.pos:]))
+	a.pos += 4
+
+	var items []any
+	for i := 0; i < n; i++ {
+		v, err := a.ReadItem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+
+	return items, nil
+}
+
 func NewWriter() *AMF {
 	return &AMF{}
 }
@@ -265,6 +265,16 @@ func TestNewReader(t *testing.T) {
 				"_result", float64(4), nil, float64(1),
 			},
 		},
+		{
+			name:   "enhanced-rtmp",
+			actual: "020007636f6e6e656374003ff00000000000000300036170700200046c697665000a666f757243634c6973740a000000020200046876633102000461763031000009",
+			expect: []any{
+				"connect", float64(1), map[string]any{
+					"app":        "live",
+					"fourCcList": []any{"hvc1", "av01"},
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
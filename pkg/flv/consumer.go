@@ -1,11 +1,18 @@
 package flv
 
 import (
+	"errors"
 	"io"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+	"github.com/AlexxIT/go2rtc/pkg/vp9"
 	"github.com/pion/rtp"
 )
 
@@ -13,24 +20,27 @@ type Consumer struct {
 	core.Connection
 	wr    *core.WriteBuffer
 	muxer *Muxer
+	mu    sync.Mutex
+
+	keyframe chan struct{}
 }
 
-func NewConsumer() *Consumer {
-	medias := []*core.Media{
-		{
-			Kind:      core.KindVideo,
-			Direction: core.DirectionSendonly,
-			Codecs: []*core.Codec{
-				{Name: core.CodecH264},
+// NewConsumer - H264 and AAC in legacy format by default, other codecs and additional medias
+// of the same kind (multitrack) in Enhanced RTMP format, only if the client supports it
+func NewConsumer(medias ...*core.Media) *Consumer {
+	if medias == nil {
+		medias = []*core.Media{
+			{
+				Kind:      core.KindVideo,
+				Direction: core.DirectionSendonly,
+				Codecs:    []*core.Codec{{Name: core.CodecH264}},
 			},
-		},
-		{
-			Kind:      core.KindAudio,
-			Direction: core.DirectionSendonly,
-			Codecs: []*core.Codec{
-				{Name: core.CodecAAC},
+			{
+				Kind:      core.KindAudio,
+				Direction: core.DirectionSendonly,
+				Codecs:    []*core.Codec{{Name: core.CodecAAC}},
 			},
-		},
+		}
 	}
 	wr := core.NewWriteBuffer(nil)
 	return &Consumer{
@@ -40,41 +50,97 @@ func NewConsumer() *Consumer {
 			Medias:     medias,
 			Transport:  wr,
 		},
-		wr:    wr,
-		muxer: &Muxer{},
+		wr:       wr,
+		muxer:    &Muxer{},
+		keyframe: make(chan struct{}),
 	}
 }
 
+// EnhancedMedias - H264 and AAC with Enhanced RTMP codecs from the client fourCcList,
+// all supported codecs if the list is empty or has "*"
+func EnhancedMedias(fourCcList ...string) []*core.Media {
+	has := func(fourCC string) bool {
+		return len(fourCcList) == 0 || slices.Contains(fourCcList, "*") || slices.Contains(fourCcList, fourCC)
+	}
+
+	video := &core.Media{
+		Kind:      core.KindVideo,
+		Direction: core.DirectionSendonly,
+		Codecs:    []*core.Codec{{Name: core.CodecH264}},
+	}
+	if has(FourCCHEVC) {
+		video.Codecs = append(video.Codecs, &core.Codec{Name: core.CodecH265})
+	}
+	if has(FourCCAV1) {
+		video.Codecs = append(video.Codecs, &core.Codec{Name: core.CodecAV1})
+	}
+	if has(FourCCVP9) {
+		video.Codecs = append(video.Codecs, &core.Codec{Name: core.CodecVP9})
+	}
+
+	audio := &core.Media{
+		Kind:      core.KindAudio,
+		Direction: core.DirectionSendonly,
+		Codecs:    []*core.Codec{{Name: core.CodecAAC}},
+	}
+	if has(FourCCOpus) {
+		audio.Codecs = append(audio.Codecs, &core.Codec{Name: core.CodecOpus})
+	}
+
+	return []*core.Media{video, audio}
+}
+
 func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Receiver) error {
 	sender := core.NewSender(media, track.Codec)
 
-	switch track.Codec.Name {
-	case core.CodecH264:
-		payload := c.muxer.GetPayloader(track.Codec)
+	c.mu.Lock()
+	i := len(c.muxer.codecs)
+	payload := c.muxer.GetPayloader(track.Codec)
+	c.mu.Unlock()
 
-		sender.Handler = func(pkt *rtp.Packet) {
-			b := payload(pkt)
-			if n, err := c.wr.Write(b); err == nil {
-				c.Send += n
-			}
+	if payload == nil {
+		return errors.New("flv: unsupported codec: " + track.Codec.String())
+	}
+
+	sender.Handler = func(pkt *rtp.Packet) {
+		c.mu.Lock()
+		if c.muxer.SetKeyframe(i, pkt.Payload) && !c.muxer.NeedKeyframe() {
+			close(c.keyframe)
 		}
+		b := payload(pkt)
+		c.mu.Unlock()
 
+		if n, err := c.wr.Write(b); err == nil {
+			c.Send += n
+		}
+	}
+
+	switch track.Codec.Name {
+	case core.CodecH264:
 		if track.Codec.IsRTP() {
 			sender.Handler = h264.RTPDepay(track.Codec, sender.Handler)
 		} else {
 			sender.Handler = h264.RepairAVCC(track.Codec, sender.Handler)
 		}
 
-	case core.CodecAAC:
-		payload := c.muxer.GetPayloader(track.Codec)
+	case core.CodecH265:
+		if track.Codec.IsRTP() {
+			sender.Handler = h265.RTPDepay(track.Codec, sender.Handler)
+		} else {
+			sender.Handler = h265.RepairAVCC(track.Codec, sender.Handler)
+		}
+
+	case core.CodecVP9:
+		if track.Codec.IsRTP() {
+			sender.Handler = vp9.RTPDepay(sender.Handler)
+		}
 
-		sender.Handler = func(pkt *rtp.Packet) {
-			b := payload(pkt)
-			if n, err := c.wr.Write(b); err == nil {
-				c.Send += n
-			}
+	case core.CodecAV1:
+		if track.Codec.IsRTP() {
+			sender.Handler = av1.RTPDepay(sender.Handler)
 		}
 
+	case core.CodecAAC:
 		if track.Codec.IsRTP() {
 			sender.Handler = aac.RTPDepay(sender.Handler)
 		}
@@ -85,8 +151,27 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 	return nil
 }
 
+// waitKeyframe - VP9 and AV1 have config only inside keyframe
+func (c *Consumer) waitKeyframe() {
+	c.mu.Lock()
+	wait := c.muxer.NeedKeyframe()
+	c.mu.Unlock()
+
+	if wait {
+		select {
+		case <-c.keyframe:
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
 func (c *Consumer) WriteTo(wr io.Writer) (int64, error) {
+	c.waitKeyframe()
+
+	c.mu.Lock()
 	b := c.muxer.GetInit()
+	c.mu.Unlock()
+
 	if _, err := wr.Write(b); err != nil {
 		return 0, err
 	}
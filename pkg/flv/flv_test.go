@@ -1,8 +1,11 @@
 package flv
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,3 +22,73 @@ func TestTimeToRTP(t *testing.T) {
 		frameN *= 2
 	}
 }
+
+func TestCompositionTime(t *testing.T) {
+	require.Equal(t, int32(80), readSI24([]byte{0, 0, 80}))
+	require.Equal(t, int32(-40), readSI24([]byte{0xFF, 0xFF, 0xD8}))
+
+	codec := &core.Codec{Name: core.CodecH264, ClockRate: 90000}
+	payloader := (&Muxer{}).GetPayloader(codec)
+
+	packet := &rtp.Packet{Header: rtp.Header{Timestamp: 9000}, Payload: []byte{0, 0, 0, 1, 0x41}}
+	core.SetCTS(packet, 7200) // 80ms
+	require.Equal(t, uint32(16200), core.GetPTS(packet))
+
+	b := payloader(packet)
+	require.Equal(t, int32(80), readSI24(b[11+2:]))
+
+	core.SetCTS(packet, -3600) // -40ms
+	require.Equal(t, uint32(5400), core.GetPTS(packet))
+
+	b = payloader(packet)
+	require.Equal(t, int32(-40), readSI24(b[11+2:]))
+
+	// no CTS for packets with real RTP extensions
+	packet = &rtp.Packet{Header: rtp.Header{Timestamp: 9000, Extension: true, ExtensionProfile: core.ExtensionProfileCTS, SSRC: 123}}
+	require.Equal(t, int32(0), core.GetCTS(packet))
+}
+
+func TestEnhancedRTMP(t *testing.T) {
+	muxer := &Muxer{}
+
+	av1Payloader := muxer.GetPayloader(&core.Codec{Name: core.CodecAV1, ClockRate: 90000})
+	opusPayloader := muxer.GetPayloader(&core.Codec{Name: core.CodecOpus, ClockRate: 48000, Channels: 1})
+	aacPayloader := muxer.GetPayloader(&core.Codec{
+		Name: core.CodecAAC, ClockRate: 16000, Channels: 1, FmtpLine: "config=1408",
+	})
+
+	// AV1 config for GetInit is taken from the first keyframe
+	keyframe := []byte{0x0A, 0x03, 0x00, 0x00, 0x00}
+	require.True(t, muxer.NeedKeyframe())
+	require.False(t, muxer.SetKeyframe(0, []byte{0x32, 0x00}))
+	require.True(t, muxer.SetKeyframe(0, keyframe))
+	require.False(t, muxer.SetKeyframe(0, keyframe))
+	require.False(t, muxer.NeedKeyframe())
+
+	b := muxer.GetInit()
+	b = append(b, av1Payloader(&rtp.Packet{Header: rtp.Header{Timestamp: 90000}, Payload: []byte{0x12, 0}})...)
+	b = append(b, opusPayloader(&rtp.Packet{Header: rtp.Header{Timestamp: 48000}, Payload: []byte{0xFC}})...)
+	b = append(b, aacPayloader(&rtp.Packet{Header: rtp.Header{Timestamp: 16000}, Payload: []byte{0x21}})...)
+
+	prod, err := Open(bytes.NewReader(b))
+	require.Nil(t, err)
+	require.Len(t, prod.Medias, 3)
+	require.Equal(t, core.CodecAV1, prod.Medias[0].Codecs[0].Name)
+	require.Equal(t, core.CodecOpus, prod.Medias[1].Codecs[0].Name)
+	require.Equal(t, uint8(1), prod.Medias[1].Codecs[0].Channels) // from OpusHead
+	require.Equal(t, core.CodecAAC, prod.Medias[2].Codecs[0].Name)
+
+	// second audio track in multitrack packet
+	frames := parseFrames(TagAudio, b[len(b)-4-8:len(b)-4]) // 7 bytes header + 1 byte frame
+	require.Len(t, frames, 1)
+	require.Equal(t, byte(1), frames[0].trackID)
+	require.Equal(t, FourCCAAC, frames[0].fourCC)
+	require.Equal(t, []byte{0x21}, frames[0].payload)
+
+	// codecs only from the client fourCcList
+	medias := EnhancedMedias(FourCCHEVC)
+	require.Len(t, medias[0].Codecs, 2)
+	require.Equal(t, core.CodecH265, medias[0].Codecs[1].Name)
+	require.Len(t, medias[1].Codecs, 1)
+	require.Len(t, EnhancedMedias("*")[0].Codecs, 4)
+}
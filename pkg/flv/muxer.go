@@ -4,14 +4,21 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/flv/amf"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+	"github.com/AlexxIT/go2rtc/pkg/mp4"
+	"github.com/AlexxIT/go2rtc/pkg/vp9"
 	"github.com/pion/rtp"
 )
 
 type Muxer struct {
-	codecs []*core.Codec
+	codecs   []*core.Codec
+	trackIDs []byte
+
+	keyframes [][]byte // first keyframe for codecs with config inside bitstream
 }
 
 const (
@@ -30,25 +37,41 @@ func (m *Muxer) GetInit() []byte {
 
 	obj := map[string]any{}
 
-	for _, codec := range m.codecs {
+	for i, codec := range m.codecs {
+		if m.trackIDs[i] != 0 {
+			continue // metadata only for default tracks
+		}
+
 		switch codec.Name {
 		case core.CodecH264:
 			b[4] |= FlagsVideo
 			obj["videocodecid"] = CodecH264
 
+		case core.CodecH265, core.CodecVP9, core.CodecAV1:
+			b[4] |= FlagsVideo
+			obj["videocodecid"] = binary.BigEndian.Uint32([]byte(fourCC(codec)))
+
 		case core.CodecAAC:
 			b[4] |= FlagsAudio
 			obj["audiocodecid"] = CodecAAC
 			obj["audiosamplerate"] = codec.ClockRate
 			obj["audiosamplesize"] = 16
 			obj["stereo"] = codec.Channels == 2
+
+		case core.CodecOpus:
+			b[4] |= FlagsAudio
+			obj["audiocodecid"] = binary.BigEndian.Uint32([]byte(fourCC(codec)))
+			obj["audiosamplerate"] = codec.ClockRate
+			obj["stereo"] = codec.Channels == 2
 		}
 	}
 
 	data := amf.EncodeItems("@setDataFrame", "onMetaData", obj)
 	b = append(b, EncodeTag(TagData, 0, data)...)
 
-	for _, codec := range m.codecs {
+	for i, codec := range m.codecs {
+		var config []byte
+
 		switch codec.Name {
 		case core.CodecH264:
 			sps, pps := h264.GetParameterSet(codec.FmtpLine)
@@ -61,39 +84,105 @@ func (m *Muxer) GetInit() []byte {
 				pps = []byte{0x68, 0xce, 0x38, 0x80}
 			}
 
-			config := h264.EncodeConfig(sps, pps)
-			video := append(encodeAVData(codec, 0), config...)
-			b = append(b, EncodeTag(TagVideo, 0, video)...)
+			config = h264.EncodeConfig(sps, pps)
+
+		case core.CodecH265:
+			vps, sps, pps := h265.GetParameterSet(codec.FmtpLine)
+			config = h265.EncodeConfig(vps, sps, pps)
+
+		case core.CodecVP9:
+			if config = vp9.KeyframeConfig(m.keyframes[i]); config == nil {
+				config = vp9.EncodeConfig(0, 40)
+			}
+
+		case core.CodecAV1:
+			if seq := av1.FindSequenceHeader(m.keyframes[i]); seq != nil {
+				config = seq.EncodeConfig()
+			} else {
+				config = av1.EncodeConfig(8)
+			}
 
 		case core.CodecAAC:
 			s := core.Between(codec.FmtpLine, "config=", ";")
-			config, _ := hex.DecodeString(s)
-			audio := append(encodeAVData(codec, 0), config...)
-			b = append(b, EncodeTag(TagAudio, 0, audio)...)
+			config, _ = hex.DecodeString(s)
+
+		case core.CodecOpus:
+			config = opusHead(codec)
+
+		default:
+			continue
 		}
+
+		header := encodeHeader(codec, m.trackIDs[i], PacketTypeSequenceStart, true)
+		b = append(b, EncodeTag(tagType(codec), 0, append(header, config...))...)
 	}
 
 	return b
 }
 
+// SetKeyframe - save first keyframe for VP9 and AV1, so GetInit can use real config.
+// Return true if it is the first keyframe for the track.
+func (m *Muxer) SetKeyframe(i int, payload []byte) bool {
+	switch name := m.codecs[i].Name; name {
+	case core.CodecVP9, core.CodecAV1:
+		if m.keyframes[i] != nil || !mp4.IsKeyframe(name, payload) {
+			return false
+		}
+		// payload buffer can be reused by the source after the handler
+		m.keyframes[i] = append([]byte(nil), payload...)
+		return true
+	}
+	return false
+}
+
+// NeedKeyframe - GetInit should wait first keyframe for VP9 and AV1 tracks
+func (m *Muxer) NeedKeyframe() bool {
+	for i, codec := range m.codecs {
+		switch codec.Name {
+		case core.CodecVP9, core.CodecAV1:
+			if m.keyframes[i] == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (m *Muxer) GetPayloader(codec *core.Codec) func(packet *rtp.Packet) []byte {
+	if fourCC(codec) == "" {
+		return nil
+	}
+
+	// next tracks of the same kind will be sent as Enhanced RTMP multitrack
+	var trackID byte
+	for _, c := range m.codecs {
+		if c.Kind() == codec.Kind() {
+			trackID++
+		}
+	}
+
 	m.codecs = append(m.codecs, codec)
+	m.trackIDs = append(m.trackIDs, trackID)
+	m.keyframes = append(m.keyframes, nil)
 
 	var ts0 uint32
 	var k = codec.ClockRate / 1000
 
 	switch codec.Name {
-	case core.CodecH264:
-		buf := encodeAVData(codec, 1)
+	case core.CodecH264, core.CodecH265, core.CodecVP9, core.CodecAV1:
+		var buf []byte
 
 		return func(packet *rtp.Packet) []byte {
-			if h264.IsKeyframe(packet.Payload) {
-				buf[0] = 1<<4 | 7
-			} else {
-				buf[0] = 2<<4 | 7
+			keyframe := mp4.IsKeyframe(codec.Name, packet.Payload)
+			buf = append(buf[:0], encodeHeader(codec, trackID, PacketTypeCodedFrames, keyframe)...)
+
+			if codec.Name == core.CodecH264 || codec.Name == core.CodecH265 {
+				// composition time = PTS - DTS in ms (for B-frames)
+				cts := core.GetCTS(packet) / int32(k)
+				buf = append(buf, byte(cts>>16), byte(cts>>8), byte(cts))
 			}
 
-			buf = append(buf[:5], packet.Payload...) // reset buffer to previous place
+			buf = append(buf, packet.Payload...)
 
 			if ts0 == 0 {
 				ts0 = packet.Timestamp
@@ -103,11 +192,12 @@ func (m *Muxer) GetPayloader(codec *core.Codec) func(packet *rtp.Packet) []byte
 			return EncodeTag(TagVideo, timeMS, buf)
 		}
 
-	case core.CodecAAC:
-		buf := encodeAVData(codec, 1)
+	case core.CodecAAC, core.CodecOpus:
+		buf := encodeHeader(codec, trackID, PacketTypeCodedFrames, false)
+		n := len(buf)
 
 		return func(packet *rtp.Packet) []byte {
-			buf = append(buf[:2], packet.Payload...)
+			buf = append(buf[:n], packet.Payload...)
 
 			if ts0 == 0 {
 				ts0 = packet.Timestamp
@@ -140,35 +230,112 @@ func EncodeTag(tagType byte, timeMS uint32, payload []byte) []byte {
 	return b
 }
 
-func encodeAVData(codec *core.Codec, isFrame byte) []byte {
+func tagType(codec *core.Codec) byte {
+	if codec.IsVideo() {
+		return TagVideo
+	}
+	return TagAudio
+}
+
+func fourCC(codec *core.Codec) string {
 	switch codec.Name {
 	case core.CodecH264:
-		return []byte{
-			1<<4 | 7, // keyframe + AVC
-			isFrame,  // 0 - config, 1 - frame
-			0, 0, 0,  // composition time = 0
+		return FourCCAVC
+	case core.CodecH265:
+		return FourCCHEVC
+	case core.CodecVP9:
+		return FourCCVP9
+	case core.CodecAV1:
+		return FourCCAV1
+	case core.CodecAAC:
+		return FourCCAAC
+	case core.CodecOpus:
+		return FourCCOpus
+	}
+	return ""
+}
+
+// encodeHeader - legacy header for default H264 and AAC tracks, Enhanced RTMP header for others,
+// composition time for H264 and H265 frames should be added after the header
+func encodeHeader(codec *core.Codec, trackID, packetType byte, keyframe bool) []byte {
+	switch codec.Name {
+	case core.CodecH264:
+		if trackID == 0 {
+			var b0 byte = 1<<4 | CodecH264 // keyframe + AVC
+			if !keyframe {
+				b0 = 2<<4 | CodecH264
+			}
+			if packetType == PacketTypeSequenceStart {
+				return []byte{b0, PacketTypeAVCHeader, 0, 0, 0} // composition time = 0
+			}
+			return []byte{b0, PacketTypeAVCNALU}
 		}
 
 	case core.CodecAAC:
-		var b0 byte = 10 << 4 // AAC
-
-		switch codec.ClockRate {
-		case 11025:
-			b0 |= 1 << 2
-		case 22050:
-			b0 |= 2 << 2
-		case 44100:
-			b0 |= 3 << 2
+		if trackID == 0 {
+			var b0 byte = CodecAAC << 4
+
+			switch codec.ClockRate {
+			case 11025:
+				b0 |= 1 << 2
+			case 22050:
+				b0 |= 2 << 2
+			case 44100:
+				b0 |= 3 << 2
+			}
+
+			b0 |= 1 << 1 // 16 bits
+
+			if codec.Channels == 2 {
+				b0 |= 1
+			}
+
+			return []byte{b0, packetType} // 0 - config, 1 - frame
 		}
+	}
 
-		b0 |= 1 << 1 // 16 bits
+	var b []byte
 
-		if codec.Channels == 2 {
-			b0 |= 1
+	if codec.IsVideo() {
+		var frameType byte = 1 // keyframe
+		if !keyframe {
+			frameType = 2
+		}
+		if trackID == 0 {
+			b = []byte{0x80 | frameType<<4 | packetType}
+		} else {
+			b = []byte{0x80 | frameType<<4 | PacketTypeMultitrack, MultitrackOneTrack<<4 | packetType}
 		}
+	} else {
+		if trackID == 0 {
+			b = []byte{CodecExHeader<<4 | packetType}
+		} else {
+			b = []byte{CodecExHeader<<4 | AudioPacketTypeMultitrack, MultitrackOneTrack<<4 | packetType}
+		}
+	}
 
-		return []byte{b0, isFrame} // 0 - config, 1 - frame
+	b = append(b, fourCC(codec)...)
+
+	if trackID != 0 {
+		b = append(b, trackID)
 	}
 
-	return nil
+	return b
+}
+
+// opusHead - Opus identification header (RFC 7845, section 5.1)
+func opusHead(codec *core.Codec) []byte {
+	channels := byte(codec.Channels)
+	if channels == 0 {
+		channels = 2
+	}
+	return []byte{
+		'O', 'p', 'u', 's', 'H', 'e', 'a', 'd',
+		1,          // version
+		channels,   // channel count
+		0x38, 0x01, // pre-skip 312 (little-endian)
+		0x80, 0xBB, 0, 0, // input sample rate 48000 (little-endian)
+		0, 0, // output gain
+		0, // channel mapping family
+	}
 }
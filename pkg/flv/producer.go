@@ -18,7 +18,15 @@ type Producer struct {
 	core.Connection
 	rd *core.ReadBuffer
 
-	video, audio *core.Receiver
+	tracks []*track
+}
+
+// track - audio or video track, Enhanced RTMP can have several tracks of each kind
+type track struct {
+	tagType  byte
+	id       byte
+	codec    *core.Codec
+	receiver *core.Receiver
 }
 
 func Open(rd io.Reader) (*Producer, error) {
@@ -43,7 +51,8 @@ const (
 	TagVideo = 9
 	TagData  = 18
 
-	CodecAAC = 10
+	CodecAAC      = 10
+	CodecExHeader = 9 // Enhanced RTMP audio
 
 	CodecH264 = 7
 	CodecHEVC = 12
@@ -62,14 +71,37 @@ const (
 	PacketTypeCodedFramesX
 	PacketTypeMetadata
 	PacketTypeMPEG2TSSequenceStart
+	PacketTypeMultitrack
+	PacketTypeModEx
+)
+
+// Enhanced RTMP v2 audio packet types, same as video for 0, 1 and 7
+const (
+	AudioPacketTypeMultichannelConfig = 4
+	AudioPacketTypeMultitrack         = 5
+)
+
+const (
+	MultitrackOneTrack = iota
+	MultitrackManyTracks
+	MultitrackManyTracksManyCodecs
+)
+
+const (
+	FourCCAVC  = "avc1"
+	FourCCHEVC = "hvc1"
+	FourCCVP9  = "vp09"
+	FourCCAV1  = "av01"
+	FourCCAAC  = "mp4a"
+	FourCCOpus = "Opus"
 )
 
 func (c *Producer) GetTrack(media *core.Media, codec *core.Codec) (*core.Receiver, error) {
 	receiver, _ := c.Connection.GetTrack(media, codec)
-	if media.Kind == core.KindVideo {
-		c.video = receiver
-	} else {
-		c.audio = receiver
+	for _, t := range c.tracks {
+		if t.codec == codec {
+			t.receiver = receiver
+		}
 	}
 	return receiver, nil
 }
@@ -83,48 +115,42 @@ func (c *Producer) Start() error {
 
 		c.Recv += len(pkt.Payload)
 
-		switch pkt.PayloadType {
-		case TagAudio:
-			if c.audio == nil || pkt.Payload[1] == 0 {
+		for _, f := range parseFrames(pkt.PayloadType, pkt.Payload) {
+			if f.packetType != PacketTypeCodedFrames {
 				continue
 			}
 
-			pkt.Timestamp = TimeToRTP(pkt.Timestamp, c.audio.Codec.ClockRate)
-			pkt.Payload = pkt.Payload[2:]
-			c.audio.WriteRTP(pkt)
-
-		case TagVideo:
-			if c.video == nil {
+			t := c.getTrack(pkt.PayloadType, f.trackID)
+			if t == nil || t.receiver == nil {
 				continue
 			}
 
-			if isExHeader(pkt.Payload) {
-				switch packetType := pkt.Payload[0] & 0b1111; packetType {
-				case PacketTypeCodedFrames:
-					// frame type 4b, packet type 4b, fourCC 32b, composition time 24b
-					pkt.Payload = pkt.Payload[8:]
-				case PacketTypeCodedFramesX:
-					// frame type 4b, packet type 4b, fourCC 32b
-					pkt.Payload = pkt.Payload[5:]
-				default:
-					continue
-				}
-			} else {
-				switch pkt.Payload[1] {
-				case PacketTypeAVCNALU:
-					// frame type 4b, codecID 4b, avc packet type 8b, composition time 24b
-					pkt.Payload = pkt.Payload[5:]
-				default:
-					continue
-				}
+			clockRate := t.codec.ClockRate
+
+			packet := &rtp.Packet{
+				Header:  rtp.Header{Timestamp: TimeToRTP(pkt.Timestamp, clockRate)},
+				Payload: f.payload,
+			}
+
+			// FLV timestamp is DTS, composition time is PTS-DTS in ms
+			if f.cts != 0 {
+				core.SetCTS(packet, int32(int64(f.cts)*int64(clockRate)/1000))
 			}
 
-			pkt.Timestamp = TimeToRTP(pkt.Timestamp, c.video.Codec.ClockRate)
-			c.video.WriteRTP(pkt)
+			t.receiver.WriteRTP(packet)
 		}
 	}
 }
 
+func (c *Producer) getTrack(tagType, id byte) *track {
+	for _, t := range c.tracks {
+		if t.tagType == tagType && t.id == id {
+			return t
+		}
+	}
+	return nil
+}
+
 func (c *Producer) probe() error {
 	if err := c.readHeader(); err != nil {
 		return err
@@ -151,7 +177,7 @@ func (c *Producer) probe() error {
 	waitAudio := true
 	timeout := time.Now().Add(time.Second * 5)
 
-	for (waitVideo || waitAudio) && time.Now().Before(timeout) {
+	for time.Now().Before(timeout) {
 		pkt, err := c.readPacket()
 		if err != nil {
 			return err
@@ -159,79 +185,45 @@ func (c *Producer) probe() error {
 
 		//log.Printf("%d %0.20s", pkt.PayloadType, pkt.Payload)
 
-		switch pkt.PayloadType {
-		case TagAudio:
-			if !waitAudio {
-				continue
-			}
-
-			_ = pkt.Payload[1] // bounds
-
-			codecID := pkt.Payload[0] >> 4 // SoundFormat
-			_ = pkt.Payload[0] & 0b1100    // SoundRate
-			_ = pkt.Payload[0] & 0b0010    // SoundSize
-			_ = pkt.Payload[0] & 0b0001    // SoundType
-
-			if codecID != CodecAAC {
-				continue
-			}
+		var header bool
 
-			if pkt.Payload[1] != 0 { // check if header
-				continue
-			}
-
-			codec := aac.ConfigToCodec(pkt.Payload[2:])
-			media := &core.Media{
-				Kind:      core.KindAudio,
-				Direction: core.DirectionRecvonly,
-				Codecs:    []*core.Codec{codec},
-			}
-			c.Medias = append(c.Medias, media)
-			waitAudio = false
-
-		case TagVideo:
-			if !waitVideo {
-				continue
-			}
-
-			var codec *core.Codec
-
-			if isExHeader(pkt.Payload) {
-				if string(pkt.Payload[1:5]) != "hvc1" {
+		switch pkt.PayloadType {
+		case TagAudio, TagVideo:
+			// multitrack can have headers for all tracks in one tag or in several tags
+			for _, f := range parseFrames(pkt.PayloadType, pkt.Payload) {
+				if f.packetType != PacketTypeSequenceStart {
 					continue
 				}
 
-				if packetType := pkt.Payload[0] & 0b1111; packetType != PacketTypeSequenceStart {
+				header = true
+
+				if c.getTrack(pkt.PayloadType, f.trackID) != nil {
 					continue
 				}
 
-				codec = h265.ConfigToCodec(pkt.Payload[5:])
-			} else {
-				_ = pkt.Payload[0] >> 4 // FrameType
-
-				if packetType := pkt.Payload[1]; packetType != PacketTypeAVCHeader { // check if header
+				codec := sequenceToCodec(f.fourCC, f.payload)
+				if codec == nil {
 					continue
 				}
 
-				switch codecID := pkt.Payload[0] & 0b1111; codecID {
-				case CodecH264:
-					codec = h264.ConfigToCodec(pkt.Payload[5:])
-				case CodecHEVC:
-					codec = h265.ConfigToCodec(pkt.Payload[5:])
-				default:
-					continue
+				media := &core.Media{
+					Kind:      codec.Kind(),
+					Direction: core.DirectionRecvonly,
+					Codecs:    []*core.Codec{codec},
 				}
-			}
+				c.Medias = append(c.Medias, media)
+				c.tracks = append(c.tracks, &track{tagType: pkt.PayloadType, id: f.trackID, codec: codec})
 
-			media := &core.Media{
-				Kind:      core.KindVideo,
-				Direction: core.DirectionRecvonly,
-				Codecs:    []*core.Codec{codec},
+				if pkt.PayloadType == TagAudio {
+					waitAudio = false
+				} else {
+					waitVideo = false
+				}
 			}
-			c.Medias = append(c.Medias, media)
-			waitVideo = false
 
 		case TagData:
+			header = true
+
 			if !bytes.Contains(pkt.Payload, []byte("onMetaData")) {
 				continue
 			}
@@ -245,11 +237,38 @@ func (c *Producer) probe() error {
 				waitAudio = false
 			}
 		}
+
+		// stop on first media frame after all headers, it will be read again after reset
+		if !waitVideo && !waitAudio && !header {
+			break
+		}
 	}
 
 	return nil
 }
 
+func sequenceToCodec(fourCC string, config []byte) *core.Codec {
+	switch fourCC {
+	case FourCCAVC:
+		return h264.ConfigToCodec(config)
+	case FourCCHEVC:
+		return h265.ConfigToCodec(config)
+	case FourCCVP9:
+		return &core.Codec{Name: core.CodecVP9, ClockRate: 90000, PayloadType: core.PayloadTypeRAW}
+	case FourCCAV1:
+		return &core.Codec{Name: core.CodecAV1, ClockRate: 90000, PayloadType: core.PayloadTypeRAW}
+	case FourCCAAC:
+		return aac.ConfigToCodec(config)
+	case FourCCOpus:
+		channels := uint8(2)
+		if len(config) >= 10 && string(config[:8]) == "OpusHead" {
+			channels = config[9]
+		}
+		return &core.Codec{Name: core.CodecOpus, ClockRate: 48000, Channels: channels, PayloadType: core.PayloadTypeRAW}
+	}
+	return nil
+}
+
 func (c *Producer) readHeader() error {
 	b := make([]byte, 9)
 	if _, err := io.ReadFull(c.rd, b); err != nil {
@@ -307,6 +326,163 @@ func TimeToRTP(timeMS, clockRate uint32) uint32 {
 	return uint32(uint64(timeMS) * uint64(clockRate) / 1000)
 }
 
-func isExHeader(data []byte) bool {
-	return data[0]&0b1000_0000 != 0
+// readSI24 - read signed 24 bit integer (composition time)
+func readSI24(b []byte) int32 {
+	return int32(uint32(b[0])<<24|uint32(b[1])<<16|uint32(b[2])<<8) >> 8
+}
+
+// frame - coded frame or sequence header for one track from the audio or video tag
+type frame struct {
+	trackID    byte
+	fourCC     string
+	packetType byte // PacketTypeSequenceStart or PacketTypeCodedFrames
+	cts        int32
+	payload    []byte
+}
+
+// parseFrames - parse legacy or Enhanced RTMP v2 audio/video tag
+// https://veovera.org/docs/enhanced/enhanced-rtmp-v2
+func parseFrames(tagType byte, b []byte) []*frame {
+	if len(b) < 2 {
+		return nil
+	}
+
+	if tagType == TagAudio {
+		if b[0]>>4 != CodecExHeader {
+			// legacy: sound format 4b, rate 2b, size 1b, type 1b, aac packet type 8b
+			if b[0]>>4 != CodecAAC {
+				return nil
+			}
+			return []*frame{{fourCC: FourCCAAC, packetType: b[1], payload: b[2:]}}
+		}
+		return parseExFrames(b[0]&0b1111, b[1:], AudioPacketTypeMultitrack, false)
+	}
+
+	if b[0]&0b1000_0000 == 0 {
+		// legacy: frame type 4b, codecID 4b, avc packet type 8b, composition time 24b
+		if len(b) < 5 {
+			return nil
+		}
+
+		var fourCC string
+		switch b[0] & 0b1111 {
+		case CodecH264:
+			fourCC = FourCCAVC
+		case CodecHEVC:
+			fourCC = FourCCHEVC
+		default:
+			return nil
+		}
+
+		return []*frame{{fourCC: fourCC, packetType: b[1], cts: readSI24(b[2:]), payload: b[5:]}}
+	}
+
+	// skip command frames without data
+	if (b[0]>>4)&0b111 == 5 {
+		return nil
+	}
+
+	return parseExFrames(b[0]&0b1111, b[1:], PacketTypeMultitrack, true)
+}
+
+func parseExFrames(packetType byte, b []byte, multitrackType byte, video bool) (frames []*frame) {
+	// modifier extensions (like nanosecond timestamp offset) are skipped
+	for packetType == PacketTypeModEx {
+		if len(b) < 1 {
+			return nil
+		}
+		size := int(b[0]) + 1
+		b = b[1:]
+		if size == 256 {
+			if len(b) < 2 {
+				return nil
+			}
+			size = int(binary.BigEndian.Uint16(b)) + 1
+			b = b[2:]
+		}
+		if len(b) < size+1 {
+			return nil
+		}
+		packetType = b[size] & 0b1111
+		b = b[size+1:]
+	}
+
+	var multitrack bool
+	var fourCC string
+
+	if packetType == multitrackType {
+		if len(b) < 1 {
+			return nil
+		}
+		multitrack = true
+		multitrackType = b[0] >> 4
+		packetType = b[0] & 0b1111
+		b = b[1:]
+	} else {
+		multitrackType = MultitrackOneTrack
+	}
+
+	if multitrackType != MultitrackManyTracksManyCodecs {
+		if len(b) < 4 {
+			return nil
+		}
+		fourCC = string(b[:4])
+		b = b[4:]
+	}
+
+	for len(b) > 0 {
+		f := &frame{fourCC: fourCC, packetType: packetType}
+
+		if multitrackType == MultitrackManyTracksManyCodecs {
+			if len(b) < 4 {
+				return
+			}
+			f.fourCC = string(b[:4])
+			b = b[4:]
+		}
+
+		body := b
+		b = nil
+
+		if multitrack {
+			if len(body) < 1 {
+				return
+			}
+			f.trackID = body[0]
+			body = body[1:]
+
+			if multitrackType != MultitrackOneTrack {
+				if len(body) < 3 {
+					return
+				}
+				size := int(body[0])<<16 | int(body[1])<<8 | int(body[2])
+				if len(body) < 3+size {
+					return
+				}
+				b = body[3+size:]
+				body = body[3 : 3+size]
+			}
+		}
+
+		if video {
+			switch packetType {
+			case PacketTypeCodedFrames:
+				// composition time only for AVC and HEVC
+				if f.fourCC == FourCCAVC || f.fourCC == FourCCHEVC {
+					if len(body) < 3 {
+						continue
+					}
+					f.cts = readSI24(body)
+					body = body[3:]
+				}
+			case PacketTypeCodedFramesX:
+				f.packetType = PacketTypeCodedFrames
+			}
+		}
+
+		f.payload = body
+		frames = append(frames, f)
+	}
+
+	return
 }
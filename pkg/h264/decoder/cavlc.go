@@ -0,0 +1,332 @@
+package decoder
+
+import "errors"
+
+// Tables 9-5, 9-7, 9-8, 9-9 and 9-10 from H.264 spec in compact form.
+// Coeff token index is TotalCoeff*4 + TrailingOnes.
+
+var coeffTokenLen = [4][4 * 17]uint8{
+	{
+		1, 0, 0, 0,
+		6, 2, 0, 0, 8, 6, 3, 0, 9, 8, 7, 5, 10, 9, 8, 6,
+		11, 10, 9, 7, 13, 11, 10, 8, 13, 13, 11, 9, 13, 13, 13, 10,
+		14, 14, 13, 11, 14, 14, 14, 13, 15, 15, 14, 14, 15, 15, 15, 14,
+		16, 15, 15, 15, 16, 16, 16, 15, 16, 16, 16, 16, 16, 16, 16, 16,
+	},
+	{
+		2, 0, 0, 0,
+		6, 2, 0, 0, 6, 5, 3, 0, 7, 6, 6, 4, 8, 6, 6, 4,
+		8, 7, 7, 5, 9, 8, 8, 6, 11, 9, 9, 6, 11, 11, 11, 7,
+		12, 11, 11, 9, 12, 12, 12, 11, 12, 12, 12, 11, 13, 13, 13, 12,
+		13, 13, 13, 13, 13, 14, 13, 13, 14, 14, 14, 13, 14, 14, 14, 14,
+	},
+	{
+		4, 0, 0, 0,
+		6, 4, 0, 0, 6, 5, 4, 0, 6, 5, 5, 4, 7, 5, 5, 4,
+		7, 5, 5, 4, 7, 6, 6, 4, 7, 6, 6, 4, 8, 7, 7, 5,
+		8, 8, 7, 6, 9, 8, 8, 7, 9, 9, 8, 8, 9, 9, 9, 8,
+		10, 9, 9, 9, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10,
+	},
+	{
+		6, 0, 0, 0,
+		6, 6, 0, 0, 6, 6, 6, 0, 6, 6, 6, 6, 6, 6, 6, 6,
+		6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
+		6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
+		6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6,
+	},
+}
+
+var coeffTokenBits = [4][4 * 17]uint8{
+	{
+		1, 0, 0, 0,
+		5, 1, 0, 0, 7, 4, 1, 0, 7, 6, 5, 3, 7, 6, 5, 3,
+		7, 6, 5, 4, 15, 6, 5, 4, 11, 14, 5, 4, 8, 10, 13, 4,
+		15, 14, 9, 4, 11, 10, 13, 12, 15, 14, 9, 12, 11, 10, 13, 8,
+		15, 1, 9, 12, 11, 14, 13, 8, 7, 10, 9, 12, 4, 6, 5, 8,
+	},
+	{
+		3, 0, 0, 0,
+		11, 2, 0, 0, 7, 7, 3, 0, 7, 10, 9, 5, 7, 6, 5, 4,
+		4, 6, 5, 6, 7, 6, 5, 8, 15, 6, 5, 4, 11, 14, 13, 4,
+		15, 10, 9, 4, 11, 14, 13, 12, 8, 10, 9, 8, 15, 14, 13, 12,
+		11, 10, 9, 12, 7, 11, 6, 8, 9, 8, 10, 1, 7, 6, 5, 4,
+	},
+	{
+		15, 0, 0, 0,
+		15, 14, 0, 0, 11, 15, 13, 0, 8, 12, 14, 12, 15, 10, 11, 11,
+		11, 8, 9, 10, 9, 14, 13, 9, 8, 10, 9, 8, 15, 14, 13, 13,
+		11, 14, 10, 12, 15, 10, 13, 12, 11, 14, 9, 12, 8, 10, 13, 8,
+		13, 7, 9, 12, 9, 12, 11, 10, 5, 8, 7, 6, 1, 4, 3, 2,
+	},
+	{
+		3, 0, 0, 0,
+		0, 1, 0, 0, 4, 5, 6, 0, 8, 9, 10, 11, 12, 13, 14, 15,
+		16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
+		32, 33, 34, 35, 36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47,
+		48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 58, 59, 60, 61, 62, 63,
+	},
+}
+
+var chromaDCCoeffTokenLen = [4 * 5]uint8{
+	2, 0, 0, 0,
+	6, 1, 0, 0,
+	6, 6, 3, 0,
+	6, 7, 7, 6,
+	6, 8, 8, 7,
+}
+
+var chromaDCCoeffTokenBits = [4 * 5]uint8{
+	1, 0, 0, 0,
+	7, 1, 0, 0,
+	4, 6, 1, 0,
+	3, 3, 2, 5,
+	2, 3, 2, 0,
+}
+
+var totalZerosLen = [15][16]uint8{
+	{1, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 9},
+	{3, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 6, 6, 6, 6},
+	{4, 3, 3, 3, 4, 4, 3, 3, 4, 5, 5, 6, 5, 6},
+	{5, 3, 4, 4, 3, 3, 3, 4, 3, 4, 5, 5, 5},
+	{4, 4, 4, 3, 3, 3, 3, 3, 4, 5, 4, 5},
+	{6, 5, 3, 3, 3, 3, 3, 3, 4, 3, 6},
+	{6, 5, 3, 3, 3, 2, 3, 4, 3, 6},
+	{6, 4, 5, 3, 2, 2, 3, 3, 6},
+	{6, 6, 4, 2, 2, 3, 2, 5},
+	{5, 5, 3, 2, 2, 2, 4},
+	{4, 4, 3, 3, 1, 3},
+	{4, 4, 2, 1, 3},
+	{3, 3, 1, 2},
+	{2, 2, 1},
+	{1, 1},
+}
+
+var totalZerosBits = [15][16]uint8{
+	{1, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 1},
+	{7, 6, 5, 4, 3, 5, 4, 3, 2, 3, 2, 3, 2, 1, 0},
+	{5, 7, 6, 5, 4, 3, 4, 3, 2, 3, 2, 1, 1, 0},
+	{3, 7, 5, 4, 6, 5, 4, 3, 3, 2, 2, 1, 0},
+	{5, 4, 3, 7, 6, 5, 4, 3, 2, 1, 1, 0},
+	{1, 1, 7, 6, 5, 4, 3, 2, 1, 1, 0},
+	{1, 1, 5, 4, 3, 3, 2, 1, 1, 0},
+	{1, 1, 1, 3, 3, 2, 2, 1, 0},
+	{1, 0, 1, 3, 2, 1, 1, 1},
+	{1, 0, 1, 3, 2, 1, 1},
+	{0, 1, 1, 2, 1, 3},
+	{0, 1, 1, 1, 1},
+	{0, 1, 1, 1},
+	{0, 1, 1},
+	{0, 1},
+}
+
+var chromaDCTotalZerosLen = [3][4]uint8{
+	{1, 2, 3, 3},
+	{1, 2, 2},
+	{1, 1},
+}
+
+var chromaDCTotalZerosBits = [3][4]uint8{
+	{1, 1, 1, 0},
+	{1, 1, 0},
+	{1, 0},
+}
+
+var runBeforeLen = [7][15]uint8{
+	{1, 1},
+	{1, 2, 2},
+	{2, 2, 2, 2},
+	{2, 2, 2, 3, 3},
+	{2, 2, 3, 3, 3, 3},
+	{2, 3, 3, 3, 3, 3, 3},
+	{3, 3, 3, 3, 3, 3, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+}
+
+var runBeforeBits = [7][15]uint8{
+	{1, 0},
+	{1, 1, 0},
+	{3, 2, 1, 0},
+	{3, 2, 1, 1, 0},
+	{3, 2, 3, 2, 1, 0},
+	{3, 0, 1, 3, 2, 5, 4},
+	{7, 6, 5, 4, 3, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+}
+
+// vlc - variable length code table, key is len<<16 | code
+type vlc map[uint32]int
+
+func newVLC(lens, codes []uint8) vlc {
+	v := vlc{}
+	for i, n := range lens {
+		if n != 0 {
+			v[uint32(n)<<16|uint32(codes[i])] = i
+		}
+	}
+	return v
+}
+
+func (v vlc) read(r *reader) (int, error) {
+	var code uint32
+	for n := uint32(1); n <= 16; n++ {
+		code = code<<1 | r.bit()
+		if i, ok := v[n<<16|code]; ok {
+			return i, nil
+		}
+	}
+	return 0, errWrongVLC
+}
+
+var errWrongVLC = errors.New("h264: wrong VLC code")
+
+var (
+	coeffTokenVLC         [4]vlc
+	chromaDCCoeffTokenVLC vlc
+	totalZerosVLC         [15]vlc
+	chromaDCTotalZerosVLC [3]vlc
+	runBeforeVLC          [7]vlc
+)
+
+func init() {
+	for i := range coeffTokenVLC {
+		coeffTokenVLC[i] = newVLC(coeffTokenLen[i][:], coeffTokenBits[i][:])
+	}
+	chromaDCCoeffTokenVLC = newVLC(chromaDCCoeffTokenLen[:], chromaDCCoeffTokenBits[:])
+	for i := range totalZerosVLC {
+		totalZerosVLC[i] = newVLC(totalZerosLen[i][:], totalZerosBits[i][:])
+	}
+	for i := range chromaDCTotalZerosVLC {
+		chromaDCTotalZerosVLC[i] = newVLC(chromaDCTotalZerosLen[i][:], chromaDCTotalZerosBits[i][:])
+	}
+	for i := range runBeforeVLC {
+		runBeforeVLC[i] = newVLC(runBeforeLen[i][:], runBeforeBits[i][:])
+	}
+}
+
+// residualBlock - residual_block_cavlc, coefficients are placed to coeffs[start:] in scan order,
+// nC = -1 for chroma DC. Returns TotalCoeff.
+func residualBlock(r *reader, coeffs []int32, start, maxNumCoeff, nC int) (int, error) {
+	var token int
+	var err error
+
+	switch {
+	case nC < 0:
+		token, err = chromaDCCoeffTokenVLC.read(r)
+	case nC < 2:
+		token, err = coeffTokenVLC[0].read(r)
+	case nC < 4:
+		token, err = coeffTokenVLC[1].read(r)
+	case nC < 8:
+		token, err = coeffTokenVLC[2].read(r)
+	default:
+		token, err = coeffTokenVLC[3].read(r)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	totalCoeff, trailingOnes := token>>2, token&3
+	if totalCoeff == 0 {
+		return 0, nil
+	}
+	if totalCoeff > maxNumCoeff {
+		return 0, errWrongVLC
+	}
+
+	var levels [16]int32
+
+	var suffixLength int
+	if totalCoeff > 10 && trailingOnes < 3 {
+		suffixLength = 1
+	}
+
+	for i := 0; i < totalCoeff; i++ {
+		if i < trailingOnes {
+			levels[i] = 1 - 2*int32(r.bit())
+			continue
+		}
+
+		prefix := 0
+		for r.bit() == 0 {
+			if prefix++; prefix > 32 {
+				return 0, errWrongVLC
+			}
+		}
+
+		code := min(15, prefix) << suffixLength
+		if suffixLength > 0 || prefix >= 14 {
+			size := suffixLength
+			if prefix == 14 && suffixLength == 0 {
+				size = 4
+			} else if prefix >= 15 {
+				size = prefix - 3
+			}
+			code += int(r.bits(size))
+		}
+		if prefix >= 15 && suffixLength == 0 {
+			code += 15
+		}
+		if prefix >= 16 {
+			code += 1<<(prefix-3) - 4096
+		}
+		if i == trailingOnes && trailingOnes < 3 {
+			code += 2
+		}
+
+		if code&1 == 0 {
+			levels[i] = int32(code+2) >> 1
+		} else {
+			levels[i] = int32(-code-1) >> 1
+		}
+
+		if suffixLength == 0 {
+			suffixLength = 1
+		}
+		if abs(levels[i]) > 3<<(suffixLength-1) && suffixLength < 6 {
+			suffixLength++
+		}
+	}
+
+	var zerosLeft int
+	if totalCoeff < maxNumCoeff {
+		if maxNumCoeff == 4 {
+			zerosLeft, err = chromaDCTotalZerosVLC[totalCoeff-1].read(r)
+		} else {
+			zerosLeft, err = totalZerosVLC[totalCoeff-1].read(r)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if totalCoeff+zerosLeft > maxNumCoeff {
+		return 0, errWrongVLC
+	}
+
+	// coefficients are coded in reverse order, from highest frequency
+	pos := totalCoeff + zerosLeft - 1
+	for i := 0; i < totalCoeff; i++ {
+		coeffs[start+pos] = levels[i]
+		if i == totalCoeff-1 {
+			break
+		}
+		run := 0
+		if zerosLeft > 0 {
+			if run, err = runBeforeVLC[min(zerosLeft, 7)-1].read(r); err != nil {
+				return 0, err
+			}
+			if run > zerosLeft {
+				return 0, errWrongVLC
+			}
+			zerosLeft -= run
+		}
+		pos -= run + 1
+	}
+
+	return totalCoeff, nil
+}
+
+func abs(i int32) int32 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
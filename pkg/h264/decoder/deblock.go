@@ -0,0 +1,200 @@
+package decoder
+
+// Table 8-16
+var alphaTable = [52]int{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 4, 5, 6, 7, 8, 9, 10, 12, 13,
+	15, 17, 20, 22, 25, 28, 32, 36, 40, 45, 50, 56, 63, 71, 80, 90, 101, 113, 127, 144, 162, 182, 203, 226, 255, 255,
+}
+
+var betaTable = [52]int{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4,
+	6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13, 14, 14, 15, 15, 16, 16, 17, 17, 18, 18,
+}
+
+// Table 8-17, only bS = 3 column is needed for intra macroblocks
+var tc0Table = [52]int{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 2, 2, 2, 2, 3, 3, 3, 4, 4, 4, 5, 6, 6, 7, 8, 9, 10, 11, 13, 14, 16, 18, 20, 23, 25,
+}
+
+// deblock - deblocking filter process (8.7) for picture with intra macroblocks only,
+// so boundary strength is 4 for macroblock edges and 3 for internal edges
+func (d *decoder) deblock() {
+	cstride := d.stride / 2
+
+	for addr := range d.mbs {
+		mb := &d.mbs[addr]
+		sl := mb.slice
+		if sl.disableDeblocking == 1 {
+			continue
+		}
+
+		mbx, mby := addr%d.width, addr/d.width
+
+		var left, top *macroblock
+		if mbx > 0 {
+			left = &d.mbs[addr-1]
+			if sl.disableDeblocking == 2 && left.slice != sl {
+				left = nil
+			}
+		}
+		if mby > 0 {
+			top = &d.mbs[addr-d.width]
+			if sl.disableDeblocking == 2 && top.slice != sl {
+				top = nil
+			}
+		}
+
+		// luma
+		x0, y0 := mbx*16, mby*16
+		for dir := 0; dir < 2; dir++ {
+			for e := 0; e < 16; e += 4 {
+				qp := mb.qp
+				bS := 3
+				if e == 0 {
+					var p *macroblock
+					if dir == 0 {
+						p = left
+					} else {
+						p = top
+					}
+					if p == nil {
+						continue
+					}
+					qp = (p.qp + mb.qp + 1) >> 1
+					bS = 4
+				}
+
+				if dir == 0 {
+					d.filterEdge(d.y, (y0*d.stride)+x0+e, 1, d.stride, 16, bS, qp, sl, false)
+				} else {
+					d.filterEdge(d.y, (y0+e)*d.stride+x0, d.stride, 1, 16, bS, qp, sl, false)
+				}
+			}
+		}
+
+		// chroma
+		x0, y0 = mbx*8, mby*8
+		for c, plane := range [2][]byte{d.cb, d.cr} {
+			offset := sl.pps.chromaQPOffset[c]
+			for dir := 0; dir < 2; dir++ {
+				for e := 0; e < 8; e += 4 {
+					qp := chromaQP(mb.qp, offset)
+					bS := 3
+					if e == 0 {
+						var p *macroblock
+						if dir == 0 {
+							p = left
+						} else {
+							p = top
+						}
+						if p == nil {
+							continue
+						}
+						qp = (chromaQP(p.qp, offset) + qp + 1) >> 1
+						bS = 4
+					}
+
+					if dir == 0 {
+						d.filterEdge(plane, y0*cstride+x0+e, 1, cstride, 8, bS, qp, sl, true)
+					} else {
+						d.filterEdge(plane, (y0+e)*cstride+x0, cstride, 1, 8, bS, qp, sl, true)
+					}
+				}
+			}
+		}
+	}
+}
+
+// filterEdge - filter n lines across edge, i - first q0 sample, step - from p0 to q0, next - between lines
+func (d *decoder) filterEdge(pix []byte, i, step, next, n, bS, qp int, sl *slice, chroma bool) {
+	indexA := min(max(qp+sl.alphaOffset, 0), 51)
+	indexB := min(max(qp+sl.betaOffset, 0), 51)
+
+	alpha := alphaTable[indexA]
+	beta := betaTable[indexB]
+	if alpha == 0 || beta == 0 {
+		return
+	}
+
+	tc0 := tc0Table[indexA]
+
+	for ; n > 0; n, i = n-1, i+next {
+		p0 := int(pix[i-step])
+		p1 := int(pix[i-2*step])
+		q0 := int(pix[i])
+		q1 := int(pix[i+step])
+
+		if absInt(p0-q0) >= alpha || absInt(p1-p0) >= beta || absInt(q1-q0) >= beta {
+			continue
+		}
+
+		if chroma {
+			if bS == 4 {
+				pix[i-step] = byte((2*p1 + p0 + q1 + 2) >> 2)
+				pix[i] = byte((2*q1 + q0 + p1 + 2) >> 2)
+			} else {
+				tc := tc0 + 1
+				delta := min(max((((q0-p0)<<2)+(p1-q1)+4)>>3, -tc), tc)
+				pix[i-step] = clip(p0 + delta)
+				pix[i] = clip(q0 - delta)
+			}
+			continue
+		}
+
+		p2 := int(pix[i-3*step])
+		q2 := int(pix[i+2*step])
+
+		ap := absInt(p2-p0) < beta
+		aq := absInt(q2-q0) < beta
+
+		if bS == 4 {
+			strong := absInt(p0-q0) < (alpha>>2)+2
+
+			if ap && strong {
+				p3 := int(pix[i-4*step])
+				pix[i-step] = byte((p2 + 2*p1 + 2*p0 + 2*q0 + q1 + 4) >> 3)
+				pix[i-2*step] = byte((p2 + p1 + p0 + q0 + 2) >> 2)
+				pix[i-3*step] = byte((2*p3 + 3*p2 + p1 + p0 + q0 + 4) >> 3)
+			} else {
+				pix[i-step] = byte((2*p1 + p0 + q1 + 2) >> 2)
+			}
+
+			if aq && strong {
+				q3 := int(pix[i+3*step])
+				pix[i] = byte((p1 + 2*p0 + 2*q0 + 2*q1 + q2 + 4) >> 3)
+				pix[i+step] = byte((p0 + q0 + q1 + q2 + 2) >> 2)
+				pix[i+2*step] = byte((2*q3 + 3*q2 + q1 + q0 + p0 + 4) >> 3)
+			} else {
+				pix[i] = byte((2*q1 + q0 + p1 + 2) >> 2)
+			}
+			continue
+		}
+
+		tc := tc0
+		if ap {
+			tc++
+		}
+		if aq {
+			tc++
+		}
+
+		delta := min(max((((q0-p0)<<2)+(p1-q1)+4)>>3, -tc), tc)
+		pix[i-step] = clip(p0 + delta)
+		pix[i] = clip(q0 - delta)
+
+		if ap {
+			pix[i-2*step] = byte(p1 + min(max((p2+((p0+q0+1)>>1)-(p1<<1))>>1, -tc0), tc0))
+		}
+		if aq {
+			pix[i+step] = byte(q1 + min(max((q2+((p0+q0+1)>>1)-(q1<<1))>>1, -tc0), tc0))
+		}
+	}
+}
+
+func absInt(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,194 @@
+// Package decoder - minimal H.264 decoder for intra (key) frames.
+// Supports progressive 8-bit 4:2:0 video with CAVLC entropy coding and 4x4 transform,
+// so only Baseline (Constrained Baseline) profile streams. Most Main/High profile cameras
+// use CABAC, which is not supported. Any unsupported feature returns an error,
+// so caller can fall back to FFmpeg.
+package decoder
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"math"
+)
+
+// Decode - decode first picture from Annex B stream with SPS, PPS and I-slices.
+// Returns full range (JPEG) YCbCr image.
+func Decode(annexb []byte) (*image.YCbCr, error) {
+	d := &decoder{
+		spss: map[uint32]*sps{},
+		ppss: map[uint32]*pps{},
+	}
+
+	for _, nalu := range splitNALU(annexb) {
+		if len(nalu) < 2 {
+			continue
+		}
+
+		rbsp := unescape(nalu[1:])
+
+		switch nalu[0] & 0x1F {
+		case naluTypeSlice, naluTypeIDR:
+			if d.mbs != nil && d.decoded == len(d.mbs) {
+				break // decode only first picture
+			}
+			if err := d.decodeSlice(rbsp, nalu[0]); err != nil {
+				return nil, err
+			}
+
+		case naluTypeSPS:
+			s, err := parseSPS(rbsp)
+			if err != nil {
+				return nil, err
+			}
+			d.spss[s.id] = s
+
+		case naluTypePPS:
+			p, err := parsePPS(rbsp)
+			if err != nil {
+				return nil, err
+			}
+			d.ppss[p.id] = p
+		}
+	}
+
+	if d.mbs == nil {
+		return nil, errors.New("h264: can't find picture")
+	}
+	if d.decoded != len(d.mbs) {
+		return nil, errors.New("h264: incomplete picture")
+	}
+
+	d.deblock()
+
+	if !d.sps.fullRange {
+		expandRange(d.y, d.cb, d.cr)
+	}
+
+	img := &image.YCbCr{
+		Y:              d.y,
+		Cb:             d.cb,
+		Cr:             d.cr,
+		YStride:        d.stride,
+		CStride:        d.stride / 2,
+		SubsampleRatio: image.YCbCrSubsampleRatio420,
+		Rect:           image.Rect(0, 0, d.width*16, d.height*16),
+	}
+
+	rect := image.Rect(
+		d.sps.cropLeft, d.sps.cropTop, d.width*16-d.sps.cropRight, d.height*16-d.sps.cropBottom,
+	)
+	if rect.Empty() {
+		return nil, errors.New("h264: wrong crop")
+	}
+	if rect != img.Rect {
+		img = img.SubImage(rect).(*image.YCbCr)
+	}
+
+	return img, nil
+}
+
+const (
+	naluTypeSlice = 1
+	naluTypeIDR   = 5
+	naluTypeSPS   = 7
+	naluTypePPS   = 8
+)
+
+type decoder struct {
+	spss map[uint32]*sps
+	ppss map[uint32]*pps
+
+	sps *sps
+
+	width  int // in macroblocks
+	height int // in macroblocks
+	stride int // luma stride
+
+	y, cb, cr []byte
+
+	mbs     []macroblock
+	decoded int
+	slices  int
+}
+
+func (d *decoder) init(s *sps) {
+	d.sps = s
+	d.width = s.widthMbs
+	d.height = s.heightMbs
+	d.stride = s.widthMbs * 16
+
+	size := d.stride * s.heightMbs * 16
+	d.y = make([]byte, size)
+	d.cb = make([]byte, size/4)
+	d.cr = make([]byte, size/4)
+	d.mbs = make([]macroblock, s.widthMbs*s.heightMbs)
+}
+
+func splitNALU(b []byte) (nalus [][]byte) {
+	sep := []byte{0, 0, 1}
+
+	i := bytes.Index(b, sep)
+	if i < 0 {
+		return
+	}
+
+	for b = b[i+3:]; ; {
+		if i = bytes.Index(b, sep); i < 0 {
+			return append(nalus, b)
+		}
+
+		// remove zero byte from four bytes start code and trailing zeros
+		nalu := b[:i]
+		for len(nalu) > 0 && nalu[len(nalu)-1] == 0 {
+			nalu = nalu[:len(nalu)-1]
+		}
+		nalus = append(nalus, nalu)
+
+		b = b[i+3:]
+	}
+}
+
+// unescape - remove emulation prevention bytes
+func unescape(b []byte) []byte {
+	i := bytes.Index(b, []byte{0, 0, 3})
+	if i < 0 {
+		return b
+	}
+
+	rbsp := make([]byte, 0, len(b))
+	for i >= 0 {
+		rbsp = append(rbsp, b[:i+2]...)
+		b = b[i+3:]
+		i = bytes.Index(b, []byte{0, 0, 3})
+	}
+	return append(rbsp, b...)
+}
+
+// expandRange - convert limited (TV) range to full (JPEG) range
+func expandRange(y, cb, cr []byte) {
+	var lumaLUT, chromaLUT [256]byte
+	for i := range 256 {
+		lumaLUT[i] = clip(int(math.Round(float64(i-16) * 255 / 219)))
+		chromaLUT[i] = clip(int(math.Round(float64(i-128)*255/224)) + 128)
+	}
+	for i, v := range y {
+		y[i] = lumaLUT[v]
+	}
+	for i, v := range cb {
+		cb[i] = chromaLUT[v]
+	}
+	for i, v := range cr {
+		cr[i] = chromaLUT[v]
+	}
+}
+
+func clip(i int) byte {
+	if i < 0 {
+		return 0
+	}
+	if i > 255 {
+		return 255
+	}
+	return byte(i)
+}
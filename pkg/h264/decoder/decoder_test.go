@@ -0,0 +1,232 @@
+package decoder
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVLC(t *testing.T) {
+	// all tables should be prefix free
+	check := func(lens, codes []uint8) {
+		for i, n1 := range lens {
+			for j, n2 := range lens {
+				if i == j || n1 == 0 || n2 == 0 || n1 > n2 {
+					continue
+				}
+				require.NotEqual(t, codes[i], codes[j]>>(n2-n1), "%v %v", lens, codes)
+			}
+		}
+	}
+
+	for i := range coeffTokenLen {
+		check(coeffTokenLen[i][:], coeffTokenBits[i][:])
+	}
+	check(chromaDCCoeffTokenLen[:], chromaDCCoeffTokenBits[:])
+	for i := range totalZerosLen {
+		check(totalZerosLen[i][:], totalZerosBits[i][:])
+	}
+	for i := range chromaDCTotalZerosLen {
+		check(chromaDCTotalZerosLen[i][:], chromaDCTotalZerosBits[i][:])
+	}
+	for i := range runBeforeLen {
+		check(runBeforeLen[i][:], runBeforeBits[i][:])
+	}
+}
+
+type writer struct {
+	buf []byte
+	n   int
+}
+
+func (w *writer) bits(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		w.buf[len(w.buf)-1] |= byte(v>>i&1) << (7 - w.n%8)
+		w.n++
+	}
+}
+
+func (w *writer) ue(v uint32) {
+	v++
+	n := 0
+	for v>>n > 1 {
+		n++
+	}
+	w.bits(0, n)
+	w.bits(v, n+1)
+}
+
+func (w *writer) nalu(header byte) []byte {
+	w.bits(1, 1) // rbsp_stop_one_bit
+	for w.n%8 != 0 {
+		w.bits(0, 1)
+	}
+	return append([]byte{0, 0, 0, 1, header}, w.buf...)
+}
+
+func TestDecode(t *testing.T) {
+	// 32x32 baseline video with full range flag
+	sps := &writer{}
+	sps.bits(66, 8)
+	sps.bits(0, 8)
+	sps.bits(30, 8)
+	sps.ue(0)      // seq_parameter_set_id
+	sps.ue(0)      // log2_max_frame_num_minus4
+	sps.ue(2)      // pic_order_cnt_type
+	sps.ue(1)      // max_num_ref_frames
+	sps.bits(0, 1) // gaps_in_frame_num_value_allowed_flag
+	sps.ue(1)      // pic_width_in_mbs_minus1
+	sps.ue(1)      // pic_height_in_map_units_minus1
+	sps.bits(1, 1) // frame_mbs_only_flag
+	sps.bits(1, 1) // direct_8x8_inference_flag
+	sps.bits(0, 1) // frame_cropping_flag
+	sps.bits(1, 1) // vui_parameters_present_flag
+	sps.bits(0, 2) // aspect_ratio_info_present_flag, overscan_info_present_flag
+	sps.bits(1, 1) // video_signal_type_present_flag
+	sps.bits(5, 3) // video_format
+	sps.bits(1, 1) // video_full_range_flag
+	sps.bits(0, 7) // other VUI flags
+	pps := &writer{}
+	pps.ue(0)      // pic_parameter_set_id
+	pps.ue(0)      // seq_parameter_set_id
+	pps.bits(0, 2) // entropy_coding_mode_flag, bottom_field_pic_order_in_frame_present_flag
+	pps.ue(0)      // num_slice_groups_minus1
+	pps.ue(0)      // num_ref_idx_l0_default_active_minus1
+	pps.ue(0)      // num_ref_idx_l1_default_active_minus1
+	pps.bits(0, 3) // weighted_pred_flag, weighted_bipred_idc
+	pps.ue(0)      // pic_init_qp_minus26
+	pps.ue(0)      // pic_init_qs_minus26
+	pps.ue(0)      // chroma_qp_index_offset
+	pps.bits(4, 3) // deblocking_filter_control_present_flag and others
+
+	slice := &writer{}
+	slice.ue(0)      // first_mb_in_slice
+	slice.ue(7)      // slice_type
+	slice.ue(0)      // pic_parameter_set_id
+	slice.bits(0, 4) // frame_num
+	slice.ue(0)      // idr_pic_id
+	slice.bits(0, 2) // dec_ref_pic_marking
+	slice.ue(0)      // slice_qp_delta
+	slice.ue(1)      // disable_deblocking_filter_idc
+
+	// macroblock 0: I_PCM
+	slice.ue(25)
+	for slice.n%8 != 0 {
+		slice.bits(0, 1)
+	}
+	for y := uint32(0); y < 16; y++ {
+		for x := uint32(0); x < 16; x++ {
+			slice.bits(16+x*4+y*8, 8)
+		}
+	}
+	for i := uint32(0); i < 64; i++ {
+		slice.bits(100+i%8, 8) // Cb
+	}
+	for i := uint32(0); i < 64; i++ {
+		slice.bits(150+i/8, 8) // Cr
+	}
+
+	// macroblock 1: I_16x16 horizontal prediction, chroma horizontal prediction
+	slice.ue(2)
+	slice.ue(1)
+	slice.ue(0)         // mb_qp_delta
+	slice.bits(0b11, 6) // Intra16x16DCLevel coeff_token for nC >= 8
+
+	// macroblock 2: I_16x16 vertical prediction, chroma vertical prediction
+	slice.ue(1)
+	slice.ue(2)
+	slice.ue(0)
+	slice.bits(0b11, 6)
+
+	// macroblock 3: I_16x16 DC prediction with DC level 10, chroma DC prediction
+	slice.ue(3)
+	slice.ue(0)
+	slice.ue(0)
+	slice.bits(0b101, 6) // coeff_token TotalCoeff=1 TrailingOnes=0 for nC=0
+	slice.bits(1, 15)    // level_prefix=14
+	slice.bits(2, 4)     // level_suffix
+	slice.bits(1, 1)     // total_zeros=0
+
+	var b []byte
+	b = append(b, sps.nalu(0x67)...)
+	b = append(b, pps.nalu(0x68)...)
+	b = append(b, slice.nalu(0x65)...)
+
+	img, err := Decode(b)
+	require.Nil(t, err)
+	require.Equal(t, 32, img.Rect.Dx())
+	require.Equal(t, 32, img.Rect.Dy())
+
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			require.Equal(t, byte(16+x*4+y*8), img.Y[y*img.YStride+x])
+			require.Equal(t, byte(16+15*4+y*8), img.Y[y*img.YStride+16+x])
+			require.Equal(t, byte(16+x*4+15*8), img.Y[(16+y)*img.YStride+x])
+			require.Equal(t, byte(204), img.Y[(16+y)*img.YStride+16+x])
+		}
+	}
+
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			require.Equal(t, byte(100+x), img.Cb[y*img.CStride+x])
+			require.Equal(t, byte(107), img.Cb[y*img.CStride+8+x])
+			require.Equal(t, byte(100+x), img.Cb[(8+y)*img.CStride+x])
+			require.Equal(t, byte(107), img.Cb[(8+y)*img.CStride+8+x])
+
+			require.Equal(t, byte(150+y), img.Cr[y*img.CStride+x])
+			require.Equal(t, byte(150+y), img.Cr[y*img.CStride+8+x])
+			require.Equal(t, byte(157), img.Cr[(8+y)*img.CStride+x])
+			require.Equal(t, byte(157), img.Cr[(8+y)*img.CStride+8+x])
+		}
+	}
+}
+
+func TestDecodeCAVLC(t *testing.T) {
+	// 48x32 keyframe from OpenH264 encoder (Baseline, CAVLC, deblocking),
+	// hash is checked with reference decoder output (converted to full range)
+	s := "000000016742c00c8c68d49a83030303c2211a800000000168ce3c800000000165b8000409fbf7376f775e001602383c" +
+		"355df03d4ab2ef7ac2a851fb08243795ffeef7014510878acdb96231b78370b4d6b4e157e42b3b91d03bb3205a6b1426" +
+		"e5dfef04e2d178fdf1a9ad7314c6b008a775422b461067b79010a82fd927b58460dbe3840c158e790908f559e646ffff" +
+		"784c2a7acc29eabd26e6f8409179c0d28550b4252fb4008b5686adc08a4e537ff7109c20d4251ab30650a25ee21c2e24" +
+		"428a3357863fde1641e16d31094819547f2d03012da8253dab5c704781da02b4200ab53f1f2c90db6203cae6099a3fba" +
+		"f32308bfd5eb78bb8bd2babfe00d413a9917162e31597ffb8008023e3322df84ae60057ffbcc03e52511cfca97fb99ca" +
+		"0069c115978212956fffec08e0ad3a9f16afb63f3fdc02ee36dfc894a5a890ef60019c341a4cc85da11a3d98c4a1d74a" +
+		"49af2283dffbf3844135153a5a8ff26c055c01a097970f5b488f2effdc033982e3eb0b967aebffb80cf100b19bc7154a" +
+		"d47f79054527292dabf62416a7006e104a9cfb1746576b34fed86030c74bbb1dc586fdbc0e8de2a8bb3d8d33c2d390e0" +
+		"48800188026dfefe6a3fdba4776779b0d2f2ef78009eaf7bf9a91d7ba37bb4f066d34ba2ef79fb83d2834fe01864be92" +
+		"265046dcc2557000f8adf46a0a86fee80afffdc084c7e72708b7e475ac2c36c2ee901b7f9fcfb40701536af633dc4c02" +
+		"5ed0194b68a4415da19853ffd6b18ecabef2da97aebdf70318a99eb54e9bdfb6f16054689285ed486add43401087c408" +
+		"9fae790adb3efb400a4a736818d4c61cdc04a43946d874074a328ff7054c162595c1f89a9286f015ab97a002d0d8ce18" +
+		"b680066a605db7f89865e5bfbde01303c0942518b48d6387d007a40e522f0b33137e5728fedf6a42e997287f64bed39f" +
+		"cb370a023a1bff000bb0efb35aa5b8dc6b00ae1f0f30e52b8935995fbd800d4110ac4731e2a0d93f7008c41fee44d5a2" +
+		"11930d7de011a07229248f4af0f451969b20036817022568217ef7001e38b538627bd52737fb8800128250e9f3b9d533" +
+		"8cb832aa918e86cc1418911ffb0c04b8d88c5fc003d3ddf793cc1b87cb4aeee86035a10b45431e9a88d18e5fef024288" +
+		"b58872daadf3557fc10d561f7f5eb24309594fff78f8503357f499f35e9f035622011527a04c6f0be80b000d0400038a" +
+		"39f99a3547039fd2c30b29a8316aa9fa71b2418a165d50a2a09394cee444fc21f27c9cd7c115e0d877cc802a166b95fc" +
+		"7e376780060a754fa13d36a18b09de0eb12822a571ab68972760813e6967917c2bc7bfffef08fb38b350f66cb18fc300" +
+		"51c2fb70d6e0dc7feda08784ee71947b9315fd710031d59c3a16bc67642bfbc01b18888824fa03d384053c6600212c8f" +
+		"89dbaec380ae1b3006a07db29566a702bffb902aa0ad8c21da44388fbc20f867275888434f37a3c8210b7194ebeb79ab" +
+		"fae32004dc724fb4f215b7fe1d4cb4097d1cb00afe8f2572608a9a170bf80040ac61169c52cedcb6b7c00505e52690f4" +
+		"aa508ddf0ca620f1c2f989373df2bc00dd105892dfee04270cfdc10116988947a39868fc7ff6bab82dccb1c001bdfda7" +
+		"086aef3fa00b8408fefbc06428df994d27e7c6c97c00220ea2cbc2ef30371e7837730faaa2b09a42117c0045094bf408" +
+		"d6224195fdffb4431ca216b3173e26847fbf7507790c5e0d27303e9fffbcc000400f5614c27b29772275a004542e6300" +
+		"ea6c7608ffbb4094e0d23a9f85e91935f5"
+	b, err := hex.DecodeString(s)
+	require.Nil(t, err)
+
+	img, err := Decode(b)
+	require.Nil(t, err)
+	require.Equal(t, image.Rect(0, 0, 48, 32), img.Rect)
+
+	h := md5.New()
+	h.Write(img.Y)
+	h.Write(img.Cb)
+	h.Write(img.Cr)
+	require.Equal(t, "3a4a743602483f22badf3f9c443c0e26", hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,157 @@
+package decoder
+
+import "errors"
+
+type sps struct {
+	id uint32
+
+	frameNumBits   int
+	pocType        uint32
+	pocLSBBits     int
+	pocDeltaAlways bool
+
+	widthMbs  int
+	heightMbs int
+
+	cropLeft, cropRight, cropTop, cropBottom int
+
+	fullRange bool
+}
+
+type pps struct {
+	id    uint32
+	spsID uint32
+
+	bottomFieldPOC    bool
+	redundantPicCnt   bool
+	deblockingControl bool
+	transform8x8      bool
+	picInitQP         int
+	chromaQPOffset    [2]int
+}
+
+func parseSPS(rbsp []byte) (*sps, error) {
+	r := newReader(rbsp)
+
+	profile := r.bits(8)
+	_ = r.bits(16) // constraint flags and level
+
+	s := &sps{id: r.ue()}
+
+	switch profile {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		if r.ue() != 1 {
+			return nil, errors.New("h264: unsupported chroma format")
+		}
+		if r.ue() != 0 || r.ue() != 0 {
+			return nil, errors.New("h264: unsupported bit depth")
+		}
+		if r.flag() {
+			return nil, errors.New("h264: unsupported transform bypass")
+		}
+		if r.flag() {
+			return nil, errors.New("h264: unsupported scaling matrix")
+		}
+	}
+
+	s.frameNumBits = int(r.ue()) + 4
+	s.pocType = r.ue()
+
+	switch s.pocType {
+	case 0:
+		s.pocLSBBits = int(r.ue()) + 4
+	case 1:
+		s.pocDeltaAlways = r.flag()
+		_ = r.se() // offset_for_non_ref_pic
+		_ = r.se() // offset_for_top_to_bottom_field
+		for n := r.ue(); n > 0; n-- {
+			_ = r.se()
+		}
+	}
+
+	_ = r.ue()   // max_num_ref_frames
+	_ = r.flag() // gaps_in_frame_num_value_allowed_flag
+
+	s.widthMbs = int(r.ue()) + 1
+	s.heightMbs = int(r.ue()) + 1
+
+	if !r.flag() {
+		return nil, errors.New("h264: unsupported interlaced video")
+	}
+
+	_ = r.flag() // direct_8x8_inference_flag
+
+	if r.flag() {
+		// crop units for 4:2:0 progressive video
+		s.cropLeft = 2 * int(r.ue())
+		s.cropRight = 2 * int(r.ue())
+		s.cropTop = 2 * int(r.ue())
+		s.cropBottom = 2 * int(r.ue())
+	}
+
+	if r.flag() {
+		// vui_parameters
+		if r.flag() {
+			if r.bits(8) == 255 {
+				_ = r.bits(32) // sar_width and sar_height
+			}
+		}
+		if r.flag() {
+			_ = r.flag() // overscan_appropriate_flag
+		}
+		if r.flag() {
+			_ = r.bits(3) // video_format
+			s.fullRange = r.flag()
+		}
+	}
+
+	if r.overrun() || s.widthMbs > 512 || s.heightMbs > 512 {
+		return nil, errors.New("h264: wrong SPS")
+	}
+
+	return s, nil
+}
+
+func parsePPS(rbsp []byte) (*pps, error) {
+	r := newReader(rbsp)
+
+	p := &pps{id: r.ue(), spsID: r.ue()}
+
+	if r.flag() {
+		return nil, errors.New("h264: unsupported CABAC")
+	}
+
+	p.bottomFieldPOC = r.flag()
+
+	if r.ue() != 0 {
+		return nil, errors.New("h264: unsupported slice groups")
+	}
+
+	_ = r.ue()    // num_ref_idx_l0_default_active_minus1
+	_ = r.ue()    // num_ref_idx_l1_default_active_minus1
+	_ = r.bits(3) // weighted_pred_flag and weighted_bipred_idc
+
+	p.picInitQP = 26 + int(r.se())
+	_ = r.se() // pic_init_qs_minus26
+
+	p.chromaQPOffset[0] = int(r.se())
+	p.chromaQPOffset[1] = p.chromaQPOffset[0]
+
+	p.deblockingControl = r.flag()
+	_ = r.flag() // constrained_intra_pred_flag
+	p.redundantPicCnt = r.flag()
+
+	if r.moreData() {
+		p.transform8x8 = r.flag()
+		if r.flag() {
+			return nil, errors.New("h264: unsupported scaling matrix")
+		}
+		p.chromaQPOffset[1] = int(r.se())
+	}
+
+	if r.overrun() {
+		return nil, errors.New("h264: wrong PPS")
+	}
+
+	return p, nil
+}
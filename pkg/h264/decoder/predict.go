@@ -0,0 +1,416 @@
+package decoder
+
+import "errors"
+
+var errPrediction = errors.New("h264: wrong intra prediction")
+
+// predict4x4 - Intra_4x4 prediction (8.3.1.2)
+func (d *decoder) predict4x4(mode uint8, mb *macroblock, mbx, mby, bx, by int) error {
+	x0, y0 := mbx*16+bx*4, mby*16+by*4
+
+	availLeft := bx > 0 || d.available(mb, mbx-1, mby)
+	availTop := by > 0 || d.available(mb, mbx, mby-1)
+
+	var availTopLeft bool
+	switch {
+	case bx > 0 && by > 0:
+		availTopLeft = true
+	case bx > 0:
+		availTopLeft = d.available(mb, mbx, mby-1)
+	case by > 0:
+		availTopLeft = d.available(mb, mbx-1, mby)
+	default:
+		availTopLeft = d.available(mb, mbx-1, mby-1)
+	}
+
+	var availTopRight bool
+	switch {
+	case by == 0 && bx < 3:
+		availTopRight = d.available(mb, mbx, mby-1)
+	case by == 0:
+		availTopRight = d.available(mb, mbx+1, mby-1)
+	case bx < 3:
+		// block is already decoded if it has lower index
+		availTopRight = blockIndex(bx+1, by-1) < blockIndex(bx, by)
+	}
+
+	// p[-1,-1], p[0..7,-1] and p[-1,0..3]
+	var q int
+	var t [8]int
+	var l [4]int
+
+	if availTop {
+		i := (y0-1)*d.stride + x0
+		for x := 0; x < 4; x++ {
+			t[x] = int(d.y[i+x])
+		}
+		if availTopRight {
+			for x := 4; x < 8; x++ {
+				t[x] = int(d.y[i+x])
+			}
+		} else {
+			t[4], t[5], t[6], t[7] = t[3], t[3], t[3], t[3]
+		}
+	}
+	if availLeft {
+		for y := 0; y < 4; y++ {
+			l[y] = int(d.y[(y0+y)*d.stride+x0-1])
+		}
+	}
+	if availTopLeft {
+		q = int(d.y[(y0-1)*d.stride+x0-1])
+	}
+
+	// p - neighbour sample p[x,y] with x = -1 or y = -1
+	p := func(x, y int) int {
+		switch {
+		case x < 0 && y < 0:
+			return q
+		case y < 0:
+			return t[x]
+		}
+		return l[y]
+	}
+
+	var pred [4][4]int
+
+	switch mode {
+	case 0: // Vertical
+		if !availTop {
+			return errPrediction
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				pred[y][x] = t[x]
+			}
+		}
+
+	case 1: // Horizontal
+		if !availLeft {
+			return errPrediction
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				pred[y][x] = l[y]
+			}
+		}
+
+	case 2: // DC
+		var dc int
+		switch {
+		case availTop && availLeft:
+			dc = (t[0] + t[1] + t[2] + t[3] + l[0] + l[1] + l[2] + l[3] + 4) >> 3
+		case availLeft:
+			dc = (l[0] + l[1] + l[2] + l[3] + 2) >> 2
+		case availTop:
+			dc = (t[0] + t[1] + t[2] + t[3] + 2) >> 2
+		default:
+			dc = 128
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				pred[y][x] = dc
+			}
+		}
+
+	case 3: // Diagonal_Down_Left
+		if !availTop {
+			return errPrediction
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				if x == 3 && y == 3 {
+					pred[y][x] = (t[6] + 3*t[7] + 2) >> 2
+				} else {
+					pred[y][x] = (t[x+y] + 2*t[x+y+1] + t[x+y+2] + 2) >> 2
+				}
+			}
+		}
+
+	case 4: // Diagonal_Down_Right
+		if !availTop || !availLeft || !availTopLeft {
+			return errPrediction
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				switch {
+				case x > y:
+					pred[y][x] = (p(x-y-2, -1) + 2*p(x-y-1, -1) + p(x-y, -1) + 2) >> 2
+				case x < y:
+					pred[y][x] = (p(-1, y-x-2) + 2*p(-1, y-x-1) + p(-1, y-x) + 2) >> 2
+				default:
+					pred[y][x] = (t[0] + 2*q + l[0] + 2) >> 2
+				}
+			}
+		}
+
+	case 5: // Vertical_Right
+		if !availTop || !availLeft || !availTopLeft {
+			return errPrediction
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				switch z := 2*x - y; {
+				case z >= 0 && z&1 == 0:
+					pred[y][x] = (p(x-y>>1-1, -1) + p(x-y>>1, -1) + 1) >> 1
+				case z >= 0:
+					pred[y][x] = (p(x-y>>1-2, -1) + 2*p(x-y>>1-1, -1) + p(x-y>>1, -1) + 2) >> 2
+				case z == -1:
+					pred[y][x] = (l[0] + 2*q + t[0] + 2) >> 2
+				default:
+					pred[y][x] = (p(-1, y-1) + 2*p(-1, y-2) + p(-1, y-3) + 2) >> 2
+				}
+			}
+		}
+
+	case 6: // Horizontal_Down
+		if !availTop || !availLeft || !availTopLeft {
+			return errPrediction
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				switch z := 2*y - x; {
+				case z >= 0 && z&1 == 0:
+					pred[y][x] = (p(-1, y-x>>1-1) + p(-1, y-x>>1) + 1) >> 1
+				case z >= 0:
+					pred[y][x] = (p(-1, y-x>>1-2) + 2*p(-1, y-x>>1-1) + p(-1, y-x>>1) + 2) >> 2
+				case z == -1:
+					pred[y][x] = (l[0] + 2*q + t[0] + 2) >> 2
+				default:
+					pred[y][x] = (p(x-1, -1) + 2*p(x-2, -1) + p(x-3, -1) + 2) >> 2
+				}
+			}
+		}
+
+	case 7: // Vertical_Left
+		if !availTop {
+			return errPrediction
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				i := x + y>>1
+				if y&1 == 0 {
+					pred[y][x] = (t[i] + t[i+1] + 1) >> 1
+				} else {
+					pred[y][x] = (t[i] + 2*t[i+1] + t[i+2] + 2) >> 2
+				}
+			}
+		}
+
+	case 8: // Horizontal_Up
+		if !availLeft {
+			return errPrediction
+		}
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				i := y + x>>1
+				switch z := x + 2*y; {
+				case z > 5:
+					pred[y][x] = l[3]
+				case z == 5:
+					pred[y][x] = (l[2] + 3*l[3] + 2) >> 2
+				case z&1 == 0:
+					pred[y][x] = (l[i] + l[i+1] + 1) >> 1
+				default:
+					pred[y][x] = (l[i] + 2*l[i+1] + l[i+2] + 2) >> 2
+				}
+			}
+		}
+
+	default:
+		return errPrediction
+	}
+
+	for y := 0; y < 4; y++ {
+		i := (y0+y)*d.stride + x0
+		for x := 0; x < 4; x++ {
+			d.y[i+x] = byte(pred[y][x])
+		}
+	}
+
+	return nil
+}
+
+// blockIndex - luma4x4BlkIdx by position of 4x4 block inside macroblock
+func blockIndex(x, y int) int {
+	return y/2*8 + x/2*4 + y%2*2 + x%2
+}
+
+// predict16x16 - Intra_16x16 prediction (8.3.3)
+func (d *decoder) predict16x16(mode int, mb *macroblock, mbx, mby int) error {
+	return d.predictPlane(d.y, d.stride, 16, mode, mb, mbx, mby)
+}
+
+// predictChroma - chroma prediction (8.3.4), modes are converted to Intra_16x16 order
+func (d *decoder) predictChroma(mode int, plane []byte, mb *macroblock, mbx, mby int) error {
+	switch mode {
+	case 0:
+		mode = 2 // DC
+	case 1:
+		mode = 1 // Horizontal
+	case 2:
+		mode = 0 // Vertical
+	}
+	return d.predictPlane(plane, d.stride/2, 8, mode, mb, mbx, mby)
+}
+
+// predictPlane - Intra_16x16 luma or 8x8 chroma prediction,
+// modes: 0 - Vertical, 1 - Horizontal, 2 - DC, 3 - Plane
+func (d *decoder) predictPlane(plane []byte, stride, size, mode int, mb *macroblock, mbx, mby int) error {
+	x0, y0 := mbx*size, mby*size
+
+	availLeft := d.available(mb, mbx-1, mby)
+	availTop := d.available(mb, mbx, mby-1)
+	availTopLeft := d.available(mb, mbx-1, mby-1)
+
+	var t, l [16]int
+	var q int
+
+	if availTop {
+		i := (y0-1)*stride + x0
+		for x := 0; x < size; x++ {
+			t[x] = int(plane[i+x])
+		}
+	}
+	if availLeft {
+		for y := 0; y < size; y++ {
+			l[y] = int(plane[(y0+y)*stride+x0-1])
+		}
+	}
+	if availTopLeft {
+		q = int(plane[(y0-1)*stride+x0-1])
+	}
+
+	set := func(x, y, v int) {
+		plane[(y0+y)*stride+x0+x] = clip(v)
+	}
+
+	switch mode {
+	case 0: // Vertical
+		if !availTop {
+			return errPrediction
+		}
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				set(x, y, t[x])
+			}
+		}
+
+	case 1: // Horizontal
+		if !availLeft {
+			return errPrediction
+		}
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				set(x, y, l[y])
+			}
+		}
+
+	case 2: // DC
+		if size == 16 {
+			dc := dcValue(t[:16], l[:16], availTop, availLeft)
+			for y := 0; y < 16; y++ {
+				for x := 0; x < 16; x++ {
+					set(x, y, dc)
+				}
+			}
+			break
+		}
+
+		// chroma DC is calculated for each 4x4 block (8.3.4.1-3)
+		for by := 0; by < 8; by += 4 {
+			for bx := 0; bx < 8; bx += 4 {
+				top, left := availTop, availLeft
+				if bx > 0 && by == 0 {
+					// prefer top samples
+					if top {
+						left = false
+					}
+				} else if bx == 0 && by > 0 {
+					// prefer left samples
+					if left {
+						top = false
+					}
+				}
+
+				dc := dcValue(t[bx:bx+4], l[by:by+4], top, left)
+				for y := by; y < by+4; y++ {
+					for x := bx; x < bx+4; x++ {
+						set(x, y, dc)
+					}
+				}
+			}
+		}
+
+	case 3: // Plane
+		if !availTop || !availLeft || !availTopLeft {
+			return errPrediction
+		}
+
+		// p[-1,-1] for index -1
+		top := func(i int) int {
+			if i < 0 {
+				return q
+			}
+			return t[i]
+		}
+		left := func(i int) int {
+			if i < 0 {
+				return q
+			}
+			return l[i]
+		}
+
+		half := size / 2
+
+		var h, v int
+		for i := 0; i < half; i++ {
+			h += (i + 1) * (top(half+i) - top(half-2-i))
+			v += (i + 1) * (left(half+i) - left(half-2-i))
+		}
+
+		var b, c int
+		if size == 16 {
+			b = (5*h + 32) >> 6
+			c = (5*v + 32) >> 6
+		} else {
+			b = (34*h + 32) >> 6
+			c = (34*v + 32) >> 6
+		}
+
+		a := 16 * (l[size-1] + t[size-1])
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				set(x, y, (a+b*(x-half+1)+c*(y-half+1)+16)>>5)
+			}
+		}
+
+	default:
+		return errPrediction
+	}
+
+	return nil
+}
+
+func dcValue(t, l []int, availTop, availLeft bool) int {
+	var sum int
+	var n int
+
+	if availTop {
+		for _, v := range t {
+			sum += v
+		}
+		n += len(t)
+	}
+	if availLeft {
+		for _, v := range l {
+			sum += v
+		}
+		n += len(l)
+	}
+
+	if n == 0 {
+		return 128
+	}
+	return (sum + n/2) / n
+}
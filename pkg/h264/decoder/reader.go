@@ -0,0 +1,79 @@
+package decoder
+
+// reader - RBSP bit reader with more_rbsp_data support
+type reader struct {
+	buf  []byte
+	pos  int // current bit position
+	stop int // position of rbsp_stop_one_bit
+}
+
+func newReader(rbsp []byte) *reader {
+	r := &reader{buf: rbsp, stop: len(rbsp) * 8}
+
+	// search rbsp_stop_one_bit, skipping trailing zero bytes
+	for i := len(rbsp) - 1; i >= 0; i-- {
+		if b := rbsp[i]; b != 0 {
+			n := 0
+			for b&1 == 0 {
+				b >>= 1
+				n++
+			}
+			r.stop = i*8 + 7 - n
+			break
+		}
+	}
+
+	return r
+}
+
+func (r *reader) bit() uint32 {
+	i, n := r.pos>>3, 7-r.pos&7
+	r.pos++
+	if i >= len(r.buf) {
+		return 0
+	}
+	return uint32(r.buf[i]>>n) & 1
+}
+
+func (r *reader) bits(n int) (v uint32) {
+	for ; n > 0; n-- {
+		v = v<<1 | r.bit()
+	}
+	return
+}
+
+func (r *reader) flag() bool {
+	return r.bit() == 1
+}
+
+// ue - unsigned Exp-Golomb code
+func (r *reader) ue() uint32 {
+	n := 0
+	for r.bit() == 0 {
+		if n++; n == 32 {
+			return 0
+		}
+	}
+	return 1<<n - 1 + r.bits(n)
+}
+
+// se - signed Exp-Golomb code
+func (r *reader) se() int32 {
+	if v := r.ue(); v&1 == 0 {
+		return -int32(v >> 1)
+	} else {
+		return int32(v+1) >> 1
+	}
+}
+
+func (r *reader) align() {
+	r.pos = (r.pos + 7) &^ 7
+}
+
+func (r *reader) moreData() bool {
+	return r.pos < r.stop
+}
+
+func (r *reader) overrun() bool {
+	return r.pos > len(r.buf)*8
+}
@@ -0,0 +1,466 @@
+package decoder
+
+import "errors"
+
+type slice struct {
+	pps *pps
+	qp  int
+
+	disableDeblocking uint32
+	alphaOffset       int
+	betaOffset        int
+}
+
+const (
+	mbTypeI4x4 = iota
+	mbTypeI16x16
+	mbTypePCM
+)
+
+type macroblock struct {
+	slice *slice // nil for not decoded macroblock
+	typ   uint8
+	qp    int // zero for I_PCM
+
+	nz  [16]uint8   // luma TotalCoeff in raster order of 4x4 blocks
+	nzc [2][4]uint8 // chroma AC TotalCoeff
+
+	modes [16]uint8 // Intra4x4PredMode in raster order of 4x4 blocks
+}
+
+func (d *decoder) decodeSlice(rbsp []byte, header byte) error {
+	r := newReader(rbsp)
+
+	firstMB := int(r.ue())
+
+	if sliceType := r.ue() % 5; sliceType != 2 {
+		return errors.New("h264: unsupported slice type")
+	}
+
+	p := d.ppss[r.ue()]
+	if p == nil {
+		return errors.New("h264: can't find PPS")
+	}
+
+	s := d.spss[p.spsID]
+	if s == nil {
+		return errors.New("h264: can't find SPS")
+	}
+
+	if d.mbs == nil {
+		d.init(s)
+	} else if d.sps != s {
+		return errors.New("h264: SPS changed")
+	}
+
+	_ = r.bits(s.frameNumBits) // frame_num
+
+	idr := header&0x1F == naluTypeIDR
+	if idr {
+		_ = r.ue() // idr_pic_id
+	}
+
+	switch s.pocType {
+	case 0:
+		_ = r.bits(s.pocLSBBits) // pic_order_cnt_lsb
+		if p.bottomFieldPOC {
+			_ = r.se() // delta_pic_order_cnt_bottom
+		}
+	case 1:
+		if !s.pocDeltaAlways {
+			_ = r.se() // delta_pic_order_cnt[0]
+			if p.bottomFieldPOC {
+				_ = r.se() // delta_pic_order_cnt[1]
+			}
+		}
+	}
+
+	if p.redundantPicCnt {
+		if r.ue() != 0 {
+			return errors.New("h264: unsupported redundant picture")
+		}
+	}
+
+	// dec_ref_pic_marking
+	if header&0x60 != 0 {
+		if idr {
+			_ = r.bits(2) // no_output_of_prior_pics_flag and long_term_reference_flag
+		} else if r.flag() {
+			for {
+				op := r.ue()
+				if op == 0 {
+					break
+				}
+				if op == 1 || op == 3 {
+					_ = r.ue() // difference_of_pic_nums_minus1
+				}
+				if op == 2 {
+					_ = r.ue() // long_term_pic_num
+				}
+				if op == 3 || op == 6 {
+					_ = r.ue() // long_term_frame_idx
+				}
+				if op == 4 {
+					_ = r.ue() // max_long_term_frame_idx_plus1
+				}
+				if r.overrun() {
+					return errors.New("h264: wrong slice header")
+				}
+			}
+		}
+	}
+
+	sl := &slice{pps: p, qp: p.picInitQP + int(r.se())}
+	if sl.qp < 0 || sl.qp > 51 {
+		return errors.New("h264: wrong slice QP")
+	}
+
+	if p.deblockingControl {
+		sl.disableDeblocking = r.ue()
+		if sl.disableDeblocking != 1 {
+			sl.alphaOffset = int(r.se()) * 2
+			sl.betaOffset = int(r.se()) * 2
+		}
+	}
+
+	if r.overrun() {
+		return errors.New("h264: wrong slice header")
+	}
+
+	for addr := firstMB; addr < len(d.mbs); addr++ {
+		if d.mbs[addr].slice != nil {
+			return errors.New("h264: macroblock decoded twice")
+		}
+
+		if err := d.decodeMB(r, sl, addr); err != nil {
+			return err
+		}
+
+		if r.overrun() {
+			return errors.New("h264: unexpected end of slice")
+		}
+
+		d.decoded++
+
+		if !r.moreData() {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Table 9-4 for Intra_4x4 macroblocks
+var cbpIntra = [48]uint8{
+	47, 31, 15, 0, 23, 27, 29, 30, 7, 11, 13, 14, 39, 43, 45, 46,
+	16, 3, 5, 10, 12, 19, 21, 26, 28, 35, 37, 42, 44, 1, 2, 4,
+	8, 17, 18, 20, 24, 6, 9, 22, 25, 32, 33, 34, 36, 40, 38, 41,
+}
+
+func (d *decoder) decodeMB(r *reader, sl *slice, addr int) error {
+	mb := &d.mbs[addr]
+	mb.slice = sl
+
+	mbx, mby := addr%d.width, addr/d.width
+
+	mbType := r.ue()
+	if mbType == 25 {
+		return d.decodePCM(r, mb, mbx, mby)
+	}
+	if mbType > 25 {
+		return errors.New("h264: wrong macroblock type")
+	}
+
+	var cbp uint8
+	var mode16 int
+
+	if mbType == 0 {
+		mb.typ = mbTypeI4x4
+
+		if sl.pps.transform8x8 && r.flag() {
+			return errors.New("h264: unsupported 8x8 transform")
+		}
+
+		for blk := 0; blk < 16; blk++ {
+			bx, by := blockXY(blk)
+			mode := d.predictedMode(mb, mbx, mby, bx, by)
+			if !r.flag() {
+				if rem := uint8(r.bits(3)); rem < mode {
+					mode = rem
+				} else {
+					mode = rem + 1
+				}
+			}
+			mb.modes[by*4+bx] = mode
+		}
+	} else {
+		mb.typ = mbTypeI16x16
+
+		t := int(mbType - 1)
+		mode16 = t % 4
+		cbp = uint8(t/4%3) << 4
+		if t >= 12 {
+			cbp |= 15
+		}
+
+		for i := range mb.modes {
+			mb.modes[i] = 2 // Intra_4x4_DC for neighbours
+		}
+	}
+
+	modeChroma := r.ue()
+	if modeChroma > 3 {
+		return errors.New("h264: wrong chroma prediction mode")
+	}
+
+	if mb.typ == mbTypeI4x4 {
+		i := r.ue()
+		if i >= 48 {
+			return errors.New("h264: wrong coded block pattern")
+		}
+		cbp = cbpIntra[i]
+	}
+
+	if cbp != 0 || mb.typ == mbTypeI16x16 {
+		delta := int(r.se())
+		if delta < -26 || delta > 25 {
+			return errors.New("h264: wrong QP delta")
+		}
+		sl.qp = (sl.qp + delta + 52) % 52
+	}
+
+	mb.qp = sl.qp
+
+	// residual
+	var dc [16]int32
+	var coeffs [16][16]int32 // in scan order for each 4x4 block in raster order
+
+	if mb.typ == mbTypeI16x16 {
+		nC := d.lumaNC(mb, mbx, mby, 0, 0)
+		if _, err := residualBlock(r, dc[:], 0, 16, nC); err != nil {
+			return err
+		}
+	}
+
+	for blk := 0; blk < 16; blk++ {
+		if cbp&(1<<(blk/4)) == 0 {
+			continue
+		}
+
+		bx, by := blockXY(blk)
+		nC := d.lumaNC(mb, mbx, mby, bx, by)
+
+		var n int
+		var err error
+		if mb.typ == mbTypeI16x16 {
+			n, err = residualBlock(r, coeffs[by*4+bx][:], 1, 15, nC)
+		} else {
+			n, err = residualBlock(r, coeffs[by*4+bx][:], 0, 16, nC)
+		}
+		if err != nil {
+			return err
+		}
+
+		mb.nz[by*4+bx] = uint8(n)
+	}
+
+	var chromaDC [2][4]int32
+	var chromaAC [2][4][16]int32
+
+	if cbp>>4 != 0 {
+		for c := 0; c < 2; c++ {
+			if _, err := residualBlock(r, chromaDC[c][:], 0, 4, -1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cbp>>4 == 2 {
+		for c := 0; c < 2; c++ {
+			for blk := 0; blk < 4; blk++ {
+				nC := d.chromaNC(mb, mbx, mby, c, blk&1, blk>>1)
+				n, err := residualBlock(r, chromaAC[c][blk][:], 1, 15, nC)
+				if err != nil {
+					return err
+				}
+				mb.nzc[c][blk] = uint8(n)
+			}
+		}
+	}
+
+	// reconstruction
+	qp := mb.qp
+	x0, y0 := mbx*16, mby*16
+
+	if mb.typ == mbTypeI4x4 {
+		for blk := 0; blk < 16; blk++ {
+			bx, by := blockXY(blk)
+			x, y := x0+bx*4, y0+by*4
+			if err := d.predict4x4(mb.modes[by*4+bx], mb, mbx, mby, bx, by); err != nil {
+				return err
+			}
+			if mb.nz[by*4+bx] != 0 {
+				block := dequant4x4(&coeffs[by*4+bx], qp, 0)
+				idct4x4(d.y[y*d.stride+x:], d.stride, &block)
+			}
+		}
+	} else {
+		if err := d.predict16x16(mode16, mb, mbx, mby); err != nil {
+			return err
+		}
+
+		dcY := lumaDC(&dc, qp)
+		for i := 0; i < 16; i++ {
+			if dcY[i] == 0 && mb.nz[i] == 0 {
+				continue
+			}
+			block := dequant4x4(&coeffs[i], qp, 1)
+			block[0] = dcY[i]
+			x, y := x0+(i%4)*4, y0+(i/4)*4
+			idct4x4(d.y[y*d.stride+x:], d.stride, &block)
+		}
+	}
+
+	cstride := d.stride / 2
+	for c, plane := range [2][]byte{d.cb, d.cr} {
+		if err := d.predictChroma(int(modeChroma), plane, mb, mbx, mby); err != nil {
+			return err
+		}
+
+		if cbp>>4 == 0 {
+			continue
+		}
+
+		qpc := chromaQP(qp, sl.pps.chromaQPOffset[c])
+		dcC := chromaDCTransform(&chromaDC[c], qpc)
+
+		for blk := 0; blk < 4; blk++ {
+			if dcC[blk] == 0 && mb.nzc[c][blk] == 0 {
+				continue
+			}
+			block := dequant4x4(&chromaAC[c][blk], qpc, 1)
+			block[0] = dcC[blk]
+			x, y := mbx*8+(blk&1)*4, mby*8+(blk>>1)*4
+			idct4x4(plane[y*cstride+x:], cstride, &block)
+		}
+	}
+
+	return nil
+}
+
+func (d *decoder) decodePCM(r *reader, mb *macroblock, mbx, mby int) error {
+	mb.typ = mbTypePCM
+	mb.qp = 0
+
+	for i := range mb.nz {
+		mb.nz[i] = 16
+		mb.modes[i] = 2
+	}
+	mb.nzc = [2][4]uint8{{16, 16, 16, 16}, {16, 16, 16, 16}}
+
+	r.align()
+
+	for y := 0; y < 16; y++ {
+		i := (mby*16+y)*d.stride + mbx*16
+		for x := 0; x < 16; x++ {
+			d.y[i+x] = byte(r.bits(8))
+		}
+	}
+
+	cstride := d.stride / 2
+	for _, plane := range [2][]byte{d.cb, d.cr} {
+		for y := 0; y < 8; y++ {
+			i := (mby*8+y)*cstride + mbx*8
+			for x := 0; x < 8; x++ {
+				plane[i+x] = byte(r.bits(8))
+			}
+		}
+	}
+
+	return nil
+}
+
+// blockXY - position of 4x4 luma block inside macroblock by luma4x4BlkIdx
+func blockXY(blk int) (x, y int) {
+	return blk/4%2*2 + blk%2, blk/8*2 + blk%4/2
+}
+
+// available - check if macroblock exists and belongs to the same slice
+func (d *decoder) available(mb *macroblock, mbx, mby int) bool {
+	if mbx < 0 || mby < 0 || mbx >= d.width {
+		return false
+	}
+	return d.mbs[mby*d.width+mbx].slice == mb.slice
+}
+
+func (d *decoder) predictedMode(mb *macroblock, mbx, mby, bx, by int) uint8 {
+	var a, b uint8
+
+	if bx > 0 {
+		a = mb.modes[by*4+bx-1]
+	} else if d.available(mb, mbx-1, mby) {
+		a = d.mbs[mby*d.width+mbx-1].modes[by*4+3]
+	} else {
+		return 2
+	}
+
+	if by > 0 {
+		b = mb.modes[(by-1)*4+bx]
+	} else if d.available(mb, mbx, mby-1) {
+		b = d.mbs[(mby-1)*d.width+mbx].modes[12+bx]
+	} else {
+		return 2
+	}
+
+	return min(a, b)
+}
+
+func (d *decoder) lumaNC(mb *macroblock, mbx, mby, bx, by int) int {
+	var a, b int
+	var availA, availB bool
+
+	if bx > 0 {
+		a, availA = int(mb.nz[by*4+bx-1]), true
+	} else if d.available(mb, mbx-1, mby) {
+		a, availA = int(d.mbs[mby*d.width+mbx-1].nz[by*4+3]), true
+	}
+
+	if by > 0 {
+		b, availB = int(mb.nz[(by-1)*4+bx]), true
+	} else if d.available(mb, mbx, mby-1) {
+		b, availB = int(d.mbs[(mby-1)*d.width+mbx].nz[12+bx]), true
+	}
+
+	return neighbourNC(a, b, availA, availB)
+}
+
+func (d *decoder) chromaNC(mb *macroblock, mbx, mby, c, bx, by int) int {
+	var a, b int
+	var availA, availB bool
+
+	if bx > 0 {
+		a, availA = int(mb.nzc[c][by*2]), true
+	} else if d.available(mb, mbx-1, mby) {
+		a, availA = int(d.mbs[mby*d.width+mbx-1].nzc[c][by*2+1]), true
+	}
+
+	if by > 0 {
+		b, availB = int(mb.nzc[c][bx]), true
+	} else if d.available(mb, mbx, mby-1) {
+		b, availB = int(d.mbs[(mby-1)*d.width+mbx].nzc[c][2+bx]), true
+	}
+
+	return neighbourNC(a, b, availA, availB)
+}
+
+func neighbourNC(a, b int, availA, availB bool) int {
+	switch {
+	case availA && availB:
+		return (a + b + 1) >> 1
+	case availA:
+		return a
+	case availB:
+		return b
+	}
+	return 0
+}
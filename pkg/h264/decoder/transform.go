@@ -0,0 +1,135 @@
+package decoder
+
+// zigzag4x4 - raster position by scan index (frame macroblocks)
+var zigzag4x4 = [16]int{0, 1, 4, 8, 5, 2, 3, 6, 9, 12, 13, 10, 7, 11, 14, 15}
+
+// normAdjust4x4 - v values for flat scaling matrix (8.5.9)
+var normAdjust4x4 = [6][3]int32{
+	{10, 16, 13}, {11, 18, 14}, {13, 20, 16}, {14, 23, 18}, {16, 25, 20}, {18, 29, 23},
+}
+
+// levelScale4x4 - normAdjust4x4 in raster order of 4x4 block
+var levelScale4x4 [6][16]int32
+
+func init() {
+	for m := 0; m < 6; m++ {
+		for i := 0; i < 16; i++ {
+			switch x, y := i%4, i/4; {
+			case x%2 == 0 && y%2 == 0:
+				levelScale4x4[m][i] = normAdjust4x4[m][0]
+			case x%2 == 1 && y%2 == 1:
+				levelScale4x4[m][i] = normAdjust4x4[m][1]
+			default:
+				levelScale4x4[m][i] = normAdjust4x4[m][2]
+			}
+		}
+	}
+}
+
+// Table 8-15
+var chromaQPTable = [22]int{
+	29, 30, 31, 32, 32, 33, 34, 34, 35, 35, 36, 36, 37, 37, 37, 38, 38, 38, 39, 39, 39, 39,
+}
+
+func chromaQP(qp, offset int) int {
+	qp += offset
+	switch {
+	case qp < 0:
+		return 0
+	case qp < 30:
+		return qp
+	case qp > 51:
+		qp = 51
+	}
+	return chromaQPTable[qp-30]
+}
+
+// dequant4x4 - scaling of coefficients in scan order to 4x4 block in raster order (8.5.12.1)
+func dequant4x4(coeffs *[16]int32, qp, start int) (block [16]int32) {
+	scale := &levelScale4x4[qp%6]
+	for i := start; i < 16; i++ {
+		if c := coeffs[i]; c != 0 {
+			pos := zigzag4x4[i]
+			block[pos] = c * scale[pos] << (qp / 6)
+		}
+	}
+	return
+}
+
+// idct4x4 - inverse transform of 4x4 block and adding result to prediction (8.5.12.2)
+func idct4x4(dst []byte, stride int, b *[16]int32) {
+	// horizontal
+	for i := 0; i < 16; i += 4 {
+		e0 := b[i] + b[i+2]
+		e1 := b[i] - b[i+2]
+		e2 := b[i+1]>>1 - b[i+3]
+		e3 := b[i+1] + b[i+3]>>1
+		b[i], b[i+1], b[i+2], b[i+3] = e0+e3, e1+e2, e1-e2, e0-e3
+	}
+
+	// vertical
+	for x := 0; x < 4; x++ {
+		e0 := b[x] + b[x+8]
+		e1 := b[x] - b[x+8]
+		e2 := b[x+4]>>1 - b[x+12]
+		e3 := b[x+4] + b[x+12]>>1
+
+		r := [4]int32{e0 + e3, e1 + e2, e1 - e2, e0 - e3}
+		for y := 0; y < 4; y++ {
+			i := y*stride + x
+			dst[i] = clip(int(dst[i]) + int((r[y]+32)>>6))
+		}
+	}
+}
+
+// lumaDC - Intra_16x16 DC coefficients transform and scaling (8.5.10)
+func lumaDC(coeffs *[16]int32, qp int) (dc [16]int32) {
+	var c [16]int32
+	var empty = true
+	for i, v := range coeffs {
+		if v != 0 {
+			c[zigzag4x4[i]] = v
+			empty = false
+		}
+	}
+	if empty {
+		return
+	}
+
+	hadamard := func(a, b, c, d int32) (int32, int32, int32, int32) {
+		return a + b + c + d, a + b - c - d, a - b - c + d, a - b + c - d
+	}
+
+	for i := 0; i < 16; i += 4 {
+		c[i], c[i+1], c[i+2], c[i+3] = hadamard(c[i], c[i+1], c[i+2], c[i+3])
+	}
+	for x := 0; x < 4; x++ {
+		c[x], c[x+4], c[x+8], c[x+12] = hadamard(c[x], c[x+4], c[x+8], c[x+12])
+	}
+
+	scale := 16 * normAdjust4x4[qp%6][0]
+	for i, f := range c {
+		if qp >= 36 {
+			dc[i] = f * scale << (qp/6 - 6)
+		} else {
+			dc[i] = (f*scale + 1<<(5-qp/6)) >> (6 - qp/6)
+		}
+	}
+	return
+}
+
+// chromaDCTransform - chroma DC coefficients transform and scaling for 4:2:0 (8.5.11)
+func chromaDCTransform(c *[4]int32, qp int) (dc [4]int32) {
+	f := [4]int32{
+		c[0] + c[1] + c[2] + c[3],
+		c[0] - c[1] + c[2] - c[3],
+		c[0] + c[1] - c[2] - c[3],
+		c[0] - c[1] - c[2] + c[3],
+	}
+
+	scale := 16 * normAdjust4x4[qp%6][0]
+	for i := range f {
+		dc[i] = (f[i] * scale << (qp / 6)) >> 5
+	}
+	return
+}
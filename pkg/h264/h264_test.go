@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -108,3 +110,24 @@ func TestAVCCToCodec(t *testing.T) {
 	codec := AVCCToCodec(b)
 	require.Equal(t, "packetization-mode=1;profile-level-id=64001f;sprop-parameter-sets=Z2QAH6wkhAFAFuwEQAAAAwBAAAAMI8YMkg==,aO4yyLA=", codec.FmtpLine)
 }
+
+func TestInsertSEI(t *testing.T) {
+	sei := DisplayOrientationSEI(90)
+	require.Equal(t, []byte{0x06, 0x2F, 0x03, 0x18, 0x00, 0x09, 0x80}, sei)
+
+	var packets []*rtp.Packet
+	codec := &core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: 96}
+	handler := InsertSEI(codec, sei, func(packet *rtp.Packet) {
+		packets = append(packets, packet)
+	})
+
+	handler(&rtp.Packet{Header: rtp.Header{SequenceNumber: 10, Timestamp: 1}, Payload: []byte{0x67, 0x42}}) // SPS
+	handler(&rtp.Packet{Header: rtp.Header{SequenceNumber: 11, Timestamp: 1}, Payload: []byte{0x65, 0x88}}) // IDR
+	handler(&rtp.Packet{Header: rtp.Header{SequenceNumber: 12, Timestamp: 2}, Payload: []byte{0x41, 0x9A}}) // P
+
+	require.Len(t, packets, 4)
+	require.Equal(t, sei, packets[0].Payload)
+	require.Equal(t, []uint16{10, 11, 12, 13}, []uint16{
+		packets[0].SequenceNumber, packets[1].SequenceNumber, packets[2].SequenceNumber, packets[3].SequenceNumber,
+	})
+}
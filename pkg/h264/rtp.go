@@ -127,7 +127,7 @@ func RTPPay(mtu uint16, handler core.HandlerFunc) core.HandlerFunc {
 					Version:        2,
 					Marker:         i == last,
 					SequenceNumber: sequencer.NextSequenceNumber(),
-					Timestamp:      packet.Timestamp,
+					Timestamp:      core.GetPTS(packet),
 				},
 				Payload: payload,
 			}
@@ -0,0 +1,89 @@
+package h264
+
+import (
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
+)
+
+const SEITypeDisplayOrientation = 47
+
+// DisplayOrientationSEI - SEI NAL unit with display orientation (Rec. ITU-T H.264, D.1.27),
+// rotate is clockwise degrees (90, 180, 270), like the MP4 matrix
+func DisplayOrientationSEI(rotate int) []byte {
+	// anticlockwise_rotation in units of 360/65536 degrees
+	rot := uint16((360 - rotate%360) % 360 * 65536 / 360)
+
+	// display_orientation_cancel_flag=0, hor_flip=0, ver_flip=0 (3 bits), anticlockwise_rotation (16 bits),
+	// display_orientation_repetition_period=1 (ue 3 bits), display_orientation_extension_flag=0 (1 bit),
+	// bit_equal_to_one (1 bit) for byte alignment
+	b := []byte{
+		NALUTypeSEI,
+		SEITypeDisplayOrientation, 3, // payload type and size
+		byte(rot >> 11), byte(rot >> 3), byte(rot<<5) | 0b01001,
+		0x80, // rbsp_trailing_bits
+	}
+	if b[3] == 0 && b[4] == 0 {
+		// emulation prevention for zero rotation
+		b = []byte{b[0], b[1], b[2], 0, 0, 3, b[5], b[6]}
+	}
+	return b
+}
+
+// InsertSEI - insert SEI NAL unit before each keyframe, for RTP packets all following
+// sequence numbers are shifted by the count of inserted packets
+func InsertSEI(codec *core.Codec, sei []byte, handler core.HandlerFunc) core.HandlerFunc {
+	if !codec.IsRTP() {
+		prefix := JoinNALU(sei)
+		return func(packet *rtp.Packet) {
+			if IsKeyframe(packet.Payload) {
+				clone := *packet
+				clone.Payload = Join(prefix, packet.Payload)
+				packet = &clone
+			}
+			handler(packet)
+		}
+	}
+
+	var offset uint16
+	var lastTS uint32
+	var started bool
+
+	return func(packet *rtp.Packet) {
+		if (!started || packet.Timestamp != lastTS) && isKeyframeStart(packet.Payload) {
+			started = true
+			lastTS = packet.Timestamp
+
+			seiPacket := &rtp.Packet{Header: packet.Header, Payload: sei}
+			seiPacket.Marker = false
+			seiPacket.SequenceNumber += offset
+			offset++
+			handler(seiPacket)
+		}
+
+		if offset != 0 {
+			clone := *packet
+			clone.SequenceNumber += offset
+			packet = &clone
+		}
+
+		handler(packet)
+	}
+}
+
+// isKeyframeStart - RTP payload starts new keyframe: SPS or IDR in single NAL, STAP-A or FU-A start
+func isKeyframeStart(payload []byte) bool {
+	if len(payload) < 2 {
+		return false
+	}
+
+	switch typ := payload[0] & 0x1F; typ {
+	case NALUTypeSPS, NALUTypeIFrame:
+		return true
+	case 24: // STAP-A, check first NAL unit
+		return len(payload) > 3 && (payload[3]&0x1F == NALUTypeSPS || payload[3]&0x1F == NALUTypeIFrame)
+	case 28: // FU-A, check start bit
+		return payload[1]&0x80 != 0 && payload[1]&0x1F == NALUTypeIFrame
+	}
+
+	return false
+}
@@ -137,7 +137,7 @@ func RTPPay(mtu uint16, handler core.HandlerFunc) core.HandlerFunc {
 					Version:        2,
 					Marker:         i == last,
 					SequenceNumber: sequencer.NextSequenceNumber(),
-					Timestamp:      packet.Timestamp,
+					Timestamp:      core.GetPTS(packet),
 				},
 				Payload: payload,
 			}
@@ -209,7 +209,7 @@ func SafariPay(mtu uint16, handler core.HandlerFunc) core.HandlerFunc {
 					Version:        2,
 					Marker:         au == nil,
 					SequenceNumber: sequencer.NextSequenceNumber(),
-					Timestamp:      packet.Timestamp,
+					Timestamp:      core.GetPTS(packet),
 				},
 				Payload: b,
 			}
@@ -0,0 +1,98 @@
+package h265
+
+import (
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/pion/rtp"
+)
+
+const SEITypeDisplayOrientation = 47
+
+// DisplayOrientationSEI - prefix SEI NAL unit with display orientation (Rec. ITU-T H.265, D.2.16),
+// rotate is clockwise degrees (90, 180, 270), like the MP4 matrix
+func DisplayOrientationSEI(rotate int) []byte {
+	// anticlockwise_rotation in units of 360/65536 degrees
+	rot := uint16((360 - rotate%360) % 360 * 65536 / 360)
+
+	// display_orientation_cancel_flag=0, hor_flip=0, ver_flip=0 (3 bits), anticlockwise_rotation (16 bits),
+	// display_orientation_persistence_flag=1 (1 bit), bit_equal_to_one and zero bits for byte alignment
+	b := []byte{
+		NALUTypePrefixSEI << 1, 1, // NAL unit header with nuh_temporal_id_plus1=1
+		SEITypeDisplayOrientation, 3, // payload type and size
+		byte(rot >> 11), byte(rot >> 3), byte(rot<<5) | 0b11000,
+		0x80, // rbsp_trailing_bits
+	}
+	if b[4] == 0 && b[5] == 0 {
+		// emulation prevention for zero rotation
+		b = []byte{b[0], b[1], b[2], b[3], 0, 0, 3, b[6], b[7]}
+	}
+	return b
+}
+
+// InsertSEI - insert SEI NAL unit before each keyframe, for RTP packets all following
+// sequence numbers are shifted by the count of inserted packets
+func InsertSEI(codec *core.Codec, sei []byte, handler core.HandlerFunc) core.HandlerFunc {
+	if !codec.IsRTP() {
+		prefix := h264.JoinNALU(sei)
+		return func(packet *rtp.Packet) {
+			if IsKeyframe(packet.Payload) {
+				clone := *packet
+				clone.Payload = h264.Join(prefix, packet.Payload)
+				packet = &clone
+			}
+			handler(packet)
+		}
+	}
+
+	var offset uint16
+	var lastTS uint32
+	var started bool
+
+	return func(packet *rtp.Packet) {
+		if (!started || packet.Timestamp != lastTS) && isKeyframeStart(packet.Payload) {
+			started = true
+			lastTS = packet.Timestamp
+
+			seiPacket := &rtp.Packet{Header: packet.Header, Payload: sei}
+			seiPacket.Marker = false
+			seiPacket.SequenceNumber += offset
+			offset++
+			handler(seiPacket)
+		}
+
+		if offset != 0 {
+			clone := *packet
+			clone.SequenceNumber += offset
+			packet = &clone
+		}
+
+		handler(packet)
+	}
+}
+
+// isKeyframeStart - RTP payload starts new keyframe: VPS, SPS or IRAP in single NAL, AP or FU start
+func isKeyframeStart(payload []byte) bool {
+	if len(payload) < 3 {
+		return false
+	}
+
+	typ := (payload[0] >> 1) & 0x3F
+	switch typ {
+	case h265NaluAggregationPacketType:
+		if len(payload) < 5 {
+			return false
+		}
+		typ = (payload[4] >> 1) & 0x3F // first NAL unit after 2 bytes size
+	case NALUTypeFU:
+		if payload[2]&0x80 == 0 {
+			return false // not start fragment
+		}
+		typ = payload[2] & 0x3F
+	}
+
+	switch typ {
+	case NALUTypeVPS, NALUTypeSPS, NALUTypeIFrame, NALUTypeIFrame2, NALUTypeIFrame3:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package hls
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// segmentIV - IV from the key attribute or the media sequence number as 128 bit big-endian
+func segmentIV(key *Key, sequence int) []byte {
+	if len(key.IV) == aes.BlockSize {
+		return key.IV
+	}
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[8:], uint64(sequence))
+	return iv
+}
+
+// decryptAES128 - decrypt whole segment with AES-128 CBC and remove PKCS7 padding
+func decryptAES128(key, iv, b []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(b)
+	if n == 0 || n%aes.BlockSize != 0 {
+		return nil, errors.New("hls: wrong encrypted segment size")
+	}
+
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(b, b)
+
+	if pad := int(b[n-1]); pad > 0 && pad <= aes.BlockSize {
+		n -= pad
+	}
+	return b[:n], nil
+}
+
+// sampleAES - decryptor for H264 and AAC elementary streams in MPEG-TS,
+// Apple "MPEG-2 Stream Encryption Format for HTTP Live Streaming"
+type sampleAES struct {
+	block cipher.Block
+	iv    []byte
+}
+
+func newSampleAES(key, iv []byte) (*sampleAES, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &sampleAES{block: block, iv: iv}, nil
+}
+
+// DecryptH264 - decrypt AnnexB payload, only slice NAL units longer than 48 bytes are encrypted:
+// 32 bytes clear leader, then one encrypted block and up to 144 clear bytes
+func (s *sampleAES) DecryptH264(b []byte) []byte {
+	var out []byte
+
+	for len(b) > 0 {
+		// start code and NAL unit until next start code
+		i := bytes.Index(b, []byte{0, 0, 1})
+		if i < 0 {
+			return append(out, b...)
+		}
+
+		out = append(out, b[:i+3]...)
+		b = b[i+3:]
+
+		nalu := b
+		if i = bytes.Index(b, []byte{0, 0, 1}); i >= 0 {
+			// zero byte before start code belongs to the next start code
+			if i > 0 && b[i-1] == 0 {
+				i--
+			}
+			nalu = b[:i]
+		}
+		b = b[len(nalu):]
+
+		if len(nalu) > 48 && (nalu[0]&0x1F == 1 || nalu[0]&0x1F == 5) {
+			rbsp := bytes.ReplaceAll(nalu, []byte{0, 0, 3}, []byte{0, 0})
+			s.decryptPattern(rbsp)
+			nalu = emulationPrevention(rbsp)
+		}
+
+		out = append(out, nalu...)
+	}
+
+	return out
+}
+
+func (s *sampleAES) decryptPattern(b []byte) {
+	cbc := cipher.NewCBCDecrypter(s.block, s.iv)
+	for i := 32; len(b)-i > aes.BlockSize; i += aes.BlockSize + 144 {
+		cbc.CryptBlocks(b[i:i+aes.BlockSize], b[i:i+aes.BlockSize])
+	}
+}
+
+// DecryptADTS - decrypt ADTS frames in place, after header 16 bytes clear leader,
+// then all full blocks are encrypted and the rest is clear
+func (s *sampleAES) DecryptADTS(b []byte) []byte {
+	for i := 0; i+7 <= len(b); {
+		header := 7
+		if b[i+1]&1 == 0 {
+			header = 9 // with CRC
+		}
+
+		size := int(b[i+3]&3)<<11 | int(b[i+4])<<3 | int(b[i+5]>>5)
+		if size < header || i+size > len(b) {
+			break
+		}
+
+		if frame := b[i+header : i+size]; len(frame) > 16 {
+			n := (len(frame) - 16) / aes.BlockSize * aes.BlockSize
+			cipher.NewCBCDecrypter(s.block, s.iv).CryptBlocks(frame[16:16+n], frame[16:16+n])
+		}
+
+		i += size
+	}
+	return b
+}
+
+// emulationPrevention - insert 0x03 after two zero bytes, before 0x00-0x03 bytes
+func emulationPrevention(b []byte) []byte {
+	out := make([]byte, 0, len(b)+len(b)/64)
+	var zeros int
+	for _, c := range b {
+		if zeros >= 2 && c <= 3 {
+			out = append(out, 3)
+			zeros = 0
+		}
+		out = append(out, c)
+		if c == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+	}
+	return out
+}
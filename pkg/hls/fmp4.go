@@ -0,0 +1,144 @@
+package hls
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/mp4"
+)
+
+type Producer struct {
+	core.Connection
+	rd  *reader
+	dem *mp4.Demuxer
+
+	segment *Segment
+	data    []byte
+}
+
+func openFMP4(rd *reader, segment *Segment, b []byte) (*Producer, error) {
+	if segment.Key != nil && segment.Key.Method != "AES-128" {
+		return nil, errors.New("hls: unsupported encryption for fMP4: " + segment.Key.Method)
+	}
+
+	if _, err := rd.getInit(segment); err != nil {
+		return nil, err
+	}
+
+	prod := &Producer{
+		Connection: core.Connection{
+			ID:         core.NewID(),
+			FormatName: "hls/fmp4",
+			RemoteAddr: rd.url.Host,
+			Transport:  rd,
+		},
+		rd:      rd,
+		dem:     &mp4.Demuxer{},
+		segment: segment,
+		data:    b,
+	}
+
+	if prod.Medias = prod.dem.Probe(rd.init); prod.Medias == nil {
+		return nil, errors.New("hls: unsupported fMP4 codecs")
+	}
+
+	return prod, nil
+}
+
+// track - continuous timestamps for the receiver after discontinuity
+type track struct {
+	receiver *core.Receiver
+	offset   uint32
+	last     uint32
+	delta    uint32
+	shift    bool
+}
+
+func (p *Producer) Start() error {
+	tracks := make(map[uint32]*track)
+	for _, receiver := range p.Receivers {
+		trackID := p.dem.GetTrackID(receiver.Codec)
+		tracks[trackID] = &track{receiver: receiver}
+	}
+
+	segment, b := p.segment, p.data
+	p.data = nil
+
+	for {
+		p.Recv += len(b)
+
+		if segment.Discontinuity {
+			for _, t := range tracks {
+				t.shift = t.last != 0
+			}
+		}
+
+		for _, fragment := range splitFragments(b) {
+			for trackID, packets := range p.dem.DemuxTracks(fragment) {
+				t := tracks[trackID]
+				if t == nil {
+					continue
+				}
+
+				for _, packet := range packets {
+					if t.shift {
+						t.offset = t.last + t.delta - packet.Timestamp
+						t.shift = false
+					}
+
+					packet.Timestamp += t.offset
+
+					if t.last != 0 && packet.Timestamp > t.last {
+						t.delta = packet.Timestamp - t.last
+					}
+					t.last = packet.Timestamp
+
+					t.receiver.WriteRTP(packet)
+				}
+			}
+		}
+
+		var err error
+		if segment, b, err = p.rd.nextSegment(); err != nil {
+			return err
+		}
+
+		if segment.Key != nil && segment.Key.Method != "AES-128" {
+			return errors.New("hls: unsupported encryption for fMP4: " + segment.Key.Method)
+		}
+
+		// new codecs should be probed again, so reconnect the source
+		if changed, err := p.rd.getInit(segment); err != nil {
+			return err
+		} else if changed {
+			return errors.New("hls: init section changed")
+		}
+	}
+}
+
+// splitFragments - split media segment to moof+mdat pairs, skip other boxes (styp, sidx, prft)
+func splitFragments(b []byte) (fragments [][]byte) {
+	start := -1
+
+	for i := 0; i+8 <= len(b); {
+		size := int(binary.BigEndian.Uint32(b[i:]))
+		if size < 8 || i+size > len(b) {
+			break
+		}
+
+		switch string(b[i+4 : i+8]) {
+		case "moof":
+			start = i
+		case "mdat":
+			if start >= 0 {
+				fragments = append(fragments, b[start:i+size])
+				start = -1
+			}
+		}
+
+		i += size
+	}
+
+	return
+}
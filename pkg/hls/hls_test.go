@@ -0,0 +1,104 @@
+package hls
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlaylist(t *testing.T) {
+	s := `#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=2000000,RESOLUTION=1280x720,CODECS="avc1.64001f,mp4a.40.2"
+720.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=5000000,RESOLUTION=1920x1080,NAME="main"
+1080.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360
+360.m3u8
+`
+	playlist := ParsePlaylist([]byte(s))
+	require.Len(t, playlist.Variants, 3)
+	require.Equal(t, "avc1.64001f,mp4a.40.2", playlist.Variants[0].Codecs)
+
+	require.Equal(t, "720.m3u8", playlist.SelectVariant("").URI)
+	require.Equal(t, "360.m3u8", playlist.SelectVariant("min").URI)
+	require.Equal(t, "1080.m3u8", playlist.SelectVariant("max").URI)
+	require.Equal(t, "720.m3u8", playlist.SelectVariant("3000000").URI)
+	require.Equal(t, "1080.m3u8", playlist.SelectVariant("main").URI)
+	require.Equal(t, "360.m3u8", playlist.SelectVariant("360p").URI)
+
+	s = `#EXTM3U
+#EXT-X-TARGETDURATION:2
+#EXT-X-MEDIA-SEQUENCE:100
+#EXT-X-MAP:URI="init.mp4"
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin",IV=0x000102030405060708090A0B0C0D0E0F
+#EXTINF:2.000,
+100.m4s
+#EXT-X-DISCONTINUITY
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:1.500,
+101.m4s
+`
+	playlist = ParsePlaylist([]byte(s))
+	require.Len(t, playlist.Segments, 2)
+	require.Equal(t, 100, playlist.Segments[0].Sequence)
+	require.Equal(t, "init.mp4", playlist.Segments[0].Map.URI)
+	require.Equal(t, "key.bin", playlist.Segments[0].Key.URI)
+	require.Len(t, playlist.Segments[0].Key.IV, 16)
+	require.False(t, playlist.Segments[0].Discontinuity)
+
+	require.Equal(t, 101, playlist.Segments[1].Sequence)
+	require.Equal(t, 1.5, playlist.Segments[1].Duration)
+	require.Nil(t, playlist.Segments[1].Key)
+	require.True(t, playlist.Segments[1].Discontinuity)
+}
+
+func TestDecryptAES128(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	iv := segmentIV(&Key{}, 5)
+	src := []byte("segment data with padding")
+
+	// PKCS7 padding
+	pad := aes.BlockSize - len(src)%aes.BlockSize
+	b := append(bytes.Clone(src), bytes.Repeat([]byte{byte(pad)}, pad)...)
+
+	block, _ := aes.NewCipher(key)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(b, b)
+
+	b, err := decryptAES128(key, iv, b)
+	require.Nil(t, err)
+	require.Equal(t, src, b)
+}
+
+func TestSampleAES(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	iv := make([]byte, aes.BlockSize)
+	block, _ := aes.NewCipher(key)
+
+	// ADTS frame: 7 bytes header, 16 bytes clear leader, 2 encrypted blocks, 5 bytes clear
+	size := 7 + 16 + 32 + 5
+	frame := []byte{0xFF, 0xF1, 0x50, 0x80, byte(size >> 3), byte(size<<5) | 0x1F, 0xFC}
+	frame = append(frame, bytes.Repeat([]byte{1}, size-7)...)
+
+	b := bytes.Clone(frame)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(b[23:55], b[23:55])
+	require.NotEqual(t, frame, b)
+
+	s, err := newSampleAES(key, iv)
+	require.Nil(t, err)
+	require.Equal(t, frame, s.DecryptADTS(b))
+
+	// H264 slice: 32 bytes clear leader, encrypted block, 144 bytes clear, encrypted block, 10 bytes clear
+	nalu := append([]byte{0x65}, bytes.Repeat([]byte{2}, 31+16+144+16+10)...)
+
+	b = bytes.Clone(nalu)
+	cbc := cipher.NewCBCEncrypter(block, iv)
+	cbc.CryptBlocks(b[32:48], b[32:48])
+	cbc.CryptBlocks(b[192:208], b[192:208])
+	b = emulationPrevention(b)
+
+	b = s.DecryptH264(append([]byte{0, 0, 0, 1}, b...))
+	require.Equal(t, append([]byte{0, 0, 0, 1}, nalu...), b)
+}
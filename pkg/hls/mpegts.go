@@ -0,0 +1,258 @@
+package hls
+
+import (
+	"encoding/binary"
+
+	"github.com/AlexxIT/go2rtc/pkg/mpegts"
+)
+
+// tsFilter - rewrite MPEG-TS segments: decrypt SAMPLE-AES elementary streams
+// and shift timestamps after discontinuity, so they continue the previous segment
+type tsFilter struct {
+	pmtPID uint16
+	types  map[uint16]byte // stream type by PID from PMT
+
+	pes map[uint16][]byte // collected encrypted PES by PID
+
+	offset uint64 // shift for PTS/DTS, 33 bit
+	last   uint64 // last timestamp with offset
+	shift  bool   // recalc offset on next timestamp
+}
+
+const (
+	timeMask  = 1<<33 - 1
+	timeDelta = mpegts.ClockRate / 30 // approximate gap between segments after discontinuity
+)
+
+// Process - segment should contain whole TS packets, it can be changed in place
+func (f *tsFilter) Process(b []byte, decrypt *sampleAES, discontinuity bool) []byte {
+	if discontinuity && f.last != 0 {
+		f.shift = true
+	}
+
+	var out []byte
+	if decrypt != nil {
+		out = make([]byte, 0, len(b))
+	}
+
+	src := b
+
+	for ; len(b) >= mpegts.PacketSize; b = b[mpegts.PacketSize:] {
+		pkt := b[:mpegts.PacketSize]
+		if pkt[0] != mpegts.SyncByte {
+			break
+		}
+
+		pusi := pkt[1]&0x40 != 0
+		pid := binary.BigEndian.Uint16(pkt[1:]) & 0x1FFF
+
+		payload := pkt[4:]
+		if pkt[3]&0x20 != 0 {
+			if size := int(payload[0]) + 1; size < len(payload) {
+				payload = payload[size:]
+			} else {
+				payload = nil
+			}
+		}
+
+		switch {
+		case pid == 0:
+			if pusi {
+				f.readPAT(payload)
+			}
+		case pid == f.pmtPID:
+			if pusi {
+				f.readPMT(payload)
+			}
+		default:
+			if pusi {
+				f.readPES(payload)
+			}
+
+			if decrypt != nil && isSampleAES(f.types[pid]) {
+				if pusi {
+					out = f.flush(out, pid, decrypt)
+				}
+				if f.pes[pid] != nil || pusi {
+					f.pes[pid] = append(f.pes[pid], payload...)
+				}
+				continue
+			}
+		}
+
+		if out != nil {
+			out = append(out, pkt...)
+		}
+	}
+
+	if out == nil {
+		return src
+	}
+
+	for pid := range f.pes {
+		out = f.flush(out, pid, decrypt)
+	}
+
+	return out
+}
+
+func isSampleAES(streamType byte) bool {
+	return streamType == mpegts.StreamTypeH264SampleAES || streamType == mpegts.StreamTypeAACSampleAES
+}
+
+// psiSection - skip pointer field and return section data after 8 bytes header without CRC
+func psiSection(b []byte) []byte {
+	if len(b) < 1 || len(b) < 1+int(b[0])+8 {
+		return nil
+	}
+	b = b[1+int(b[0]):]
+	size := int(binary.BigEndian.Uint16(b[1:]) & 0x3FF)
+	if size < 9 || 3+size > len(b) {
+		return nil
+	}
+	return b[8 : 3+size-4]
+}
+
+func (f *tsFilter) readPAT(b []byte) {
+	for b = psiSection(b); len(b) >= 4; b = b[4:] {
+		if program := binary.BigEndian.Uint16(b); program != 0 {
+			f.pmtPID = binary.BigEndian.Uint16(b[2:]) & 0x1FFF
+			return
+		}
+	}
+}
+
+func (f *tsFilter) readPMT(b []byte) {
+	b = psiSection(b)
+	if len(b) < 4 {
+		return
+	}
+
+	size := int(binary.BigEndian.Uint16(b[2:]) & 0x3FF) // program info length
+	if 4+size > len(b) {
+		return
+	}
+
+	f.types = map[uint16]byte{}
+
+	for b = b[4+size:]; len(b) >= 5; {
+		pid := binary.BigEndian.Uint16(b[1:]) & 0x1FFF
+		f.types[pid] = b[0]
+
+		size = 5 + int(binary.BigEndian.Uint16(b[3:])&0x3FF) // ES info length
+		if size > len(b) {
+			break
+		}
+		b = b[size:]
+	}
+
+	if f.pes == nil {
+		f.pes = map[uint16][]byte{}
+	}
+}
+
+// readPES - read and shift PTS and DTS in the PES header
+func (f *tsFilter) readPES(b []byte) {
+	if len(b) < 9 || b[0] != 0 || b[1] != 0 || b[2] != 1 || b[6]&0xC0 != 0x80 {
+		return
+	}
+
+	flags := b[7] >> 6 // PTS and DTS indicators
+	if flags&0b10 == 0 || len(b) < 9+5 || flags == 0b11 && len(b) < 9+10 {
+		return
+	}
+
+	pts := b[9 : 9+5]
+
+	// DTS if exists or PTS
+	ts := readTime(pts)
+	if flags == 0b11 {
+		ts = readTime(b[14 : 14+5])
+	}
+
+	if f.shift {
+		f.offset = (f.last + timeDelta - ts) & timeMask
+		f.shift = false
+	}
+
+	if f.offset != 0 {
+		writeTime(pts, (readTime(pts)+f.offset)&timeMask)
+		if flags == 0b11 {
+			writeTime(b[14:14+5], (ts+f.offset)&timeMask)
+		}
+	}
+
+	f.last = (ts + f.offset) & timeMask
+}
+
+// flush - decrypt collected PES and write it as new TS packets
+func (f *tsFilter) flush(out []byte, pid uint16, decrypt *sampleAES) []byte {
+	pes := f.pes[pid]
+	if len(pes) < 9 {
+		delete(f.pes, pid)
+		return out
+	}
+
+	header := 9 + int(pes[8])
+	if header > len(pes) {
+		delete(f.pes, pid)
+		return out
+	}
+
+	var payload []byte
+	if f.types[pid] == mpegts.StreamTypeH264SampleAES {
+		payload = decrypt.DecryptH264(pes[header:])
+	} else {
+		payload = decrypt.DecryptADTS(pes[header:])
+	}
+
+	pes = append(pes[:header:header], payload...)
+
+	// PES packet length can be zero only for video
+	if size := len(pes) - 6; size <= 0xFFFF && f.types[pid] != mpegts.StreamTypeH264SampleAES {
+		binary.BigEndian.PutUint16(pes[4:], uint16(size))
+	} else {
+		binary.BigEndian.PutUint16(pes[4:], 0)
+	}
+
+	delete(f.pes, pid)
+
+	for counter := byte(0); len(pes) > 0; counter++ {
+		pkt := [mpegts.PacketSize]byte{mpegts.SyncByte, byte(pid >> 8), byte(pid)}
+		if counter == 0 {
+			pkt[1] |= 0x40 // PUSI
+		}
+
+		if n := len(pes); n < mpegts.PacketSize-4 {
+			// adaptation field with stuffing
+			pkt[3] = 0x30 | counter&0xF
+			pkt[4] = byte(mpegts.PacketSize - 4 - 1 - n)
+			if pkt[4] > 0 {
+				for i := 6; i < mpegts.PacketSize-n; i++ {
+					pkt[i] = 0xFF
+				}
+			}
+			copy(pkt[mpegts.PacketSize-n:], pes)
+			pes = nil
+		} else {
+			pkt[3] = 0x10 | counter&0xF
+			pes = pes[copy(pkt[4:], pes):]
+		}
+
+		out = append(out, pkt[:]...)
+	}
+
+	return out
+}
+
+func readTime(b []byte) uint64 {
+	return uint64(b[0]>>1&0b111)<<30 | uint64(b[1])<<22 | uint64(b[2]>>1)<<15 | uint64(b[3])<<7 | uint64(b[4]>>1)
+}
+
+func writeTime(b []byte, t uint64) {
+	b[0] = b[0]&0xF0 | byte(t>>29)&0b1110 | 1
+	b[1] = byte(t >> 22)
+	b[2] = byte(t>>14) | 1
+	b[3] = byte(t >> 7)
+	b[4] = byte(t<<1) | 1
+}
@@ -0,0 +1,212 @@
+package hls
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Playlist - multivariant (with variants) or media (with segments) playlist
+type Playlist struct {
+	Variants []*Variant
+	Segments []*Segment
+
+	TargetDuration float64
+	Ended          bool
+}
+
+type Variant struct {
+	URI        string
+	Name       string
+	Resolution string
+	Codecs     string
+	Bandwidth  int
+}
+
+type Segment struct {
+	URI      string
+	Sequence int // media sequence number
+	Duration float64
+
+	Key *Key
+	Map *Map
+
+	Discontinuity bool
+}
+
+type Key struct {
+	Method string // AES-128 or SAMPLE-AES
+	URI    string
+	IV     []byte // nil if IV should be the media sequence number
+}
+
+type Map struct {
+	URI       string
+	ByteRange string
+	Key       *Key
+}
+
+func ParsePlaylist(b []byte) *Playlist {
+	playlist := &Playlist{}
+
+	var key *Key
+	var mapping *Map
+	var segment *Segment
+	var variant *Variant
+	var sequence int
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line[0] != '#' {
+			switch {
+			case variant != nil:
+				variant.URI = line
+				playlist.Variants = append(playlist.Variants, variant)
+				variant = nil
+			case segment != nil:
+				segment.URI = line
+				segment.Sequence = sequence
+				segment.Key = key
+				segment.Map = mapping
+				playlist.Segments = append(playlist.Segments, segment)
+				segment = nil
+				sequence++
+			}
+			continue
+		}
+
+		tag, value, _ := strings.Cut(line, ":")
+
+		switch tag {
+		case "#EXT-X-STREAM-INF":
+			attrs := parseAttributes(value)
+			variant = &Variant{
+				Name:       attrs["NAME"],
+				Resolution: attrs["RESOLUTION"],
+				Codecs:     attrs["CODECS"],
+			}
+			variant.Bandwidth, _ = strconv.Atoi(attrs["BANDWIDTH"])
+
+		case "#EXT-X-MEDIA-SEQUENCE":
+			sequence, _ = strconv.Atoi(value)
+
+		case "#EXT-X-TARGETDURATION":
+			playlist.TargetDuration, _ = strconv.ParseFloat(value, 64)
+
+		case "#EXT-X-ENDLIST":
+			playlist.Ended = true
+
+		case "#EXT-X-KEY":
+			attrs := parseAttributes(value)
+			if method := attrs["METHOD"]; method != "NONE" {
+				key = &Key{Method: method, URI: attrs["URI"]}
+				if iv := attrs["IV"]; len(iv) > 2 {
+					key.IV, _ = hex.DecodeString(iv[2:]) // skip 0x
+				}
+			} else {
+				key = nil
+			}
+
+		case "#EXT-X-MAP":
+			attrs := parseAttributes(value)
+			mapping = &Map{URI: attrs["URI"], ByteRange: attrs["BYTERANGE"], Key: key}
+
+		case "#EXT-X-DISCONTINUITY":
+			if segment == nil {
+				segment = &Segment{}
+			}
+			segment.Discontinuity = true
+
+		case "#EXTINF":
+			if segment == nil {
+				segment = &Segment{}
+			}
+			duration, _, _ := strings.Cut(value, ",")
+			segment.Duration, _ = strconv.ParseFloat(duration, 64)
+		}
+	}
+
+	return playlist
+}
+
+// SelectVariant - select variant by name or resolution (1280x720 or 720p),
+// by bandwidth (max, min or closest below the value), default is the first one
+func (p *Playlist) SelectVariant(query string) *Variant {
+	if len(p.Variants) == 0 {
+		return nil
+	}
+
+	variants := make([]*Variant, len(p.Variants))
+	copy(variants, p.Variants)
+	sort.SliceStable(variants, func(i, j int) bool {
+		return variants[i].Bandwidth < variants[j].Bandwidth
+	})
+
+	switch query {
+	case "":
+		return p.Variants[0]
+	case "min":
+		return variants[0]
+	case "max":
+		return variants[len(variants)-1]
+	}
+
+	if bandwidth, err := strconv.Atoi(query); err == nil {
+		selected := variants[0]
+		for _, v := range variants {
+			if v.Bandwidth <= bandwidth {
+				selected = v
+			}
+		}
+		return selected
+	}
+
+	for _, v := range p.Variants {
+		if v.Name == query || v.Resolution == query || strings.HasSuffix(v.Resolution, "x"+strings.TrimSuffix(query, "p")) {
+			return v
+		}
+	}
+
+	return p.Variants[0]
+}
+
+// parseAttributes - parse attribute list: KEY=VALUE,KEY="QUOTED,VALUE"
+func parseAttributes(s string) map[string]string {
+	attrs := map[string]string{}
+
+	for s != "" {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			value = value[1:]
+			i := strings.IndexByte(value, '"')
+			if i < 0 {
+				i = len(value)
+			}
+			attrs[key] = value[:i]
+			s = value[min(i+1, len(value)):]
+		} else {
+			i := strings.IndexByte(value, ',')
+			if i < 0 {
+				i = len(value)
+			}
+			attrs[key] = value[:i]
+			s = value[i:]
+		}
+
+		s = strings.TrimPrefix(s, ",")
+	}
+
+	return attrs
+}
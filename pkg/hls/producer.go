@@ -2,21 +2,38 @@ package hls
 
 import (
 	"io"
-	"net/url"
+	"net/http"
 
+	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/mpegts"
 )
 
-func OpenURL(u *url.URL, body io.ReadCloser) (*mpegts.Producer, error) {
-	rd, err := NewReader(u, body)
+// OpenURL - HLS client for MPEG-TS and fMP4 segments, variant from multivariant playlist
+// can be selected by name, resolution or bandwidth (see Playlist.SelectVariant)
+func OpenURL(req *http.Request, body io.ReadCloser, variant string) (core.Producer, error) {
+	rd, err := newReader(req, body, variant)
 	if err != nil {
 		return nil, err
 	}
+
+	segment, b, err := rd.nextSegment()
+	if err != nil {
+		return nil, err
+	}
+
+	if segment.Map != nil {
+		return openFMP4(rd, segment, b)
+	}
+
+	if rd.buf, err = rd.processTS(segment, b); err != nil {
+		return nil, err
+	}
+
 	prod, err := mpegts.Open(rd)
 	if err != nil {
 		return nil, err
 	}
 	prod.FormatName = "hls/mpegts"
-	prod.RemoteAddr = u.Host
+	prod.RemoteAddr = req.URL.Host
 	return prod, nil
 }
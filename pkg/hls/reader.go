@@ -2,67 +2,78 @@ package hls
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
-	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
 )
 
 type reader struct {
-	client  *http.Client
-	request *http.Request
-
-	playlist    []byte
-	lastSegment []byte
-	lastTime    time.Time
-
-	buf []byte
+	client *http.Client
+	header http.Header
+	url    *url.URL // media playlist
+
+	segments []*Segment // queue of segments for download
+	sequence int        // media sequence number of next segment
+	started  bool
+	ended    bool
+	lastTime time.Time
+
+	keys    map[string][]byte
+	initKey string
+	init    []byte
+
+	filter tsFilter
+	buf    []byte
 }
 
-func NewReader(u *url.URL, body io.ReadCloser) (io.Reader, error) {
+// newReader - select variant from multivariant playlist and load media playlist
+func newReader(req *http.Request, body io.ReadCloser, variant string) (*reader, error) {
 	b, err := io.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
 
-	var rawURL string
+	r := &reader{
+		client: &http.Client{Timeout: core.ConnDialTimeout},
+		header: req.Header,
+		url:    req.URL,
+		keys:   map[string][]byte{},
+	}
+
+	playlist := ParsePlaylist(b)
 
-	re := regexp.MustCompile(`#EXT-X-STREAM-INF.+?\n(\S+)`)
-	m := re.FindSubmatch(b)
-	if m != nil {
-		ref, err := url.Parse(string(m[1]))
-		if err != nil {
+	if v := playlist.SelectVariant(variant); v != nil {
+		if r.url, err = r.url.Parse(v.URI); err != nil {
+			return nil, err
+		}
+		if playlist, err = r.loadPlaylist(); err != nil {
 			return nil, err
 		}
-
-		rawURL = u.ResolveReference(ref).String()
-	} else {
-		rawURL = u.String()
 	}
 
-	req, err := http.NewRequest("GET", rawURL, nil)
-	if err != nil {
-		return nil, err
-	}
+	r.update(playlist)
 
-	rd := &reader{
-		client:  &http.Client{Timeout: core.ConnDialTimeout},
-		request: req,
-	}
-	return rd, nil
+	return r, nil
 }
 
 func (r *reader) Read(dst []byte) (n int, err error) {
 	// 1. Check temporary tempbuffer
 	if len(r.buf) == 0 {
-		src, err2 := r.getSegment()
+		segment, src, err2 := r.nextSegment()
 		if err2 != nil {
 			return 0, err2
 		}
 
+		if src, err2 = r.processTS(segment, src); err2 != nil {
+			return 0, err2
+		}
+
 		// 2. Check if the message fits in the buffer
 		if len(src) <= len(dst) {
 			return copy(dst, src), nil
@@ -87,79 +98,194 @@ func (r *reader) RoundTrip(_ *http.Request) (*http.Response, error) {
 	return nil, io.EOF
 }
 
-func (r *reader) getSegment() ([]byte, error) {
-	for i := 0; i < 10; i++ {
-		if r.playlist == nil {
-			if wait := time.Second - time.Since(r.lastTime); wait > 0 {
-				time.Sleep(wait)
-			}
+// update - add new segments from the playlist to the queue
+func (r *reader) update(playlist *Playlist) {
+	segments := playlist.Segments
 
-			// 1. Load playlist
-			res, err := r.client.Do(r.request)
-			if err != nil {
-				return nil, err
-			}
+	if !r.started && len(segments) > 0 {
+		// start live playlist three segments from the end
+		if !playlist.Ended && len(segments) > 3 {
+			segments = segments[len(segments)-3:]
+		}
+		r.sequence = segments[0].Sequence
+		r.started = true
+	}
 
-			r.playlist, err = io.ReadAll(res.Body)
-			if err != nil {
-				return nil, err
-			}
+	r.segments = r.segments[:0]
+	for _, segment := range segments {
+		if segment.Sequence >= r.sequence {
+			r.segments = append(r.segments, segment)
+		}
+	}
 
-			r.lastTime = time.Now()
+	r.ended = playlist.Ended
+}
 
-			//log.Printf("[hls] load playlist\n%s", r.playlist)
+// nextSegment - download next segment, decrypted if METHOD=AES-128
+func (r *reader) nextSegment() (*Segment, []byte, error) {
+	for i := 0; len(r.segments) == 0; i++ {
+		if r.ended || i == 10 {
+			return nil, nil, io.EOF
 		}
 
-		for r.playlist != nil {
-			// 2. Remove all previous segments from playlist
-			if i := bytes.Index(r.playlist, r.lastSegment); i > 0 {
-				r.playlist = r.playlist[i:]
-			}
+		if wait := time.Second - time.Since(r.lastTime); wait > 0 {
+			time.Sleep(wait)
+		}
 
-			// 3. Get link to new segment
-			segment := getSegment(r.playlist)
-			if segment == nil {
-				r.playlist = nil
-				break
-			}
+		playlist, err := r.loadPlaylist()
+		if err != nil {
+			return nil, nil, err
+		}
 
-			//log.Printf("[hls] load segment: %s", segment)
+		r.update(playlist)
+	}
 
-			ref, err := url.Parse(string(segment))
-			if err != nil {
-				return nil, err
-			}
+	segment := r.segments[0]
+	r.segments = r.segments[1:]
+	r.sequence = segment.Sequence + 1
 
-			ref = r.request.URL.ResolveReference(ref)
-			res, err := r.client.Get(ref.String())
-			if err != nil {
-				return nil, err
-			}
+	b, err := r.get(segment.URI, "")
+	if err != nil {
+		return nil, nil, err
+	}
 
-			r.lastSegment = segment
+	if key := segment.Key; key != nil && key.Method == "AES-128" {
+		if b, err = r.decrypt(key, segment.Sequence, b); err != nil {
+			return nil, nil, err
+		}
+	}
 
-			return io.ReadAll(res.Body)
+	return segment, b, nil
+}
+
+// processTS - decrypt SAMPLE-AES and fix timestamps after discontinuity
+func (r *reader) processTS(segment *Segment, b []byte) ([]byte, error) {
+	var decrypt *sampleAES
+
+	if key := segment.Key; key != nil && key.Method != "AES-128" {
+		if key.Method != "SAMPLE-AES" {
+			return nil, errors.New("hls: unsupported encryption: " + key.Method)
+		}
+
+		k, err := r.getKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if decrypt, err = newSampleAES(k, segmentIV(key, segment.Sequence)); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil, io.EOF
+	return r.filter.Process(b, decrypt, segment.Discontinuity), nil
 }
 
-func getSegment(src []byte) []byte {
-	for ok := false; !ok; {
-		ok = bytes.HasPrefix(src, []byte("#EXTINF"))
+// getInit - download fMP4 init section, returns true if it was changed
+func (r *reader) getInit(segment *Segment) (bool, error) {
+	m := segment.Map
+	if m == nil {
+		return false, errors.New("hls: no init section")
+	}
+
+	if key := m.URI + "@" + m.ByteRange; key != r.initKey {
+		b, err := r.get(m.URI, m.ByteRange)
+		if err != nil {
+			return false, err
+		}
 
-		i := bytes.IndexByte(src, '\n') + 1
-		if i == 0 {
-			return nil
+		if m.Key != nil {
+			if m.Key.Method != "AES-128" {
+				return false, errors.New("hls: unsupported encryption for fMP4: " + m.Key.Method)
+			}
+			if b, err = r.decrypt(m.Key, segment.Sequence, b); err != nil {
+				return false, err
+			}
 		}
 
-		src = src[i:]
+		changed := r.init != nil && !bytes.Equal(b, r.init)
+		r.init = b
+		r.initKey = key
+		return changed, nil
+	}
+
+	return false, nil
+}
+
+func (r *reader) decrypt(key *Key, sequence int, b []byte) ([]byte, error) {
+	k, err := r.getKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return decryptAES128(k, segmentIV(key, sequence), b)
+}
+
+func (r *reader) getKey(key *Key) ([]byte, error) {
+	if k, ok := r.keys[key.URI]; ok {
+		return k, nil
+	}
+
+	if !strings.HasPrefix(key.URI, "http") && strings.Contains(key.URI, ":") {
+		return nil, errors.New("hls: unsupported key: " + key.URI) // DRM, like skd://
+	}
+
+	k, err := r.get(key.URI, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(k) != 16 {
+		return nil, errors.New("hls: wrong key size")
+	}
+
+	r.keys[key.URI] = k
+	return k, nil
+}
+
+func (r *reader) loadPlaylist() (*Playlist, error) {
+	b, err := r.get("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	r.lastTime = time.Now()
+
+	//log.Printf("[hls] load playlist\n%s", b)
+
+	return ParsePlaylist(b), nil
+}
+
+// get - request URI relative to the media playlist with headers from the source,
+// byteRange in playlist format: length@offset
+func (r *reader) get(uri, byteRange string) ([]byte, error) {
+	ref, err := r.url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", ref.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+
+	if byteRange != "" {
+		length, offset, _ := strings.Cut(byteRange, "@")
+		n, _ := strconv.Atoi(length)
+		i, _ := strconv.Atoi(offset)
+		req.Header.Set("Range", "bytes="+strconv.Itoa(i)+"-"+strconv.Itoa(i+n-1))
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer res.Body.Close()
 
-	if i := bytes.IndexByte(src, '\n'); i > 0 {
-		return src[:i]
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		return nil, errors.New("hls: " + res.Status)
 	}
 
-	return src
+	return io.ReadAll(res.Body)
 }
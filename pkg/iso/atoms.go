@@ -261,6 +261,8 @@ func (m *Movie) WriteAudioTrack(id uint32, codec string, timescale uint32, chann
 }
 
 const (
+	TfhdBaseDataOffset        = 0x000001
+	TfhdSampleDescription     = 0x000002
 	TfhdDefaultSampleDuration = 0x000008
 	TfhdDefaultSampleSize     = 0x000010
 	TfhdDefaultSampleFlags    = 0x000020
@@ -276,7 +278,8 @@ const (
 	TrunSampleCTS        = 0x0000800
 )
 
-func (m *Movie) WriteMovieFragment(seq, tid, duration, size, flags uint32, dts uint64, cts uint32) {
+// WriteMovieFragment - fragment with one sample, trun version 1 for negative CTS
+func (m *Movie) WriteMovieFragment(seq, tid, duration, size, flags uint32, dts uint64, cts int32) {
 	m.StartAtom(Moof)
 
 	m.StartAtom(MoofMfhd)
@@ -308,7 +311,11 @@ func (m *Movie) WriteMovieFragment(seq, tid, duration, size, flags uint32, dts u
 	m.EndAtom()
 
 	m.StartAtom(MoofTrafTrun)
-	m.Skip(1) // version
+	if cts < 0 {
+		m.WriteBytes(1) // version with signed CTS
+	} else {
+		m.Skip(1) // version
+	}
 
 	if cts == 0 {
 		m.WriteUint24(TrunDataOffset) // flags
@@ -322,7 +329,7 @@ func (m *Movie) WriteMovieFragment(seq, tid, duration, size, flags uint32, dts u
 
 		// data offset: current pos + uint32 len + CTS + MDAT header len
 		m.WriteUint32(uint32(len(m.b)) + 4 + 4 + 8)
-		m.WriteUint32(cts)
+		m.WriteUint32(uint32(cts))
 	}
 
 	m.EndAtom() // TRUN
@@ -12,6 +12,12 @@ func (m *Movie) WriteVideo(codec string, width, height uint16, conf []byte) {
 		m.StartAtom("avc1")
 	case core.CodecH265:
 		m.StartAtom("hev1")
+	case core.CodecVP8:
+		m.StartAtom("vp08")
+	case core.CodecVP9:
+		m.StartAtom("vp09")
+	case core.CodecAV1:
+		m.StartAtom("av01")
 	default:
 		panic("unsupported iso video: " + codec)
 	}
@@ -37,6 +43,10 @@ func (m *Movie) WriteVideo(codec string, width, height uint16, conf []byte) {
 		m.StartAtom("avcC")
 	case core.CodecH265:
 		m.StartAtom("hvcC")
+	case core.CodecVP8, core.CodecVP9:
+		m.StartAtom("vpcC")
+	case core.CodecAV1:
+		m.StartAtom("av1C")
 	}
 	m.Write(conf)
 	m.EndAtom() // AVCC
@@ -46,6 +56,17 @@ func (m *Movie) WriteVideo(codec string, width, height uint16, conf []byte) {
 	m.WriteUint32(1)    // vSpacing
 	m.EndAtom()
 
+	m.StartAtom("clap")           // Clean Aperture, full frame by default
+	m.WriteUint32(uint32(width))  // cleanApertureWidthN
+	m.WriteUint32(1)              // cleanApertureWidthD
+	m.WriteUint32(uint32(height)) // cleanApertureHeightN
+	m.WriteUint32(1)              // cleanApertureHeightD
+	m.WriteUint32(0)              // horizOffN
+	m.WriteUint32(1)              // horizOffD
+	m.WriteUint32(0)              // vertOffN
+	m.WriteUint32(1)              // vertOffD
+	m.EndAtom()
+
 	m.EndAtom() // AVC1
 }
 
@@ -53,6 +74,8 @@ func (m *Movie) WriteAudio(codec string, channels uint16, sampleRate uint32, con
 	switch codec {
 	case core.CodecAAC, core.CodecMP3:
 		m.StartAtom("mp4a") // supported in all players and browsers
+	case core.CodecELD:
+		m.StartAtom("mp4a") // supported in FFmpeg and Apple players
 	case core.CodecFLAC:
 		m.StartAtom("fLaC") // supported in all players and browsers
 	case core.CodecOpus:
@@ -81,7 +104,7 @@ func (m *Movie) WriteAudio(codec string, channels uint16, sampleRate uint32, con
 	m.WriteFloat32(float64(sampleRate)) // sample_rate
 
 	switch codec {
-	case core.CodecAAC:
+	case core.CodecAAC, core.CodecELD:
 		m.WriteEsdsAAC(conf)
 	case core.CodecMP3:
 		m.WriteEsdsMP3()
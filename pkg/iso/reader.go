@@ -52,6 +52,7 @@ type AtomTfdt struct {
 }
 
 type AtomTrun struct {
+	SampleCount      uint32
 	DataOffset       uint32
 	FirstSampleFlags uint32
 	SamplesDuration  []uint32
@@ -86,7 +87,7 @@ func DecodeAtom(b []byte) (any, error) {
 			return DecodeAtom(data[1+3+4:])
 		}
 
-	case "avc1", "hev1":
+	case "avc1", "hev1", "hvc1":
 		b = data[6+2+2+2+4+4+4+2+2+4+4+4+2+32+2+2:]
 		atom, err := DecodeAtom(b)
 		if err != nil {
@@ -127,9 +128,14 @@ func DecodeAtom(b []byte) (any, error) {
 			TrackID: rd.ReadUint32(),
 		}
 
+		if flags&TfhdBaseDataOffset != 0 {
+			_ = rd.ReadBytes(8) // skip
+		}
+		if flags&TfhdSampleDescription != 0 {
+			_ = rd.ReadUint32() // skip
+		}
 		if flags&TfhdDefaultSampleDuration != 0 {
 			atom.SampleDuration = rd.ReadUint32()
-
 		}
 		if flags&TfhdDefaultSampleSize != 0 {
 			atom.SampleSize = rd.ReadUint32()
@@ -141,6 +147,10 @@ func DecodeAtom(b []byte) (any, error) {
 		return atom, nil
 
 	case MoofTrafTfdt:
+		if data[0] == 0 {
+			// version 0 with 32 bit decode time
+			return &AtomTfdt{DecodeTime: uint64(binary.BigEndian.Uint32(data[4:]))}, nil
+		}
 		return &AtomTfdt{DecodeTime: binary.BigEndian.Uint64(data[4:])}, nil
 
 	case MoofTrafTrun:
@@ -149,7 +159,7 @@ func DecodeAtom(b []byte) (any, error) {
 		flags := rd.ReadUint24()
 		samples := rd.ReadUint32()
 
-		atom := &AtomTrun{}
+		atom := &AtomTrun{SampleCount: samples}
 
 		if flags&TrunDataOffset != 0 {
 			atom.DataOffset = rd.ReadUint32()
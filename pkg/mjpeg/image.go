@@ -0,0 +1,156 @@
+package mjpeg
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+)
+
+// EncodeImage - encode YCbCr image to JPEG with optional scale and rotate, same as FFmpeg filters:
+// width or height -1 (or 0) keeps aspect ratio, rotate can be 90, 180, 270 (-90)
+func EncodeImage(img *image.YCbCr, width, height, rotate int) ([]byte, error) {
+	if width > 0 || height > 0 {
+		img = Resize(img, width, height)
+	}
+
+	if rotate != 0 {
+		img = Rotate(img, rotate)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := jpeg.Encode(buf, img, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Crop - copy part of 4:2:0 image, rect aligned to even values for subsampling
+func Crop(src *image.YCbCr, rect image.Rectangle) *image.YCbCr {
+	rect.Min.X &^= 1
+	rect.Min.Y &^= 1
+	rect.Max.X &^= 1
+	rect.Max.Y &^= 1
+
+	rect = rect.Add(src.Rect.Min).Intersect(src.Rect)
+	if rect.Empty() || rect == src.Rect {
+		return src
+	}
+
+	size := rect.Size()
+	dst := image.NewYCbCr(image.Rect(0, 0, size.X, size.Y), image.YCbCrSubsampleRatio420)
+
+	for y := 0; y < size.Y; y++ {
+		i := src.YOffset(rect.Min.X, rect.Min.Y+y)
+		copy(dst.Y[y*dst.YStride:], src.Y[i:i+size.X])
+	}
+
+	for y := 0; y < size.Y/2; y++ {
+		i := src.COffset(rect.Min.X, rect.Min.Y+y*2)
+		copy(dst.Cb[y*dst.CStride:], src.Cb[i:i+size.X/2])
+		copy(dst.Cr[y*dst.CStride:], src.Cr[i:i+size.X/2])
+	}
+
+	return dst
+}
+
+// Resize - scale 4:2:0 image, uses area average for downscale and nearest neighbor for upscale
+func Resize(src *image.YCbCr, width, height int) *image.YCbCr {
+	size := src.Rect.Size()
+
+	if width <= 0 && height <= 0 {
+		return src
+	}
+	if width <= 0 {
+		width = size.X * height / size.Y
+	} else if height <= 0 {
+		height = size.Y * width / size.X
+	}
+
+	// even size for 4:2:0 subsampling
+	width = max(width&^1, 2)
+	height = max(height&^1, 2)
+
+	if width == size.X && height == size.Y {
+		return src
+	}
+
+	dst := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio420)
+
+	x0, y0 := src.Rect.Min.X, src.Rect.Min.Y
+	resizePlane(
+		dst.Y, dst.YStride, width, height,
+		src.Y[y0*src.YStride+x0:], src.YStride, size.X, size.Y,
+	)
+
+	ci := y0/2*src.CStride + x0/2
+	cw, ch := (size.X+1)/2, (size.Y+1)/2
+	resizePlane(dst.Cb, dst.CStride, width/2, height/2, src.Cb[ci:], src.CStride, cw, ch)
+	resizePlane(dst.Cr, dst.CStride, width/2, height/2, src.Cr[ci:], src.CStride, cw, ch)
+
+	return dst
+}
+
+func resizePlane(dst []byte, dstStride, dstW, dstH int, src []byte, srcStride, srcW, srcH int) {
+	for y := 0; y < dstH; y++ {
+		sy0 := y * srcH / dstH
+		sy1 := max((y+1)*srcH/dstH, sy0+1)
+
+		for x := 0; x < dstW; x++ {
+			sx0 := x * srcW / dstW
+			sx1 := max((x+1)*srcW/dstW, sx0+1)
+
+			var sum, n int
+			for sy := sy0; sy < sy1; sy++ {
+				i := sy * srcStride
+				for sx := sx0; sx < sx1; sx++ {
+					sum += int(src[i+sx])
+				}
+				n += sx1 - sx0
+			}
+
+			dst[y*dstStride+x] = byte((sum + n/2) / n)
+		}
+	}
+}
+
+// Rotate - rotate 4:2:0 image clockwise by 90, 180 or 270 (-90) degrees
+func Rotate(src *image.YCbCr, angle int) *image.YCbCr {
+	size := src.Rect.Size()
+
+	var dst *image.YCbCr
+	switch angle {
+	case 90, -90, 270:
+		dst = image.NewYCbCr(image.Rect(0, 0, size.Y, size.X), image.YCbCrSubsampleRatio420)
+	case 180:
+		dst = image.NewYCbCr(image.Rect(0, 0, size.X, size.Y), image.YCbCrSubsampleRatio420)
+	default:
+		return src
+	}
+
+	x0, y0 := src.Rect.Min.X, src.Rect.Min.Y
+	rotatePlane(dst.Y, dst.YStride, src.Y[y0*src.YStride+x0:], src.YStride, size.X, size.Y, angle)
+
+	ci := y0/2*src.CStride + x0/2
+	cw, ch := (size.X+1)/2, (size.Y+1)/2
+	rotatePlane(dst.Cb, dst.CStride, src.Cb[ci:], src.CStride, cw, ch, angle)
+	rotatePlane(dst.Cr, dst.CStride, src.Cr[ci:], src.CStride, cw, ch, angle)
+
+	return dst
+}
+
+func rotatePlane(dst []byte, dstStride int, src []byte, srcStride, w, h, angle int) {
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var i int
+			switch angle {
+			case 90:
+				i = x*dstStride + h - 1 - y
+			case 180:
+				i = (h-1-y)*dstStride + w - 1 - x
+			default: // 270 or -90
+				i = (w-1-x)*dstStride + y
+			}
+			dst[i] = src[y*srcStride+x]
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package mjpeg
 
 import (
+	"image"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,3 +12,29 @@ func TestRFC2435(t *testing.T) {
 	require.Equal(t, byte(9), lqt[0])
 	require.Equal(t, byte(10), cqt[0])
 }
+
+func TestResizeRotate(t *testing.T) {
+	img := image.NewYCbCr(image.Rect(0, 0, 8, 4), image.YCbCrSubsampleRatio420)
+	for i := range img.Y {
+		img.Y[i] = byte(i)
+	}
+
+	dst := Resize(img, 4, -1)
+	require.Equal(t, image.Rect(0, 0, 4, 2), dst.Rect)
+	require.Equal(t, []byte{5, 7, 9, 11, 21, 23, 25, 27}, dst.Y)
+
+	dst = Rotate(img, 90)
+	require.Equal(t, image.Rect(0, 0, 4, 8), dst.Rect)
+	require.Equal(t, []byte{24, 16, 8, 0}, dst.Y[:4])
+
+	dst = Rotate(img, -90)
+	require.Equal(t, []byte{7, 15, 23, 31}, dst.Y[:4])
+
+	dst = Crop(img, image.Rect(2, 0, 6, 2))
+	require.Equal(t, image.Rect(0, 0, 4, 2), dst.Rect)
+	require.Equal(t, []byte{2, 3, 4, 5, 10, 11, 12, 13}, dst.Y)
+
+	b, err := EncodeImage(img, 0, 2, 180)
+	require.Nil(t, err)
+	require.Equal(t, []byte{0xFF, markerSOI}, b[:2])
+}
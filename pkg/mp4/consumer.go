@@ -2,14 +2,19 @@ package mp4
 
 import (
 	"errors"
+	"image"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
 	"github.com/AlexxIT/go2rtc/pkg/h265"
 	"github.com/AlexxIT/go2rtc/pkg/pcm"
+	"github.com/AlexxIT/go2rtc/pkg/vp8"
+	"github.com/AlexxIT/go2rtc/pkg/vp9"
 	"github.com/pion/rtp"
 )
 
@@ -20,9 +25,13 @@ type Consumer struct {
 	mu    sync.Mutex
 	start bool
 
+	keyframe chan struct{} // closed on first VP8, VP9 or AV1 keyframe
+
 	Rotate int `json:"-"`
 	ScaleX int `json:"-"`
 	ScaleY int `json:"-"`
+
+	Crop image.Rectangle `json:"-"`
 }
 
 func NewConsumer(medias []*core.Media) *Consumer {
@@ -35,6 +44,9 @@ func NewConsumer(medias []*core.Media) *Consumer {
 				Codecs: []*core.Codec{
 					{Name: core.CodecH264},
 					{Name: core.CodecH265},
+					{Name: core.CodecVP8},
+					{Name: core.CodecVP9},
+					{Name: core.CodecAV1},
 				},
 			},
 			{
@@ -42,6 +54,7 @@ func NewConsumer(medias []*core.Media) *Consumer {
 				Direction: core.DirectionSendonly,
 				Codecs: []*core.Codec{
 					{Name: core.CodecAAC},
+					{Name: core.CodecELD},
 				},
 			},
 		}
@@ -55,8 +68,9 @@ func NewConsumer(medias []*core.Media) *Consumer {
 			Medias:     medias,
 			Transport:  wr,
 		},
-		muxer: &Muxer{},
-		wr:    wr,
+		muxer:    &Muxer{},
+		wr:       wr,
+		keyframe: make(chan struct{}),
 	}
 }
 
@@ -69,15 +83,15 @@ func (c *Consumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiv
 	switch track.Codec.Name {
 	case core.CodecH264:
 		handler.Handler = func(packet *rtp.Packet) {
+			// important to use Mutex because right fragment order
+			c.mu.Lock()
 			if !c.start {
 				if !h264.IsKeyframe(packet.Payload) {
+					c.mu.Unlock()
 					return
 				}
 				c.start = true
 			}
-
-			// important to use Mutex because right fragment order
-			c.mu.Lock()
 			b := c.muxer.GetPayload(trackID, packet)
 			if n, err := c.wr.Write(b); err == nil {
 				c.Send += n
@@ -93,15 +107,15 @@ func (c *Consumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiv
 
 	case core.CodecH265:
 		handler.Handler = func(packet *rtp.Packet) {
+			// important to use Mutex because right fragment order
+			c.mu.Lock()
 			if !c.start {
 				if !h265.IsKeyframe(packet.Payload) {
+					c.mu.Unlock()
 					return
 				}
 				c.start = true
 			}
-
-			// important to use Mutex because right fragment order
-			c.mu.Lock()
 			b := c.muxer.GetPayload(trackID, packet)
 			if n, err := c.wr.Write(b); err == nil {
 				c.Send += n
@@ -115,14 +129,46 @@ func (c *Consumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiv
 			handler.Handler = h265.RepairAVCC(track.Codec, handler.Handler)
 		}
 
-	default:
+	case core.CodecVP8, core.CodecVP9, core.CodecAV1:
 		handler.Handler = func(packet *rtp.Packet) {
+			// important to use Mutex because right fragment order
+			c.mu.Lock()
 			if !c.start {
-				return
+				if !IsKeyframe(codec.Name, packet.Payload) {
+					c.mu.Unlock()
+					return
+				}
+				c.start = true
+			}
+			if c.muxer.SetKeyframe(trackID, packet.Payload) && !c.muxer.NeedKeyframe() {
+				close(c.keyframe)
+			}
+			b := c.muxer.GetPayload(trackID, packet)
+			if n, err := c.wr.Write(b); err == nil {
+				c.Send += n
+			}
+			c.mu.Unlock()
+		}
+
+		if track.Codec.IsRTP() {
+			switch track.Codec.Name {
+			case core.CodecVP8:
+				handler.Handler = vp8.RTPDepay(handler.Handler)
+			case core.CodecVP9:
+				handler.Handler = vp9.RTPDepay(handler.Handler)
+			case core.CodecAV1:
+				handler.Handler = av1.RTPDepay(handler.Handler)
 			}
+		}
 
+	default:
+		handler.Handler = func(packet *rtp.Packet) {
 			// important to use Mutex because right fragment order
 			c.mu.Lock()
+			if !c.start {
+				c.mu.Unlock()
+				return
+			}
 			b := c.muxer.GetPayload(trackID, packet)
 			if n, err := c.wr.Write(b); err == nil {
 				c.Send += n
@@ -135,6 +181,10 @@ func (c *Consumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiv
 			if track.Codec.IsRTP() {
 				handler.Handler = aac.RTPDepay(handler.Handler)
 			}
+		case core.CodecELD:
+			if track.Codec.IsRTP() {
+				handler.Handler = aac.RTPDepayFrames(aac.FrameLength(track.Codec), handler.Handler)
+			}
 		case core.CodecOpus, core.CodecMP3: // no changes
 		case core.CodecPCMA, core.CodecPCMU, core.CodecPCM, core.CodecPCML:
 			codec.Name = core.CodecFLAC
@@ -144,6 +194,14 @@ func (c *Consumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiv
 				codec.ClockRate *= 2
 			}
 			handler.Handler = pcm.FLACEncoder(track.Codec.Name, codec.ClockRate, handler.Handler)
+		case core.CodecG722:
+			// MP4 has no G.722, decode to 16 kHz PCM and pack to FLAC like other PCM codecs
+			pcmCodec := &core.Codec{Name: core.CodecPCM, ClockRate: pcm.G722SampleRate, Channels: 1}
+			codec.Name = core.CodecFLAC
+			codec.ClockRate = pcm.G722SampleRate
+			codec.Channels = 1
+			handler.Handler = pcm.FLACEncoder(core.CodecPCM, codec.ClockRate, handler.Handler)
+			handler.Handler = pcm.TranscodeHandler(pcmCodec, track.Codec, handler.Handler)
 
 		default:
 			handler.Handler = nil
@@ -164,12 +222,45 @@ func (c *Consumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiv
 	return nil
 }
 
+// waitKeyframe - VP8, VP9 and AV1 have size and config only inside keyframe
+func (c *Consumer) waitKeyframe() {
+	c.mu.Lock()
+	wait := c.muxer.NeedKeyframe()
+	c.mu.Unlock()
+
+	if wait {
+		select {
+		case <-c.keyframe:
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// MimeCodecs - codecs string with VP9 and AV1 config from the keyframe, if it was received
+func (c *Consumer) MimeCodecs() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.muxer.MimeCodecs()
+}
+
+// ContentType - waits for the keyframe like WriteTo, so VP9 and AV1 have real codecs string
+func (c *Consumer) ContentType() string {
+	c.waitKeyframe()
+	return `video/mp4; codecs="` + c.MimeCodecs() + `"`
+}
+
 func (c *Consumer) WriteTo(wr io.Writer) (int64, error) {
 	if len(c.Senders) == 1 && c.Senders[0].Codec.IsAudio() {
+		c.mu.Lock()
 		c.start = true
+		c.mu.Unlock()
 	}
 
+	c.waitKeyframe()
+
+	c.mu.Lock()
 	init, err := c.muxer.GetInit()
+	c.mu.Unlock()
 	if err != nil {
 		return 0, err
 	}
@@ -180,6 +271,9 @@ func (c *Consumer) WriteTo(wr io.Writer) (int64, error) {
 	if c.ScaleX != 0 && c.ScaleY != 0 {
 		PatchVideoScale(init, c.ScaleX, c.ScaleY)
 	}
+	if !c.Crop.Empty() {
+		PatchVideoCrop(init, c.Crop)
+	}
 
 	if _, err = wr.Write(init); err != nil {
 		return 0, err
@@ -187,3 +281,32 @@ func (c *Consumer) WriteTo(wr io.Writer) (int64, error) {
 
 	return c.wr.WriteTo(wr)
 }
+
+// Skip - drop frames until the next video keyframe, for clients that are behind the stream.
+// Timeline stays continuous because the muxer removes the gap from all tracks the same way.
+func (c *Consumer) Skip() {
+	c.mu.Lock()
+	for _, sender := range c.Senders {
+		if sender.Codec.IsVideo() {
+			c.start = false
+			c.muxer.Skip()
+			break
+		}
+	}
+	c.mu.Unlock()
+}
+
+// Time - media time of the written data in seconds (the end of the MSE timeline)
+func (c *Consumer) Time() (t float64) {
+	c.mu.Lock()
+	for i, codec := range c.muxer.codecs {
+		if codec.ClockRate == 0 {
+			continue
+		}
+		if v := float64(c.muxer.dts[i]) / float64(codec.ClockRate); v > t {
+			t = v
+		}
+	}
+	c.mu.Unlock()
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/AlexxIT/go2rtc/pkg/aac"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
 	"github.com/AlexxIT/go2rtc/pkg/iso"
 	"github.com/pion/rtp"
 )
@@ -34,6 +35,8 @@ func (d *Demuxer) Probe(init []byte) (medias []*core.Media) {
 			switch atom.Name {
 			case "avc1":
 				codec = h264.ConfigToCodec(atom.Config)
+			case "hev1", "hvc1":
+				codec = h265.ConfigToCodec(atom.Config)
 			}
 		case *iso.AtomAudio:
 			switch atom.Name {
@@ -108,9 +111,105 @@ func (d *Demuxer) Demux(data2 []byte) (trackID uint32, packets []*core.Packet) {
 			Payload: data[:size],
 		}
 
+		// composition offset for B-frames (signed in trun v1)
+		if i < len(trun.SamplesCTS) {
+			if cts := int32(trun.SamplesCTS[i]); cts != 0 {
+				core.SetCTS(packets[i], int32(float32(cts)*timeScale))
+			}
+		}
+
 		data = data[size:]
 		ts += duration
 	}
 
 	return
 }
+
+// DemuxTracks - demux one moof+mdat fragment with any number of tracks,
+// supports default sample duration and size from tfhd
+func (d *Demuxer) DemuxTracks(fragment []byte) map[uint32][]*core.Packet {
+	atoms, err := iso.DecodeAtoms(fragment)
+	if err != nil {
+		return nil
+	}
+
+	var data []byte
+	for _, atom := range atoms {
+		if mdat, ok := atom.(*iso.AtomMdat); ok {
+			data = mdat.Data
+		}
+	}
+	if data == nil {
+		return nil
+	}
+
+	// mdat should be the last atom, so offset of the data from the moof start is known
+	dataOffset := len(fragment) - len(data)
+
+	tracks := make(map[uint32][]*core.Packet)
+
+	var tfhd *iso.AtomTfhd
+	var ts uint64
+	var offset int
+
+	for _, atom := range atoms {
+		switch atom := atom.(type) {
+		case *iso.AtomTfhd:
+			tfhd = atom
+			ts = 0
+		case *iso.AtomTfdt:
+			ts = atom.DecodeTime
+		case *iso.AtomTrun:
+			if tfhd == nil {
+				continue
+			}
+
+			timeScale := float64(d.timeScales[tfhd.TrackID])
+			if timeScale == 0 {
+				continue
+			}
+
+			if atom.DataOffset != 0 {
+				offset = int(int32(atom.DataOffset)) - dataOffset
+			}
+
+			n := int(atom.SampleCount)
+			packets := tracks[tfhd.TrackID]
+
+			for i := 0; i < n; i++ {
+				duration, size := tfhd.SampleDuration, tfhd.SampleSize
+				if i < len(atom.SamplesDuration) {
+					duration = atom.SamplesDuration[i]
+				}
+				if i < len(atom.SamplesSize) {
+					size = atom.SamplesSize[i]
+				}
+
+				if offset < 0 || offset+int(size) > len(data) {
+					break
+				}
+
+				packet := &core.Packet{
+					Header:  rtp.Header{Timestamp: uint32(float64(ts) * timeScale)},
+					Payload: data[offset : offset+int(size)],
+				}
+
+				// composition offset for B-frames (signed in trun v1)
+				if i < len(atom.SamplesCTS) {
+					if cts := int32(atom.SamplesCTS[i]); cts != 0 {
+						core.SetCTS(packet, int32(float64(cts)*timeScale))
+					}
+				}
+
+				packets = append(packets, packet)
+
+				offset += int(size)
+				ts += uint64(duration)
+			}
+
+			tracks[tfhd.TrackID] = packets
+		}
+	}
+
+	return tracks
+}
@@ -3,9 +3,12 @@ package mp4
 import (
 	"bytes"
 	"encoding/binary"
+	"image"
 	"strings"
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
 )
 
 // ParseQuery - like usual parse, but with mp4 param handler
@@ -18,6 +21,9 @@ func ParseQuery(query map[string][]string) []*core.Media {
 				Codecs: []*core.Codec{
 					{Name: core.CodecH264},
 					{Name: core.CodecH265},
+					{Name: core.CodecVP8},
+					{Name: core.CodecVP9},
+					{Name: core.CodecAV1},
 				},
 			},
 			{
@@ -25,6 +31,7 @@ func ParseQuery(query map[string][]string) []*core.Media {
 				Direction: core.DirectionSendonly,
 				Codecs: []*core.Codec{
 					{Name: core.CodecAAC},
+					{Name: core.CodecELD},
 				},
 			},
 		}
@@ -38,6 +45,7 @@ func ParseQuery(query map[string][]string) []*core.Media {
 			&core.Codec{Name: core.CodecPCMU},
 			&core.Codec{Name: core.CodecPCM},
 			&core.Codec{Name: core.CodecPCML},
+			&core.Codec{Name: core.CodecG722},
 		)
 
 		if v[0] == "flac" {
@@ -67,15 +75,28 @@ func ParseCodecs(codecs string, parseAudio bool) (medias []*core.Media) {
 		case MimeH265:
 			codec := &core.Codec{Name: core.CodecH265}
 			videos = append(videos, codec)
+		case MimeVP8:
+			codec := &core.Codec{Name: core.CodecVP8}
+			videos = append(videos, codec)
+		case MimeVP9:
+			codec := &core.Codec{Name: core.CodecVP9}
+			videos = append(videos, codec)
+		case MimeAV1:
+			codec := &core.Codec{Name: core.CodecAV1}
+			videos = append(videos, codec)
 		case MimeAAC:
 			codec := &core.Codec{Name: core.CodecAAC}
 			audios = append(audios, codec)
+		case MimeELD:
+			codec := &core.Codec{Name: core.CodecELD}
+			audios = append(audios, codec)
 		case MimeFlac:
 			audios = append(audios,
 				&core.Codec{Name: core.CodecPCMA},
 				&core.Codec{Name: core.CodecPCMU},
 				&core.Codec{Name: core.CodecPCM},
 				&core.Codec{Name: core.CodecPCML},
+				&core.Codec{Name: core.CodecG722},
 			)
 		case MimeOpus:
 			codec := &core.Codec{Name: core.CodecOpus}
@@ -164,3 +185,57 @@ func PatchVideoScale(init []byte, scaleX, scaleY int) bool {
 
 	return true
 }
+
+// PatchVideoCrop - update video clean aperture and track size.
+// Clean aperture supported by Safari and QuickTime, other players show full frame.
+func PatchVideoCrop(init []byte, crop image.Rectangle) bool {
+	i := bytes.Index(init, []byte("clap"))
+	if i < 0 || crop.Empty() {
+		return false
+	}
+	i += 4
+
+	width := int(binary.BigEndian.Uint32(init[i:]))
+	height := int(binary.BigEndian.Uint32(init[i+8:]))
+
+	crop = crop.Intersect(image.Rect(0, 0, width, height))
+	if crop.Empty() {
+		return false
+	}
+
+	// offsets from the frame center, in half pixels
+	binary.BigEndian.PutUint32(init[i:], uint32(crop.Dx()))
+	binary.BigEndian.PutUint32(init[i+8:], uint32(crop.Dy()))
+	binary.BigEndian.PutUint32(init[i+16:], uint32(2*crop.Min.X+crop.Dx()-width))
+	binary.BigEndian.PutUint32(init[i+20:], 2)
+	binary.BigEndian.PutUint32(init[i+24:], uint32(2*crop.Min.Y+crop.Dy()-height))
+	binary.BigEndian.PutUint32(init[i+28:], 2)
+
+	// seek to track width and height after video matrix
+	if i = bytes.Index(init, []byte("vide")); i > 0 {
+		i -= 4 + 3 + 1 + 8 + 32 + 8 + 4 + 4
+		binary.BigEndian.PutUint32(init[i:], uint32(crop.Dx())<<16)
+		binary.BigEndian.PutUint32(init[i+4:], uint32(crop.Dy())<<16)
+	}
+
+	return true
+}
+
+// VideoSize - video size from SPS in the codec FmtpLine, zero if unknown
+func VideoSize(codec *core.Codec) (width, height uint16) {
+	switch codec.Name {
+	case core.CodecH264:
+		if sps, _ := h264.GetParameterSet(codec.FmtpLine); sps != nil {
+			if s := h264.DecodeSPS(sps); s != nil {
+				return s.Width(), s.Height()
+			}
+		}
+	case core.CodecH265:
+		if _, sps, _ := h265.GetParameterSet(codec.FmtpLine); sps != nil {
+			if s := h265.DecodeSPS(sps); s != nil {
+				return s.Width(), s.Height()
+			}
+		}
+	}
+	return 0, 0
+}
@@ -1,6 +1,9 @@
 package mp4
 
 import (
+	"fmt"
+
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
 )
@@ -9,8 +12,12 @@ const (
 	MimeH264 = "avc1.640029"
 	MimeH265 = "hvc1.1.6.L153.B0"
 	MimeAAC  = "mp4a.40.2"
+	MimeELD  = "mp4a.40.39"
 	MimeFlac = "flac"
 	MimeOpus = "opus"
+	MimeVP8  = "vp8"
+	MimeVP9  = "vp09.00.40.08"
+	MimeAV1  = "av01.0.08M.08"
 )
 
 func MimeCodecs(codecs []*core.Codec) string {
@@ -28,8 +35,18 @@ func MimeCodecs(codecs []*core.Codec) string {
 			// H.265 profile=main level=5.1
 			// hvc1 - supported in Safari, hev1 - doesn't, both supported in Chrome
 			s += MimeH265
+		case core.CodecVP8:
+			s += MimeVP8
+		case core.CodecVP9:
+			// VP9 profile=0 level=4.0 8bit
+			s += MimeVP9
+		case core.CodecAV1:
+			// AV1 profile=main level=4.0 8bit
+			s += MimeAV1
 		case core.CodecAAC:
 			s += MimeAAC
+		case core.CodecELD:
+			s += MimeELD
 		case core.CodecOpus:
 			s += MimeOpus
 		case core.CodecFLAC:
@@ -40,6 +57,29 @@ func MimeCodecs(codecs []*core.Codec) string {
 	return s
 }
 
+// MimeVP9Config - codecs string from VP Codec Configuration Box (vpcC)
+func MimeVP9Config(config []byte) string {
+	// profile, level, bit depth
+	return fmt.Sprintf("vp09.%02d.%02d.%02d", config[4], config[5], config[6]>>4)
+}
+
+// MimeAV1Config - codecs string from sequence header
+func MimeAV1Config(seq *av1.SequenceHeader) string {
+	tier := "M"
+	if seq.Tier != 0 {
+		tier = "H"
+	}
+
+	depth := 8
+	if seq.HighBitdepth {
+		if depth = 10; seq.TwelveBit {
+			depth = 12
+		}
+	}
+
+	return fmt.Sprintf("av01.%d.%02d%s.%02d", seq.Profile, seq.Level, tier, depth)
+}
+
 func ContentType(codecs []*core.Codec) string {
 	return `video/mp4; codecs="` + MimeCodecs(codecs) + `"`
 }
@@ -3,10 +3,14 @@ package mp4
 import (
 	"encoding/hex"
 
+	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
 	"github.com/AlexxIT/go2rtc/pkg/h265"
 	"github.com/AlexxIT/go2rtc/pkg/iso"
+	"github.com/AlexxIT/go2rtc/pkg/vp8"
+	"github.com/AlexxIT/go2rtc/pkg/vp9"
 	"github.com/pion/rtp"
 )
 
@@ -15,12 +19,74 @@ type Muxer struct {
 	dts    []uint64
 	pts    []uint32
 	codecs []*core.Codec
+
+	keyframes [][]byte // first keyframe for codecs with config inside bitstream
+	skips     []bool   // drop the gap before the next sample after skipped frames
 }
 
 func (m *Muxer) AddTrack(codec *core.Codec) {
 	m.dts = append(m.dts, 0)
 	m.pts = append(m.pts, 0)
 	m.codecs = append(m.codecs, codec)
+	m.keyframes = append(m.keyframes, nil)
+	m.skips = append(m.skips, false)
+}
+
+// SetKeyframe - save first keyframe for VP8, VP9 and AV1, so GetInit can use real
+// video size and config. Return true if it is the first keyframe for the track.
+func (m *Muxer) SetKeyframe(trackID byte, payload []byte) bool {
+	if m.keyframes[trackID] != nil {
+		return false
+	}
+	// payload buffer can be reused by the source after the handler
+	m.keyframes[trackID] = append([]byte(nil), payload...)
+	return true
+}
+
+// NeedKeyframe - GetInit should wait first keyframe for VP8, VP9 and AV1 tracks
+func (m *Muxer) NeedKeyframe() bool {
+	for i, codec := range m.codecs {
+		switch codec.Name {
+		case core.CodecVP8, core.CodecVP9, core.CodecAV1:
+			if m.keyframes[i] == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MimeCodecs - codecs string with real VP9 and AV1 profile, level and bit depth from the keyframe
+func (m *Muxer) MimeCodecs() string {
+	var s string
+
+	for i, codec := range m.codecs {
+		var mime string
+
+		switch codec.Name {
+		case core.CodecVP9:
+			if config := vp9.KeyframeConfig(m.keyframes[i]); config != nil {
+				mime = MimeVP9Config(config)
+			}
+		case core.CodecAV1:
+			if seq := av1.FindSequenceHeader(m.keyframes[i]); seq != nil {
+				mime = MimeAV1Config(seq)
+			}
+		}
+
+		if mime == "" {
+			if mime = MimeCodecs([]*core.Codec{codec}); mime == "" {
+				continue
+			}
+		}
+
+		if s != "" {
+			s += ","
+		}
+		s += mime
+	}
+
+	return s
 }
 
 func (m *Muxer) GetInit() ([]byte, error) {
@@ -81,7 +147,39 @@ func (m *Muxer) GetInit() ([]byte, error) {
 				uint32(i+1), codec.Name, codec.ClockRate, width, height, h265.EncodeConfig(vps, sps, pps),
 			)
 
-		case core.CodecAAC:
+		case core.CodecVP8:
+			width, height := vp8.DecodeSize(m.keyframes[i])
+			if width == 0 || height == 0 {
+				width, height = 1920, 1080
+			}
+
+			mv.WriteVideoTrack(
+				uint32(i+1), codec.Name, codec.ClockRate, width, height, vp8.EncodeConfig(m.keyframes[i]),
+			)
+
+		case core.CodecVP9:
+			width, height := vp9.DecodeSize(m.keyframes[i])
+			config := vp9.KeyframeConfig(m.keyframes[i])
+			if width == 0 || height == 0 || config == nil {
+				// no keyframe, real size will be taken from keyframe by decoder
+				width, height, config = 1920, 1080, vp9.EncodeConfig(0, 40)
+			}
+
+			mv.WriteVideoTrack(
+				uint32(i+1), codec.Name, codec.ClockRate, width, height, config,
+			)
+
+		case core.CodecAV1:
+			width, height, config := uint16(1920), uint16(1080), av1.EncodeConfig(8)
+			if seq := av1.FindSequenceHeader(m.keyframes[i]); seq != nil {
+				width, height, config = seq.Width, seq.Height, seq.EncodeConfig()
+			}
+
+			mv.WriteVideoTrack(
+				uint32(i+1), codec.Name, codec.ClockRate, width, height, config,
+			)
+
+		case core.CodecAAC, core.CodecELD:
 			s := core.Between(codec.FmtpLine, "config=", ";")
 			b, err := hex.DecodeString(s)
 			if err != nil {
@@ -118,6 +216,14 @@ func (m *Muxer) Reset() {
 	}
 }
 
+// Skip - next sample of each track continues right after the previous one,
+// so audio and video timelines stay in sync after skipped frames
+func (m *Muxer) Skip() {
+	for i := range m.skips {
+		m.skips[i] = true
+	}
+}
+
 func (m *Muxer) GetPayload(trackID byte, packet *rtp.Packet) []byte {
 	codec := m.codecs[trackID]
 
@@ -126,18 +232,17 @@ func (m *Muxer) GetPayload(trackID byte, packet *rtp.Packet) []byte {
 	duration := packet.Timestamp - m.pts[trackID]
 	m.pts[trackID] = packet.Timestamp
 
+	if m.skips[trackID] {
+		m.skips[trackID] = false
+		duration = 0 // will be replaced with minimum duration
+	}
+
 	// flags important for Apple Finder video preview
 	var flags uint32
 
 	switch codec.Name {
-	case core.CodecH264:
-		if h264.IsKeyframe(packet.Payload) {
-			flags = iso.SampleVideoIFrame
-		} else {
-			flags = iso.SampleVideoNonIFrame
-		}
-	case core.CodecH265:
-		if h265.IsKeyframe(packet.Payload) {
+	case core.CodecH264, core.CodecH265, core.CodecVP8, core.CodecVP9, core.CodecAV1:
+		if IsKeyframe(codec.Name, packet.Payload) {
 			flags = iso.SampleVideoIFrame
 		} else {
 			flags = iso.SampleVideoNonIFrame
@@ -145,6 +250,9 @@ func (m *Muxer) GetPayload(trackID byte, packet *rtp.Packet) []byte {
 	case core.CodecAAC:
 		duration = 1024         // important for Apple Finder and QuickTime
 		flags = iso.SampleAudio // not important?
+	case core.CodecELD:
+		duration = aac.FrameLength(codec)
+		flags = iso.SampleAudio
 	default:
 		flags = iso.SampleAudio // important for FLAC on Android Telegram
 	}
@@ -159,8 +267,7 @@ func (m *Muxer) GetPayload(trackID byte, packet *rtp.Packet) []byte {
 
 	mv := iso.NewMovie(1024 + size)
 	mv.WriteMovieFragment(
-		// ExtensionProfile - wrong place for CTS (supported by mpegts.Demuxer)
-		m.index, uint32(trackID+1), duration, uint32(size), flags, m.dts[trackID], uint32(packet.ExtensionProfile),
+		m.index, uint32(trackID+1), duration, uint32(size), flags, m.dts[trackID], core.GetCTS(packet),
 	)
 	mv.WriteData(packet.Payload)
 
@@ -170,3 +277,20 @@ func (m *Muxer) GetPayload(trackID byte, packet *rtp.Packet) []byte {
 
 	return mv.Bytes()
 }
+
+// IsKeyframe - check keyframe for video codecs supported by MP4 muxer
+func IsKeyframe(codecName string, payload []byte) bool {
+	switch codecName {
+	case core.CodecH264:
+		return h264.IsKeyframe(payload)
+	case core.CodecH265:
+		return h265.IsKeyframe(payload)
+	case core.CodecVP8:
+		return vp8.IsKeyframe(payload)
+	case core.CodecVP9:
+		return vp9.IsKeyframe(payload)
+	case core.CodecAV1:
+		return av1.IsKeyframe(payload)
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package mpegts
+
+import (
+	"github.com/AlexxIT/go2rtc/pkg/bits"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+// AC-3 and E-AC-3 in MPEG-TS (ATSC A/52 Annex A, ETSI TS 102 366 Annex B)
+// ATSC: StreamType=0x81 (AC-3) and 0x87 (E-AC-3) with registration descriptor
+// DVB: StreamType=0x06 (private) with AC-3 descriptor (0x6A) or enhanced AC-3 descriptor (0x7A)
+
+var ac3Info = []byte{ // registration_descriptor
+	0x05,               // descriptor_tag
+	0x04,               // descriptor_length
+	'A', 'C', '-', '3', // format_identifier
+}
+
+var eac3Info = []byte{ // registration_descriptor
+	0x05,               // descriptor_tag
+	0x04,               // descriptor_length
+	'E', 'A', 'C', '3', // format_identifier
+}
+
+const (
+	descriptorAC3  = 0x6A
+	descriptorEAC3 = 0x7A
+)
+
+var ac3Rates = [3]uint32{48000, 44100, 32000}
+var eac3Rates = [3]uint32{24000, 22050, 16000} // reduced sample rates (fscod2)
+var ac3Channels = [8]uint8{2, 1, 2, 3, 3, 4, 4, 5}
+
+// AC3ToCodec - codec from syncframe header, support AC-3 and E-AC-3 frames
+func AC3ToCodec(b []byte) *core.Codec {
+	if len(b) < 8 || b[0] != 0x0B || b[1] != 0x77 {
+		return nil
+	}
+
+	codec := &core.Codec{PayloadType: core.PayloadTypeRAW}
+
+	var acmod, lfeon byte
+
+	if bsid := b[5] >> 3; bsid <= 10 {
+		// AC-3: syncword (16), crc1 (16), fscod (2), frmsizecod (6), bsid (5), bsmod (3)
+		fscod := b[4] >> 6
+		if fscod == 3 {
+			return nil
+		}
+		codec.Name = core.CodecAC3
+		codec.ClockRate = ac3Rates[fscod]
+
+		r := bits.NewReader(b[6:])
+		acmod = r.ReadBits8(3)
+		if acmod&1 != 0 && acmod != 1 {
+			_ = r.ReadBits8(2) // cmixlev
+		}
+		if acmod&4 != 0 {
+			_ = r.ReadBits8(2) // surmixlev
+		}
+		if acmod == 2 {
+			_ = r.ReadBits8(2) // dsurmod
+		}
+		lfeon = r.ReadBit()
+	} else if bsid <= 16 {
+		// E-AC-3: syncword (16), strmtyp (2), substreamid (3), frmsiz (11)
+		r := bits.NewReader(b[4:])
+		fscod := r.ReadBits8(2)
+		if fscod == 3 {
+			codec.ClockRate = eac3Rates[r.ReadBits8(2)%3]
+		} else {
+			codec.ClockRate = ac3Rates[fscod]
+			_ = r.ReadBits8(2) // numblkscod
+		}
+		codec.Name = core.CodecEAC3
+
+		acmod = r.ReadBits8(3)
+		lfeon = r.ReadBit()
+	} else {
+		return nil
+	}
+
+	codec.Channels = ac3Channels[acmod] + lfeon
+
+	return codec
+}
@@ -0,0 +1,15 @@
+package mpegts
+
+import "github.com/AlexxIT/go2rtc/pkg/av1"
+
+// https://aomediacodec.github.io/av1-mpeg2-ts/
+var av1Info = append([]byte{ // registration_descriptor
+	0x05,               // descriptor_tag
+	0x04,               // descriptor_length
+	'A', 'V', '0', '1', // format_identifier
+	0x80, // av1_video_descriptor tag
+	0x04, // descriptor_length
+}, av1.EncodeConfig(8)...) // same fields as in av1C box
+
+// av1TemporalDelimiter - each PES payload should start from TD OBU
+var av1TemporalDelimiter = []byte{0x12, 0x00}
@@ -1,9 +1,12 @@
 package mpegts
 
 import (
+	"encoding/hex"
+	"errors"
 	"io"
 
 	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
 	"github.com/AlexxIT/go2rtc/pkg/h265"
@@ -16,14 +19,34 @@ type Consumer struct {
 	wr    *core.WriteBuffer
 }
 
-func NewConsumer() *Consumer {
-	medias := []*core.Media{
+// NewConsumer - with default medias for HLS and players, if medias are not set.
+// PCMA and PCMU are supported by the muxer, but only on request (VLC, FFmpeg)
+func NewConsumer(medias ...*core.Media) *Consumer {
+	if medias == nil {
+		medias = defaultMedias()
+	}
+	wr := core.NewWriteBuffer(nil)
+	return &Consumer{
+		core.Connection{
+			ID:         core.NewID(),
+			FormatName: "mpegts",
+			Medias:     medias,
+			Transport:  wr,
+		},
+		NewMuxer(),
+		wr,
+	}
+}
+
+func defaultMedias() []*core.Media {
+	return []*core.Media{
 		{
 			Kind:      core.KindVideo,
 			Direction: core.DirectionSendonly,
 			Codecs: []*core.Codec{
 				{Name: core.CodecH264},
 				{Name: core.CodecH265},
+				{Name: core.CodecAV1},
 			},
 		},
 		{
@@ -31,20 +54,14 @@ func NewConsumer() *Consumer {
 			Direction: core.DirectionSendonly,
 			Codecs: []*core.Codec{
 				{Name: core.CodecAAC},
+				{Name: core.CodecELD},
+				{Name: core.CodecMP3},
+				{Name: core.CodecAC3},
+				{Name: core.CodecEAC3},
+				{Name: core.CodecOpus},
 			},
 		},
 	}
-	wr := core.NewWriteBuffer(nil)
-	return &Consumer{
-		core.Connection{
-			ID:         core.NewID(),
-			FormatName: "mpegts",
-			Medias:     medias,
-			Transport:  wr,
-		},
-		NewMuxer(),
-		wr,
-	}
 }
 
 func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Receiver) error {
@@ -55,7 +72,7 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 		pid := c.muxer.AddTrack(StreamTypeH264)
 
 		sender.Handler = func(pkt *rtp.Packet) {
-			b := c.muxer.GetPayload(pid, pkt.Timestamp, pkt.Payload)
+			b := c.muxer.GetPayloadCTS(pid, pkt.Timestamp, core.GetCTS(pkt), pkt.Payload)
 			if n, err := c.wr.Write(b); err == nil {
 				c.Send += n
 			}
@@ -71,7 +88,7 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 		pid := c.muxer.AddTrack(StreamTypeH265)
 
 		sender.Handler = func(pkt *rtp.Packet) {
-			b := c.muxer.GetPayload(pid, pkt.Timestamp, pkt.Payload)
+			b := c.muxer.GetPayloadCTS(pid, pkt.Timestamp, core.GetCTS(pkt), pkt.Payload)
 			if n, err := c.wr.Write(b); err == nil {
 				c.Send += n
 			}
@@ -81,6 +98,20 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 			sender.Handler = h265.RTPDepay(track.Codec, sender.Handler)
 		}
 
+	case core.CodecAV1:
+		pid := c.muxer.AddTrack(StreamTypePrivateAV1)
+
+		sender.Handler = func(pkt *rtp.Packet) {
+			b := c.muxer.GetPayload(pid, pkt.Timestamp, pkt.Payload)
+			if n, err := c.wr.Write(b); err == nil {
+				c.Send += n
+			}
+		}
+
+		if track.Codec.IsRTP() {
+			sender.Handler = av1.RTPDepay(sender.Handler)
+		}
+
 	case core.CodecAAC:
 		pid := c.muxer.AddTrack(StreamTypeAAC)
 
@@ -100,6 +131,67 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 		} else {
 			sender.Handler = aac.EncodeToADTS(track.Codec, sender.Handler)
 		}
+
+	case core.CodecELD:
+		conf, _ := hex.DecodeString(core.Between(track.Codec.FmtpLine, "config=", ";"))
+		if ascBits(conf) == 0 {
+			return ErrLATMConfig
+		}
+
+		pid := c.muxer.AddTrack(StreamTypeAACLATM)
+
+		dt := 90000 / float64(track.Codec.ClockRate)
+
+		sender.Handler = func(pkt *rtp.Packet) {
+			pts := uint32(float64(pkt.Timestamp) * dt)
+			frame, _ := LATMEncode(conf, pkt.Payload) // config is checked above
+			b := c.muxer.GetPayload(pid, pts, frame)
+			if n, err := c.wr.Write(b); err == nil {
+				c.Send += n
+			}
+		}
+
+		if track.Codec.IsRTP() {
+			sender.Handler = aac.RTPDepayFrames(aac.FrameLength(track.Codec), sender.Handler)
+		}
+
+	case core.CodecOpus:
+		pid := c.muxer.AddTrack(StreamTypePrivateOPUS)
+
+		dt := 90000 / float64(track.Codec.ClockRate)
+
+		sender.Handler = func(pkt *rtp.Packet) {
+			pts := uint32(float64(pkt.Timestamp) * dt)
+			b := c.muxer.GetPayload(pid, pts, AppendOPUSPacket(nil, pkt.Payload))
+			if n, err := c.wr.Write(b); err == nil {
+				c.Send += n
+			}
+		}
+
+	case core.CodecMP3, core.CodecAC3, core.CodecEAC3, core.CodecPCMA, core.CodecPCMU:
+		pid := c.muxer.AddTrack(StreamType(track.Codec))
+
+		dt := 90000 / float64(track.Codec.ClockRate)
+
+		// MPEG audio over RTP (RFC 2250) has 4 bytes header and 90000 clock rate
+		var skip int
+		if track.Codec.Name == core.CodecMP3 && track.Codec.IsRTP() && track.Codec.ClockRate == 90000 {
+			skip = 4
+		}
+
+		sender.Handler = func(pkt *rtp.Packet) {
+			if len(pkt.Payload) <= skip {
+				return
+			}
+			pts := uint32(float64(pkt.Timestamp) * dt)
+			b := c.muxer.GetPayload(pid, pts, pkt.Payload[skip:])
+			if n, err := c.wr.Write(b); err == nil {
+				c.Send += n
+			}
+		}
+
+	default:
+		return errors.New("mpegts: unsupported codec: " + track.Codec.Name)
 	}
 
 	sender.HandleRTP(track)
@@ -6,7 +6,9 @@ import (
 	"io"
 
 	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/bits"
+	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264/annexb"
 	"github.com/pion/rtp"
 )
@@ -66,6 +68,16 @@ func (d *Demuxer) ReadPacket(rd io.Reader) (*rtp.Packet, error) {
 	}
 }
 
+// Config - last codec config from the stream of this type (only LATM for now)
+func (d *Demuxer) Config(streamType byte) []byte {
+	for _, pes := range d.pes {
+		if pes.StreamType == streamType {
+			return pes.Config
+		}
+	}
+	return nil
+}
+
 func (d *Demuxer) readPacketHeader() (pid uint16, start bool, err error) {
 	d.reset()
 
@@ -167,8 +179,24 @@ func (d *Demuxer) readPMT() {
 		size = d.readBits(10)      // ES Info length
 		info := d.readBytes(byte(size))
 
-		if streamType == StreamTypePrivate && bytes.HasPrefix(info, opusInfo) {
-			streamType = StreamTypePrivateOPUS
+		switch streamType {
+		case StreamTypePrivate:
+			switch {
+			case bytes.HasPrefix(info, opusInfo):
+				streamType = StreamTypePrivateOPUS
+			case bytes.HasPrefix(info, av1Info[:6]):
+				streamType = StreamTypePrivateAV1
+			case hasDescriptor(info, descriptorAC3) || bytes.HasPrefix(info, ac3Info):
+				streamType = StreamTypeAC3
+			case hasDescriptor(info, descriptorEAC3) || bytes.HasPrefix(info, eac3Info):
+				streamType = StreamTypeEAC3
+			}
+		case StreamTypeMP3LSF:
+			streamType = StreamTypeMP3
+		case StreamTypeH264SampleAES:
+			streamType = StreamTypeH264
+		case StreamTypeAACSampleAES:
+			streamType = StreamTypeAAC
 		}
 
 		d.pes[pid] = &PES{StreamType: streamType}
@@ -249,6 +277,21 @@ func (d *Demuxer) readPES(pid uint16, start bool) *rtp.Packet {
 	return nil
 }
 
+// hasDescriptor - search descriptor with tag in ES info loop
+func hasDescriptor(info []byte, tag byte) bool {
+	for len(info) >= 2 {
+		if info[0] == tag {
+			return true
+		}
+		size := 2 + int(info[1])
+		if size > len(info) {
+			break
+		}
+		info = info[size:]
+	}
+	return false
+}
+
 func (d *Demuxer) reset() {
 	d.pos = 0
 	d.end = PacketSize
@@ -324,14 +367,22 @@ const (
 
 // https://en.wikipedia.org/wiki/Program-specific_information#Elementary_stream_types
 const (
-	StreamTypeMetadata    = 0    // Reserved
-	StreamTypePrivate     = 0x06 // PCMU or PCMA or FLAC from FFmpeg
-	StreamTypeAAC         = 0x0F
-	StreamTypeH264        = 0x1B
-	StreamTypeH265        = 0x24
-	StreamTypePCMATapo    = 0x90
-	StreamTypePCMUTapo    = 0x91
-	StreamTypePrivateOPUS = 0xEB
+	StreamTypeMetadata      = 0    // Reserved
+	StreamTypeMP3           = 0x03 // MPEG-1 audio
+	StreamTypeMP3LSF        = 0x04 // MPEG-2 audio, demuxed as StreamTypeMP3
+	StreamTypePrivate       = 0x06 // PCMU or PCMA or FLAC from FFmpeg
+	StreamTypeAAC           = 0x0F
+	StreamTypeAACLATM       = 0x11 // AAC-ELD in LOAS/LATM
+	StreamTypeH264          = 0x1B
+	StreamTypeH265          = 0x24
+	StreamTypeAC3           = 0x81 // ATSC AC-3
+	StreamTypeEAC3          = 0x87 // ATSC E-AC-3
+	StreamTypePCMATapo      = 0x90
+	StreamTypePCMUTapo      = 0x91
+	StreamTypeAACSampleAES  = 0xCF // HLS SAMPLE-AES, demuxed as StreamTypeAAC after decryption
+	StreamTypeH264SampleAES = 0xDB // HLS SAMPLE-AES, demuxed as StreamTypeH264 after decryption
+	StreamTypePrivateOPUS   = 0xEB
+	StreamTypePrivateAV1    = 0xEC
 )
 
 // PES - Packetized Elementary Stream
@@ -344,6 +395,7 @@ type PES struct {
 	DTS        uint32
 	Payload    []byte // from PES body
 	Size       int    // from PES header, can be 0
+	Config     []byte // from LATM StreamMuxConfig
 
 	wr *bits.Writer
 }
@@ -370,12 +422,20 @@ func (p *PES) GetPacket() (pkt *rtp.Packet) {
 
 		if p.DTS != 0 {
 			pkt.Timestamp = p.DTS
-			// wrong place for CTS, but we don't have another one
-			pkt.ExtensionProfile = uint16(p.PTS - p.DTS)
+			core.SetCTS(pkt, int32(p.PTS-p.DTS))
 		} else {
 			pkt.Timestamp = p.PTS
 		}
 
+	case StreamTypePrivateAV1:
+		pkt = &rtp.Packet{
+			Header: rtp.Header{
+				PayloadType: p.StreamType,
+				Timestamp:   p.PTS,
+			},
+			Payload: av1.TrimTemporalDelimiter(p.Payload),
+		}
+
 	case StreamTypeAAC:
 		p.Sequence++
 
@@ -393,7 +453,25 @@ func (p *PES) GetPacket() (pkt *rtp.Packet) {
 
 		//p.Timestamp += aac.RTPTimeSize(pkt.Payload) // update next timestamp!
 
-	case StreamTypePCMATapo, StreamTypePCMUTapo:
+	case StreamTypeAACLATM:
+		p.Sequence++
+
+		pkt = &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         true,
+				PayloadType:    p.StreamType,
+				SequenceNumber: p.Sequence,
+				Timestamp:      p.PTS,
+			},
+		}
+
+		var conf []byte
+		if conf, pkt.Payload = LATMToRTP(p.Payload); conf != nil {
+			p.Config = conf
+		}
+
+	case StreamTypePCMATapo, StreamTypePCMUTapo, StreamTypeMP3, StreamTypeAC3, StreamTypeEAC3:
 		p.Sequence++
 
 		pkt = &rtp.Packet{
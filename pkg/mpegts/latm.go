@@ -0,0 +1,231 @@
+package mpegts
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/bits"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+// AAC-ELD can't be stored in ADTS, so it uses LOAS/LATM (ISO/IEC 14496-3, 1.7.3)
+// with StreamMuxConfig (audioMuxVersion=0) inside each frame.
+
+const loasSyncWord = 0x2B7
+
+var ErrLATMConfig = errors.New("mpegts: unsupported LATM config")
+
+// LATMEncode - raw AU to LOAS AudioSyncStream frame, only AAC-ELD config without LD-SBR is supported
+func LATMEncode(conf, au []byte) ([]byte, error) {
+	size := ascBits(conf)
+	if size == 0 {
+		return nil, ErrLATMConfig
+	}
+
+	wr := bits.NewWriter(nil)
+	wr.WriteBits16(loasSyncWord, 11)
+	wr.WriteBits16(0, 13) // audioMuxLengthBytes, will be set later
+
+	wr.WriteBit(0)      // useSameStreamMux
+	wr.WriteBit(0)      // audioMuxVersion
+	wr.WriteBit(1)      // allStreamsSameTimeFraming
+	wr.WriteBits8(0, 6) // numSubFrames
+	wr.WriteBits8(0, 4) // numProgram
+	wr.WriteBits8(0, 3) // numLayer
+
+	// AudioSpecificConfig without padding bits
+	for i := 0; i < size; i++ {
+		wr.WriteBit(conf[i/8] >> (7 - i%8) & 1)
+	}
+
+	wr.WriteBits8(0, 3)    // frameLengthType
+	wr.WriteBits8(0xFF, 8) // latmBufferFullness
+	wr.WriteBit(0)         // otherDataPresent
+	wr.WriteBit(0)         // crcCheckPresent
+
+	// PayloadLengthInfo
+	for n := len(au); ; n -= 255 {
+		if n < 255 {
+			wr.WriteBits8(byte(n), 8)
+			break
+		}
+		wr.WriteBits8(255, 8)
+	}
+
+	for _, b := range au {
+		wr.WriteBits8(b, 8)
+	}
+
+	b := wr.Bytes()
+	n := len(b) - 3
+	b[1] |= byte(n >> 8)
+	b[2] = byte(n)
+	return b, nil
+}
+
+// LATMDecode - LOAS frames to config and raw AUs, support only frames
+// with StreamMuxConfig (audioMuxVersion=0) and one AU per frame
+func LATMDecode(b []byte) (conf []byte, units [][]byte) {
+	for len(b) >= 3 {
+		if binary.BigEndian.Uint16(b)>>5 != loasSyncWord {
+			return
+		}
+
+		n := 3 + int(binary.BigEndian.Uint16(b[1:])&0x1FFF)
+		if n > len(b) {
+			return
+		}
+
+		frame := b[3:n]
+		b = b[n:]
+
+		// useSameStreamMux=0 and audioMuxVersion=0, so the config is byte aligned
+		if len(frame) < 2 || frame[0]&0xC0 != 0 {
+			continue
+		}
+
+		size := ascBits(frame[2:])
+		if size == 0 {
+			continue
+		}
+
+		rd := bits.NewReader(frame[2:])
+		for i := 0; i < size; i++ {
+			_ = rd.ReadBit()
+		}
+
+		if rd.ReadBits8(3) != 0 { // frameLengthType
+			continue
+		}
+		_ = rd.ReadBits8(8) // latmBufferFullness
+		_ = rd.ReadBit()    // otherDataPresent, ignore
+		_ = rd.ReadBit()    // crcCheckPresent, ignore
+
+		var auSize int
+		for {
+			i := rd.ReadBits8(8)
+			auSize += int(i)
+			if i != 255 {
+				break
+			}
+		}
+
+		au := make([]byte, auSize)
+		for i := range au {
+			au[i] = rd.ReadBits8(8)
+		}
+
+		if rd.EOF {
+			continue
+		}
+
+		conf = make([]byte, (size+7)/8)
+		copy(conf, frame[2:])
+		if size%8 != 0 {
+			conf[len(conf)-1] &= 0xFF << (8 - size%8)
+		}
+
+		units = append(units, au)
+	}
+
+	return
+}
+
+// LATMToRTP - LOAS frames to RFC 3640 payload, same as aac.ADTStoRTP
+func LATMToRTP(b []byte) (conf []byte, payload []byte) {
+	conf, units := LATMDecode(b)
+	if units == nil {
+		return nil, nil
+	}
+
+	payload = make([]byte, 2, 2+2*len(units))
+	for _, au := range units {
+		payload = binary.BigEndian.AppendUint16(payload, uint16(len(au))<<3)
+	}
+	binary.BigEndian.PutUint16(payload, uint16(2*len(units))<<3)
+	for _, au := range units {
+		payload = append(payload, au...)
+	}
+	return
+}
+
+// LATMToCodec - codec from AudioSpecificConfig of LOAS frames (check LATMToRTP)
+func LATMToCodec(conf []byte) *core.Codec {
+	if conf == nil {
+		return nil
+	}
+
+	objType, _, channels, sampleRate := aac.DecodeConfig(conf)
+	if objType != aac.TypeAACELD {
+		return nil
+	}
+
+	return &core.Codec{
+		Name:      core.CodecELD,
+		ClockRate: sampleRate,
+		Channels:  channels,
+		FmtpLine:  aac.FMTP + hex.EncodeToString(conf),
+	}
+}
+
+// ascBits - AudioSpecificConfig size in bits, only for AAC-ELD without LD-SBR
+func ascBits(conf []byte) int {
+	rd := bits.NewReader(conf)
+
+	size := 5 + 4 + 4
+	objType := rd.ReadBits8(5)
+	if objType == aac.TypeESCAPE {
+		objType = 32 + rd.ReadBits8(6)
+		size += 6
+	}
+	if rd.ReadBits8(4) == 0x0F {
+		_ = rd.ReadBits(24)
+		size += 24
+	}
+	_ = rd.ReadBits8(4) // channels
+
+	if objType != aac.TypeAACELD {
+		return 0
+	}
+
+	_ = rd.ReadBit()    // frameLengthFlag
+	_ = rd.ReadBits8(3) // resilience flags
+	if rd.ReadBit() != 0 {
+		return 0 // ldSbrPresentFlag
+	}
+	size += 5
+
+	// eldExtType until ELDEXT_TERM
+	for rd.ReadBits8(4) != 0 {
+		n := int(rd.ReadBits8(4))
+		size += 8
+		if n == 15 {
+			i := int(rd.ReadBits8(8))
+			n += i
+			size += 8
+			if i == 255 {
+				n += int(rd.ReadBits16(16))
+				size += 16
+			}
+		}
+		for i := 0; i < n; i++ {
+			_ = rd.ReadBits8(8)
+		}
+		size += 8 * n
+		if rd.EOF {
+			return 0
+		}
+	}
+	size += 4
+
+	_ = rd.ReadBits8(2) // epConfig
+	size += 2
+
+	if rd.EOF {
+		return 0
+	}
+
+	return size
+}
@@ -0,0 +1,38 @@
+package mpegts
+
+import "github.com/AlexxIT/go2rtc/pkg/core"
+
+var mp3Rates = [3]uint32{44100, 48000, 32000}
+
+// MP3ToCodec - codec from MPEG audio frame header, support MPEG-1, MPEG-2 and MPEG-2.5
+func MP3ToCodec(b []byte) *core.Codec {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return nil
+	}
+
+	version := (b[1] >> 3) & 0b11 // 3 - MPEG-1, 2 - MPEG-2, 0 - MPEG-2.5
+	index := (b[2] >> 2) & 0b11
+	if version == 1 || index == 3 {
+		return nil
+	}
+
+	codec := &core.Codec{
+		Name:        core.CodecMP3,
+		ClockRate:   mp3Rates[index],
+		Channels:    2,
+		PayloadType: core.PayloadTypeRAW,
+	}
+
+	switch version {
+	case 2:
+		codec.ClockRate /= 2
+	case 0:
+		codec.ClockRate /= 4
+	}
+
+	if b[3]>>6 == 3 {
+		codec.Channels = 1 // single channel mode
+	}
+
+	return codec
+}
@@ -0,0 +1,87 @@
+package mpegts
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudioCodecs(t *testing.T) {
+	ac3 := []byte{0x0B, 0x77, 0, 0, 0x00, 0x40, 0x40, 0} // 48000 Hz, stereo
+	mp3 := []byte{0xFF, 0xFB, 0x90, 0x64}                // 44100 Hz, joint stereo
+	opus := []byte{0xFC, 0xFF, 0xFE}
+
+	muxer := NewMuxer()
+	pid1 := muxer.AddTrack(StreamTypeAC3)
+	pid2 := muxer.AddTrack(StreamTypeMP3)
+	pid3 := muxer.AddTrack(StreamTypePrivateOPUS)
+
+	b := muxer.GetHeader()
+	b = append(b, muxer.GetPayload(pid1, 1000, ac3)...)
+	b = append(b, muxer.GetPayload(pid2, 1000, mp3)...)
+	b = append(b, muxer.GetPayload(pid3, 1000, AppendOPUSPacket(nil, opus))...)
+
+	prod, err := Open(bytes.NewReader(b))
+	require.Nil(t, err)
+	require.Len(t, prod.Medias, 3)
+
+	codecs := map[string]*core.Codec{}
+	for _, media := range prod.Medias {
+		codecs[media.Codecs[0].Name] = media.Codecs[0]
+	}
+
+	require.Equal(t, uint32(48000), codecs[core.CodecAC3].ClockRate)
+	require.Equal(t, uint8(2), codecs[core.CodecAC3].Channels)
+	require.Equal(t, uint32(44100), codecs[core.CodecMP3].ClockRate)
+	require.Equal(t, uint8(2), codecs[core.CodecMP3].Channels)
+	require.NotNil(t, codecs[core.CodecOpus])
+
+	eac3 := []byte{0x0B, 0x77, 0, 0, 0x3F, 0x80, 0, 0} // 48000 Hz, 5.1
+	codec := AC3ToCodec(eac3)
+	require.Equal(t, core.CodecEAC3, codec.Name)
+	require.Equal(t, uint32(48000), codec.ClockRate)
+	require.Equal(t, uint8(6), codec.Channels)
+
+	// Opus in the TS has the control header for each packet
+	packet, left := CutOPUSPacket(AppendOPUSPacket(nil, opus))
+	require.Equal(t, opus, packet)
+	require.Len(t, left, 0)
+
+	// Opus is in the default medias for HLS, PCMA only on request
+	audio := NewConsumer().GetMedias()[1]
+	require.NotNil(t, audio.MatchCodec(&core.Codec{Name: core.CodecOpus}))
+	require.Nil(t, audio.MatchCodec(&core.Codec{Name: core.CodecPCMA}))
+}
+
+func TestLATM(t *testing.T) {
+	conf := aac.EncodeConfig(aac.TypeAACELD, 24000, 1, true)
+	au := []byte{1, 2, 3, 4, 5}
+
+	frame, err := LATMEncode(conf, au)
+	require.Nil(t, err)
+
+	muxer := NewMuxer()
+	pid := muxer.AddTrack(StreamTypeAACLATM)
+
+	b := muxer.GetHeader()
+	b = append(b, muxer.GetPayload(pid, 1000, frame)...)
+
+	// codec is built from the config of the first frame in the demuxer
+	prod, err := Open(bytes.NewReader(b))
+	require.Nil(t, err)
+	require.Len(t, prod.Medias, 1)
+
+	codec := prod.Medias[0].Codecs[0]
+	require.Equal(t, core.CodecELD, codec.Name)
+	require.Equal(t, uint32(24000), codec.ClockRate)
+	require.Equal(t, uint8(1), codec.Channels)
+	require.Equal(t, aac.FMTP+hex.EncodeToString(conf), codec.FmtpLine)
+
+	// AAC-LC config can't be encoded to LATM
+	_, err = LATMEncode(aac.EncodeConfig(aac.TypeAACLC, 24000, 1, false), au)
+	require.Equal(t, ErrLATMConfig, err)
+}
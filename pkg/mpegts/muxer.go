@@ -22,10 +22,12 @@ func (m *Muxer) AddTrack(streamType byte) (pid uint16) {
 
 	// Audio streams (0xC0-0xDF), Video streams (0xE0-0xEF)
 	switch streamType {
-	case StreamTypeH264, StreamTypeH265:
+	case StreamTypeH264, StreamTypeH265, StreamTypePrivateAV1:
 		pes.StreamID = 0xE0
-	case StreamTypeAAC, StreamTypePCMATapo:
+	case StreamTypeAAC, StreamTypeAACLATM, StreamTypeMP3, StreamTypePCMATapo, StreamTypePCMUTapo:
 		pes.StreamID = 0xC0
+	case StreamTypeAC3, StreamTypeEAC3, StreamTypePrivateOPUS:
+		pes.StreamID = 0xBD // private_stream_1, same as FFmpeg
 	}
 
 	pid = pes0PID + uint16(len(m.pes))
@@ -43,11 +45,18 @@ func (m *Muxer) GetHeader() []byte {
 
 // GetPayload - safe to run concurently with different pid
 func (m *Muxer) GetPayload(pid uint16, timestamp uint32, payload []byte) []byte {
+	return m.GetPayloadCTS(pid, timestamp, 0, payload)
+}
+
+// GetPayloadCTS - same as GetPayload, but timestamp is DTS and cts is PTS-DTS offset (for B-frames)
+func (m *Muxer) GetPayloadCTS(pid uint16, timestamp uint32, cts int32, payload []byte) []byte {
 	pes := m.pes[pid]
 
 	switch pes.StreamType {
 	case StreamTypeH264, StreamTypeH265:
 		payload = annexb.DecodeAVCCWithAUD(payload)
+	case StreamTypePrivateAV1:
+		payload = append(av1TemporalDelimiter, payload...)
 	}
 
 	if pes.Timestamp != 0 {
@@ -55,10 +64,16 @@ func (m *Muxer) GetPayload(pid uint16, timestamp uint32, payload []byte) []byte
 	}
 	pes.Timestamp = timestamp
 
+	// PES header length: PTS (5 byte) or PTS and DTS (10 byte)
+	var hdrSize byte = 5
+	if cts != 0 {
+		hdrSize = 10
+	}
+
 	// min header size (3 byte) + adv header size (PES)
-	size := 3 + 5 + len(payload)
+	size := 3 + int(hdrSize) + len(payload)
 
-	b := make([]byte, 6+3+5)
+	b := make([]byte, 6+3+hdrSize)
 
 	b[0], b[1], b[2] = 0, 0, 1 // Packet start code prefix
 	b[3] = pes.StreamID        // Stream ID
@@ -69,11 +84,17 @@ func (m *Muxer) GetPayload(pid uint16, timestamp uint32, payload []byte) []byte
 	}
 
 	// Optional PES header:
-	b[6] = 0x80 // Marker bits (binary)
-	b[7] = 0x80 // PTS indicator
-	b[8] = 5    // PES header length
+	b[6] = 0x80    // Marker bits (binary)
+	b[8] = hdrSize // PES header length
 
-	WriteTime(b[9:], pes.PTS)
+	if cts == 0 {
+		b[7] = 0x80 // PTS indicator
+		WriteTime(b[9:], pes.PTS)
+	} else {
+		b[7] = 0xC0 // PTS and DTS indicator
+		writeTime(b[9:], timePTSWithDTS, pes.PTS+uint32(cts))
+		writeTime(b[14:], timeDTS, pes.PTS)
+	}
 
 	pes.Payload = append(b, payload...)
 	pes.Size = 1 // set PUSI in first PES
@@ -114,8 +135,13 @@ func (m *Muxer) writePAT(wr *bits.Writer) {
 
 func (m *Muxer) writePMT(wr *bits.Writer) {
 	m.writeHeader(wr, pmtPID)
-	i := wr.Len() + 1                               // start for CRC32
-	m.writePSIHeader(wr, 2, 4+uint16(len(m.pes))*5) // 4 bytes below + 5 bytes each PES
+	i := wr.Len() + 1 // start for CRC32
+
+	size := uint16(4) // 4 bytes below + 5 bytes and ES info each PES
+	for _, pes := range m.pes {
+		size += 5 + uint16(len(esInfo(pes.StreamType)))
+	}
+	m.writePSIHeader(wr, 2, size)
 
 	wr.WriteBits8(0b111, 3)    // Reserved bits (all to 1)
 	wr.WriteBits16(0x1FFF, 13) // Program map PID (not used)
@@ -129,12 +155,15 @@ func (m *Muxer) writePMT(wr *bits.Writer) {
 		if !ok {
 			break
 		}
-		wr.WriteByte(pes.StreamType) // Stream type
-		wr.WriteBits8(0b111, 3)      // Reserved bits (all to 1)
-		wr.WriteBits16(pid, 13)      // Elementary PID
-		wr.WriteBits8(0b1111, 4)     // Reserved bits (all to 1)
-		wr.WriteBits(0, 2)           // ES Info length unused bits
-		wr.WriteBits16(0, 10)        // ES Info length
+		info := esInfo(pes.StreamType)
+
+		wr.WriteByte(pmtStreamType(pes.StreamType)) // Stream type
+		wr.WriteBits8(0b111, 3)                     // Reserved bits (all to 1)
+		wr.WriteBits16(pid, 13)                     // Elementary PID
+		wr.WriteBits8(0b1111, 4)                    // Reserved bits (all to 1)
+		wr.WriteBits(0, 2)                          // ES Info length unused bits
+		wr.WriteBits16(uint16(len(info)), 10)       // ES Info length
+		wr.WriteBytes(info...)
 	}
 
 	crc := checksum(wr.Bytes()[i:])
@@ -143,6 +172,30 @@ func (m *Muxer) writePMT(wr *bits.Writer) {
 	m.WriteTail(wr)
 }
 
+// pmtStreamType - convert internal stream types to real stream types
+func pmtStreamType(streamType byte) byte {
+	switch streamType {
+	case StreamTypePrivateOPUS, StreamTypePrivateAV1:
+		return StreamTypePrivate
+	}
+	return streamType
+}
+
+// esInfo - descriptors for private stream types
+func esInfo(streamType byte) []byte {
+	switch streamType {
+	case StreamTypePrivateOPUS:
+		return append(opusInfo, opusExtInfo...)
+	case StreamTypePrivateAV1:
+		return av1Info
+	case StreamTypeAC3:
+		return ac3Info
+	case StreamTypeEAC3:
+		return eac3Info
+	}
+	return nil
+}
+
 func (m *Muxer) writePES(wr *bits.Writer, pid uint16, pes *PES) {
 	const flagPUSI = 0b01000000_00000000
 	const flagAdaptation = 0b00100000
@@ -215,10 +268,19 @@ func (m *Muxer) WriteTail(wr *bits.Writer) {
 	wr.WriteBytes(make([]byte, size)...)
 }
 
+const (
+	timeOnlyPTS    = 0x20
+	timePTSWithDTS = 0x30
+	timeDTS        = 0x10
+)
+
 func WriteTime(b []byte, t uint32) {
+	writeTime(b, timeOnlyPTS, t)
+}
+
+func writeTime(b []byte, prefix byte, t uint32) {
 	_ = b[4] // bounds
-	const onlyPTS = 0x20
-	b[0] = onlyPTS | byte(t>>(32-3)) | 1
+	b[0] = prefix | byte(t>>(32-3)) | 1
 	b[1] = byte(t >> (24 - 2))
 	b[2] = byte(t>>(16-2)) | 1
 	b[3] = byte(t >> (8 - 1))
@@ -7,13 +7,36 @@ import (
 // opusDT - each AU from FFmpeg has 5 OPUS packets. Each packet len = 960 in the 48000 clock.
 const opusDT = 960 * ClockRate / 48000
 
-// https://opus-codec.org/docs/
+// https://opus-codec.org/docs/ETSI_TS_opus-v0.1.3-draft.pdf
 var opusInfo = []byte{ // registration_descriptor
 	0x05,               // descriptor_tag
 	0x04,               // descriptor_length
 	'O', 'p', 'u', 's', // format_identifier
 }
 
+// opusExtInfo - extension_descriptor with opus_audio_descriptor,
+// RTP and WebRTC always signal Opus as stereo, and the decoder gets mono or stereo from each packet
+var opusExtInfo = []byte{
+	0x7F, // descriptor_tag (DVB extension)
+	0x02, // descriptor_length
+	0x80, // descriptor_tag_extension (opus_audio_descriptor)
+	0x02, // channel_config_code (stereo)
+}
+
+// AppendOPUSPacket - opus_control_header and Opus packet, each PES can contain multiple packets
+func AppendOPUSPacket(dst, packet []byte) []byte {
+	// control_header_prefix (0x3FF), no trim flags, no extension
+	dst = append(dst, 0x7F, 0xE0)
+	for n := len(packet); ; n -= 255 {
+		if n < 255 {
+			dst = append(dst, byte(n))
+			break
+		}
+		dst = append(dst, 255)
+	}
+	return append(dst, packet...)
+}
+
 //goland:noinspection GoSnakeCaseUsage
 func CutOPUSPacket(b []byte) (packet []byte, left []byte) {
 	r := bits.NewReader(b)
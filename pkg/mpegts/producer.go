@@ -91,7 +91,8 @@ func (c *Producer) probe() error {
 		case StreamTypeMetadata:
 			for _, streamType := range pkt.Payload {
 				switch streamType {
-				case StreamTypeH264, StreamTypeH265, StreamTypeAAC, StreamTypePrivateOPUS, StreamTypePCMATapo:
+				case StreamTypeH264, StreamTypeH265, StreamTypePrivateAV1, StreamTypeAAC, StreamTypeAACLATM,
+					StreamTypePrivateOPUS, StreamTypePCMATapo, StreamTypePCMUTapo, StreamTypeMP3, StreamTypeAC3, StreamTypeEAC3:
 					waitType = append(waitType, streamType)
 				}
 			}
@@ -114,6 +115,19 @@ func (c *Producer) probe() error {
 			}
 			c.Medias = append(c.Medias, media)
 
+		case StreamTypePrivateAV1:
+			codec := &core.Codec{
+				Name:        core.CodecAV1,
+				ClockRate:   90000,
+				PayloadType: core.PayloadTypeRAW,
+			}
+			media := &core.Media{
+				Kind:      core.KindVideo,
+				Direction: core.DirectionRecvonly,
+				Codecs:    []*core.Codec{codec},
+			}
+			c.Medias = append(c.Medias, media)
+
 		case StreamTypeAAC:
 			codec := aac.RTPToCodec(pkt.Payload)
 			media := &core.Media{
@@ -123,6 +137,19 @@ func (c *Producer) probe() error {
 			}
 			c.Medias = append(c.Medias, media)
 
+		case StreamTypeAACLATM:
+			// only AAC-ELD, packets are delivered with RFC 3640 AU headers, same as AAC
+			codec := LATMToCodec(rd.Config(StreamTypeAACLATM))
+			if codec == nil {
+				continue
+			}
+			media := &core.Media{
+				Kind:      core.KindAudio,
+				Direction: core.DirectionRecvonly,
+				Codecs:    []*core.Codec{codec},
+			}
+			c.Medias = append(c.Medias, media)
+
 		case StreamTypePrivateOPUS:
 			codec := &core.Codec{
 				Name:      core.CodecOpus,
@@ -136,11 +163,31 @@ func (c *Producer) probe() error {
 			}
 			c.Medias = append(c.Medias, media)
 
-		case StreamTypePCMATapo:
+		case StreamTypePCMATapo, StreamTypePCMUTapo:
 			codec := &core.Codec{
 				Name:      core.CodecPCMA,
 				ClockRate: 8000,
 			}
+			if pkt.PayloadType == StreamTypePCMUTapo {
+				codec.Name = core.CodecPCMU
+			}
+			media := &core.Media{
+				Kind:      core.KindAudio,
+				Direction: core.DirectionRecvonly,
+				Codecs:    []*core.Codec{codec},
+			}
+			c.Medias = append(c.Medias, media)
+
+		case StreamTypeMP3, StreamTypeAC3, StreamTypeEAC3:
+			var codec *core.Codec
+			if pkt.PayloadType == StreamTypeMP3 {
+				codec = MP3ToCodec(pkt.Payload)
+			} else {
+				codec = AC3ToCodec(pkt.Payload)
+			}
+			if codec == nil {
+				continue
+			}
 			media := &core.Media{
 				Kind:      core.KindAudio,
 				Direction: core.DirectionRecvonly,
@@ -159,12 +206,24 @@ func StreamType(codec *core.Codec) uint8 {
 		return StreamTypeH264
 	case core.CodecH265:
 		return StreamTypeH265
+	case core.CodecAV1:
+		return StreamTypePrivateAV1
 	case core.CodecAAC:
 		return StreamTypeAAC
+	case core.CodecELD:
+		return StreamTypeAACLATM
 	case core.CodecPCMA:
 		return StreamTypePCMATapo
+	case core.CodecPCMU:
+		return StreamTypePCMUTapo
 	case core.CodecOpus:
 		return StreamTypePrivateOPUS
+	case core.CodecMP3:
+		return StreamTypeMP3
+	case core.CodecAC3:
+		return StreamTypeAC3
+	case core.CodecEAC3:
+		return StreamTypeEAC3
 	}
 	return 0
 }
@@ -0,0 +1,454 @@
+package codec
+
+import "math"
+
+// Band shape coding: splitting, stereo and folding (RFC 6716, 4.3.4)
+
+const (
+	qthetaOffset         = 4
+	qthetaOffsetTwoPhase = 16
+)
+
+type bandCtx struct {
+	enc *rangeEncoder
+
+	band            int
+	intensity       int
+	spread          int
+	tfChange        int
+	remainingBits   int
+	bandE           []float32
+	avoidSplitNoise bool
+}
+
+func fracMul16(a, b int) int {
+	return (16384 + int(int16(a))*int(int16(b))) >> 15
+}
+
+func bitexactCos(x int) int {
+	tmp := (4096 + x*x) >> 13
+	x2 := tmp
+	x2 = (32767 - x2) + fracMul16(x2, -7651+fracMul16(x2, 8277+fracMul16(-626, x2)))
+	return 1 + x2
+}
+
+func bitexactLog2tan(isin, icos int) int {
+	lc := ilog(uint32(icos))
+	ls := ilog(uint32(isin))
+	icos <<= 15 - lc
+	isin <<= 15 - ls
+	return (ls-lc)*(1<<11) + fracMul16(isin, fracMul16(isin, -2597)+7932) - fracMul16(icos, fracMul16(icos, -2597)+7932)
+}
+
+func computeQN(n, b, offset, pulseCap int, stereo bool) int {
+	exp2Table8 := [8]int{16384, 17866, 19483, 21247, 23170, 25267, 27554, 30048}
+	n2 := 2*n - 1
+	if stereo && n == 2 {
+		n2--
+	}
+	qb := sdiv(b+n2*offset, n2)
+	qb = min(b-pulseCap-4<<bitRes, qb)
+	qb = min(8<<bitRes, qb)
+	if qb < 1<<bitRes>>1 {
+		return 1
+	}
+	qn := exp2Table8[qb&7] >> (14 - qb>>bitRes)
+	return (qn + 1) >> 1 << 1
+}
+
+// sdiv - signed division with truncation
+func sdiv(a, b int) int {
+	return a / b
+}
+
+type splitCtx struct {
+	delta  int
+	itheta int
+	qalloc int
+}
+
+func (ctx *bandCtx) computeTheta(sctx *splitCtx, x, y []float32, n int, b *int, b0, lm int, stereo bool) {
+	pulseCap := logN[ctx.band] + lm*(1<<bitRes)
+	offset := pulseCap >> 1
+	if stereo && n == 2 {
+		offset -= qthetaOffsetTwoPhase
+	} else {
+		offset -= qthetaOffset
+	}
+	qn := computeQN(n, *b, offset, pulseCap, stereo)
+	if stereo && ctx.band >= ctx.intensity {
+		qn = 1
+	}
+
+	e := ctx.enc
+	itheta := stereoItheta(x, y, stereo, n)
+	tell := e.tellFrac()
+
+	if qn != 1 {
+		itheta = (itheta*qn + 8192) >> 14
+		if !stereo && ctx.avoidSplitNoise && itheta > 0 && itheta < qn {
+			unquantized := itheta * 16384 / qn
+			imid := bitexactCos(unquantized)
+			iside := bitexactCos(16384 - unquantized)
+			delta := fracMul16((n-1)<<7, bitexactLog2tan(iside, imid))
+			if delta > *b {
+				itheta = qn
+			} else if delta < -*b {
+				itheta = 0
+			}
+		}
+
+		switch {
+		case stereo && n > 2:
+			p0 := 3
+			x0 := qn / 2
+			ft := uint32(p0*(x0+1) + x0)
+			if v := itheta; v <= x0 {
+				e.encode(uint32(p0*v), uint32(p0*(v+1)), ft)
+			} else {
+				e.encode(uint32(v-1-x0+(x0+1)*p0), uint32(v-x0+(x0+1)*p0), ft)
+			}
+
+		case b0 > 1 || stereo:
+			e.uint(uint32(itheta), uint32(qn+1))
+
+		default:
+			ft := ((qn >> 1) + 1) * ((qn >> 1) + 1)
+			var fs, fl int
+			if itheta <= qn>>1 {
+				fs = itheta + 1
+				fl = itheta * (itheta + 1) >> 1
+			} else {
+				fs = qn + 1 - itheta
+				fl = ft - ((qn + 1 - itheta) * (qn + 2 - itheta) >> 1)
+			}
+			e.encode(uint32(fl), uint32(fl+fs), uint32(ft))
+		}
+
+		itheta = itheta * 16384 / qn
+
+		if stereo {
+			if itheta == 0 {
+				ctx.intensityStereo(x, y, n)
+			} else {
+				stereoSplit(x, y, n)
+			}
+		}
+	} else if stereo {
+		inv := itheta > 8192
+		if inv {
+			for j := 0; j < n; j++ {
+				y[j] = -y[j]
+			}
+		}
+		ctx.intensityStereo(x, y, n)
+		if *b > 2<<bitRes && ctx.remainingBits > 2<<bitRes {
+			e.bitLogp(inv, 2)
+		}
+		itheta = 0
+	}
+
+	qalloc := e.tellFrac() - tell
+	*b -= qalloc
+
+	var delta int
+	switch itheta {
+	case 0:
+		delta = -16384
+	case 16384:
+		delta = 16384
+	default:
+		imid := bitexactCos(itheta)
+		iside := bitexactCos(16384 - itheta)
+		delta = fracMul16((n-1)<<7, bitexactLog2tan(iside, imid))
+	}
+
+	*sctx = splitCtx{delta: delta, itheta: itheta, qalloc: qalloc}
+}
+
+func (ctx *bandCtx) intensityStereo(x, y []float32, n int) {
+	left := ctx.bandE[ctx.band]
+	right := ctx.bandE[ctx.band+nbEBands]
+	norm := 1e-15 + float32(math.Sqrt(float64(1e-15+left*left+right*right)))
+	a1 := left / norm
+	a2 := right / norm
+	for j := 0; j < n; j++ {
+		x[j] = a1*x[j] + a2*y[j]
+	}
+}
+
+func stereoSplit(x, y []float32, n int) {
+	for j := 0; j < n; j++ {
+		l := 0.70710678 * x[j]
+		r := 0.70710678 * y[j]
+		x[j] = l + r
+		y[j] = r - l
+	}
+}
+
+func haar1(x []float32, n0, stride int) {
+	n0 >>= 1
+	for i := 0; i < stride; i++ {
+		for j := 0; j < n0; j++ {
+			tmp1 := 0.70710678 * x[stride*2*j+i]
+			tmp2 := 0.70710678 * x[stride*(2*j+1)+i]
+			x[stride*2*j+i] = tmp1 + tmp2
+			x[stride*(2*j+1)+i] = tmp1 - tmp2
+		}
+	}
+}
+
+func deinterleaveHadamard(x []float32, n0, stride int, hadamard bool) {
+	n := n0 * stride
+	tmp := make([]float32, n)
+	for i := 0; i < stride; i++ {
+		k := i
+		if hadamard {
+			k = orderyTable[stride-2+i]
+		}
+		for j := 0; j < n0; j++ {
+			tmp[k*n0+j] = x[j*stride+i]
+		}
+	}
+	copy(x, tmp)
+}
+
+func (ctx *bandCtx) quantBandN1(x, y []float32) {
+	for _, v := range [][]float32{x, y} {
+		if v == nil {
+			break
+		}
+		if ctx.remainingBits >= 1<<bitRes {
+			sign := uint32(0)
+			if v[0] < 0 {
+				sign = 1
+			}
+			ctx.enc.bits(sign, 1)
+			ctx.remainingBits -= 1 << bitRes
+		}
+	}
+}
+
+// splitThreshold - split the band if it needs 1.5 more bits than cache can produce
+func splitThreshold(band, lm int) int {
+	cache := pulseCache(band, lm)
+	return int(cache[cache[0]]) + 12
+}
+
+// quantPartition - mono partition, can be split recursively up to 8 parts
+func (ctx *bandCtx) quantPartition(x []float32, n, b, bb, lm int) {
+	b0 := bb
+
+	if lm != -1 && b > splitThreshold(ctx.band, lm) && n > 2 {
+		n >>= 1
+		y := x[n:]
+		lm--
+		bb = (bb + 1) >> 1
+
+		var sctx splitCtx
+		ctx.computeTheta(&sctx, x, y, n, &b, b0, lm, false)
+		delta := sctx.delta
+		itheta := sctx.itheta
+
+		if b0 > 1 && itheta&0x3fff != 0 {
+			if itheta > 8192 {
+				delta -= delta >> (4 - lm)
+			} else {
+				delta = min(0, delta+(n<<bitRes>>(5-lm)))
+			}
+		}
+		mbits := max(0, min(b, (b-delta)/2))
+		sbits := b - mbits
+		ctx.remainingBits -= sctx.qalloc
+
+		rebalance := ctx.remainingBits
+		if mbits >= sbits {
+			ctx.quantPartition(x, n, mbits, bb, lm)
+			rebalance = mbits - (rebalance - ctx.remainingBits)
+			if rebalance > 3<<bitRes && itheta != 0 {
+				sbits += rebalance - 3<<bitRes
+			}
+			ctx.quantPartition(y, n, sbits, bb, lm)
+		} else {
+			ctx.quantPartition(y, n, sbits, bb, lm)
+			rebalance = sbits - (rebalance - ctx.remainingBits)
+			if rebalance > 3<<bitRes && itheta != 16384 {
+				mbits += rebalance - 3<<bitRes
+			}
+			ctx.quantPartition(x, n, mbits, bb, lm)
+		}
+		return
+	}
+
+	q := bits2pulses(ctx.band, lm, b)
+	currBits := pulses2bits(ctx.band, lm, q)
+	ctx.remainingBits -= currBits
+	for ctx.remainingBits < 0 && q > 0 {
+		ctx.remainingBits += currBits
+		q--
+		currBits = pulses2bits(ctx.band, lm, q)
+		ctx.remainingBits -= currBits
+	}
+
+	if q != 0 {
+		algQuant(ctx.enc, x, n, getPulses(q), ctx.spread, bb)
+	}
+}
+
+func (ctx *bandCtx) quantBand(x []float32, n, b, bb, lm int) {
+	nb := n / bb
+	longBlocks := bb == 1
+	recombine := 0
+	tfChange := ctx.tfChange
+
+	if n == 1 {
+		ctx.quantBandN1(x, nil)
+		return
+	}
+
+	if tfChange > 0 {
+		recombine = tfChange
+	}
+
+	for k := 0; k < recombine; k++ {
+		haar1(x, n>>k, 1<<k)
+	}
+	bb >>= recombine
+	nb <<= recombine
+
+	for nb&1 == 0 && tfChange < 0 {
+		haar1(x, nb, bb)
+		bb <<= 1
+		nb >>= 1
+		tfChange++
+	}
+
+	if bb > 1 {
+		deinterleaveHadamard(x, nb>>recombine, bb<<recombine, longBlocks)
+	}
+
+	ctx.quantPartition(x, n, b, bb, lm)
+}
+
+func (ctx *bandCtx) quantBandStereo(x, y []float32, n, b, bb, lm int) {
+	if n == 1 {
+		ctx.quantBandN1(x, y)
+		return
+	}
+
+	var sctx splitCtx
+	ctx.computeTheta(&sctx, x, y, n, &b, bb, lm, true)
+	itheta := sctx.itheta
+
+	if n == 2 {
+		mbits := b
+		sbits := 0
+		if itheta != 0 && itheta != 16384 {
+			sbits = 1 << bitRes
+		}
+		mbits -= sbits
+		ctx.remainingBits -= sctx.qalloc + sbits
+
+		x2, y2 := x, y
+		if itheta > 8192 {
+			x2, y2 = y, x
+		}
+
+		if sbits != 0 {
+			sign := uint32(0)
+			if x2[0]*y2[1]-x2[1]*y2[0] < 0 {
+				sign = 1
+			}
+			ctx.enc.bits(sign, 1)
+		}
+
+		ctx.quantBand(x2, n, mbits, bb, lm)
+		return
+	}
+
+	mbits := max(0, min(b, (b-sctx.delta)/2))
+	sbits := b - mbits
+	ctx.remainingBits -= sctx.qalloc
+
+	rebalance := ctx.remainingBits
+	if mbits >= sbits {
+		ctx.quantBand(x, n, mbits, bb, lm)
+		rebalance = mbits - (rebalance - ctx.remainingBits)
+		if rebalance > 3<<bitRes && itheta != 0 {
+			sbits += rebalance - 3<<bitRes
+		}
+		ctx.quantBand(y, n, sbits, bb, lm)
+	} else {
+		ctx.quantBand(y, n, sbits, bb, lm)
+		rebalance = sbits - (rebalance - ctx.remainingBits)
+		if rebalance > 3<<bitRes && itheta != 16384 {
+			mbits += rebalance - 3<<bitRes
+		}
+		ctx.quantBand(x, n, mbits, bb, lm)
+	}
+}
+
+// quantAllBands - encode normalized spectrum of all bands, x and y (nil for mono) are band shapes
+func quantAllBands(enc *rangeEncoder, start, end int, x, y []float32, bandE []float32, a *allocation,
+	shortBlocks bool, spread int, tfRes []int, totalBits, lm int) {
+
+	m := 1 << lm
+	bb := 1
+	if shortBlocks {
+		bb = m
+	}
+
+	ctx := &bandCtx{
+		enc:             enc,
+		intensity:       a.intensity,
+		spread:          spread,
+		bandE:           bandE,
+		avoidSplitNoise: bb > 1,
+	}
+
+	dualStereo := a.dualStereo
+	balance := a.balance
+
+	for i := start; i < end; i++ {
+		ctx.band = i
+
+		xi := x[m*eBands[i]:]
+		var yi []float32
+		if y != nil {
+			yi = y[m*eBands[i]:]
+		}
+		n := m*eBands[i+1] - m*eBands[i]
+
+		tell := enc.tellFrac()
+		if i != start {
+			balance -= tell
+		}
+		remainingBits := totalBits - tell - 1
+		ctx.remainingBits = remainingBits
+
+		b := 0
+		if i <= a.codedBands-1 {
+			currBalance := sdiv(balance, min(3, a.codedBands-i))
+			b = max(0, min(16383, min(remainingBits+1, a.pulses[i]+currBalance)))
+		}
+
+		ctx.tfChange = tfRes[i]
+
+		if dualStereo && i == ctx.intensity {
+			dualStereo = false
+		}
+
+		switch {
+		case dualStereo:
+			ctx.quantBand(xi, n, b/2, bb, lm)
+			ctx.quantBand(yi, n, b/2, bb, lm)
+		case yi != nil:
+			ctx.quantBandStereo(xi, yi, n, b, bb, lm)
+		default:
+			ctx.quantBand(xi, n, b, bb, lm)
+		}
+
+		balance += a.pulses[i] + tell
+		ctx.avoidSplitNoise = false
+	}
+}
@@ -0,0 +1,218 @@
+package codec
+
+import "math"
+
+const (
+	preemphCoef = 0.85
+	sigScale    = 32768
+)
+
+// intensityThresholds - bitrate (kbps) thresholds for intensity stereo start band
+var intensityThresholds = [nbEBands]int{1, 2, 3, 4, 5, 6, 7, 8, 16, 24, 36, 44, 50, 56, 62, 67, 72, 79, 88, 106, 134}
+
+// celtEncoder - simplified CELT layer encoder: long blocks only, without pitch prefilter,
+// TF analysis and dynamic allocation, but the bitstream is fully compatible
+type celtEncoder struct {
+	channels int
+
+	oldBandE [2 * nbEBands]float32
+
+	frames         int
+	lastCodedBands int
+
+	preemphMem [2]float32
+	in         [2][]float32 // overlap from previous frame + current frame
+	mdct       [maxLM + 1]*mdct
+	buf        []float32
+}
+
+func newCELTEncoder(channels int) *celtEncoder {
+	e := &celtEncoder{channels: channels}
+	for lm := range e.mdct {
+		e.mdct[lm] = newMDCT(2 * shortMdct << lm)
+	}
+	for c := range e.in {
+		e.in[c] = make([]float32, overlap+shortMdct<<maxLM)
+	}
+	e.buf = make([]float32, 2*shortMdct<<maxLM)
+	return e
+}
+
+// encode - encode interleaved pcm with n samples per channel to packet with fixed size,
+// bitrate is used only for stereo decisions
+func (e *celtEncoder) encode(pcm []float32, n, size, bitrate int) []byte {
+	lm := 0
+	for shortMdct<<lm != n {
+		lm++
+	}
+	m := 1 << lm
+	cc := e.channels
+	end := nbEBands
+
+	silence := true
+	for c := 0; c < cc; c++ {
+		in := e.in[c]
+		copy(in, in[n:n+overlap])
+		mem := e.preemphMem[c]
+		for i := 0; i < n; i++ {
+			v := pcm[i*cc+c] * sigScale
+			if v != 0 {
+				silence = false
+			}
+			in[overlap+i] = v - mem
+			mem = preemphCoef * v
+		}
+		e.preemphMem[c] = mem
+	}
+
+	enc := newRangeEncoder(make([]byte, size))
+	totalBits := size * 8
+
+	if enc.tell() == 1 {
+		enc.bitLogp(silence, 15)
+	}
+	if silence {
+		for i := range e.oldBandE {
+			e.oldBandE[i] = -28
+		}
+		enc.done()
+		return enc.buf
+	}
+
+	// MDCT, band energies and normalization
+	freq := make([]float32, cc*n)
+	x := make([]float32, cc*n)
+	var bandE, bandLogE [2 * nbEBands]float32
+
+	for c := 0; c < cc; c++ {
+		e.analysis(e.in[c][:n+overlap], freq[c*n:(c+1)*n], lm)
+
+		for i := 0; i < end; i++ {
+			lo, hi := c*n+m*eBands[i], c*n+m*eBands[i+1]
+			sum := float32(1e-27)
+			for _, v := range freq[lo:hi] {
+				sum += v * v
+			}
+			amp := float32(math.Sqrt(float64(sum)))
+			bandE[c*nbEBands+i] = amp
+			bandLogE[c*nbEBands+i] = float32(math.Log2(float64(amp))) - eMeans[i]
+
+			g := 1 / amp
+			for j := lo; j < hi; j++ {
+				x[j] = freq[j] * g
+			}
+		}
+	}
+
+	if enc.tell()+16 <= totalBits {
+		enc.bitLogp(false, 1) // no postfilter
+	}
+	if lm > 0 && enc.tell()+3 <= totalBits {
+		enc.bitLogp(false, 3) // no transient
+	}
+
+	intra := e.frames == 0
+	e.frames++
+
+	var errors [2 * nbEBands]float32
+	maxDecay := min(16, 0.125*float32(size))
+	quantCoarseEnergy(enc, 0, end, bandLogE[:], e.oldBandE[:], errors[:], intra, cc, lm, maxDecay)
+
+	var tfRes [nbEBands]int
+	tfEncode(enc, 0, end, tfRes[:], lm)
+
+	if enc.tell()+4 <= totalBits {
+		enc.icdf(spreadNormal, spreadICDF, 5)
+	}
+
+	var caps, offsets [nbEBands]int
+	initCaps(caps[:], lm, cc)
+
+	totalBits <<= bitRes
+	dynallocLogp := 6
+	for i := 0; i < end; i++ {
+		if enc.tellFrac()+dynallocLogp<<bitRes < totalBits && caps[i] > 0 {
+			enc.bitLogp(false, dynallocLogp) // no boost
+		}
+	}
+
+	allocTrim := 5
+	if enc.tellFrac()+6<<bitRes <= totalBits {
+		enc.icdf(allocTrim, trimICDF, 7)
+	}
+
+	bits := totalBits - enc.tellFrac() - 1
+
+	a := allocation{intensity: end}
+	if cc == 2 {
+		kbps := bitrate / 1000
+		a.intensity = 0
+		for a.intensity < end && kbps >= intensityThresholds[a.intensity] {
+			a.intensity++
+		}
+	}
+	computeAllocation(&a, 0, end, offsets[:], caps[:], allocTrim, bits, cc, lm, enc, e.lastCodedBands, end-1)
+	e.lastCodedBands = a.codedBands
+
+	quantFineEnergy(enc, 0, end, e.oldBandE[:], errors[:], a.fineQuant[:], cc)
+
+	var y []float32
+	if cc == 2 {
+		y = x[n:]
+	}
+	quantAllBands(enc, 0, end, x[:n], y, bandE[:], &a, false, spreadNormal, tfRes[:], size*(8<<bitRes), lm)
+
+	quantEnergyFinalise(enc, 0, end, e.oldBandE[:], errors[:], a.fineQuant[:], a.finePriority[:], size*8-enc.tell(), cc)
+
+	if cc == 1 {
+		copy(e.oldBandE[nbEBands:], e.oldBandE[:nbEBands])
+	}
+
+	enc.done()
+	return enc.buf
+}
+
+// analysis - windowed forward MDCT for long block
+func (e *celtEncoder) analysis(in, freq []float32, lm int) {
+	n := len(freq)
+	buf := e.buf[:2*n]
+	clear(buf)
+
+	dst := buf[(n-overlap)/2:]
+	for i := 0; i < overlap; i++ {
+		dst[i] = in[i] * window[i]
+		dst[n+i] = in[n+i] * window[overlap-1-i]
+	}
+	copy(dst[overlap:n], in[overlap:n])
+
+	e.mdct[lm].forward(freq, buf)
+
+	scale := float32(2) / float32(n)
+	for i := range freq {
+		freq[i] *= scale
+	}
+}
+
+// tfEncode - encode zero TF resolution changes for all bands
+func tfEncode(enc *rangeEncoder, start, end int, tfRes []int, lm int) {
+	budget := len(enc.buf) * 8
+	logp := 4
+	tfSelectRsv := lm > 0 && enc.tell()+logp+1 <= budget
+	if tfSelectRsv {
+		budget--
+	}
+
+	for i := start; i < end; i++ {
+		if enc.tell()+logp <= budget {
+			enc.bit(0, logp)
+		}
+		logp = 5
+	}
+
+	if tfSelectRsv && tfSelectTable[lm][0] != tfSelectTable[lm][2] {
+		enc.bit(0, 1)
+	}
+	for i := start; i < end; i++ {
+		tfRes[i] = tfSelectTable[lm][0]
+	}
+}
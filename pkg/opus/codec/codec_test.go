@@ -0,0 +1,31 @@
+package codec
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	for _, channels := range []int{1, 2} {
+		enc := NewEncoder(channels, 64000*channels)
+
+		for i := 0; i < 50; i++ {
+			pcm := make([]float32, enc.FrameSize*channels)
+			for j := range pcm {
+				k := float64(i*len(pcm)+j) / float64(channels)
+				pcm[j] = float32(0.5 * math.Sin(2*math.Pi*440*k/SampleRate))
+			}
+
+			packet, err := enc.Encode(pcm)
+			require.Nil(t, err)
+			require.Len(t, packet, 160*channels)
+			require.Equal(t, byte(31), packet[0]>>3)
+			require.Equal(t, channels == 2, packet[0]&0b100 != 0)
+		}
+
+		_, err := enc.Encode(make([]float32, 100))
+		require.Equal(t, errWrongFrame, err)
+	}
+}
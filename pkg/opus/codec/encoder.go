@@ -0,0 +1,61 @@
+package codec
+
+import "errors"
+
+const (
+	SampleRate       = 48000
+	DefaultBitrate   = 64000
+	DefaultFrameSize = 960 // 20 ms
+
+	maxPacketSize = 1275
+)
+
+var errWrongFrame = errors.New("opus: wrong frame size")
+
+// Encoder - Opus encoder with CELT-only fullband packets (RFC 6716), input is always 48 kHz
+type Encoder struct {
+	Channels  int
+	Bitrate   int
+	FrameSize int // samples per channel: 120, 240, 480 or 960
+
+	celt *celtEncoder
+}
+
+// NewEncoder - constant bitrate encoder with 20 ms frames
+func NewEncoder(channels, bitrate int) *Encoder {
+	if bitrate == 0 {
+		bitrate = DefaultBitrate
+	}
+	return &Encoder{
+		Channels:  channels,
+		Bitrate:   bitrate,
+		FrameSize: DefaultFrameSize,
+		celt:      newCELTEncoder(channels),
+	}
+}
+
+// Encode - encode interleaved samples in [-1, 1] range with exactly FrameSize samples per channel
+func (e *Encoder) Encode(pcm []float32) ([]byte, error) {
+	n := e.FrameSize
+	if len(pcm) != n*e.Channels {
+		return nil, errWrongFrame
+	}
+
+	lm := 0
+	for shortMdct<<lm != n {
+		if lm++; lm > maxLM {
+			return nil, errWrongFrame
+		}
+	}
+
+	size := e.Bitrate*n/(SampleRate*8) - 1 // minus TOC byte
+	size = max(2, min(size, maxPacketSize-1))
+
+	toc := byte(28+lm) << 3 // CELT-only fullband, one frame
+	if e.Channels == 2 {
+		toc |= 0b100
+	}
+
+	frame := e.celt.encode(pcm, n, size, e.Bitrate)
+	return append([]byte{toc}, frame...), nil
+}
@@ -0,0 +1,159 @@
+package codec
+
+import "math"
+
+// Laplace distribution for coarse energy (RFC 6716, 4.3.2.1)
+const (
+	laplaceMinP = 1
+	laplaceNMin = 16
+)
+
+func laplaceFreq1(fs0, decay int) int {
+	ft := 32768 - laplaceMinP*(2*laplaceNMin) - fs0
+	return ft * (16384 - decay) >> 15
+}
+
+func (e *rangeEncoder) laplace(value, fs, decay int) int {
+	fl := 0
+	if value != 0 {
+		s := 0
+		if value < 0 {
+			s = -1
+		}
+		val := (value + s) ^ s
+		fl = fs
+		fs = laplaceFreq1(fs, decay)
+		i := 1
+		for ; fs > 0 && i < val; i++ {
+			fs *= 2
+			fl += fs + 2*laplaceMinP
+			fs = fs * decay >> 15
+		}
+		if fs == 0 {
+			ndiMax := 32768 - fl + laplaceMinP - 1
+			ndiMax = (ndiMax - s) >> 1
+			di := min(val-i, ndiMax-1)
+			fl += (2*di + 1 + s) * laplaceMinP
+			fs = min(laplaceMinP, 32768-fl)
+			value = (i + di + s) ^ s
+		} else {
+			fs += laplaceMinP
+			fl += fs &^ s
+		}
+	}
+	e.encodeBin(uint32(fl), uint32(fl+fs), 15)
+	return value
+}
+
+// coarse energy prediction coefficients
+func energyCoefs(intra bool, lm int) (coef, beta float32) {
+	if intra {
+		return 0, betaIntra
+	}
+	return predCoef[lm], betaCoef[lm]
+}
+
+func quantCoarseEnergy(e *rangeEncoder, start, end int, bandLogE, oldE, errors []float32, intra bool, channels, lm int, maxDecay float32) {
+	budget := len(e.buf) * 8
+	if e.tell()+3 <= budget {
+		e.bitLogp(intra, 3)
+	}
+
+	probIdx := 0
+	if intra {
+		probIdx = 1
+	}
+	prob := &eProbModel[lm][probIdx]
+	coef, beta := energyCoefs(intra, lm)
+
+	var prev [2]float32
+
+	for i := start; i < end; i++ {
+		for c := 0; c < channels; c++ {
+			x := bandLogE[i+c*nbEBands]
+			old := max(-9, oldE[i+c*nbEBands])
+			f := x - coef*old - prev[c]
+			qi := int(math.Floor(float64(0.5 + f)))
+
+			decayBound := max(-28, oldE[i+c*nbEBands]) - maxDecay
+			if qi < 0 && x < decayBound {
+				qi += int(decayBound - x)
+				qi = min(qi, 0)
+			}
+
+			tell := e.tell()
+			bitsLeft := budget - tell - 3*channels*(end-i)
+			if i != start && bitsLeft < 30 {
+				if bitsLeft < 24 {
+					qi = min(1, qi)
+				}
+				if bitsLeft < 16 {
+					qi = max(-1, qi)
+				}
+			}
+
+			switch {
+			case budget-tell >= 15:
+				pi := 2 * min(i, 20)
+				qi = e.laplace(qi, int(prob[pi])<<7, int(prob[pi+1])<<6)
+			case budget-tell >= 2:
+				qi = max(-1, min(qi, 1))
+				s := 2 * qi
+				if qi < 0 {
+					s = -s - 1
+				}
+				e.icdf(s, smallEnergyICDF, 2)
+			case budget-tell >= 1:
+				qi = min(0, qi)
+				e.bit(-qi, 1)
+			default:
+				qi = -1
+			}
+
+			q := float32(qi)
+			errors[i+c*nbEBands] = f - q
+			oldE[i+c*nbEBands] = coef*old + prev[c] + q
+			prev[c] = prev[c] + q - beta*q
+		}
+	}
+}
+
+func quantFineEnergy(e *rangeEncoder, start, end int, oldE, errors []float32, fineQuant []int, channels int) {
+	for i := start; i < end; i++ {
+		if fineQuant[i] <= 0 {
+			continue
+		}
+		frac := 1 << fineQuant[i]
+		for c := 0; c < channels; c++ {
+			q2 := int(math.Floor(float64((errors[i+c*nbEBands] + 0.5) * float32(frac))))
+			q2 = max(0, min(q2, frac-1))
+			e.bits(uint32(q2), fineQuant[i])
+
+			offset := (float32(q2)+0.5)*float32(int(1)<<(14-fineQuant[i]))/16384 - 0.5
+			oldE[i+c*nbEBands] += offset
+			errors[i+c*nbEBands] -= offset
+		}
+	}
+}
+
+func quantEnergyFinalise(e *rangeEncoder, start, end int, oldE, errors []float32, fineQuant, finePriority []int, bitsLeft, channels int) {
+	for prio := 0; prio < 2; prio++ {
+		for i := start; i < end && bitsLeft >= channels; i++ {
+			if fineQuant[i] >= maxFineBits || finePriority[i] != prio {
+				continue
+			}
+			for c := 0; c < channels; c++ {
+				var q2 uint32
+				if errors[i+c*nbEBands] >= 0 {
+					q2 = 1
+				}
+				e.bits(q2, 1)
+
+				offset := (float32(q2) - 0.5) * float32(int(1)<<(14-fineQuant[i]-1)) / 16384
+				oldE[i+c*nbEBands] += offset
+				errors[i+c*nbEBands] -= offset
+				bitsLeft--
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package codec
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// window - CELT low-overlap power complementary window (rising half)
+var window [overlap]float32
+
+func init() {
+	for i := range window {
+		s := math.Sin(0.5 * math.Pi * (float64(i) + 0.5) / overlap)
+		window[i] = float32(math.Sin(0.5 * math.Pi * s * s))
+	}
+}
+
+// mdct - MDCT with n/2 coefficients and n samples, uses n-point complex FFT:
+// X[k] = sum(x[i] * cos(2pi/n * (i + n0) * (k + 1/2))), n0 = (n/2 + 1) / 2
+type mdct struct {
+	n         int
+	fft       *fft
+	pre, post []complex128
+	buf       []complex128
+}
+
+func newMDCT(n int) *mdct {
+	m := &mdct{
+		n:    n,
+		fft:  newFFT(n),
+		pre:  make([]complex128, n),
+		post: make([]complex128, n/2),
+		buf:  make([]complex128, n),
+	}
+	n0 := float64(n/2+1) / 2
+	for i := range m.pre {
+		m.pre[i] = cmplx.Exp(complex(0, -math.Pi*float64(i)/float64(n)))
+	}
+	for k := range m.post {
+		m.post[k] = cmplx.Exp(complex(0, -math.Pi*n0*float64(2*k+1)/float64(n)))
+	}
+	return m
+}
+
+// forward - src with n samples to dst with n/2 coefficients
+func (m *mdct) forward(dst, src []float32) {
+	for i, x := range src[:m.n] {
+		m.buf[i] = complex(float64(x), 0) * m.pre[i]
+	}
+	m.fft.transform(m.buf)
+	for k := range dst[:m.n/2] {
+		dst[k] = float32(real(m.buf[k] * m.post[k]))
+	}
+}
+
+// fft - mixed radix complex FFT for sizes with factors 2, 3, 4 and 5
+type fft struct {
+	n       int
+	factors []int // pairs of radix and remaining size
+	twiddle []complex128
+	scratch []complex128
+	tmp     []complex128
+}
+
+func newFFT(n int) *fft {
+	f := &fft{n: n, twiddle: make([]complex128, n), tmp: make([]complex128, n)}
+	for i := range f.twiddle {
+		f.twiddle[i] = cmplx.Exp(complex(0, -2*math.Pi*float64(i)/float64(n)))
+	}
+	for m := n; m > 1; {
+		p := 4
+		for m%p != 0 {
+			switch p {
+			case 4:
+				p = 2
+			case 2:
+				p = 3
+			default:
+				p += 2
+			}
+		}
+		m /= p
+		f.factors = append(f.factors, p, m)
+	}
+	f.scratch = make([]complex128, 5)
+	return f
+}
+
+// transform - forward transform in place: sum(z[i] * exp(-2pi*j*k*i/n))
+func (f *fft) transform(z []complex128) {
+	copy(f.tmp, z)
+	f.work(z, f.tmp, 1, f.factors)
+}
+
+func (f *fft) work(out, in []complex128, fstride int, factors []int) {
+	p, m := factors[0], factors[1]
+	if m == 1 {
+		for j := 0; j < p; j++ {
+			out[j] = in[j*fstride]
+		}
+	} else {
+		for j := 0; j < p; j++ {
+			f.work(out[j*m:], in[j*fstride:], fstride*p, factors[2:])
+		}
+	}
+
+	scratch := f.scratch[:p]
+	for u := 0; u < m; u++ {
+		for q := 0; q < p; q++ {
+			scratch[q] = out[u+q*m]
+		}
+		for q1 := 0; q1 < p; q1++ {
+			k := u + q1*m
+			sum := scratch[0]
+			idx := 0
+			for q := 1; q < p; q++ {
+				idx += fstride * k
+				idx %= f.n
+				sum += scratch[q] * f.twiddle[idx]
+			}
+			out[k] = sum
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package codec
+
+import "math/bits"
+
+// Range coder constants (RFC 6716, 4.1)
+const (
+	symBits   = 8
+	codeBits  = 32
+	symMax    = 1<<symBits - 1
+	codeShift = codeBits - symBits - 1
+	codeTop   = 1 << (codeBits - 1)
+	codeBot   = codeTop >> symBits
+	uintBits  = 8
+)
+
+func ilog(x uint32) int {
+	return 32 - bits.LeadingZeros32(x)
+}
+
+func tellFrac(nbits int, rng uint32) int {
+	correction := [8]uint32{35733, 38967, 42495, 46340, 50535, 55109, 60097, 65535}
+	l := ilog(rng)
+	r := rng >> (l - 16)
+	b := int(r>>12) - 8
+	if r > correction[b] {
+		b++
+	}
+	return nbits<<bitRes - (l<<3 + b)
+}
+
+// rangeEncoder - entropy encoder for fixed size frame
+type rangeEncoder struct {
+	buf     []byte
+	offs    int
+	endOffs int
+
+	endWindow uint32
+	nendBits  int
+	nbitsAll  int
+
+	rng uint32
+	val uint32
+	ext uint32
+	rem int
+	err bool
+}
+
+func newRangeEncoder(buf []byte) *rangeEncoder {
+	return &rangeEncoder{buf: buf, nbitsAll: codeBits + 1, rng: codeTop, rem: -1}
+}
+
+func (e *rangeEncoder) writeByte(v uint32) {
+	if e.offs+e.endOffs >= len(e.buf) {
+		e.err = true
+		return
+	}
+	e.buf[e.offs] = byte(v)
+	e.offs++
+}
+
+func (e *rangeEncoder) writeByteAtEnd(v uint32) {
+	if e.offs+e.endOffs >= len(e.buf) {
+		e.err = true
+		return
+	}
+	e.endOffs++
+	e.buf[len(e.buf)-e.endOffs] = byte(v)
+}
+
+func (e *rangeEncoder) carryOut(c int) {
+	if c == symMax {
+		e.ext++
+		return
+	}
+	carry := c >> symBits
+	if e.rem >= 0 {
+		e.writeByte(uint32(e.rem + carry))
+	}
+	for ; e.ext > 0; e.ext-- {
+		e.writeByte(uint32(symMax+carry) & symMax)
+	}
+	e.rem = c & symMax
+}
+
+func (e *rangeEncoder) normalize() {
+	for e.rng <= codeBot {
+		e.carryOut(int(e.val >> codeShift))
+		e.val = e.val << symBits & (codeTop - 1)
+		e.rng <<= symBits
+		e.nbitsAll += symBits
+	}
+}
+
+func (e *rangeEncoder) encode(fl, fh, ft uint32) {
+	r := e.rng / ft
+	if fl > 0 {
+		e.val += e.rng - r*(ft-fl)
+		e.rng = r * (fh - fl)
+	} else {
+		e.rng -= r * (ft - fh)
+	}
+	e.normalize()
+}
+
+func (e *rangeEncoder) encodeBin(fl, fh uint32, bits int) {
+	r := e.rng >> bits
+	if fl > 0 {
+		e.val += e.rng - r*(1<<bits-fl)
+		e.rng = r * (fh - fl)
+	} else {
+		e.rng -= r * (1<<bits - fh)
+	}
+	e.normalize()
+}
+
+func (e *rangeEncoder) bitLogp(val bool, logp int) {
+	s := e.rng >> logp
+	r := e.rng - s
+	if val {
+		e.val += r
+		e.rng = s
+	} else {
+		e.rng = r
+	}
+	e.normalize()
+}
+
+func (e *rangeEncoder) bit(val int, logp int) {
+	e.bitLogp(val != 0, logp)
+}
+
+func (e *rangeEncoder) icdf(s int, icdf []uint8, ftb int) {
+	r := e.rng >> ftb
+	if s > 0 {
+		e.val += e.rng - r*uint32(icdf[s-1])
+		e.rng = r * uint32(icdf[s-1]-icdf[s])
+	} else {
+		e.rng -= r * uint32(icdf[s])
+	}
+	e.normalize()
+}
+
+func (e *rangeEncoder) uint(fl, ft uint32) {
+	ft--
+	ftb := ilog(ft)
+	if ftb > uintBits {
+		ftb -= uintBits
+		ft1 := ft>>ftb + 1
+		fl1 := fl >> ftb
+		e.encode(fl1, fl1+1, ft1)
+		e.bits(fl&(1<<ftb-1), ftb)
+	} else {
+		e.encode(fl, fl+1, ft+1)
+	}
+}
+
+// bits - raw bits to the end of the frame
+func (e *rangeEncoder) bits(fl uint32, n int) {
+	window := e.endWindow
+	used := e.nendBits
+	if used+n > 32 {
+		for used >= symBits {
+			e.writeByteAtEnd(window & symMax)
+			window >>= symBits
+			used -= symBits
+		}
+	}
+	e.endWindow = window | fl<<used
+	e.nendBits = used + n
+	e.nbitsAll += n
+}
+
+func (e *rangeEncoder) tell() int {
+	return e.nbitsAll - ilog(e.rng)
+}
+
+func (e *rangeEncoder) tellFrac() int {
+	return tellFrac(e.nbitsAll, e.rng)
+}
+
+// done - flush the encoder, the rest of the buffer is zero padded
+func (e *rangeEncoder) done() {
+	l := codeBits - ilog(e.rng)
+	msk := uint32(codeTop-1) >> l
+	end := (e.val + msk) &^ msk
+	if end|msk >= e.val+e.rng {
+		l++
+		msk >>= 1
+		end = (e.val + msk) &^ msk
+	}
+	for l > 0 {
+		e.carryOut(int(end >> codeShift))
+		end = end << symBits & (codeTop - 1)
+		l -= symBits
+	}
+	if e.rem >= 0 || e.ext > 0 {
+		e.carryOut(0)
+	}
+
+	window := e.endWindow
+	used := e.nendBits
+	for used >= symBits {
+		e.writeByteAtEnd(window & symMax)
+		window >>= symBits
+		used -= symBits
+	}
+
+	if e.err {
+		return
+	}
+	clear(e.buf[e.offs : len(e.buf)-e.endOffs])
+	if used > 0 {
+		if e.endOffs >= len(e.buf) {
+			e.err = true
+			return
+		}
+		l = -l
+		if e.offs+e.endOffs >= len(e.buf) && l < used {
+			window &= 1<<l - 1
+			e.err = true
+		}
+		e.buf[len(e.buf)-e.endOffs-1] |= byte(window)
+	}
+}
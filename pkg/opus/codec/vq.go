@@ -0,0 +1,212 @@
+package codec
+
+import "math"
+
+const (
+	spreadNone       = 0
+	spreadLight      = 1
+	spreadNormal     = 2
+	spreadAggressive = 3
+)
+
+// Pyramid vector quantization (RFC 6716, 4.3.4)
+
+// pvqU - number of PVQ codewords U(N,K), table is symmetric
+var pvqU [][]uint32
+
+const pvqMax = 177
+
+func init() {
+	pvqU = make([][]uint32, pvqMax)
+	for n := range pvqU {
+		pvqU[n] = make([]uint32, pvqMax)
+	}
+	pvqU[0][0] = 1
+	for n := 1; n < pvqMax; n++ {
+		for k := 1; k < pvqMax; k++ {
+			v := uint64(pvqU[n-1][k]) + uint64(pvqU[n][k-1]) + uint64(pvqU[n-1][k-1])
+			pvqU[n][k] = uint32(min(v, math.MaxUint32))
+		}
+	}
+}
+
+// pvqV - number of vectors with N dimensions and K pulses
+func pvqV(n, k int) uint32 {
+	return pvqU[n][k] + pvqU[n][k+1]
+}
+
+// icwrs - encode pulses vector to codeword index
+func icwrs(n int, y []int) uint32 {
+	j := n - 1
+	var i uint32
+	if y[j] < 0 {
+		i = 1
+	}
+	k := abs(y[j])
+	for j > 0 {
+		j--
+		i += pvqU[n-j][k]
+		k += abs(y[j])
+		if y[j] < 0 {
+			i += pvqU[n-j][k+1]
+		}
+	}
+	return i
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func encodePulses(e *rangeEncoder, y []int, n, k int) {
+	e.uint(icwrs(n, y), pvqV(n, k))
+}
+
+func expRotation1(x []float32, n, stride int, c, s float32) {
+	for i := 0; i < n-stride; i++ {
+		x1, x2 := x[i], x[i+stride]
+		x[i+stride] = c*x2 + s*x1
+		x[i] = c*x1 - s*x2
+	}
+	for i := n - 2*stride - 1; i >= 0; i-- {
+		x1, x2 := x[i], x[i+stride]
+		x[i+stride] = c*x2 + s*x1
+		x[i] = c*x1 - s*x2
+	}
+}
+
+// expRotation - spreading rotation to avoid tonal artifacts
+func expRotation(x []float32, n, stride, k, spread int) {
+	if 2*k >= n || spread == spreadNone {
+		return
+	}
+
+	factor := [3]int{15, 10, 5}[spread-1]
+	gain := float32(n) / float32(n+factor*k)
+	theta := 0.5 * gain * gain
+	c := float32(math.Cos(0.5 * math.Pi * float64(theta)))
+	s := float32(math.Cos(0.5 * math.Pi * float64(1-theta)))
+
+	stride2 := 0
+	if n >= 8*stride {
+		stride2 = 1
+		for (stride2*stride2+stride2)*stride+stride>>2 < n {
+			stride2++
+		}
+	}
+
+	n /= stride
+	for i := 0; i < stride; i++ {
+		xi := x[i*n:]
+		expRotation1(xi, n, 1, c, -s)
+		if stride2 != 0 {
+			expRotation1(xi, n, stride2, s, -c)
+		}
+	}
+}
+
+func algQuant(e *rangeEncoder, x []float32, n, k, spread, b int) {
+	y := make([]int, n)
+	expRotation(x, n, b, k, spread)
+	pvqSearch(x, y, k, n)
+	encodePulses(e, y, n, k)
+}
+
+// pvqSearch - find the closest pulses vector with K pulses, returns sum of squares
+func pvqSearch(x []float32, iy []int, k, n int) float32 {
+	y := make([]float32, n)
+	signx := make([]bool, n)
+	ax := make([]float32, n)
+
+	for j := 0; j < n; j++ {
+		signx[j] = x[j] < 0
+		ax[j] = float32(math.Abs(float64(x[j])))
+		iy[j] = 0
+	}
+
+	var xy, yy float32
+	pulsesLeft := k
+
+	if k > n>>1 {
+		var sum float32
+		for j := 0; j < n; j++ {
+			sum += ax[j]
+		}
+		if !(sum > 1e-15 && sum < 64) {
+			ax[0] = 1
+			clear(ax[1:])
+			sum = 1
+		}
+		rcp := float32(k) / sum
+		for j := 0; j < n; j++ {
+			iy[j] = int(math.Floor(float64(rcp * ax[j])))
+			y[j] = float32(iy[j])
+			yy += y[j] * y[j]
+			xy += ax[j] * y[j]
+			y[j] *= 2
+			pulsesLeft -= iy[j]
+		}
+	}
+
+	if pulsesLeft > n+3 {
+		tmp := float32(pulsesLeft)
+		yy += tmp * tmp
+		yy += tmp * y[0]
+		iy[0] += pulsesLeft
+		pulsesLeft = 0
+	}
+
+	for i := 0; i < pulsesLeft; i++ {
+		yy++
+		bestID := 0
+		rxy := xy + ax[0]
+		bestNum := rxy * rxy
+		bestDen := yy + y[0]
+		for j := 1; j < n; j++ {
+			rxy = xy + ax[j]
+			ryy := yy + y[j]
+			rxy *= rxy
+			if bestDen*rxy > ryy*bestNum {
+				bestDen = ryy
+				bestNum = rxy
+				bestID = j
+			}
+		}
+		xy += ax[bestID]
+		yy += y[bestID]
+		y[bestID] += 2
+		iy[bestID]++
+	}
+
+	for j := 0; j < n; j++ {
+		if signx[j] {
+			iy[j] = -iy[j]
+		}
+	}
+
+	return yy
+}
+
+// stereoItheta - angle between mid and side (stereo) or between two halves
+func stereoItheta(x, y []float32, stereo bool, n int) int {
+	emid, eside := float32(1e-15), float32(1e-15)
+	if stereo {
+		for i := 0; i < n; i++ {
+			m := x[i] + y[i]
+			s := x[i] - y[i]
+			emid += m * m
+			eside += s * s
+		}
+	} else {
+		for i := 0; i < n; i++ {
+			emid += x[i] * x[i]
+			eside += y[i] * y[i]
+		}
+	}
+	mid := math.Sqrt(float64(emid))
+	side := math.Sqrt(float64(eside))
+	return int(math.Floor(0.5 + 16384*0.63662*math.Atan2(side, mid)))
+}
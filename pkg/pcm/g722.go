@@ -0,0 +1,308 @@
+package pcm
+
+// G.722 64 kbit/s codec, 16000 Hz samples, 2 samples per byte.
+// RTP clock rate is 8000 for historical reasons (RFC 3551), so 1 byte is 1 RTP tick.
+// https://www.itu.int/rec/T-REC-G.722
+// https://github.com/freeswitch/spandsp/blob/master/src/g722.c
+
+const G722SampleRate = 16000
+
+var (
+	g722QMF  = [12]int32{3, -11, 12, 32, -210, 951, 3876, -805, 362, -156, 53, -11}
+	g722WL   = [8]int32{-60, -30, 58, 172, 334, 538, 1198, 3042}
+	g722RL42 = [16]int32{0, 7, 6, 5, 4, 3, 2, 1, 7, 6, 5, 4, 3, 2, 1, 0}
+	g722ILB  = [32]int32{
+		2048, 2093, 2139, 2186, 2233, 2282, 2332, 2383, 2435, 2489, 2543, 2599, 2656, 2714, 2774, 2834,
+		2896, 2960, 3025, 3091, 3158, 3228, 3298, 3371, 3444, 3520, 3597, 3676, 3756, 3838, 3922, 4008,
+	}
+	g722WH  = [3]int32{0, -214, 798}
+	g722RH2 = [4]int32{2, 1, 2, 1}
+	g722QM2 = [4]int32{-7408, -1616, 7408, 1616}
+	g722QM4 = [16]int32{
+		0, -20456, -12896, -8968, -6288, -4240, -2584, -1200,
+		20456, 12896, 8968, 6288, 4240, 2584, 1200, 0,
+	}
+	g722QM6 = [64]int32{
+		-136, -136, -136, -136, -24808, -21904, -19008, -16704,
+		-14984, -13512, -12280, -11192, -10232, -9360, -8576, -7856,
+		-7192, -6576, -6000, -5456, -4944, -4464, -4008, -3576,
+		-3168, -2776, -2400, -2032, -1688, -1360, -1040, -728,
+		24808, 21904, 19008, 16704, 14984, 13512, 12280, 11192,
+		10232, 9360, 8576, 7856, 7192, 6576, 6000, 5456,
+		4944, 4464, 4008, 3576, 3168, 2776, 2400, 2032,
+		1688, 1360, 1040, 728, 432, 136, -432, -136,
+	}
+	g722Q6 = [32]int32{
+		0, 35, 72, 110, 150, 190, 233, 276, 323, 370, 422, 473, 530, 587, 650, 714,
+		786, 858, 940, 1023, 1121, 1219, 1339, 1458, 1612, 1765, 1980, 2195, 2557, 2919, 0, 0,
+	}
+	g722ILN = [32]int32{
+		0, 63, 62, 31, 30, 29, 28, 27, 26, 25, 24, 23, 22, 21, 20, 19,
+		18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 0,
+	}
+	g722ILP = [32]int32{
+		0, 61, 60, 59, 58, 57, 56, 55, 54, 53, 52, 51, 50, 49, 48, 47,
+		46, 45, 44, 43, 42, 41, 40, 39, 38, 37, 36, 35, 34, 33, 32, 0,
+	}
+	g722IHN = [3]int32{0, 1, 0}
+	g722IHP = [3]int32{0, 3, 2}
+)
+
+type g722Band struct {
+	s, sp, sz int32
+	r, a, ap  [3]int32
+	p         [3]int32
+	d, b, bp  [7]int32
+	sg        [7]int32
+	nb, det   int32
+}
+
+// G722Decoder - G.722 payload to 16 bit samples
+type G722Decoder struct {
+	band [2]g722Band
+	x    [24]int32
+}
+
+// G722Encoder - 16 bit samples to G.722 payload
+type G722Encoder struct {
+	band [2]g722Band
+	x    [24]int32
+	tail []int16
+}
+
+func NewG722Decoder() *G722Decoder {
+	d := &G722Decoder{}
+	d.band[0].det = 32
+	d.band[1].det = 8
+	return d
+}
+
+func NewG722Encoder() *G722Encoder {
+	e := &G722Encoder{}
+	e.band[0].det = 32
+	e.band[1].det = 8
+	return e
+}
+
+// Decode - each byte is two samples
+func (d *G722Decoder) Decode(src []byte) []int16 {
+	dst := make([]int16, 0, 2*len(src))
+
+	for _, code := range src {
+		low := int32(code & 0x3F)
+		high := int32(code >> 6)
+
+		// low band reconstructed with 6 bit and adapted with 4 bit code
+		lo := &d.band[0]
+		rlow := clamp(lo.s+(lo.det*g722QM6[low])>>15, -16384, 16383)
+		dlow := (lo.det * g722QM4[low>>2]) >> 15
+		lo.scale(g722WL[g722RL42[low>>2]], 18432, 8)
+		lo.update(dlow)
+
+		hi := &d.band[1]
+		dhigh := (hi.det * g722QM2[high]) >> 15
+		rhigh := clamp(hi.s+dhigh, -16384, 16383)
+		hi.scale(g722WH[g722RH2[high]], 22528, 10)
+		hi.update(dhigh)
+
+		// receive QMF
+		copy(d.x[:], d.x[2:])
+		d.x[22] = rlow + rhigh
+		d.x[23] = rlow - rhigh
+
+		var out1, out2 int32
+		for i := 0; i < 12; i++ {
+			out2 += d.x[2*i] * g722QMF[i]
+			out1 += d.x[2*i+1] * g722QMF[11-i]
+		}
+
+		dst = append(dst, int16(saturate(out1>>11)), int16(saturate(out2>>11)))
+	}
+
+	return dst
+}
+
+// Encode - each two samples is one byte, odd sample is kept for the next call
+func (e *G722Encoder) Encode(src []int16) []byte {
+	if e.tail != nil {
+		src = append(e.tail, src...)
+		e.tail = nil
+	}
+	if len(src)%2 != 0 {
+		e.tail = []int16{src[len(src)-1]}
+	}
+
+	dst := make([]byte, 0, len(src)/2)
+
+	for i := 0; i+1 < len(src); i += 2 {
+		// transmit QMF
+		copy(e.x[:], e.x[2:])
+		e.x[22] = int32(src[i])
+		e.x[23] = int32(src[i+1])
+
+		var even, odd int32
+		for j := 0; j < 12; j++ {
+			odd += e.x[2*j] * g722QMF[j]
+			even += e.x[2*j+1] * g722QMF[11-j]
+		}
+		xlow := (even + odd) >> 14
+		xhigh := (even - odd) >> 14
+
+		// low band, 6 bit quantizer
+		lo := &e.band[0]
+		el := saturate(xlow - lo.s)
+		wd := el
+		if el < 0 {
+			wd = -(el + 1)
+		}
+		j := int32(1)
+		for ; j < 30; j++ {
+			if wd < (g722Q6[j]*lo.det)>>12 {
+				break
+			}
+		}
+		var low int32
+		if el < 0 {
+			low = g722ILN[j]
+		} else {
+			low = g722ILP[j]
+		}
+		dlow := (lo.det * g722QM4[low>>2]) >> 15
+		lo.scale(g722WL[g722RL42[low>>2]], 18432, 8)
+		lo.update(dlow)
+
+		// high band, 2 bit quantizer
+		hi := &e.band[1]
+		eh := saturate(xhigh - hi.s)
+		wd = eh
+		if eh < 0 {
+			wd = -(eh + 1)
+		}
+		mih := 1
+		if wd >= (564*hi.det)>>12 {
+			mih = 2
+		}
+		var high int32
+		if eh < 0 {
+			high = g722IHN[mih]
+		} else {
+			high = g722IHP[mih]
+		}
+		dhigh := (hi.det * g722QM2[high]) >> 15
+		hi.scale(g722WH[g722RH2[high]], 22528, 10)
+		hi.update(dhigh)
+
+		dst = append(dst, byte(high<<6|low))
+	}
+
+	return dst
+}
+
+// scale - LOGSCL and SCALEL (SCALEH) blocks
+func (b *g722Band) scale(w, limit, shift int32) {
+	b.nb = clamp((b.nb*127)>>7+w, 0, limit)
+
+	wd1 := (b.nb >> 6) & 31
+	wd2 := shift - (b.nb >> 11)
+	var wd3 int32
+	if wd2 < 0 {
+		wd3 = g722ILB[wd1] << -wd2
+	} else {
+		wd3 = g722ILB[wd1] >> wd2
+	}
+	b.det = wd3 << 2
+}
+
+// update - adaptive predictor, block 4 of the standard
+func (b *g722Band) update(d int32) {
+	// RECONS, PARREC
+	b.d[0] = d
+	b.r[0] = saturate(b.s + d)
+	b.p[0] = saturate(b.sz + d)
+
+	// UPPOL2
+	for i := 0; i < 3; i++ {
+		b.sg[i] = b.p[i] >> 15
+	}
+	wd1 := saturate(b.a[1] << 2)
+	wd2 := wd1
+	if b.sg[0] == b.sg[1] {
+		wd2 = -wd1
+	}
+	if wd2 > 32767 {
+		wd2 = 32767
+	}
+	wd3 := int32(-128)
+	if b.sg[0] == b.sg[2] {
+		wd3 = 128
+	}
+	wd3 += wd2 >> 7
+	wd3 += (b.a[2] * 32512) >> 15
+	b.ap[2] = clamp(wd3, -12288, 12288)
+
+	// UPPOL1
+	wd1 = -192
+	if b.sg[0] == b.sg[1] {
+		wd1 = 192
+	}
+	wd2 = (b.a[1] * 32640) >> 15
+	b.ap[1] = saturate(wd1 + wd2)
+	wd3 = saturate(15360 - b.ap[2])
+	b.ap[1] = clamp(b.ap[1], -wd3, wd3)
+
+	// UPZERO
+	wd1 = 128
+	if d == 0 {
+		wd1 = 0
+	}
+	b.sg[0] = d >> 15
+	for i := 1; i < 7; i++ {
+		b.sg[i] = b.d[i] >> 15
+		wd2 = -wd1
+		if b.sg[i] == b.sg[0] {
+			wd2 = wd1
+		}
+		wd3 = (b.b[i] * 32640) >> 15
+		b.bp[i] = saturate(wd2 + wd3)
+	}
+
+	// DELAYA
+	for i := 6; i > 0; i-- {
+		b.d[i] = b.d[i-1]
+		b.b[i] = b.bp[i]
+	}
+	for i := 2; i > 0; i-- {
+		b.r[i] = b.r[i-1]
+		b.p[i] = b.p[i-1]
+		b.a[i] = b.ap[i]
+	}
+
+	// FILTEP
+	wd1 = (b.a[1] * saturate(b.r[1]+b.r[1])) >> 15
+	wd2 = (b.a[2] * saturate(b.r[2]+b.r[2])) >> 15
+	b.sp = saturate(wd1 + wd2)
+
+	// FILTEZ
+	b.sz = 0
+	for i := 6; i > 0; i-- {
+		b.sz += (b.b[i] * saturate(b.d[i]+b.d[i])) >> 15
+	}
+	b.sz = saturate(b.sz)
+
+	// PREDIC
+	b.s = saturate(b.sp + b.sz)
+}
+
+func saturate(v int32) int32 {
+	return clamp(v, -32768, 32767)
+}
+
+func clamp(v, lo, hi int32) int32 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
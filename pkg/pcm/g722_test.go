@@ -0,0 +1,40 @@
+package pcm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestG722(t *testing.T) {
+	// low band and high band tones
+	for _, freq := range []float64{1000, 6000} {
+		enc := NewG722Encoder()
+		dec := NewG722Decoder()
+
+		var src, dst []int16
+		for i := 0; i < 50; i++ {
+			samples := make([]int16, 320)
+			for j := range samples {
+				samples[j] = int16(10000 * math.Sin(2*math.Pi*freq*float64(i*320+j)/G722SampleRate))
+			}
+			src = append(src, samples...)
+
+			payload := enc.Encode(samples)
+			require.Len(t, payload, 160)
+
+			dst = append(dst, dec.Decode(payload)...)
+		}
+
+		// QMF delay is 22 samples, skip the start for the predictor adaptation
+		const delay = 22
+		var signal, noise float64
+		for i := 4000; i < len(src)-delay; i++ {
+			d := float64(dst[i+delay]) - float64(src[i])
+			signal += float64(src[i]) * float64(src[i])
+			noise += d * d
+		}
+		require.Greater(t, 10*math.Log10(signal/noise), 20.0)
+	}
+}
@@ -87,8 +87,8 @@ func BytesPerSample(codec *core.Codec) int {
 	switch codec.Name {
 	case core.CodecPCML, core.CodecPCM:
 		return 2
-	case core.CodecPCMU, core.CodecPCMA:
-		return 1
+	case core.CodecPCMU, core.CodecPCMA, core.CodecG722:
+		return 1 // G.722 has 2 samples per byte, but 1 byte per RTP tick
 	}
 	return 0
 }
@@ -71,13 +71,47 @@ func FlipEndian(src []byte) (dst []byte) {
 }
 
 func Transcode(dst, src *core.Codec) func([]byte) []byte {
-	var reader func([]byte) []int16
-	var writer func([]int16) []byte
+	reader := newReader(src.Name)
+	writer := newWriter(dst.Name)
+
 	var filters []func([]int16) []int16
 
-	switch src.Name {
+	if src.Channels > 1 {
+		filters = append(filters, Downsample(float32(src.Channels)))
+	}
+
+	if srcRate, dstRate := sampleRate(src), sampleRate(dst); srcRate > dstRate {
+		filters = append(filters, Downsample(float32(srcRate)/float32(dstRate)))
+	} else if srcRate < dstRate {
+		filters = append(filters, Upsample(float32(dstRate)/float32(srcRate)))
+	}
+
+	if dst.Channels > 1 {
+		filters = append(filters, Upsample(float32(dst.Channels)))
+	}
+
+	return func(b []byte) []byte {
+		samples := reader(b)
+		for _, filter := range filters {
+			samples = filter(samples)
+		}
+		return writer(samples)
+	}
+}
+
+// sampleRate - G.722 has different sample rate and RTP clock rate
+func sampleRate(codec *core.Codec) uint32 {
+	if codec.Name == core.CodecG722 {
+		return G722SampleRate
+	}
+	return codec.ClockRate
+}
+
+// newReader - PCM/PCMA/PCMU/PCML/G722 payload to 16 bit samples
+func newReader(name string) func([]byte) []int16 {
+	switch name {
 	case core.CodecPCML:
-		reader = func(src []byte) (dst []int16) {
+		return func(src []byte) (dst []int16) {
 			var i, j int
 			n := len(src)
 			dst = make([]int16, n/2)
@@ -92,7 +126,7 @@ func Transcode(dst, src *core.Codec) func([]byte) []byte {
 			return
 		}
 	case core.CodecPCM:
-		reader = func(src []byte) (dst []int16) {
+		return func(src []byte) (dst []int16) {
 			var i, j int
 			n := len(src)
 			dst = make([]int16, n/2)
@@ -107,7 +141,7 @@ func Transcode(dst, src *core.Codec) func([]byte) []byte {
 			return
 		}
 	case core.CodecPCMU:
-		reader = func(src []byte) (dst []int16) {
+		return func(src []byte) (dst []int16) {
 			var i int
 			dst = make([]int16, len(src))
 			for _, sample := range src {
@@ -117,7 +151,7 @@ func Transcode(dst, src *core.Codec) func([]byte) []byte {
 			return
 		}
 	case core.CodecPCMA:
-		reader = func(src []byte) (dst []int16) {
+		return func(src []byte) (dst []int16) {
 			var i int
 			dst = make([]int16, len(src))
 			for _, sample := range src {
@@ -126,25 +160,18 @@ func Transcode(dst, src *core.Codec) func([]byte) []byte {
 			}
 			return
 		}
+	case core.CodecG722:
+		return NewG722Decoder().Decode
 	}
 
-	if src.Channels > 1 {
-		filters = append(filters, Downsample(float32(src.Channels)))
-	}
-
-	if src.ClockRate > dst.ClockRate {
-		filters = append(filters, Downsample(float32(src.ClockRate)/float32(dst.ClockRate)))
-	} else if src.ClockRate < dst.ClockRate {
-		filters = append(filters, Upsample(float32(dst.ClockRate)/float32(src.ClockRate)))
-	}
-
-	if dst.Channels > 1 {
-		filters = append(filters, Upsample(float32(dst.Channels)))
-	}
+	return nil
+}
 
-	switch dst.Name {
+// newWriter - 16 bit samples to PCM/PCMA/PCMU/PCML/G722 payload
+func newWriter(name string) func([]int16) []byte {
+	switch name {
 	case core.CodecPCML:
-		writer = func(src []int16) (dst []byte) {
+		return func(src []int16) (dst []byte) {
 			var i int
 			dst = make([]byte, len(src)*2)
 			for _, sample := range src {
@@ -156,7 +183,7 @@ func Transcode(dst, src *core.Codec) func([]byte) []byte {
 			return
 		}
 	case core.CodecPCM:
-		writer = func(src []int16) (dst []byte) {
+		return func(src []int16) (dst []byte) {
 			var i int
 			dst = make([]byte, len(src)*2)
 			for _, sample := range src {
@@ -168,7 +195,7 @@ func Transcode(dst, src *core.Codec) func([]byte) []byte {
 			return
 		}
 	case core.CodecPCMU:
-		writer = func(src []int16) (dst []byte) {
+		return func(src []int16) (dst []byte) {
 			var i int
 			dst = make([]byte, len(src))
 			for _, sample := range src {
@@ -178,7 +205,7 @@ func Transcode(dst, src *core.Codec) func([]byte) []byte {
 			return
 		}
 	case core.CodecPCMA:
-		writer = func(src []int16) (dst []byte) {
+		return func(src []int16) (dst []byte) {
 			var i int
 			dst = make([]byte, len(src))
 			for _, sample := range src {
@@ -187,15 +214,11 @@ func Transcode(dst, src *core.Codec) func([]byte) []byte {
 			}
 			return
 		}
+	case core.CodecG722:
+		return NewG722Encoder().Encode
 	}
 
-	return func(b []byte) []byte {
-		samples := reader(b)
-		for _, filter := range filters {
-			samples = filter(samples)
-		}
-		return writer(samples)
-	}
+	return nil
 }
 
 func ConsumerCodecs() []*core.Codec {
@@ -0,0 +1,403 @@
+package pcm
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/AlexxIT/go2rtc/pkg/aac"
+	aacdec "github.com/AlexxIT/go2rtc/pkg/aac/decoder"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	opus "github.com/AlexxIT/go2rtc/pkg/opus/codec"
+	"github.com/pion/rtp"
+)
+
+// In-process audio transcoding graph: decoder => mixer => resampler => encoder.
+// Samples between nodes are interleaved float32 in [-1, 1] range.
+
+// CanDecode - codec can be decoded by in-process audio graph. Opus is not here, because
+// there is no in-process Opus decoder, so Opus sources are transcoded with FFmpeg.
+func CanDecode(codec *core.Codec) bool {
+	switch codec.Name {
+	case core.CodecAAC, core.CodecELD, core.CodecG722,
+		core.CodecPCMA, core.CodecPCMU, core.CodecPCM, core.CodecPCML:
+		return true
+	}
+	return false
+}
+
+// CanEncode - codec can be encoded by in-process audio graph
+func CanEncode(codec *core.Codec) bool {
+	switch codec.Name {
+	case core.CodecOpus, core.CodecG722, core.CodecPCMA, core.CodecPCMU, core.CodecPCM, core.CodecPCML:
+		return true
+	}
+	return false
+}
+
+// MatchTranscode - same as core.Media.MatchMedia, but for codecs that don't match directly
+// and can be transcoded in-process. Source codec depends on the direction of producer media.
+func MatchTranscode(prodMedia, consMedia *core.Media) (prodCodec, consCodec *core.Codec) {
+	if prodMedia.Kind != core.KindAudio || consMedia.Kind != core.KindAudio ||
+		prodMedia.Direction == core.DirectionSendonly && consMedia.Direction != core.DirectionRecvonly ||
+		prodMedia.Direction == core.DirectionRecvonly && consMedia.Direction != core.DirectionSendonly {
+		return nil, nil
+	}
+
+	// consumer codecs order is the priority for recvonly and producer codecs order for backchannel
+	for _, consCodec = range consMedia.Codecs {
+		for _, prodCodec = range prodMedia.Codecs {
+			if prodMedia.Direction == core.DirectionRecvonly {
+				if CanDecode(prodCodec) && CanEncode(consCodec) {
+					return
+				}
+			} else if CanDecode(consCodec) && CanEncode(prodCodec) {
+				return
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// TranscodeReceiver - new receiver with dst codec, that gets packets from the src track
+// and transcodes them in-process. Receiver is closed with the last consumer.
+// Optional onError is called for each packet that can't be decoded.
+func TranscodeReceiver(media *core.Media, dst *core.Codec, track *core.Receiver, onError func(error)) (*core.Receiver, error) {
+	dst = OutputCodec(dst, track.Codec)
+
+	recv := core.NewReceiver(media, dst)
+
+	handler, err := transcodeAudio(dst, track.Codec, recv.Input, onError)
+	if err != nil {
+		return nil, err
+	}
+
+	recv.Input = handler
+	recv.Node.WithParent(&track.Node)
+	return recv, nil
+}
+
+// OutputCodec - fill unknown clock rate and channels of dst codec
+func OutputCodec(dst, src *core.Codec) *core.Codec {
+	dst = dst.Clone()
+
+	switch dst.Name {
+	case core.CodecOpus:
+		dst.ClockRate = 48000
+		if dst.Channels == 0 {
+			dst.Channels = 2
+		}
+	case core.CodecPCMA, core.CodecPCMU:
+		if dst.ClockRate == 0 {
+			dst.ClockRate = 8000
+		}
+	case core.CodecG722:
+		dst.ClockRate = 8000 // RTP clock rate is always 8000
+		dst.Channels = 1
+	case core.CodecPCM, core.CodecPCML:
+		if dst.ClockRate == 0 {
+			dst.ClockRate = min(src.ClockRate, 48000)
+		}
+		if dst.Channels == 0 {
+			dst.Channels = src.Channels
+		}
+	}
+
+	if dst.Channels == 0 {
+		dst.Channels = 1
+	}
+
+	return dst
+}
+
+var ErrTranscode = errors.New("pcm: unsupported transcoding")
+
+// TranscodeAudio - in-process transcoding handler from src to dst codec,
+// dst codec should have known clock rate and channels (check OutputCodec)
+func TranscodeAudio(dst, src *core.Codec, handler core.HandlerFunc) (core.HandlerFunc, error) {
+	return transcodeAudio(dst, src, handler, nil)
+}
+
+func transcodeAudio(dst, src *core.Codec, handler core.HandlerFunc, onError func(error)) (core.HandlerFunc, error) {
+	decode, srcRate, srcChannels, err := newDecoder(src)
+	if err != nil {
+		return nil, err
+	}
+
+	dstRate, dstChannels := sampleRate(dst), dst.Channels
+
+	if dst.Name == core.CodecOpus {
+		// Opus packets may be mono inside the stereo RTP session (RFC 7587)
+		dstChannels = min(srcChannels, dstChannels)
+	}
+
+	encode := newEncoder(dst, dstChannels)
+	if encode == nil {
+		return nil, ErrTranscode
+	}
+
+	mix := Mixer(srcChannels, dstChannels)
+	resample := Resampler(srcRate, dstRate, dstChannels)
+
+	var mu sync.Mutex
+	var seq uint16
+	var ts uint32
+	var next uint32 // expected timestamp of the next src packet
+	var started bool
+
+	fn := func(packet *rtp.Packet) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		samples, err := decode(packet.Payload)
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			return
+		}
+		if len(samples) == 0 {
+			return
+		}
+
+		// packet duration in src clock rate
+		duration := uint32(uint64(len(samples)/int(srcChannels)) * uint64(src.ClockRate) / uint64(srcRate))
+
+		// keep sync with other tracks of the same source,
+		// re-anchor after source gaps larger than one frame
+		if !started || int32(packet.Timestamp-next) > int32(duration) {
+			ts = uint32(uint64(packet.Timestamp) * uint64(dst.ClockRate) / uint64(src.ClockRate))
+			started = true
+		}
+		next = packet.Timestamp + duration
+
+		samples = resample(mix(samples))
+
+		encode(samples, func(payload []byte, frames int) {
+			pkt := &rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					SequenceNumber: seq,
+					Timestamp:      ts,
+					SSRC:           packet.SSRC,
+				},
+				Payload: payload,
+			}
+			seq++
+			ts += uint32(frames)
+			handler(pkt)
+		})
+	}
+
+	if src.IsRTP() {
+		switch src.Name {
+		case core.CodecAAC, core.CodecELD:
+			return aac.RTPDepayFrames(aac.FrameLength(src), fn), nil
+		}
+	}
+
+	return fn, nil
+}
+
+// newDecoder - payload to samples with actual sample rate and channels
+func newDecoder(codec *core.Codec) (func([]byte) ([]float32, error), uint32, uint8, error) {
+	switch codec.Name {
+	case core.CodecAAC, core.CodecELD:
+		conf, err := hex.DecodeString(core.Between(codec.FmtpLine, "config=", ";"))
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		dec, err := aacdec.New(conf)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		decode := func(b []byte) ([]float32, error) {
+			samples, err := dec.DecodeFloat(b)
+			for i, sample := range samples {
+				samples[i] = sample / 32768
+			}
+			return samples, err
+		}
+		return decode, dec.SampleRate, dec.Channels, nil
+
+	}
+
+	if reader := newReader(codec.Name); reader != nil {
+		decode := func(b []byte) ([]float32, error) {
+			samples := reader(b)
+			dst := make([]float32, len(samples))
+			for i, sample := range samples {
+				dst[i] = float32(sample) / 32768
+			}
+			return dst, nil
+		}
+		return decode, sampleRate(codec), max(codec.Channels, 1), nil
+	}
+
+	return nil, 0, 0, ErrTranscode
+}
+
+// newEncoder - samples to payloads, emit is called with payload and its duration in frames
+func newEncoder(codec *core.Codec, channels uint8) func(samples []float32, emit func([]byte, int)) {
+	if codec.Name == core.CodecOpus {
+		enc := opus.NewEncoder(int(channels), opus.DefaultBitrate*int(channels))
+		frameSize := enc.FrameSize * int(channels)
+
+		var buf []float32
+
+		return func(samples []float32, emit func([]byte, int)) {
+			buf = append(buf, samples...)
+			for len(buf) >= frameSize {
+				if payload, err := enc.Encode(buf[:frameSize]); err == nil {
+					emit(payload, enc.FrameSize)
+				}
+				buf = buf[frameSize:]
+			}
+			// move tail to the start of the buffer
+			buf = append(buf[:0:0], buf...)
+		}
+	}
+
+	if writer := newWriter(codec.Name); writer != nil {
+		return func(samples []float32, emit func([]byte, int)) {
+			dst := make([]int16, len(samples))
+			for i, sample := range samples {
+				dst[i] = clip16(sample * 32768)
+			}
+			payload := writer(dst)
+			emit(payload, len(payload)/BytesPerFrame(codec))
+		}
+	}
+
+	return nil
+}
+
+func clip16(f float32) int16 {
+	switch {
+	case f >= 32767:
+		return 32767
+	case f <= -32768:
+		return -32768
+	}
+	return int16(f)
+}
+
+// Mixer - convert interleaved samples between channels count,
+// downmix is average of all channels, upmix is duplicate of mono
+func Mixer(srcChannels, dstChannels uint8) func([]float32) []float32 {
+	if srcChannels == dstChannels {
+		return func(src []float32) []float32 {
+			return src
+		}
+	}
+
+	sch, dch := int(srcChannels), int(dstChannels)
+	k := 1 / float32(sch)
+
+	return func(src []float32) []float32 {
+		n := len(src) / sch
+		dst := make([]float32, n*dch)
+		for i := 0; i < n; i++ {
+			var sum float32
+			for _, sample := range src[i*sch : i*sch+sch] {
+				sum += sample
+			}
+			for j := 0; j < dch; j++ {
+				dst[i*dch+j] = sum * k
+			}
+		}
+		return dst
+	}
+}
+
+// Resampler - linear interpolation resampler for interleaved samples,
+// with low-pass filter for downsampling
+func Resampler(srcRate, dstRate uint32, channels uint8) func([]float32) []float32 {
+	if srcRate == dstRate || srcRate == 0 || dstRate == 0 {
+		return func(src []float32) []float32 {
+			return src
+		}
+	}
+
+	var filter func([]float32) []float32
+	if dstRate < srcRate {
+		filter = Lowpass(0.45*float64(dstRate)/float64(srcRate), channels)
+	}
+
+	ch := int(channels)
+	step := float64(srcRate) / float64(dstRate)
+
+	var pos float64             // position of the next output frame, -1 is the last frame of prev chunk
+	prev := make([]float32, ch) // last frame of the previous chunk
+
+	return func(src []float32) []float32 {
+		if filter != nil {
+			src = filter(src)
+		}
+
+		n := len(src) / ch
+		dst := make([]float32, 0, int(float64(n)/step+1)*ch)
+
+		for ; pos < float64(n-1); pos += step {
+			i := int(pos+1) - 1 // floor for pos >= -1
+			f := float32(pos - float64(i))
+			for c := 0; c < ch; c++ {
+				s0 := prev[c]
+				if i >= 0 {
+					s0 = src[i*ch+c]
+				}
+				s1 := src[(i+1)*ch+c]
+				dst = append(dst, s0+(s1-s0)*f)
+			}
+		}
+
+		if n > 0 {
+			copy(prev, src[(n-1)*ch:])
+			pos -= float64(n)
+		}
+
+		return dst
+	}
+}
+
+// Lowpass - windowed sinc FIR filter for interleaved samples, cutoff is relative to sample rate
+func Lowpass(cutoff float64, channels uint8) func([]float32) []float32 {
+	taps := int(4/cutoff) | 1
+	center := taps / 2
+
+	kernel := make([]float32, taps)
+	var sum float64
+	for i := range kernel {
+		x := float64(i - center)
+		v := 2 * cutoff
+		if x != 0 {
+			v = math.Sin(2*math.Pi*cutoff*x) / (math.Pi * x)
+		}
+		v *= 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(taps-1)) // Hamming
+		kernel[i] = float32(v)
+		sum += v
+	}
+	for i := range kernel {
+		kernel[i] /= float32(sum)
+	}
+
+	ch := int(channels)
+	history := make([]float32, (taps-1)*ch)
+
+	return func(src []float32) []float32 {
+		buf := append(history, src...)
+		dst := make([]float32, len(src))
+		for i := range dst {
+			var acc float32
+			for j, k := range kernel {
+				acc += buf[i+j*ch] * k
+			}
+			dst[i] = acc
+		}
+		history = append(history[:0:0], buf[len(buf)-len(history):]...)
+		return dst
+	}
+}
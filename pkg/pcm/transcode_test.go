@@ -0,0 +1,116 @@
+package pcm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchTranscode(t *testing.T) {
+	prod := &core.Media{
+		Kind: core.KindAudio, Direction: core.DirectionRecvonly,
+		Codecs: []*core.Codec{{Name: core.CodecAAC, ClockRate: 16000, Channels: 1}},
+	}
+	cons := &core.Media{
+		Kind: core.KindAudio, Direction: core.DirectionSendonly,
+		Codecs: []*core.Codec{{Name: core.CodecOpus, ClockRate: 48000, Channels: 2}},
+	}
+	prodCodec, consCodec := MatchTranscode(prod, cons)
+	require.Equal(t, prod.Codecs[0], prodCodec)
+	require.Equal(t, cons.Codecs[0], consCodec)
+
+	// backchannel: AAC can't be encoded
+	prod.Direction = core.DirectionSendonly
+	cons.Direction = core.DirectionRecvonly
+	prodCodec, _ = MatchTranscode(prod, cons)
+	require.Nil(t, prodCodec)
+
+	// backchannel: browser Opus may be in SILK mode, so it is left for FFmpeg
+	prod.Codecs = []*core.Codec{{Name: core.CodecPCMA, ClockRate: 8000}}
+	prodCodec, _ = MatchTranscode(prod, cons)
+	require.Nil(t, prodCodec)
+}
+
+func TestTranscodeAudio(t *testing.T) {
+	src := &core.Codec{Name: core.CodecPCML, ClockRate: 16000, Channels: 1}
+	opus := OutputCodec(&core.Codec{Name: core.CodecOpus}, src)
+	pcma := OutputCodec(&core.Codec{Name: core.CodecPCMA}, src)
+	require.Equal(t, uint32(8000), pcma.ClockRate)
+
+	var frames int
+	toOpus, err := TranscodeAudio(opus, src, func(packet *rtp.Packet) {
+		require.Equal(t, uint32(frames), packet.Timestamp)
+		require.Equal(t, byte(31), packet.Payload[0]>>3)
+		frames += 960
+	})
+	require.Nil(t, err)
+
+	var packets []*rtp.Packet
+	toPCMA, err := TranscodeAudio(pcma, src, func(packet *rtp.Packet) {
+		packets = append(packets, packet)
+	})
+	require.Nil(t, err)
+
+	// 1 second of 440 Hz tone in 20 ms packets
+	writer := newWriter(src.Name)
+	for i := 0; i < 50; i++ {
+		samples := make([]int16, 320)
+		for j := range samples {
+			samples[j] = int16(8000 * math.Sin(2*math.Pi*440*float64(i*320+j)/16000))
+		}
+		packet := &rtp.Packet{Header: rtp.Header{Timestamp: uint32(i * 320)}, Payload: writer(samples)}
+		toOpus(packet)
+		toPCMA(packet)
+	}
+
+	// resampler and encoder keep the tail for the next packet
+	require.Equal(t, 49*960, frames)
+	require.Len(t, packets, 50)
+
+	// check tone level in the last packet
+	var sum float64
+	samples := newReader(core.CodecPCMA)(packets[49].Payload)
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	require.InDelta(t, 8000/math.Sqrt2, rms, 500)
+}
+
+func TestTranscodeG722(t *testing.T) {
+	src := &core.Codec{Name: core.CodecPCML, ClockRate: 16000, Channels: 1}
+	g722 := OutputCodec(&core.Codec{Name: core.CodecG722}, src)
+	require.Equal(t, uint32(8000), g722.ClockRate)
+
+	var packets []*rtp.Packet
+	toG722, err := TranscodeAudio(g722, src, func(packet *rtp.Packet) {
+		packets = append(packets, packet)
+	})
+	require.Nil(t, err)
+
+	// 16000 Hz samples without resampling, 1 byte per 2 samples and per 1 RTP tick
+	writer := newWriter(src.Name)
+	for i := 0; i < 3; i++ {
+		toG722(&rtp.Packet{Payload: writer(make([]int16, 320))})
+	}
+
+	require.Len(t, packets, 3)
+	require.Len(t, packets[2].Payload, 160)
+	require.Equal(t, uint32(320), packets[2].Timestamp)
+
+	// source gap larger than one frame moves output timestamp with the source
+	toG722(&rtp.Packet{Header: rtp.Header{Timestamp: 6400}, Payload: writer(make([]int16, 320))})
+	require.Equal(t, uint32(3200), packets[3].Timestamp)
+}
+
+func TestResampler(t *testing.T) {
+	resample := Resampler(48000, 8000, 2)
+	var n int
+	for i := 0; i < 10; i++ {
+		n += len(resample(make([]float32, 2*1024)))
+	}
+	require.InDelta(t, 2*10*1024/6, n, 2)
+}
@@ -0,0 +1,271 @@
+package probe
+
+import (
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+)
+
+// Analyzer - passive consumer, that measures stream health for each track
+type Analyzer struct {
+	core.Connection
+	tracks []*Track
+}
+
+func NewAnalyzer(query url.Values) *Analyzer {
+	medias := core.ParseQuery(query)
+	if medias == nil {
+		// all tracks with any codecs by default
+		medias = core.ParseQuery(url.Values{"video": {""}, "audio": {""}})
+	}
+
+	return &Analyzer{
+		Connection: core.Connection{
+			ID:         core.NewID(),
+			FormatName: "analyzer",
+			Medias:     medias,
+		},
+	}
+}
+
+func (a *Analyzer) AddTrack(media *core.Media, codec *core.Codec, track *core.Receiver) error {
+	t := NewTrack(track.Codec)
+	sender := core.NewSender(media, track.Codec)
+	sender.Handler = func(packet *core.Packet) {
+		a.Send += len(packet.Payload)
+		t.Input(packet)
+	}
+	sender.HandleRTP(track)
+	a.Senders = append(a.Senders, sender)
+	a.tracks = append(a.tracks, t)
+	return nil
+}
+
+func (a *Analyzer) Start() error {
+	return nil
+}
+
+func (a *Analyzer) Stats() []*Stats {
+	stats := make([]*Stats, 0, len(a.tracks))
+	for _, track := range a.tracks {
+		stats = append(stats, track.Stats())
+	}
+	return stats
+}
+
+func (a *Analyzer) MarshalJSON() ([]byte, error) {
+	v := struct {
+		*core.Connection
+		Tracks []*Stats `json:"tracks"`
+	}{
+		Connection: &a.Connection,
+		Tracks:     a.Stats(),
+	}
+	return json.Marshal(v)
+}
+
+type Stats struct {
+	Codec    string  `json:"codec"`
+	Duration float64 `json:"duration"` // seconds from the first packet
+	Idle     float64 `json:"idle"`     // seconds from the last packet
+
+	Bytes   int `json:"bytes"`
+	Packets int `json:"packets"`
+	Bitrate int `json:"bitrate"` // bits per second
+
+	Frames           int     `json:"frames,omitempty"`
+	FPS              float64 `json:"fps,omitempty"`
+	Keyframes        int     `json:"keyframes,omitempty"`
+	GOP              int     `json:"gop,omitempty"`               // frames between last two keyframes
+	KeyframeInterval float64 `json:"keyframe_interval,omitempty"` // seconds between last two keyframes
+
+	Width             uint16 `json:"width,omitempty"`
+	Height            uint16 `json:"height,omitempty"`
+	ResolutionChanges int    `json:"resolution_changes,omitempty"`
+
+	TimestampGaps int `json:"timestamp_gaps,omitempty"` // jumps back or more than 1 second forward
+	PacketsLost   int `json:"packets_lost,omitempty"`   // only for RTP sources
+}
+
+// Track - health counters for one track
+type Track struct {
+	codec *core.Codec
+	frame core.HandlerFunc // handler for H264/H265 frames in AVCC format
+
+	first, last time.Time
+
+	bytes, packets   int
+	frames           int
+	keyframes        int
+	gop              int
+	keyframeInterval uint32
+
+	width, height     uint16
+	resolutionChanges int
+
+	timestampGaps int
+	packetsLost   int
+
+	lastSeq      uint16
+	lastTS       uint32
+	lastKeyFrame int
+	lastKeyTS    uint32
+
+	mu sync.Mutex
+}
+
+func NewTrack(codec *core.Codec) *Track {
+	t := &Track{codec: codec}
+
+	switch codec.Name {
+	case core.CodecH264:
+		t.frame = t.frameH264
+		if codec.IsRTP() {
+			t.frame = h264.RTPDepay(codec, t.frame)
+		}
+	case core.CodecH265:
+		t.frame = t.frameH265
+		if codec.IsRTP() {
+			t.frame = h265.RTPDepay(codec, t.frame)
+		}
+	}
+
+	return t
+}
+
+func (t *Track) Input(packet *core.Packet) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+
+	if t.packets > 0 {
+		if t.codec.IsRTP() {
+			if n := int16(packet.SequenceNumber - t.lastSeq); n > 1 {
+				t.packetsLost += int(n) - 1
+			}
+		}
+
+		if delta := int32(packet.Timestamp - t.lastTS); delta < 0 || delta > int32(t.codec.ClockRate) {
+			t.timestampGaps++
+		}
+	} else {
+		t.first = now
+	}
+
+	newFrame := t.packets == 0 || packet.Timestamp != t.lastTS || !t.codec.IsRTP()
+
+	t.last = now
+	t.bytes += len(packet.Payload)
+	t.packets++
+	t.lastSeq = packet.SequenceNumber
+	t.lastTS = packet.Timestamp
+
+	if t.frame != nil {
+		t.frame(packet)
+	} else if newFrame && t.codec.IsVideo() {
+		t.frames++
+	}
+}
+
+func (t *Track) Stats() *Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := &Stats{
+		Codec:             t.codec.String(),
+		Bytes:             t.bytes,
+		Packets:           t.packets,
+		Frames:            t.frames,
+		Keyframes:         t.keyframes,
+		GOP:               t.gop,
+		Width:             t.width,
+		Height:            t.height,
+		ResolutionChanges: t.resolutionChanges,
+		TimestampGaps:     t.timestampGaps,
+		PacketsLost:       t.packetsLost,
+	}
+
+	if t.packets == 0 {
+		return s
+	}
+
+	s.Duration = time.Since(t.first).Seconds()
+	s.Idle = time.Since(t.last).Seconds()
+
+	if elapsed := t.last.Sub(t.first).Seconds(); elapsed > 0 {
+		s.Bitrate = int(float64(8*t.bytes) / elapsed)
+		if t.frames > 1 {
+			s.FPS = float64(t.frames-1) / elapsed
+		}
+	}
+
+	if t.keyframeInterval != 0 && t.codec.ClockRate != 0 {
+		s.KeyframeInterval = float64(t.keyframeInterval) / float64(t.codec.ClockRate)
+	}
+
+	return s
+}
+
+// LastPacket - time of the last received packet, zero if there were no packets
+func (t *Track) LastPacket() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
+
+func (t *Track) frameH264(packet *core.Packet) {
+	t.onFrame(packet, h264.IsKeyframe(packet.Payload))
+
+	for _, nalu := range h264.SplitNALU(packet.Payload) {
+		if len(nalu) > 5 && h264.NALUType(nalu) == h264.NALUTypeSPS {
+			if sps := h264.DecodeSPS(nalu[4:]); sps != nil {
+				t.onResolution(sps.Width(), sps.Height())
+			}
+		}
+	}
+}
+
+func (t *Track) frameH265(packet *core.Packet) {
+	t.onFrame(packet, h265.IsKeyframe(packet.Payload))
+
+	for _, nalu := range h264.SplitNALU(packet.Payload) {
+		if len(nalu) > 6 && h265.NALUType(nalu) == h265.NALUTypeSPS {
+			if sps := h265.DecodeSPS(nalu[4:]); sps != nil {
+				t.onResolution(sps.Width(), sps.Height())
+			}
+		}
+	}
+}
+
+func (t *Track) onFrame(packet *core.Packet, keyframe bool) {
+	t.frames++
+
+	if !keyframe {
+		return
+	}
+
+	if t.keyframes > 0 {
+		t.gop = t.frames - t.lastKeyFrame
+		t.keyframeInterval = packet.Timestamp - t.lastKeyTS
+	}
+
+	t.keyframes++
+	t.lastKeyFrame = t.frames
+	t.lastKeyTS = packet.Timestamp
+}
+
+func (t *Track) onResolution(width, height uint16) {
+	if width == 0 || height == 0 {
+		return
+	}
+	if t.width != 0 && (t.width != width || t.height != height) {
+		t.resolutionChanges++
+	}
+	t.width, t.height = width, height
+}
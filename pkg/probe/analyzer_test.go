@@ -0,0 +1,51 @@
+package probe
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackH264(t *testing.T) {
+	sps1, _ := base64.StdEncoding.DecodeString("R00AKZmgHgCJ+WEAAAMD6AAATiCE") // 1920x1080
+	sps2, _ := base64.StdEncoding.DecodeString("Z01AMqaAKAC1kAA=")             // 2560x1440
+	iframe := []byte{h264.NALUTypeIFrame, 0}
+	pframe := []byte{h264.NALUTypePFrame, 0}
+
+	track := NewTrack(&core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: core.PayloadTypeRAW})
+
+	var ts uint32
+	for i := 0; i < 20; i++ {
+		var payload []byte
+		switch i {
+		case 0:
+			payload = h264.JoinNALU(sps1, iframe)
+		case 10:
+			payload = h264.JoinNALU(sps2, iframe)
+		default:
+			payload = h264.JoinNALU(pframe)
+		}
+
+		if i == 15 {
+			ts += 5 * 90000 // timestamp gap
+		}
+
+		track.Input(&core.Packet{Payload: payload, Header: rtp.Header{Timestamp: ts}})
+		ts += 3000
+	}
+
+	stats := track.Stats()
+	require.Equal(t, 20, stats.Frames)
+	require.Equal(t, 2, stats.Keyframes)
+	require.Equal(t, 10, stats.GOP)
+	require.Equal(t, float64(1)/3, stats.KeyframeInterval)
+	require.Equal(t, uint16(2560), stats.Width)
+	require.Equal(t, uint16(1440), stats.Height)
+	require.Equal(t, 1, stats.ResolutionChanges)
+	require.Equal(t, 1, stats.TimestampGaps)
+	require.Equal(t, 0, stats.PacketsLost)
+}
@@ -26,6 +26,8 @@ type Conn struct {
 	Stream string
 	Intent string
 
+	FourCcList []string // Enhanced RTMP codecs from the client connect command
+
 	rdPacketSize uint32
 	wrPacketSize uint32
 
@@ -148,6 +148,12 @@ func (c *Conn) acceptCommand(b []byte) error {
 		if len(items) == 3 {
 			if v, ok := items[2].(map[string]any); ok {
 				c.App, _ = v["app"].(string)
+				list, _ := v["fourCcList"].([]any)
+				for _, item := range list {
+					if s, ok := item.(string); ok {
+						c.FourCcList = append(c.FourCcList, s)
+					}
+				}
 			}
 		}
 
@@ -177,6 +183,11 @@ func (c *Conn) acceptCommand(b []byte) error {
 		c.Intent = cmd
 		c.streamID = 1
 
+		// stream key, can be with query: key?token=xxx
+		if len(items) >= 4 {
+			c.Stream, _ = items[3].(string)
+		}
+
 	default:
 		println("rtmp: unknown command: " + cmd)
 	}
@@ -196,6 +207,19 @@ func (c *Conn) WriteStart() error {
 	return c.writeMessage(3, TypeCommand, 0, payload)
 }
 
+const (
+	StatusPublishBadName = "NetStream.Publish.BadName"
+	StatusPlayNotFound   = "NetStream.Play.StreamNotFound"
+)
+
+// WriteError - reject publish or play command with the status code
+func (c *Conn) WriteError(code, description string) error {
+	payload := amf.EncodeItems("onStatus", 0, nil, map[string]any{
+		"level": "error", "code": code, "description": description,
+	})
+	return c.writeMessage(3, TypeCommand, 0, payload)
+}
+
 func nowMS() uint32 {
 	return uint32(time.Now().UnixNano() / int64(time.Millisecond))
 }
@@ -23,13 +23,14 @@ type Conn struct {
 
 	// public
 
-	Backchannel bool
-	Media       string
-	OnClose     func() error
-	PacketSize  uint16
-	SessionName string
-	Timeout     int
-	Transport   string // custom transport support, ex. RTSP over WebSocket
+	Backchannel  bool
+	Media        string
+	OnClose      func() error
+	PacketSize   uint16
+	RejectStatus string // custom DESCRIBE status when no senders, ex. "453 Not Enough Bandwidth"
+	SessionName  string
+	Timeout      int
+	Transport    string // custom transport support, ex. RTSP over WebSocket
 
 	URL *url.URL
 
@@ -4,11 +4,14 @@ import (
 	"time"
 
 	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
 	"github.com/AlexxIT/go2rtc/pkg/h265"
 	"github.com/AlexxIT/go2rtc/pkg/mjpeg"
 	"github.com/AlexxIT/go2rtc/pkg/pcm"
+	"github.com/AlexxIT/go2rtc/pkg/vp8"
+	"github.com/AlexxIT/go2rtc/pkg/vp9"
 	"github.com/pion/rtp"
 )
 
@@ -156,8 +159,16 @@ func (c *Conn) packetWriter(codec *core.Codec, channel, payloadType uint8) core.
 			handlerFunc = h265.RTPPay(c.PacketSize, handlerFunc)
 		case core.CodecAAC:
 			handlerFunc = aac.RTPPay(handlerFunc)
+		case core.CodecELD:
+			handlerFunc = aac.RTPPayFrames(aac.FrameLength(codec), handlerFunc)
 		case core.CodecJPEG:
 			handlerFunc = mjpeg.RTPPay(handlerFunc)
+		case core.CodecVP8:
+			handlerFunc = vp8.RTPPay(c.PacketSize, handlerFunc)
+		case core.CodecVP9:
+			handlerFunc = vp9.RTPPay(c.PacketSize, handlerFunc)
+		case core.CodecAV1:
+			handlerFunc = av1.RTPPay(c.PacketSize, handlerFunc)
 		}
 	} else if codec.Name == core.CodecPCML {
 		handlerFunc = pcm.LittleToBig(handlerFunc)
@@ -47,7 +47,8 @@ func (c *Conn) Accept() error {
 
 		c.Fire(req)
 
-		if valid, empty := c.auth.Validate(req); !valid {
+		valid, empty := c.auth.Validate(req)
+		if !valid {
 			res := &tcp.Response{
 				Status:  "401 Unauthorized",
 				Header:  map[string][]string{"Www-Authenticate": {`Basic realm="go2rtc"`}},
@@ -64,6 +65,10 @@ func (c *Conn) Accept() error {
 			return FailedAuth
 		}
 
+		if c.auth != nil {
+			c.User = c.auth.Username()
+		}
+
 		// Receiver: OPTIONS > DESCRIBE > SETUP... > PLAY > TEARDOWN
 		// Sender: OPTIONS > ANNOUNCE > SETUP... > RECORD > TEARDOWN
 		switch req.Method {
@@ -114,6 +119,9 @@ func (c *Conn) Accept() error {
 					Status:  "404 Not Found",
 					Request: req,
 				}
+				if c.RejectStatus != "" {
+					res.Status = c.RejectStatus
+				}
 				return c.WriteResponse(res)
 			}
 
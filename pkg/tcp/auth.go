@@ -85,6 +85,10 @@ func (a *Auth) Write(req *Request) {
 	}
 }
 
+func (a *Auth) Username() string {
+	return a.user
+}
+
 func (a *Auth) Validate(req *Request) (valid, empty bool) {
 	if a == nil {
 		return true, true
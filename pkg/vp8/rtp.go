@@ -0,0 +1,74 @@
+package vp8
+
+import (
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+)
+
+// RTPPacketVersionVP8 - mark packets with full frames inside
+const RTPPacketVersionVP8 = 0
+
+func RTPDepay(handler core.HandlerFunc) core.HandlerFunc {
+	depack := &codecs.VP8Packet{}
+
+	buf := make([]byte, 0, 512*1024) // 512K
+
+	return func(packet *rtp.Packet) {
+		payload, err := depack.Unmarshal(packet.Payload)
+		if err != nil {
+			return
+		}
+
+		// start of new frame, drop unfinished one (lost packet with marker)
+		// and protect from memory overflow
+		if depack.S == 1 && depack.PID == 0 || len(buf) > 5*1024*1024 {
+			buf = buf[:0]
+		}
+
+		buf = append(buf, payload...)
+
+		if !packet.Marker {
+			return
+		}
+
+		clone := *packet
+		clone.Version = RTPPacketVersionVP8
+		clone.Payload = buf
+		handler(&clone)
+
+		buf = buf[:0]
+	}
+}
+
+func RTPPay(mtu uint16, handler core.HandlerFunc) core.HandlerFunc {
+	if mtu == 0 {
+		mtu = 1472
+	}
+
+	payloader := &codecs.VP8Payloader{EnablePictureID: true}
+	sequencer := rtp.NewRandomSequencer()
+	mtu -= 12 // rtp.Header size
+
+	return func(packet *rtp.Packet) {
+		if packet.Version != RTPPacketVersionVP8 {
+			handler(packet)
+			return
+		}
+
+		payloads := payloader.Payload(mtu, packet.Payload)
+		last := len(payloads) - 1
+		for i, payload := range payloads {
+			clone := rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         i == last,
+					SequenceNumber: sequencer.NextSequenceNumber(),
+					Timestamp:      packet.Timestamp,
+				},
+				Payload: payload,
+			}
+			handler(&clone)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package vp8
+
+import "encoding/binary"
+
+// IsKeyframe - check frame type bit from VP8 frame tag
+// https://datatracker.ietf.org/doc/html/rfc6386#section-9.1
+func IsKeyframe(b []byte) bool {
+	return len(b) >= 3 && b[0]&1 == 0
+}
+
+// DecodeSize - get width and height from keyframe header
+func DecodeSize(b []byte) (width, height uint16) {
+	if !IsKeyframe(b) || len(b) < 10 {
+		return
+	}
+	// start code: 9D 01 2A
+	if b[3] != 0x9D || b[4] != 0x01 || b[5] != 0x2A {
+		return
+	}
+	width = binary.LittleEndian.Uint16(b[6:]) & 0x3FFF
+	height = binary.LittleEndian.Uint16(b[8:]) & 0x3FFF
+	return
+}
+
+// EncodeConfig - VP Codec Configuration Box (vpcC) with profile (version) from keyframe,
+// VP8 is always 8 bit 4:2:0 and level is not defined for it
+// https://www.webmproject.org/vp9/mp4/
+func EncodeConfig(keyframe []byte) []byte {
+	var profile byte
+	if len(keyframe) > 0 {
+		profile = (keyframe[0] >> 1) & 0b111
+	}
+	return []byte{
+		1,       // version
+		0, 0, 0, // flags
+		profile, // profile
+		10,      // level 1
+		0x82,    // bitDepth=8 (4 bit), chromaSubsampling=1 (3 bit), videoFullRangeFlag=0 (1 bit)
+		2,       // colourPrimaries = unspecified
+		2,       // transferCharacteristics = unspecified
+		2,       // matrixCoefficients = unspecified
+		0, 0,    // codecIntializationDataSize (must be 0 for VP8 and VP9)
+	}
+}
@@ -0,0 +1,76 @@
+package vp9
+
+import (
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+)
+
+// RTPPacketVersionVP9 - mark packets with full frames inside
+const RTPPacketVersionVP9 = 0
+
+func RTPDepay(handler core.HandlerFunc) core.HandlerFunc {
+	depack := &codecs.VP9Packet{}
+
+	buf := make([]byte, 0, 512*1024) // 512K
+
+	return func(packet *rtp.Packet) {
+		payload, err := depack.Unmarshal(packet.Payload)
+		if err != nil {
+			return
+		}
+
+		// start of new picture, drop unfinished one (lost packet with marker)
+		// and protect from memory overflow
+		if depack.B && depack.SID == 0 || len(buf) > 5*1024*1024 {
+			buf = buf[:0]
+		}
+
+		// spatial layers (SVC) are joined in one superframe without index,
+		// this is OK for the most common single layer streams
+		buf = append(buf, payload...)
+
+		if !packet.Marker {
+			return
+		}
+
+		clone := *packet
+		clone.Version = RTPPacketVersionVP9
+		clone.Payload = buf
+		handler(&clone)
+
+		buf = buf[:0]
+	}
+}
+
+func RTPPay(mtu uint16, handler core.HandlerFunc) core.HandlerFunc {
+	if mtu == 0 {
+		mtu = 1472
+	}
+
+	payloader := &codecs.VP9Payloader{}
+	sequencer := rtp.NewRandomSequencer()
+	mtu -= 12 // rtp.Header size
+
+	return func(packet *rtp.Packet) {
+		if packet.Version != RTPPacketVersionVP9 {
+			handler(packet)
+			return
+		}
+
+		payloads := payloader.Payload(mtu, packet.Payload)
+		last := len(payloads) - 1
+		for i, payload := range payloads {
+			clone := rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         i == last,
+					SequenceNumber: sequencer.NextSequenceNumber(),
+					Timestamp:      packet.Timestamp,
+				},
+				Payload: payload,
+			}
+			handler(&clone)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package vp9
+
+import "github.com/pion/rtp/codecs/vp9"
+
+// IsKeyframe - check frame type from VP9 uncompressed header
+func IsKeyframe(b []byte) bool {
+	var header vp9.Header
+	if err := header.Unmarshal(b); err != nil {
+		return false
+	}
+	return !header.ShowExistingFrame && !header.NonKeyFrame
+}
+
+// DecodeSize - get width and height from keyframe header
+func DecodeSize(b []byte) (width, height uint16) {
+	var header vp9.Header
+	if err := header.Unmarshal(b); err != nil {
+		return
+	}
+	return header.Width(), header.Height()
+}
+
+// EncodeConfig - VP Codec Configuration Box (vpcC) for profile 0, 8 bit, 4:2:0
+// https://www.webmproject.org/vp9/mp4/
+func EncodeConfig(profile, level byte) []byte {
+	return encodeConfig(profile, level, 0x82)
+}
+
+// KeyframeConfig - vpcC with profile, level, bit depth and subsampling from keyframe,
+// nil if it is not a keyframe
+func KeyframeConfig(b []byte) []byte {
+	var header vp9.Header
+	if err := header.Unmarshal(b); err != nil || header.NonKeyFrame || header.ColorConfig == nil {
+		return nil
+	}
+
+	color := header.ColorConfig
+
+	chroma := byte(1) // 4:2:0
+	switch {
+	case color.SubsamplingX && !color.SubsamplingY:
+		chroma = 2 // 4:2:2
+	case !color.SubsamplingX && !color.SubsamplingY:
+		chroma = 3 // 4:4:4
+	}
+
+	flags := color.BitDepth<<4 | chroma<<1
+	if color.ColorRange {
+		flags |= 1
+	}
+
+	return encodeConfig(header.Profile, level(header.Width(), header.Height()), flags)
+}
+
+func encodeConfig(profile, level, flags byte) []byte {
+	return []byte{
+		1,       // version
+		0, 0, 0, // flags
+		profile, // profile
+		level,   // level
+		flags,   // bitDepth (4 bit), chromaSubsampling (3 bit), videoFullRangeFlag (1 bit)
+		1,       // colourPrimaries = BT.709
+		1,       // transferCharacteristics = BT.709
+		1,       // matrixCoefficients = BT.709
+		0, 0,    // codecIntializationDataSize (must be 0 for VP8 and VP9)
+	}
+}
+
+// level - minimal level for picture size, without bitrate limits
+// https://www.webmproject.org/vp9/levels/
+func level(width, height uint16) byte {
+	size := int(width) * int(height)
+	for _, l := range []struct {
+		size  int
+		level byte
+	}{
+		{36864, 10}, {73728, 11}, {122880, 20}, {245760, 21},
+		{552960, 30}, {983040, 31}, {2228224, 40}, {8912896, 50},
+	} {
+		if size <= l.size {
+			return l.level
+		}
+	}
+	return 60
+}
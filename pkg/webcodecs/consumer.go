@@ -0,0 +1,328 @@
+package webcodecs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/av1"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+	"github.com/AlexxIT/go2rtc/pkg/mp4"
+	"github.com/pion/rtp"
+)
+
+// Consumer - raw frames for the browser WebCodecs API, H264 and H265 in AVCC format
+// with the decoder config (avcC, hvcC) before the first keyframe and on parameter sets change, AV1 in OBU format,
+// AAC with the AudioSpecificConfig and Opus packets as is
+type Consumer struct {
+	core.Connection
+	wr    *core.WriteBuffer
+	start time.Time
+	mu    sync.Mutex
+	seq   uint16
+}
+
+// NewConsumer - with selected video, audio and microphone (backchannel) medias
+func NewConsumer(video, audio, microphone bool) *Consumer {
+	var medias []*core.Media
+	if video {
+		medias = append(medias, &core.Media{
+			Kind:      core.KindVideo,
+			Direction: core.DirectionSendonly,
+			Codecs: []*core.Codec{
+				{Name: core.CodecH264},
+				{Name: core.CodecH265},
+				{Name: core.CodecAV1},
+			},
+		})
+	}
+	if audio {
+		medias = append(medias, &core.Media{
+			Kind:      core.KindAudio,
+			Direction: core.DirectionSendonly,
+			Codecs: []*core.Codec{
+				{Name: core.CodecOpus},
+				{Name: core.CodecAAC},
+			},
+		})
+	}
+	if microphone {
+		medias = append(medias, &core.Media{
+			Kind:      core.KindAudio,
+			Direction: core.DirectionRecvonly,
+			Codecs: []*core.Codec{
+				{Name: core.CodecOpus, ClockRate: 48000, Channels: 2},
+				{Name: core.CodecPCMA, ClockRate: 8000},
+				{Name: core.CodecPCMU, ClockRate: 8000},
+			},
+		})
+	}
+
+	wr := core.NewWriteBuffer(nil)
+	return &Consumer{
+		Connection: core.Connection{
+			ID:         core.NewID(),
+			FormatName: "webcodecs",
+			Medias:     medias,
+			Transport:  wr,
+		},
+		wr:    wr,
+		start: time.Now(),
+	}
+}
+
+// Track - info for VideoDecoder and AudioDecoder configure, codec in WebCodecs format
+type Track struct {
+	ID         byte   `json:"id"`
+	Kind       string `json:"kind"`
+	Codec      string `json:"codec"`
+	SampleRate uint32 `json:"sample_rate,omitempty"`
+	Channels   uint8  `json:"channels,omitempty"`
+}
+
+// Tracks - info for sending frames (tracks) and for the microphone (backchannel)
+func (c *Consumer) Tracks() (tracks []*Track, microphone *Track) {
+	for i, sender := range c.Senders {
+		tracks = append(tracks, newTrack(byte(i), sender.Codec))
+	}
+	if len(c.Receivers) > 0 {
+		microphone = newTrack(0, c.Receivers[0].Codec)
+	}
+	return
+}
+
+func newTrack(id byte, codec *core.Codec) *Track {
+	track := &Track{ID: id, Kind: codec.Kind()}
+	switch codec.Name {
+	case core.CodecPCMA:
+		track.Codec = "alaw"
+	case core.CodecPCMU:
+		track.Codec = "ulaw"
+	default:
+		track.Codec = mp4.MimeCodecs([]*core.Codec{codec})
+	}
+	if codec.IsAudio() {
+		track.SampleRate = codec.ClockRate
+		track.Channels = codec.Channels
+	}
+	return track
+}
+
+func (c *Consumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiver) error {
+	trackID := byte(len(c.Senders))
+
+	codec := track.Codec
+	sender := core.NewSender(media, codec)
+
+	var ts int64 // timestamp in clock rate units, from the first packet
+	var last uint32
+	var first = true
+	var configured bool
+	var config []byte // last sent decoder config
+
+	sender.Handler = func(packet *rtp.Packet) {
+		var flags byte
+
+		if codec.IsVideo() {
+			if mp4.IsKeyframe(codec.Name, packet.Payload) {
+				flags = FlagKeyframe
+			} else if !configured {
+				return // wait first keyframe
+			}
+		} else {
+			flags = FlagKeyframe
+		}
+
+		pts := core.GetPTS(packet)
+		if first {
+			// start offset for audio and video sync
+			ts = int64(time.Since(c.start)) * int64(codec.ClockRate) / int64(time.Second)
+			first = false
+		} else {
+			ts += int64(int32(pts - last))
+		}
+		last = pts
+
+		timestamp := ts * 1_000_000 / int64(codec.ClockRate)
+
+		c.mu.Lock()
+		if !configured {
+			if config = decoderConfig(codec, packet.Payload); config != nil {
+				c.write(trackID, FlagConfig, timestamp, config)
+			}
+			configured = true
+		} else if codec.IsVideo() && flags == FlagKeyframe {
+			// resolution or profile may change on the fly, ex. camera settings
+			if b := keyframeConfig(codec, packet.Payload); b != nil && !bytes.Equal(b, config) {
+				c.write(trackID, FlagConfig, timestamp, b)
+				config = b
+			}
+		}
+		c.write(trackID, flags, timestamp, packet.Payload)
+		c.mu.Unlock()
+	}
+
+	switch codec.Name {
+	case core.CodecH264:
+		if codec.IsRTP() {
+			sender.Handler = h264.RTPDepay(codec, sender.Handler)
+		} else {
+			sender.Handler = h264.RepairAVCC(codec, sender.Handler)
+		}
+	case core.CodecH265:
+		if codec.IsRTP() {
+			sender.Handler = h265.RTPDepay(codec, sender.Handler)
+		} else {
+			sender.Handler = h265.RepairAVCC(codec, sender.Handler)
+		}
+	case core.CodecAV1:
+		if codec.IsRTP() {
+			sender.Handler = av1.RTPDepay(sender.Handler)
+		}
+	case core.CodecAAC:
+		if codec.IsRTP() {
+			sender.Handler = aac.RTPDepay(sender.Handler)
+		}
+	case core.CodecOpus: // no changes
+	default:
+		return errors.New("webcodecs: unsupported codec: " + codec.String())
+	}
+
+	sender.HandleRTP(track)
+	c.Senders = append(c.Senders, sender)
+	return nil
+}
+
+const (
+	FlagKeyframe = 0b01
+	FlagConfig   = 0b10 // payload is decoder config (description)
+
+	headerSize = 4 + 1 + 1 + 8
+)
+
+// write - frame with size (4 bytes), track ID (1 byte), flags (1 byte),
+// timestamp in microseconds (8 bytes) and payload
+func (c *Consumer) write(trackID, flags byte, timestamp int64, payload []byte) {
+	b := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(b, uint32(len(b)))
+	b[4] = trackID
+	b[5] = flags
+	binary.BigEndian.PutUint64(b[6:], uint64(timestamp))
+	copy(b[headerSize:], payload)
+
+	if n, err := c.wr.Write(b); err == nil {
+		c.Send += n
+	}
+}
+
+func (c *Consumer) WriteTo(wr io.Writer) (int64, error) {
+	return c.wr.WriteTo(wr)
+}
+
+func (c *Consumer) GetTrack(media *core.Media, codec *core.Codec) (*core.Receiver, error) {
+	return c.Connection.GetTrack(media, codec)
+}
+
+func (c *Consumer) Start() error {
+	return nil
+}
+
+// WriteBackchannel - microphone frame from the client in the same format as output frames,
+// encoded with the codec from the microphone track
+func (c *Consumer) WriteBackchannel(b []byte) error {
+	if len(b) < headerSize || int(binary.BigEndian.Uint32(b)) != len(b) {
+		return errors.New("webcodecs: wrong frame")
+	}
+
+	if len(c.Receivers) == 0 {
+		return errors.New("webcodecs: no backchannel")
+	}
+
+	receiver := c.Receivers[0]
+	timestamp := binary.BigEndian.Uint64(b[6:])
+
+	c.seq++
+
+	pkt := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         true,
+			SequenceNumber: c.seq,
+			Timestamp:      uint32(timestamp * uint64(receiver.Codec.ClockRate) / 1_000_000),
+		},
+		Payload: b[headerSize:],
+	}
+	receiver.WriteRTP(pkt)
+	c.Recv += len(b)
+
+	return nil
+}
+
+// decoderConfig - description for the WebCodecs decoder, nil if not needed
+func decoderConfig(codec *core.Codec, payload []byte) []byte {
+	// parameter sets from the keyframe first, because they may differ from SDP
+	if b := keyframeConfig(codec, payload); b != nil {
+		return b
+	}
+
+	switch codec.Name {
+	case core.CodecH264:
+		if sps, pps := h264.GetParameterSet(codec.FmtpLine); sps != nil && pps != nil {
+			return h264.EncodeConfig(sps, pps)
+		}
+
+	case core.CodecH265:
+		if vps, sps, pps := h265.GetParameterSet(codec.FmtpLine); vps != nil && sps != nil && pps != nil {
+			return h265.EncodeConfig(vps, sps, pps)
+		}
+
+	case core.CodecAAC:
+		b, _ := hex.DecodeString(core.Between(codec.FmtpLine, "config=", ";"))
+		return b
+	}
+
+	return nil
+}
+
+// keyframeConfig - description from the parameter sets inside the keyframe, nil if there are none
+func keyframeConfig(codec *core.Codec, payload []byte) []byte {
+	switch codec.Name {
+	case core.CodecH264:
+		sps, pps := findNALU(payload, h264.NALUTypeSPS, 0x1F), findNALU(payload, h264.NALUTypePPS, 0x1F)
+		if sps != nil && pps != nil {
+			return h264.EncodeConfig(sps, pps)
+		}
+
+	case core.CodecH265:
+		vps := findNALU(payload, h265.NALUTypeVPS<<1, 0x7E)
+		sps := findNALU(payload, h265.NALUTypeSPS<<1, 0x7E)
+		pps := findNALU(payload, h265.NALUTypePPS<<1, 0x7E)
+		if vps != nil && sps != nil && pps != nil {
+			return h265.EncodeConfig(vps, sps, pps)
+		}
+	}
+
+	return nil
+}
+
+// findNALU - find NAL unit in AVCC payload by the masked first byte
+func findNALU(payload []byte, typ, mask byte) []byte {
+	for len(payload) > 4 {
+		size := 4 + int(binary.BigEndian.Uint32(payload))
+		if size > len(payload) {
+			break
+		}
+		if payload[4]&mask == typ {
+			return payload[4:size]
+		}
+		payload = payload[size:]
+	}
+	return nil
+}
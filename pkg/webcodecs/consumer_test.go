@@ -0,0 +1,88 @@
+package webcodecs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoderConfig(t *testing.T) {
+	codec := &core.Codec{Name: core.CodecAAC, FmtpLine: "streamtype=5;profile-level-id=1;mode=AAC-hbr;config=1210"}
+	require.Equal(t, []byte{0x12, 0x10}, decoderConfig(codec, nil))
+
+	sps := []byte{h264.NALUTypeSPS | 0x60, 0x64, 0x00, 0x29}
+	pps := []byte{h264.NALUTypePPS | 0x60, 0xEE}
+	idr := []byte{h264.NALUTypeIFrame | 0x60, 0x88}
+
+	var payload []byte
+	for _, nalu := range [][]byte{sps, pps, idr} {
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(nalu)))
+		payload = append(payload, nalu...)
+	}
+
+	codec = &core.Codec{Name: core.CodecH264}
+	require.Equal(t, h264.EncodeConfig(sps, pps), decoderConfig(codec, payload))
+}
+
+func TestConfigChange(t *testing.T) {
+	cons := NewConsumer(true, false, false)
+
+	media := cons.GetMedias()[0]
+	codec := &core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: core.PayloadTypeRAW}
+	require.Nil(t, cons.AddTrack(media, codec, core.NewReceiver(media, codec)))
+
+	keyframe := func(profile byte) []byte {
+		var payload []byte
+		for _, nalu := range [][]byte{
+			{h264.NALUTypeSPS | 0x60, profile, 0x00, 0x29},
+			{h264.NALUTypePPS | 0x60, 0xEE},
+			{h264.NALUTypeIFrame | 0x60, 0x88},
+		} {
+			payload = binary.BigEndian.AppendUint32(payload, uint32(len(nalu)))
+			payload = append(payload, nalu...)
+		}
+		return payload
+	}
+
+	// config only before the first keyframe and after the SPS change
+	for _, profile := range []byte{0x64, 0x64, 0x4D} {
+		cons.Senders[0].Handler(&rtp.Packet{Payload: keyframe(profile)})
+	}
+
+	var configs int
+	b := cons.wr.Writer.(*bytes.Buffer).Bytes()
+	for len(b) >= headerSize {
+		if b[5]&FlagConfig != 0 {
+			configs++
+		}
+		b = b[binary.BigEndian.Uint32(b):]
+	}
+	require.Equal(t, 2, configs)
+}
+
+func TestBackchannel(t *testing.T) {
+	cons := NewConsumer(false, false, true)
+
+	media := cons.GetMedias()[0]
+	receiver, err := cons.GetTrack(media, media.Codecs[1]) // PCMA
+	require.Nil(t, err)
+
+	var pkt *rtp.Packet
+	receiver.Input = func(packet *rtp.Packet) {
+		pkt = packet
+	}
+
+	b := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x0F, 0x42, 0x40, 1, 2, 3} // 1 second
+	binary.BigEndian.PutUint32(b, uint32(len(b)))
+
+	require.Nil(t, cons.WriteBackchannel(b))
+	require.Equal(t, uint32(8000), pkt.Timestamp)
+	require.Equal(t, []byte{1, 2, 3}, pkt.Payload)
+
+	require.NotNil(t, cons.WriteBackchannel(b[:10]))
+}
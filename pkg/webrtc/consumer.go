@@ -3,10 +3,13 @@ package webrtc
 import (
 	"errors"
 
+	"github.com/AlexxIT/go2rtc/pkg/av1"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
 	"github.com/AlexxIT/go2rtc/pkg/h265"
 	"github.com/AlexxIT/go2rtc/pkg/pcm"
+	"github.com/AlexxIT/go2rtc/pkg/vp8"
+	"github.com/AlexxIT/go2rtc/pkg/vp9"
 	"github.com/pion/rtp"
 )
 
@@ -63,7 +66,16 @@ func (c *Conn) AddTrack(media *core.Media, codec *core.Codec, track *core.Receiv
 			sender.Handler = h265.RepairAVCC(track.Codec, sender.Handler)
 		}
 
-	case core.CodecPCMA, core.CodecPCMU, core.CodecPCM, core.CodecPCML:
+	case core.CodecVP8:
+		sender.Handler = vp8.RTPPay(1200, sender.Handler)
+
+	case core.CodecVP9:
+		sender.Handler = vp9.RTPPay(1200, sender.Handler)
+
+	case core.CodecAV1:
+		sender.Handler = av1.RTPPay(1200, sender.Handler)
+
+	case core.CodecPCMA, core.CodecPCMU, core.CodecPCM, core.CodecPCML, core.CodecG722:
 		// Fix audio quality https://github.com/AlexxIT/WebRTC/issues/500
 		// should be before ResampleToG711, because it will be called last
 		sender.Handler = pcm.RepackG711(false, sender.Handler)
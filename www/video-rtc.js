@@ -26,6 +26,9 @@ export class VideoRTC extends HTMLElement {
             'avc1.64002A',      // H.264 high 4.2 (Chromecast 3rd Gen)
             'avc1.640033',      // H.264 high 5.1 (Chromecast with Google TV)
             'hvc1.1.6.L153.B0', // H.265 main 5.1 (Chromecast Ultra)
+            'vp09.00.40.08',    // VP9 profile 0 level 4.0
+            'av01.0.08M.08',    // AV1 main level 4.0
+            'vp8',              // VP8
             'mp4a.40.2',        // AAC LC
             'mp4a.40.5',        // AAC HE
             'flac',             // FLAC (PCM compatible)
@@ -185,7 +188,7 @@ export class VideoRTC extends HTMLElement {
     /** @param {Function} isSupported */
     codecs(isSupported) {
         return this.CODECS
-            .filter(codec => this.media.includes(codec.includes('vc1') ? 'video' : 'audio'))
+            .filter(codec => this.media.includes(/vc1|vp0|av01|vp8/.test(codec) ? 'video' : 'audio'))
             .filter(codec => isSupported(`video/mp4; codecs="${codec}"`)).join();
     }
 
@@ -421,26 +424,29 @@ export class VideoRTC extends HTMLElement {
         /** @type {MediaSource} */
         let ms;
 
-        if ('ManagedMediaSource' in window) {
-            const MediaSource = window.ManagedMediaSource;
+        const newMediaSource = onopen => {
+            if ('ManagedMediaSource' in window) {
+                ms = new window.ManagedMediaSource();
+                ms.addEventListener('sourceopen', onopen, {once: true});
 
-            ms = new MediaSource();
-            ms.addEventListener('sourceopen', () => {
-                this.send({type: 'mse', value: this.codecs(MediaSource.isTypeSupported)});
-            }, {once: true});
+                this.video.disableRemotePlayback = true;
+                this.video.srcObject = ms;
+            } else {
+                ms = new MediaSource();
+                ms.addEventListener('sourceopen', () => {
+                    URL.revokeObjectURL(this.video.src);
+                    onopen();
+                }, {once: true});
+
+                this.video.src = URL.createObjectURL(ms);
+                this.video.srcObject = null;
+            }
+        };
 
-            this.video.disableRemotePlayback = true;
-            this.video.srcObject = ms;
-        } else {
-            ms = new MediaSource();
-            ms.addEventListener('sourceopen', () => {
-                URL.revokeObjectURL(this.video.src);
-                this.send({type: 'mse', value: this.codecs(MediaSource.isTypeSupported)});
-            }, {once: true});
-
-            this.video.src = URL.createObjectURL(ms);
-            this.video.srcObject = null;
-        }
+        newMediaSource(() => {
+            const MediaSource = window.ManagedMediaSource || window.MediaSource;
+            this.send({type: 'mse', value: this.codecs(MediaSource.isTypeSupported)});
+        });
 
         this.play();
 
@@ -449,6 +455,22 @@ export class VideoRTC extends HTMLElement {
         this.onmessage['mse'] = msg => {
             if (msg.type !== 'mse') return;
 
+            // server can change codecs (ex. audio-only fallback), this requires new MediaSource
+            if (this.mseCodecs) {
+                this.mseCodecs = '';
+
+                const pending = [];
+                this.ondata = data => pending.push(data);
+
+                newMediaSource(() => {
+                    this.onmessage['mse'](msg);
+                    pending.forEach(data => this.ondata(data));
+                });
+
+                this.play();
+                return;
+            }
+
             this.mseCodecs = msg.value;
 
             const sb = ms.addSourceBuffer(msg.value);
@@ -477,12 +499,20 @@ export class VideoRTC extends HTMLElement {
                     }
                     const gap = end - this.video.currentTime;
                     this.video.playbackRate = gap > 0.1 ? gap : 0.1;
+
+                    // buffer level feedback for server-side latency control
+                    const now = Date.now();
+                    if (now - feedbackTS > 1000) {
+                        feedbackTS = now;
+                        this.send({type: 'mse/buffer', value: {time: this.video.currentTime, buffer: gap}});
+                    }
                     // console.debug('VideoRTC.buffered', gap, this.video.playbackRate, this.video.readyState);
                 }
             });
 
             const buf = new Uint8Array(2 * 1024 * 1024);
             let bufLen = 0;
+            let feedbackTS = 0;
 
             this.ondata = data => {
                 if (sb.updating || bufLen > 0) {